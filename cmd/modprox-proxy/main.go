@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"os"
 
 	"gophers.dev/pkgs/loggy"
@@ -12,6 +13,28 @@ import (
 
 func main() {
 	log := loggy.New("modprox-proxy")
+
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			// e.g. modprox-proxy migrate from.json to.json
+			migrate(log, os.Args[2:])
+			return
+		case "export":
+			// e.g. modprox-proxy export -registered config.json bundle.tar
+			export(log, os.Args[2:])
+			return
+		case "fsck":
+			// e.g. modprox-proxy fsck -repair config.json
+			fsck(log, os.Args[2:])
+			return
+		case "import":
+			// e.g. modprox-proxy import config.json bundle.tar
+			load(log, os.Args[2:])
+			return
+		}
+	}
+
 	log.Infof("--- starting up ---")
 
 	configFilename, err := configutil.GetConfigFilename(os.Args)
@@ -30,3 +53,105 @@ func main() {
 
 	proxy.Start(configuration)
 }
+
+func migrate(log loggy.Logger, args []string) {
+	if len(args) != 2 {
+		log.Errorf("usage: modprox-proxy migrate <from config> <to config>")
+		os.Exit(1)
+	}
+
+	from, err := loadConfig(log, args[0])
+	if err != nil {
+		log.Errorf("failed to migrate: %v", err)
+		os.Exit(1)
+	}
+
+	to, err := loadConfig(log, args[1])
+	if err != nil {
+		log.Errorf("failed to migrate: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("--- migrating modules from %s to %s ---", args[0], args[1])
+	if err := proxy.Migrate(from, to); err != nil {
+		log.Errorf("failed to migrate: %v", err)
+		os.Exit(1)
+	}
+	log.Infof("--- migration complete ---")
+}
+
+func export(log loggy.Logger, args []string) {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	registered := flags.Bool("registered", false, "only export modules listed by the registry")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 2 {
+		log.Errorf("usage: modprox-proxy export [-registered] <config> <bundle>")
+		os.Exit(1)
+	}
+
+	configuration, err := loadConfig(log, flags.Arg(0))
+	if err != nil {
+		log.Errorf("failed to export: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("--- exporting modules to %s ---", flags.Arg(1))
+	if err := proxy.Export(configuration, flags.Arg(1), *registered); err != nil {
+		log.Errorf("failed to export: %v", err)
+		os.Exit(1)
+	}
+	log.Infof("--- export complete ---")
+}
+
+func load(log loggy.Logger, args []string) {
+	if len(args) != 2 {
+		log.Errorf("usage: modprox-proxy import <config> <bundle>")
+		os.Exit(1)
+	}
+
+	configuration, err := loadConfig(log, args[0])
+	if err != nil {
+		log.Errorf("failed to import: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("--- importing modules from %s ---", args[1])
+	if err := proxy.Import(configuration, args[1]); err != nil {
+		log.Errorf("failed to import: %v", err)
+		os.Exit(1)
+	}
+	log.Infof("--- import complete ---")
+}
+
+func fsck(log loggy.Logger, args []string) {
+	flags := flag.NewFlagSet("fsck", flag.ExitOnError)
+	repair := flags.Bool("repair", false, "repair the problems which can be repaired")
+	_ = flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		log.Errorf("usage: modprox-proxy fsck [-repair] <config>")
+		os.Exit(1)
+	}
+
+	configuration, err := loadConfig(log, flags.Arg(0))
+	if err != nil {
+		log.Errorf("failed to fsck: %v", err)
+		os.Exit(1)
+	}
+
+	log.Infof("--- checking index and zips ---")
+	if err := proxy.Fsck(configuration, *repair); err != nil {
+		log.Errorf("failed to fsck: %v", err)
+		os.Exit(1)
+	}
+	log.Infof("--- fsck complete ---")
+}
+
+func loadConfig(log loggy.Logger, filename string) (config.Configuration, error) {
+	log.Infof("loading configuration from: %s", filename)
+
+	var configuration config.Configuration
+	err := configutil.LoadConfig(filename, &configuration)
+	return configuration, err
+}
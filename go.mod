@@ -13,8 +13,9 @@ require (
 	github.com/pkg/errors v0.8.1
 	github.com/stretchr/testify v1.4.0
 	golang.org/x/lint v0.0.0-20190909230951-414d861bb4ac // indirect
+	golang.org/x/mod v0.2.0
 	golang.org/x/sys v0.0.0-20190916202348-b4ddaad3f8a3 // indirect
-	golang.org/x/tools v0.0.0-20190917032747-2dc213d980bc // indirect
+	golang.org/x/tools v0.0.0-20191119224855-298f0cb1881e // indirect
 	google.golang.org/appengine v1.6.2 // indirect
 	gophers.dev/cmds/petrify/v5 v5.2.1
 	gophers.dev/pkgs/atomicfs v0.3.2
@@ -0,0 +1,12 @@
+-- Adds the table of download problems to the database of an existing Proxy,
+-- which was created before download problems were persisted. Safe to run
+-- more than once.
+
+create table if not exists proxy_problems (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  problem text not null, -- JSON of the most recent problem downloading the module
+  primary key(id),
+  unique (source, version)
+) engine=InnoDB default charset=utf8;
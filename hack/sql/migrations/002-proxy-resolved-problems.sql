@@ -0,0 +1,13 @@
+-- Adds the table of resolved download problems to the database of an existing
+-- Proxy, which was created before resolved problems were kept. Safe to run
+-- more than once.
+
+create table if not exists proxy_resolved_problems (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  resolved bigint not null, -- unix nanoseconds of when the problem was resolved
+  problem text not null, -- JSON of the resolved problem
+  primary key(id),
+  index (resolved)
+) engine=InnoDB default charset=utf8;
@@ -0,0 +1,14 @@
+-- Adds the hashes of modules to the index of an existing Proxy, which was
+-- created before hashes were recorded. Unlike the other migrations, this one
+-- alters a table, and fails if run more than once.
+--
+-- Modules already in the index are left with empty hashes, which mean the
+-- hashes are not known yet, rather than that they do not match. The integrity
+-- check (if enabled) verifies those modules against the checksum database and
+-- records their hashes, and until then a content addressed zip store keeps
+-- every zip, since any of them may be referenced by such a module.
+
+alter table proxy_modules_index
+  add column zip_hash varchar(64) not null default '', -- h1 hash of the zip file, e.g. h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=
+  add column go_mod_hash varchar(64) not null default '', -- h1 hash of the go.mod file
+  add index (zip_hash);
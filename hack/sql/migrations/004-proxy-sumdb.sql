@@ -0,0 +1,18 @@
+-- Adds the tables of the checksum database to the database of an existing
+-- Proxy, which was created before the Proxy served a checksum database. Safe
+-- to run more than once.
+
+create table if not exists proxy_sumdb_records (
+  id bigint unsigned not null, -- record number in the checksum database log, starting from 0
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  data text not null, -- go.sum lines of the module
+  primary key(id),
+  unique (source, version)
+) engine=InnoDB default charset=utf8;
+
+create table if not exists proxy_sumdb_hashes (
+  id bigint unsigned not null, -- stored hash index in the checksum database log
+  hash binary(32) not null, -- SHA-256 hash of a node of the log
+  primary key(id)
+) engine=InnoDB default charset=utf8;
@@ -0,0 +1,14 @@
+-- Adds the table of module accesses to the database of an existing Proxy,
+-- which was created before accesses were counted for eviction. Safe to run
+-- more than once.
+
+create table if not exists proxy_module_accesses (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  zip_size bigint not null, -- bytes of the zip of the module, 0 if not yet known
+  last_access bigint not null, -- unix nanoseconds of when the zip was last served
+  access_count bigint not null, -- number of times the zip has been served
+  primary key(id),
+  unique (source, version)
+) engine=InnoDB default charset=utf8;
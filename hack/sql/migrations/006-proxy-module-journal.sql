@@ -0,0 +1,13 @@
+-- Adds the journal of module additions and removals to the database of an
+-- existing Proxy, which was created before modules were ingested through the
+-- journal. Safe to run more than once.
+
+create table if not exists proxy_module_journal (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  kind varchar(16) not null, -- whether the module is being added or removed
+  started bigint not null, -- unix nanoseconds of when the change was begun
+  primary key(id),
+  unique (source, version)
+) engine=InnoDB default charset=utf8;
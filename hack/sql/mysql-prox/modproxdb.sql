@@ -13,6 +13,64 @@ create table proxy_modules_index (
   registry_mod_id int(5) unsigned not null, -- registry serial number of the module
   go_mod_file text not null, -- text of the go.mod file of the module
   version_info text not null, -- JSON of .info pseudo file
+  zip_hash varchar(64) not null default '', -- h1 hash of the zip file, e.g. h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=
+  go_mod_hash varchar(64) not null default '', -- h1 hash of the go.mod file
+  primary key(id),
+  unique (source, version),
+  index (zip_hash)
+) engine=InnoDB default charset=utf8;
+
+create table proxy_problems (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  problem text not null, -- JSON of the most recent problem downloading the module
+  primary key(id),
+  unique (source, version)
+) engine=InnoDB default charset=utf8;
+
+create table proxy_module_accesses (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  zip_size bigint not null, -- bytes of the zip of the module, 0 if not yet known
+  last_access bigint not null, -- unix nanoseconds of when the zip was last served
+  access_count bigint not null, -- number of times the zip has been served
+  primary key(id),
+  unique (source, version)
+) engine=InnoDB default charset=utf8;
+
+create table proxy_module_journal (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  kind varchar(16) not null, -- whether the module is being added or removed
+  started bigint not null, -- unix nanoseconds of when the change was begun
   primary key(id),
   unique (source, version)
 ) engine=InnoDB default charset=utf8;
+
+create table proxy_resolved_problems (
+  id int(5) unsigned not null auto_increment,
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  resolved bigint not null, -- unix nanoseconds of when the problem was resolved
+  problem text not null, -- JSON of the resolved problem
+  primary key(id),
+  index (resolved)
+) engine=InnoDB default charset=utf8;
+
+create table proxy_sumdb_records (
+  id bigint unsigned not null, -- record number in the checksum database log, starting from 0
+  source varchar(256) not null, -- module package, e.g. github.com/pkg/errors
+  version varchar(256) not null, -- module version, e.g. v1.0.0-alpha1
+  data text not null, -- go.sum lines of the module
+  primary key(id),
+  unique (source, version)
+) engine=InnoDB default charset=utf8;
+
+create table proxy_sumdb_hashes (
+  id bigint unsigned not null, -- stored hash index in the checksum database log
+  hash binary(32) not null, -- SHA-256 hash of a node of the log
+  primary key(id)
+) engine=InnoDB default charset=utf8;
@@ -0,0 +1,163 @@
+package checksum
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	clean "github.com/hashicorp/go-cleanhttp"
+	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+)
+
+// ops implements the sumdb.ClientOps used by the sumdb.Client to talk
+// to the checksum database, and to keep track of what it has verified.
+type ops struct {
+	httpClient *http.Client
+	url        string
+	key        string
+	cachePath  string
+	log        loggy.Logger
+
+	lock   sync.Mutex
+	config map[string][]byte // used only when there is no cachePath
+}
+
+func newOps(opts Options, log loggy.Logger) *ops {
+	httpClient := clean.DefaultPooledClient()
+	httpClient.Timeout = opts.Timeout
+
+	return &ops{
+		httpClient: httpClient,
+		url:        strings.TrimSuffix(opts.URL, "/"),
+		key:        opts.Key,
+		cachePath:  opts.CachePath,
+		log:        log,
+		config:     make(map[string][]byte),
+	}
+}
+
+func (o *ops) ReadRemote(path string) ([]byte, error) {
+	response, err := o.httpClient.Get(o.url + path)
+	if err != nil {
+		return nil, err
+	}
+	defer ignore.Drain(response.Body)
+
+	if response.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response from checksum database for %s, code: %d", path, response.StatusCode)
+	}
+
+	return ioutil.ReadAll(response.Body)
+}
+
+func (o *ops) ReadConfig(file string) ([]byte, error) {
+	if file == "key" {
+		return []byte(o.key), nil
+	}
+
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	return o.readConfig(file)
+}
+
+// readConfig returns an empty tree when nothing is known yet, which
+// causes the sumdb.Client to start over from the first tree it sees.
+func (o *ops) readConfig(file string) ([]byte, error) {
+	if o.cachePath == "" {
+		return o.config[file], nil
+	}
+
+	bs, err := ioutil.ReadFile(o.filename("config", file))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return bs, err
+}
+
+func (o *ops) WriteConfig(file string, old, new []byte) error {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	current, err := o.readConfig(file)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(current, old) {
+		return sumdb.ErrWriteConflict
+	}
+
+	if o.cachePath == "" {
+		o.config[file] = new
+		return nil
+	}
+
+	return writeFile(o.filename("config", file), new)
+}
+
+func (o *ops) ReadCache(file string) ([]byte, error) {
+	if o.cachePath == "" {
+		return nil, os.ErrNotExist
+	}
+	return ioutil.ReadFile(o.filename("cache", file))
+}
+
+func (o *ops) WriteCache(file string, data []byte) {
+	if o.cachePath == "" {
+		return
+	}
+
+	if err := writeFile(o.filename("cache", file), data); err != nil {
+		// not fatal, the data will be read from the checksum database again
+		o.log.Warnf("failed to write checksum database cache file %s, %v", file, err)
+	}
+}
+
+func (o *ops) Log(msg string) {
+	o.log.Infof("%s", msg)
+}
+
+func (o *ops) SecurityError(msg string) {
+	// the sumdb.Client returns sumdb.ErrSecurity after calling this, so the
+	// module that triggered it will fail to download (and become a problem)
+	o.log.Errorf("checksum database security error: %s", msg)
+}
+
+func (o *ops) filename(kind, file string) string {
+	return filepath.Join(o.cachePath, kind, filepath.FromSlash(file))
+}
+
+// writeFile writes data to a temporary file, then renames that file into
+// place, so that readers never see a partially written file.
+func writeFile(filename string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), ".tmp-")
+	if err != nil {
+		return err
+	}
+
+	if _, err := tmp.Write(data); err != nil {
+		ignore.Close(tmp)
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+
+	return os.Rename(tmp.Name(), filename)
+}
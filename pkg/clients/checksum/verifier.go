@@ -0,0 +1,168 @@
+package checksum
+
+import (
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i Verifier -s _mock.go
+
+// A Verifier is used to check the hashes of a downloaded module against
+// the hashes recorded for that module in a Go checksum database (sumdb).
+type Verifier interface {
+	// Verify returns an error if the checksum database records different hashes
+	// for the module than the given zip and go.mod hashes, or if the hashes
+	// recorded by the checksum database could not be determined.
+	Verify(mod coordinates.Module, zipHash, modHash string) error
+}
+
+// A Mismatch is the error returned by a Verifier when a downloaded module
+// does not match the hash recorded in the checksum database.
+type Mismatch struct {
+	Module   coordinates.Module
+	File     string // either "zip" or "go.mod"
+	Expected string // as recorded in the checksum database
+	Actual   string // as computed from the download
+}
+
+func (m *Mismatch) Error() string {
+	return "checksum mismatch for " + m.File + " of " + m.Module.String() +
+		", downloaded " + m.Actual + ", checksum database has " + m.Expected
+}
+
+// IsMismatch returns whether the cause of err is a Mismatch.
+func IsMismatch(err error) bool {
+	_, is := errors.Cause(err).(*Mismatch)
+	return is
+}
+
+type Options struct {
+	// URL of the checksum database, e.g. https://sum.golang.org
+	URL string
+
+	// Key is the verifier key of the checksum database, e.g.
+	// sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8
+	Key string
+
+	// Private is a list of module path prefix patterns (in the same format
+	// as GONOSUMDB) for modules that are not verified, because the checksum
+	// database will never know about them.
+	Private []string
+
+	// CachePath is a directory in which the latest signed tree head and the
+	// tiles of the checksum database are cached. If empty, the tree head is
+	// only kept in memory and nothing else is cached.
+	CachePath string
+
+	// Timeout of each request made to the checksum database.
+	Timeout time.Duration
+}
+
+// New creates a Verifier which checks modules against the checksum
+// database described by opts.
+func New(opts Options) (Verifier, error) {
+	if opts.URL == "" {
+		return nil, errors.New("checksum database url must be provided")
+	}
+
+	if opts.Key == "" {
+		return nil, errors.New("checksum database key must be provided")
+	}
+
+	if opts.Timeout <= 0 {
+		// some reasonable default timeout
+		opts.Timeout = 1 * time.Minute
+	}
+
+	log := loggy.New("checksum-verifier")
+	client := sumdb.NewClient(newOps(opts, log))
+	client.SetGONOSUMDB(strings.Join(opts.Private, ","))
+
+	return &verifier{
+		client: client,
+		log:    log,
+	}, nil
+}
+
+// Disabled creates a Verifier which never checks anything, for use when
+// the proxy is not configured to use a checksum database.
+func Disabled() Verifier {
+	return disabled{}
+}
+
+type disabled struct{}
+
+func (disabled) Verify(coordinates.Module, string, string) error {
+	return nil
+}
+
+// Chain creates a Verifier which checks modules with each of verifiers in
+// order, stopping at the first one that fails.
+func Chain(verifiers ...Verifier) Verifier {
+	return chain(verifiers)
+}
+
+type chain []Verifier
+
+func (c chain) Verify(mod coordinates.Module, zipHash, modHash string) error {
+	for _, v := range c {
+		if err := v.Verify(mod, zipHash, modHash); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type verifier struct {
+	client *sumdb.Client
+	log    loggy.Logger
+}
+
+func (v *verifier) Verify(mod coordinates.Module, zipHash, modHash string) error {
+	if err := v.verify(mod, mod.Version, "zip", zipHash); err != nil {
+		return err
+	}
+	return v.verify(mod, mod.Version+"/go.mod", "go.mod", modHash)
+}
+
+func (v *verifier) verify(mod coordinates.Module, version, file, actual string) error {
+	lines, err := v.client.Lookup(mod.Source, version)
+	if err == sumdb.ErrGONOSUMDB {
+		v.log.Tracef("not verifying %s of private module %s", file, mod)
+		return nil
+	}
+	if err != nil {
+		return errors.Wrapf(err, "failed to lookup %s of %s in checksum database", file, mod)
+	}
+
+	// each line is of the form "<source> <version> <hash>"
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		expected := fields[2]
+		if !strings.HasPrefix(expected, "h1:") {
+			continue // some other kind of hash we do not compute
+		}
+		if expected != actual {
+			return &Mismatch{
+				Module:   mod,
+				File:     file,
+				Expected: expected,
+				Actual:   actual,
+			}
+		}
+		v.log.Tracef("verified %s of %s is %s", file, mod, actual)
+		return nil
+	}
+
+	return errors.Errorf("checksum database has no h1 hash for %s of %s", file, mod)
+}
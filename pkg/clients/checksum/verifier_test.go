@@ -0,0 +1,151 @@
+package checksum
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+const (
+	zipHash = "h1:DaLgb81tB9bWlUSbzgQDlxb08dI2NyLKbjnOeG7aC8M="
+	modHash = "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0="
+)
+
+var mod = coordinates.Module{
+	Source:  "example.com/a",
+	Version: "v1.0.0",
+}
+
+// testSumDB starts a local stand-in for a checksum database, which knows
+// about only the one example module.
+func testSumDB(t *testing.T) (*httptest.Server, string) {
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sumdb.test")
+	require.NoError(t, err)
+
+	gosum := func(path, version string) ([]byte, error) {
+		if path != mod.Source || version != mod.Version {
+			return nil, os.ErrNotExist
+		}
+		return []byte(fmt.Sprintf(
+			"%s %s %s\n%s %s/go.mod %s\n",
+			path, version, zipHash,
+			path, version, modHash,
+		)), nil
+	}
+
+	server := httptest.NewServer(sumdb.NewServer(sumdb.NewTestServer(skey, gosum)))
+	return server, vkey
+}
+
+func Test_Verify_ok(t *testing.T) {
+	server, key := testSumDB(t)
+	defer server.Close()
+
+	v, err := New(Options{URL: server.URL, Key: key})
+	require.NoError(t, err)
+
+	err = v.Verify(mod, zipHash, modHash)
+	require.NoError(t, err)
+}
+
+func Test_Verify_mismatch(t *testing.T) {
+	server, key := testSumDB(t)
+	defer server.Close()
+
+	v, err := New(Options{URL: server.URL, Key: key})
+	require.NoError(t, err)
+
+	err = v.Verify(mod, "h1:tampered=", modHash)
+	require.Error(t, err)
+	require.True(t, IsMismatch(err))
+
+	err = v.Verify(mod, zipHash, "h1:tampered=")
+	require.Error(t, err)
+	require.True(t, IsMismatch(err))
+}
+
+func Test_Verify_unknown(t *testing.T) {
+	server, key := testSumDB(t)
+	defer server.Close()
+
+	v, err := New(Options{URL: server.URL, Key: key})
+	require.NoError(t, err)
+
+	err = v.Verify(coordinates.Module{
+		Source:  "example.com/b",
+		Version: "v1.0.0",
+	}, zipHash, modHash)
+	require.Error(t, err)
+	require.False(t, IsMismatch(err))
+}
+
+func Test_Verify_private(t *testing.T) {
+	server, key := testSumDB(t)
+	defer server.Close()
+
+	v, err := New(Options{
+		URL:     server.URL,
+		Key:     key,
+		Private: []string{"internal.example.com"},
+	})
+	require.NoError(t, err)
+
+	// never looked up, so no error even though the database does not know it
+	err = v.Verify(coordinates.Module{
+		Source:  "internal.example.com/c",
+		Version: "v1.0.0",
+	}, zipHash, modHash)
+	require.NoError(t, err)
+}
+
+func Test_Verify_cache(t *testing.T) {
+	server, key := testSumDB(t)
+
+	dir, err := ioutil.TempDir("", "checksum-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	v, err := New(Options{URL: server.URL, Key: key, CachePath: dir})
+	require.NoError(t, err)
+	err = v.Verify(mod, zipHash, modHash)
+	require.NoError(t, err)
+
+	// with the database gone, a new verifier can still verify from the cache
+	server.Close()
+	v, err = New(Options{URL: server.URL, Key: key, CachePath: dir})
+	require.NoError(t, err)
+	err = v.Verify(mod, zipHash, modHash)
+	require.NoError(t, err)
+}
+
+func Test_Disabled(t *testing.T) {
+	err := Disabled().Verify(mod, "h1:anything=", "h1:anything=")
+	require.NoError(t, err)
+}
+
+func Test_Chain(t *testing.T) {
+	first := NewVerifierMock(t)
+	defer first.MinimockFinish()
+	second := NewVerifierMock(t)
+	defer second.MinimockFinish()
+
+	first.VerifyMock.When(mod, zipHash, modHash).Then(nil)
+	second.VerifyMock.When(mod, zipHash, modHash).Then(nil)
+	err := Chain(first, second).Verify(mod, zipHash, modHash)
+	require.NoError(t, err)
+
+	// the second is never asked once the first has failed
+	bad := coordinates.Module{Source: "example.com/bad", Version: "v1.0.0"}
+	first.VerifyMock.When(bad, zipHash, modHash).Then(&Mismatch{Module: bad})
+	err = Chain(first, second).Verify(bad, zipHash, modHash)
+	require.True(t, IsMismatch(err))
+}
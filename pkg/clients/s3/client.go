@@ -0,0 +1,224 @@
+package s3
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	clean "github.com/hashicorp/go-cleanhttp"
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+)
+
+// ErrNotFound is returned when an object does not exist in the bucket.
+var ErrNotFound = errors.New("object not found")
+
+// IsNotFound returns whether err was caused by an object not existing.
+func IsNotFound(err error) bool {
+	return errors.Cause(err) == ErrNotFound
+}
+
+// A Client stores objects in a single bucket of an S3 compatible object
+// store, e.g. AWS S3 or MinIO. Objects are streamed rather than being held
+// in memory.
+type Client interface {
+	// Put stores size bytes of content as the object of key.
+	Put(key string, content io.ReaderAt, size int64) error
+
+	// Get streams the object of key, starting offset bytes into the
+	// object. The returned content must be closed by the caller.
+	Get(key string, offset int64) (io.ReadCloser, error)
+
+	// Stat returns information about the object of key, or ErrNotFound
+	// if there is no object of key.
+	Stat(key string) (Object, error)
+
+	Delete(key string) error
+}
+
+// Object describes an object stored in the bucket.
+type Object struct {
+	Size         int64
+	LastModified time.Time
+}
+
+type Options struct {
+	// Endpoint is the base URL of the object store, e.g.
+	// "https://s3.us-east-1.amazonaws.com" or "http://localhost:9000".
+	// Buckets are always addressed by path rather than by virtual host,
+	// which every S3 compatible object store supports.
+	Endpoint string
+
+	// Region is used when signing requests, e.g. "us-east-1". Most
+	// S3 compatible object stores other than AWS ignore it.
+	Region string
+
+	Bucket          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Timeout         time.Duration
+}
+
+type client struct {
+	httpClient *http.Client
+	endpoint   *url.URL
+	options    Options
+	now        func() time.Time
+}
+
+func New(options Options) (Client, error) {
+	if options.Bucket == "" {
+		return nil, errors.New("s3 bucket is not set")
+	}
+
+	if options.AccessKeyID == "" || options.SecretAccessKey == "" {
+		return nil, errors.New("s3 credentials are not set")
+	}
+
+	endpoint, err := url.Parse(strings.TrimSuffix(options.Endpoint, "/"))
+	if err != nil {
+		return nil, errors.Wrap(err, "s3 endpoint is not a valid url")
+	}
+	if endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, errors.Errorf("s3 endpoint must be an absolute url, got %q", options.Endpoint)
+	}
+
+	if options.Region == "" {
+		options.Region = "us-east-1"
+	}
+
+	httpClient := clean.DefaultPooledClient()
+	httpClient.Timeout = options.Timeout
+
+	return &client{
+		httpClient: httpClient,
+		endpoint:   endpoint,
+		options:    options,
+		now:        time.Now,
+	}, nil
+}
+
+func (c *client) Put(key string, content io.ReaderAt, size int64) error {
+	// the signature covers the hash of the content, which means reading
+	// it once up front, and then again as the request is sent
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(content, 0, size)); err != nil {
+		return err
+	}
+	payloadHash := hex.EncodeToString(h.Sum(nil))
+
+	body := io.NewSectionReader(content, 0, size)
+	response, err := c.do(http.MethodPut, key, body, size, payloadHash, nil)
+	if err != nil {
+		return err
+	}
+	defer ignore.Drain(response.Body)
+
+	return c.check(response, http.MethodPut, key)
+}
+
+func (c *client) Get(key string, offset int64) (io.ReadCloser, error) {
+	var headers map[string]string
+	if offset > 0 {
+		headers = map[string]string{"Range": fmt.Sprintf("bytes=%d-", offset)}
+	}
+
+	response, err := c.do(http.MethodGet, key, nil, 0, emptyHash, headers)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.check(response, http.MethodGet, key); err != nil {
+		ignore.Drain(response.Body)
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+func (c *client) Stat(key string) (Object, error) {
+	response, err := c.do(http.MethodHead, key, nil, 0, emptyHash, nil)
+	if err != nil {
+		return Object{}, err
+	}
+	defer ignore.Drain(response.Body)
+
+	if err := c.check(response, http.MethodHead, key); err != nil {
+		return Object{}, err
+	}
+
+	lastModified, _ := http.ParseTime(response.Header.Get("Last-Modified"))
+	return Object{
+		Size:         response.ContentLength,
+		LastModified: lastModified,
+	}, nil
+}
+
+// Delete removes the object of key. Like S3 itself, deleting an object
+// which does not exist is not an error.
+func (c *client) Delete(key string) error {
+	response, err := c.do(http.MethodDelete, key, nil, 0, emptyHash, nil)
+	if err != nil {
+		return err
+	}
+	defer ignore.Drain(response.Body)
+
+	return c.check(response, http.MethodDelete, key)
+}
+
+// the hash of an empty payload, used by every request without a body
+const emptyHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+func (c *client) do(
+	method, key string,
+	body io.Reader,
+	size int64,
+	payloadHash string,
+	headers map[string]string,
+) (*http.Response, error) {
+	path := "/" + c.options.Bucket + "/" + strings.TrimPrefix(key, "/")
+
+	u := *c.endpoint
+	u.Path = c.endpoint.Path + path
+	u.RawPath = escapePath(u.Path)
+
+	request, err := http.NewRequest(method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+	request.ContentLength = size
+
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	sign(request, payloadHash, signer{
+		region:          c.options.Region,
+		accessKeyID:     c.options.AccessKeyID,
+		secretAccessKey: c.options.SecretAccessKey,
+	}, c.now())
+
+	return c.httpClient.Do(request)
+}
+
+func (c *client) check(response *http.Response, method, key string) error {
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return errors.Wrapf(ErrNotFound, "s3 %s of %s", method, key)
+	case response.StatusCode < 200 || response.StatusCode > 299:
+		msg, _ := ioutil.ReadAll(response.Body)
+		return errors.Errorf(
+			"unexpected response from s3 for %s of %s, code: %d, %s",
+			method, key, response.StatusCode, bytes.TrimSpace(msg),
+		)
+	}
+	return nil
+}
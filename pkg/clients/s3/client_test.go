@@ -0,0 +1,110 @@
+package s3
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, secretAccessKey string) (Client, func()) {
+	ts := httptest.NewServer(NewFake("modules", "AKIAEXAMPLE", "secret"))
+
+	client, err := New(Options{
+		Endpoint:        ts.URL,
+		Region:          "us-west-2",
+		Bucket:          "modules",
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: secretAccessKey,
+	})
+	require.NoError(t, err)
+
+	return client, ts.Close
+}
+
+func Test_New_bad_options(t *testing.T) {
+	_, err := New(Options{
+		Endpoint:        "http://localhost:9000",
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	require.Error(t, err) // no bucket
+
+	_, err = New(Options{
+		Endpoint: "http://localhost:9000",
+		Bucket:   "modules",
+	})
+	require.Error(t, err) // no credentials
+
+	_, err = New(Options{
+		Endpoint:        "localhost:9000",
+		Bucket:          "modules",
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	require.Error(t, err) // not an absolute url
+}
+
+func read(t *testing.T, client Client, key string, offset int64) string {
+	content, err := client.Get(key, offset)
+	require.NoError(t, err)
+	defer content.Close()
+
+	bs, err := ioutil.ReadAll(content)
+	require.NoError(t, err)
+	return string(bs)
+}
+
+func Test_Client(t *testing.T) {
+	client, done := newTestClient(t, "secret")
+	defer done()
+
+	key := "mods/github.com/pkg/errors/v0.8.1.zip"
+
+	_, err := client.Stat(key)
+	require.True(t, IsNotFound(err))
+
+	_, err = client.Get(key, 0)
+	require.True(t, IsNotFound(err))
+
+	err = client.Put(key, strings.NewReader("zip content"), 11)
+	require.NoError(t, err)
+
+	object, err := client.Stat(key)
+	require.NoError(t, err)
+	require.Equal(t, int64(11), object.Size)
+	require.False(t, object.LastModified.IsZero())
+
+	require.Equal(t, "zip content", read(t, client, key, 0))
+	require.Equal(t, "content", read(t, client, key, 4))
+
+	err = client.Delete(key)
+	require.NoError(t, err)
+
+	_, err = client.Stat(key)
+	require.True(t, IsNotFound(err))
+}
+
+func Test_Client_escaped_key(t *testing.T) {
+	client, done := newTestClient(t, "secret")
+	defer done()
+
+	key := "github.com/!burnt!sushi/toml/@v/v0.3.1+incompatible.zip"
+
+	err := client.Put(key, strings.NewReader("zip content"), 11)
+	require.NoError(t, err)
+
+	require.Equal(t, "zip content", read(t, client, key, 0))
+}
+
+func Test_Client_bad_credentials(t *testing.T) {
+	client, done := newTestClient(t, "wrong")
+	defer done()
+
+	err := client.Put("some/key", strings.NewReader("zip content"), 11)
+	require.Error(t, err)
+	require.False(t, IsNotFound(err))
+	require.Contains(t, err.Error(), "code: 403")
+}
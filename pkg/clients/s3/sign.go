@@ -0,0 +1,157 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// The requests to the object store are signed with AWS Signature Version 4,
+// which is described in detail at
+//   https://docs.aws.amazon.com/AmazonS3/latest/API/sig-v4-header-based-auth.html
+// and is supported by every S3 compatible object store.
+
+const (
+	algorithm     = "AWS4-HMAC-SHA256"
+	service       = "s3"
+	amzDateFormat = "20060102T150405Z"
+	dateFormat    = "20060102"
+)
+
+type signer struct {
+	region          string
+	accessKeyID     string
+	secretAccessKey string
+}
+
+// sign adds the Authorization header to request, along with the headers
+// the signature depends on. Every header already set on request is signed.
+func sign(request *http.Request, payloadHash string, s signer, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format(amzDateFormat)
+	date := now.Format(dateFormat)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	names, canonicalHeaders := canonicalHeaders(request)
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		request.Method,
+		request.URL.EscapedPath(),
+		canonicalQuery(request),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := strings.Join([]string{date, s.region, service, "aws4_request"}, "/")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{
+		algorithm,
+		amzDate,
+		scope,
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := hmacOf([]byte("AWS4"+s.secretAccessKey), date)
+	key = hmacOf(key, s.region)
+	key = hmacOf(key, service)
+	key = hmacOf(key, "aws4_request")
+	signature := hex.EncodeToString(hmacOf(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		algorithm, s.accessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+func hmacOf(key []byte, content string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(content))
+	return h.Sum(nil)
+}
+
+func canonicalHeaders(request *http.Request) ([]string, string) {
+	values := map[string]string{"host": request.URL.Host}
+	if request.Host != "" {
+		values["host"] = request.Host
+	}
+
+	for name, vs := range request.Header {
+		name = strings.ToLower(name)
+		if name == "authorization" {
+			continue
+		}
+		trimmed := make([]string, 0, len(vs))
+		for _, v := range vs {
+			trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+		}
+		values[name] = strings.Join(trimmed, ",")
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		b.WriteString(name)
+		b.WriteString(":")
+		b.WriteString(values[name])
+		b.WriteString("\n")
+	}
+
+	return names, b.String()
+}
+
+func canonicalQuery(request *http.Request) string {
+	query := request.URL.Query()
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		vs := query[key]
+		sort.Strings(vs)
+		for _, v := range vs {
+			pairs = append(pairs, escape(key, false)+"="+escape(v, false))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
+
+// escapePath URI encodes every element of path, the way the signature
+// expects, which is stricter than what url.URL does on its own.
+func escapePath(path string) string {
+	return escape(path, true)
+}
+
+func escape(s string, path bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+			b.WriteByte(c)
+		case c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && path:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
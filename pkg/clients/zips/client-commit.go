@@ -0,0 +1,265 @@
+package zips
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	clean "github.com/hashicorp/go-cleanhttp"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i CommitClient -s _mock.go
+
+// A CommitClient is used to look up the commit a version of a module refers
+// to, using the API of the code hosting service of its upstream origin.
+type CommitClient interface {
+	// Commit returns the commit information of the version of the request,
+	// which must have passed through all of the Transform functors
+	Commit(*upstream.Request) (repository.RevInfo, error)
+}
+
+// An APIKind is the flavor of the API of a code hosting service.
+type APIKind string
+
+const (
+	GitHub APIKind = "github"
+	GitLab APIKind = "gitlab"
+	Gitea  APIKind = "gitea"
+)
+
+// An API is where the commits of the repositories of a domain are looked up.
+type API struct {
+	Kind    APIKind
+	BaseURL string // e.g. https://api.github.com
+}
+
+// DefaultAPIs are the APIs of the well known public code hosting services.
+func DefaultAPIs() map[string]API {
+	return map[string]API{
+		"github.com": {Kind: GitHub, BaseURL: "https://api.github.com"},
+		"gitlab.com": {Kind: GitLab, BaseURL: "https://gitlab.com"},
+	}
+}
+
+type CommitClientOptions struct {
+	APIs    map[string]API // by domain of the upstream request
+	Timeout time.Duration  // about 1 minute is good
+}
+
+type commitClient struct {
+	httpClient iHTTPClient
+	apis       map[string]API
+	log        loggy.Logger
+}
+
+// NewCommitClient creates a CommitClient which looks up commits using the
+// APIs of options.
+func NewCommitClient(opts CommitClientOptions) CommitClient {
+	if opts.Timeout <= 0 {
+		panic("commit client Timeout must be positive")
+	}
+
+	httpClient := clean.DefaultPooledClient()
+	httpClient.Timeout = opts.Timeout
+
+	return &commitClient{
+		httpClient: httpClient,
+		apis:       opts.APIs,
+		log:        loggy.New("commit-client"),
+	}
+}
+
+func (c *commitClient) Commit(r *upstream.Request) (repository.RevInfo, error) {
+	if r == nil {
+		return repository.RevInfo{}, errors.New("request is nil")
+	}
+
+	api, exists := c.apis[r.Domain]
+	if !exists {
+		return repository.RevInfo{}, errors.Errorf("no commit api for domain %s", r.Domain)
+	}
+
+	var (
+		hash string
+		when time.Time
+		err  error
+	)
+
+	switch api.Kind {
+	case GitHub:
+		hash, when, err = c.github(api, r)
+	case GitLab:
+		hash, when, err = c.gitlab(api, r)
+	case Gitea:
+		hash, when, err = c.gitea(api, r)
+	default:
+		err = errors.Errorf("unsupported commit api %q for domain %s", api.Kind, r.Domain)
+	}
+
+	if err != nil {
+		return repository.RevInfo{}, err
+	}
+
+	if hash == "" || when.IsZero() {
+		return repository.RevInfo{}, errors.Errorf("no commit for %s in %s", r.Ref(), r.Domain)
+	}
+
+	return repository.RevInfo{Version: r.Version}.WithCommit(hash, when), nil
+}
+
+// the response of github to a request for a commit, of which only the hash
+// and the time are of interest, gitea uses the same layout
+type githubCommit struct {
+	SHA    string `json:"sha"`
+	Commit struct {
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+}
+
+// e.g. GET https://api.github.com/repos/pkg/errors/commits/v0.8.1
+func (c *commitClient) github(api API, r *upstream.Request) (string, time.Time, error) {
+	owner, repo, err := ownerRepoOf(r)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	uri := fmt.Sprintf(
+		"%s/repos/%s/%s/commits/%s",
+		strings.TrimSuffix(api.BaseURL, "/"),
+		owner,
+		repo,
+		url.PathEscape(r.Ref()),
+	)
+
+	var commit githubCommit
+	if err := c.get(uri, r.Headers, &commit); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return commit.SHA, commit.Commit.Committer.Date, nil
+}
+
+// e.g. GET https://gitea.com/api/v1/repos/gitea/tea/commits?sha=v0.1.0&limit=1
+func (c *commitClient) gitea(api API, r *upstream.Request) (string, time.Time, error) {
+	owner, repo, err := ownerRepoOf(r)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	uri := fmt.Sprintf(
+		"%s/api/v1/repos/%s/%s/commits?sha=%s&limit=1",
+		strings.TrimSuffix(api.BaseURL, "/"),
+		owner,
+		repo,
+		url.QueryEscape(r.Ref()),
+	)
+
+	var commits []githubCommit
+	if err := c.get(uri, r.Headers, &commits); err != nil {
+		return "", time.Time{}, err
+	}
+
+	if len(commits) == 0 {
+		return "", time.Time{}, nil
+	}
+
+	return commits[0].SHA, commits[0].Commit.Committer.Date, nil
+}
+
+// the response of gitlab to a request for a commit
+type gitlabCommit struct {
+	ID            string    `json:"id"`
+	CommittedDate time.Time `json:"committed_date"`
+}
+
+// e.g. GET https://gitlab.com/api/v4/projects/cryptsetup%2Fcryptsetup/repository/commits/v2.0.1
+func (c *commitClient) gitlab(api API, r *upstream.Request) (string, time.Time, error) {
+	project := projectOf(r)
+	if project == "" {
+		return "", time.Time{}, errors.Errorf("no project in request for %s", r.Domain)
+	}
+
+	uri := fmt.Sprintf(
+		"%s/api/v4/projects/%s/repository/commits/%s",
+		strings.TrimSuffix(api.BaseURL, "/"),
+		url.PathEscape(project),
+		url.PathEscape(r.Ref()),
+	)
+
+	var commit gitlabCommit
+	if err := c.get(uri, r.Headers, &commit); err != nil {
+		return "", time.Time{}, err
+	}
+
+	return commit.ID, commit.CommittedDate, nil
+}
+
+func (c *commitClient) get(uri string, headers map[string]string, result interface{}) error {
+	c.log.Tracef("making commit request to %s", uri)
+
+	request, err := http.NewRequest(http.MethodGet, uri, nil)
+	if err != nil {
+		return errors.Wrap(err, "unable to create request")
+	}
+
+	// the same headers used for downloading, e.g. credentials
+	for k, v := range headers {
+		request.Header.Set(k, v)
+	}
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return errors.Wrapf(err, "could not do request for %s", uri)
+	}
+	defer ignore.Drain(response.Body)
+
+	if response.StatusCode >= 400 {
+		bs, _ := ioutil.ReadAll(response.Body)
+		body := string(bs)
+		if len(body) > maxLoggedBody {
+			body = body[:maxLoggedBody] + "..."
+		}
+		c.log.Errorf("bad response (%d), body: %s", response.StatusCode, body)
+		return errors.Errorf("unexpected response (%d)", response.StatusCode)
+	}
+
+	if err := json.NewDecoder(response.Body).Decode(result); err != nil {
+		return errors.Wrapf(err, "could not decode response of %s", uri)
+	}
+	return nil
+}
+
+// e.g. github.com/pkg/errors/v2 => pkg, errors
+func ownerRepoOf(r *upstream.Request) (string, string, error) {
+	if len(r.Namespace) < 2 {
+		return "", "", errors.Errorf("no owner and repository in request for %s", r.Domain)
+	}
+	return r.Namespace[0], r.Namespace[1], nil
+}
+
+// a major version suffix is part of the module path, but not of the project
+var majorSuffix = regexp.MustCompile(`^v[0-9]+$`)
+
+// e.g. gitlab.com/group/subgroup/project/v2 => group/subgroup/project
+func projectOf(r *upstream.Request) string {
+	namespace := r.Namespace
+	if n := len(namespace); n > 2 && majorSuffix.MatchString(namespace[n-1]) {
+		namespace = namespace[:n-1]
+	}
+	return strings.Join(namespace, "/")
+}
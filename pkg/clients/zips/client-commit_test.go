@@ -0,0 +1,146 @@
+package zips
+
+import (
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+func commitClientOf(httpClient iHTTPClient, apis map[string]API) *commitClient {
+	return &commitClient{
+		httpClient: httpClient,
+		apis:       apis,
+		log:        loggy.New(""),
+	}
+}
+
+func respond(body string) *http.Response {
+	return &http.Response{
+		Body:       ioutil.NopCloser(strings.NewReader(body)),
+		StatusCode: http.StatusOK,
+	}
+}
+
+func TestCommitClient_github(t *testing.T) {
+	httpClient := NewIHTTPClientMock(t)
+	defer httpClient.MinimockFinish()
+
+	httpClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+		require.Equal(t, "https://api.github.com/repos/pkg/errors/commits/v0.8.1", req.URL.String())
+		require.Equal(t, "token abc123", req.Header.Get("Authorization"))
+		return respond(`{
+  "sha": "645ef00459ed84a119197bfb8d8205042c6df63d",
+  "commit": {
+    "committer": {"name": "Dave Cheney", "date": "2019-01-03T06:42:23Z"}
+  }
+}`), nil
+	})
+
+	client := commitClientOf(httpClient, DefaultAPIs())
+	info, err := client.Commit(&upstream.Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: []string{"pkg", "errors"},
+		Version:   "v0.8.1",
+		Headers:   map[string]string{"Authorization": "token abc123"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, repository.RevInfo{
+		Version: "v0.8.1",
+		Name:    "645ef00459ed84a119197bfb8d8205042c6df63d",
+		Short:   "645ef00459ed",
+		Time:    time.Date(2019, 1, 3, 6, 42, 23, 0, time.UTC),
+	}, info)
+}
+
+func TestCommitClient_gitlab(t *testing.T) {
+	httpClient := NewIHTTPClientMock(t)
+	defer httpClient.MinimockFinish()
+
+	httpClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+		require.Equal(t, "https://gitlab.com/api/v4/projects/group%2Fsub%2Fproject/repository/commits/fbec762f837d", req.URL.String())
+		return respond(`{
+  "id": "fbec762f837dc349b73d1eaa820552e2ad177942",
+  "committed_date": "2018-01-11T04:04:09.000+00:00"
+}`), nil
+	})
+
+	client := commitClientOf(httpClient, DefaultAPIs())
+	info, err := client.Commit(&upstream.Request{
+		Transport: "https",
+		Domain:    "gitlab.com",
+		Namespace: []string{"group", "sub", "project", "v2"},
+		Version:   "v2.0.0-20180111040409-fbec762f837d",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "fbec762f837dc349b73d1eaa820552e2ad177942", info.Name)
+	require.Equal(t, "fbec762f837d", info.Short)
+	require.Equal(t, time.Date(2018, 1, 11, 4, 4, 9, 0, time.UTC), info.Time)
+}
+
+func TestCommitClient_gitea(t *testing.T) {
+	httpClient := NewIHTTPClientMock(t)
+	defer httpClient.MinimockFinish()
+
+	httpClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+		require.Equal(t, "https://code.example.com/api/v1/repos/go/foo/commits?sha=v0.0.1&limit=1", req.URL.String())
+		return respond(`[{
+  "sha": "8914546b1a7c87772fbec762f837dc349b73d1ea",
+  "commit": {"committer": {"date": "2020-02-03T04:05:06+01:00"}}
+}]`), nil
+	})
+
+	client := commitClientOf(httpClient, map[string]API{
+		"code.example.com": {Kind: Gitea, BaseURL: "https://code.example.com/"},
+	})
+	info, err := client.Commit(&upstream.Request{
+		Transport: "https",
+		Domain:    "code.example.com",
+		Namespace: []string{"go", "foo"},
+		Version:   "v0.0.1",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "8914546b1a7c", info.Short)
+	require.Equal(t, time.Date(2020, 2, 3, 3, 5, 6, 0, time.UTC), info.Time)
+}
+
+func TestCommitClient_no_api(t *testing.T) {
+	client := commitClientOf(NewIHTTPClientMock(t), DefaultAPIs())
+	_, err := client.Commit(&upstream.Request{
+		Transport: "https",
+		Domain:    "code.example.com",
+		Namespace: []string{"go", "foo"},
+		Version:   "v0.0.1",
+	})
+	require.EqualError(t, err, "no commit api for domain code.example.com")
+}
+
+func TestCommitClient_not_found(t *testing.T) {
+	httpClient := NewIHTTPClientMock(t)
+	defer httpClient.MinimockFinish()
+
+	httpClient.DoMock.Set(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			Body:       ioutil.NopCloser(strings.NewReader(`{"message": "Not Found"}`)),
+			StatusCode: http.StatusNotFound,
+		}, nil
+	})
+
+	client := commitClientOf(httpClient, DefaultAPIs())
+	_, err := client.Commit(&upstream.Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: []string{"pkg", "errors"},
+		Version:   "v9.9.9",
+	})
+	require.EqualError(t, err, "unexpected response (404)")
+}
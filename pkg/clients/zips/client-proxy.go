@@ -2,6 +2,7 @@ package zips
 
 import (
 	"bufio"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -28,10 +29,13 @@ import (
 // A ProxyClient is used for making requests to a Go Module Proxy
 // which is expected to return archives already in the correct format.
 type ProxyClient interface {
-	// Get returns the contents of the repo specified by the coordinates
-	Get(coordinates.Module) (repository.Blob, error)
+	// Get returns the contents of the repo specified by the coordinates,
+	// which is streamed from the proxy, and must be closed by the caller
+	Get(coordinates.Module) (io.ReadCloser, error)
 	// List returns all available versions of the repo specified by the coordinates, in descending logical order
 	List(source string) ([]semantic.Tag, error)
+	// Info returns the commit information of the version specified by the coordinates
+	Info(coordinates.Module) (repository.RevInfo, error)
 }
 
 //go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i iHTTPClient
@@ -43,13 +47,15 @@ type proxyClient struct {
 	httpClient iHTTPClient
 	baseURL    string
 	protocol   string
+	headers    map[string]string
 	log        loggy.Logger
 }
 
 type ProxyClientOptions struct {
-	Protocol string        // typically https
-	BaseURL  string        // typically proxy.golang.org
-	Timeout  time.Duration // about 1 minute is good
+	Protocol string            // typically https
+	BaseURL  string            // typically proxy.golang.org
+	Timeout  time.Duration     // about 1 minute is good
+	Headers  map[string]string // set on every request, e.g. for auth
 }
 
 // NewProxyClient creates a ProxyClient with some options.
@@ -73,6 +79,7 @@ func NewProxyClient(opts ProxyClientOptions) ProxyClient {
 		baseURL:    opts.BaseURL,
 		httpClient: httpClient,
 		protocol:   opts.Protocol,
+		headers:    opts.Headers,
 		log:        loggy.New("proxy-client"),
 	}
 }
@@ -84,22 +91,37 @@ func (c *proxyClient) zipURIOf(module coordinates.Module) string {
 		module.Version,
 	))
 
-	s := url.URL{
-		Scheme: c.protocol,
-		Host:   c.baseURL,
-		Path:   modZipPath,
-	}
+	return c.uriOf(modZipPath)
+}
 
-	return s.String()
+func (c *proxyClient) infoURIOf(module coordinates.Module) string {
+	modInfoPath := mangle(fmt.Sprintf(
+		"/%s/@v/%s.info",
+		module.Source,
+		module.Version,
+	))
+
+	return c.uriOf(modInfoPath)
 }
 
 func (c *proxyClient) listURIOf(source string) string {
 	modListPath := mangle(fmt.Sprintf("/%s/@v/list", source))
 
+	return c.uriOf(modListPath)
+}
+
+// uriOf returns the URI of p on the proxy, where the BaseURL of the proxy may
+// have a path of its own, e.g. proxy.example.com/go.
+func (c *proxyClient) uriOf(p string) string {
+	host, prefix := c.baseURL, ""
+	if i := strings.Index(host, "/"); i >= 0 {
+		host, prefix = host[:i], strings.TrimSuffix(host[i:], "/")
+	}
+
 	s := url.URL{
 		Scheme: c.protocol,
-		Host:   c.baseURL,
-		Path:   modListPath,
+		Host:   host,
+		Path:   prefix + p,
 	}
 
 	return s.String()
@@ -135,20 +157,14 @@ func mangle(source string) string {
 	return builder.String()
 }
 
-func (c *proxyClient) Get(mod coordinates.Module) (repository.Blob, error) {
+func (c *proxyClient) Get(mod coordinates.Module) (io.ReadCloser, error) {
 	// request looks like
 	//
 	// GET https://proxy.golang.org/oss.indeed.com/go/taggit/@v/v0.3.3.zip
 	zipURI := c.zipURIOf(mod)
 	c.log.Tracef("making zip proxy request to %s", zipURI)
 
-	response, err := c.sendRequest(mod.String(), zipURI)
-	if err != nil {
-		return nil, err
-	}
-	defer ignore.Drain(response)
-
-	return ioutil.ReadAll(response)
+	return c.sendRequest(mod.String(), zipURI)
 }
 
 func (c *proxyClient) List(source string) ([]semantic.Tag, error) {
@@ -179,6 +195,27 @@ func (c *proxyClient) List(source string) ([]semantic.Tag, error) {
 	return result, nil
 }
 
+func (c *proxyClient) Info(mod coordinates.Module) (repository.RevInfo, error) {
+	// request looks like
+	//
+	// GET https://proxy.golang.org/oss.indeed.com/go/taggit/@v/v0.3.3.info
+	infoURI := c.infoURIOf(mod)
+	c.log.Tracef("making info proxy request to %s", infoURI)
+
+	response, err := c.sendRequest(mod.String(), infoURI)
+	if err != nil {
+		return repository.RevInfo{}, err
+	}
+	defer ignore.Drain(response)
+
+	var info repository.RevInfo
+	if err := json.NewDecoder(response).Decode(&info); err != nil {
+		return repository.RevInfo{}, errors.Wrapf(err, "failed to decode info of %s", mod)
+	}
+
+	return info, nil
+}
+
 func (c *proxyClient) sendRequest(subject, uri string) (io.ReadCloser, error) {
 	// create the request for the module, from the proxy
 	request, err := c.newRequest(uri)
@@ -195,6 +232,7 @@ func (c *proxyClient) sendRequest(subject, uri string) (io.ReadCloser, error) {
 	// if we get a bad response code, try to read the body and log it
 	// todo: can we make this generic? copied from http.go
 	if response.StatusCode >= 400 {
+		defer ignore.Drain(response.Body)
 		bs, err := ioutil.ReadAll(response.Body)
 		if err != nil {
 			return nil, errors.Wrapf(err, "could not read body of bad response (%d)", response.StatusCode)
@@ -205,7 +243,7 @@ func (c *proxyClient) sendRequest(subject, uri string) (io.ReadCloser, error) {
 		} else {
 			c.log.Errorf("bad response(%d) trunc body: %s...", response.StatusCode, body[:maxLoggedBody])
 		}
-		return nil, errors.Wrapf(err, "unexpected response (%d)", response.StatusCode)
+		return nil, errors.Errorf("unexpected response (%d)", response.StatusCode)
 	}
 
 	// response is good, read the bytes
@@ -221,5 +259,8 @@ func (c *proxyClient) newRequest(uri string) (*http.Request, error) {
 	if err != nil {
 		return nil, errors.Wrap(err, "unable to create request")
 	}
+	for k, v := range c.headers {
+		request.Header.Set(k, v)
+	}
 	return request, nil
 }
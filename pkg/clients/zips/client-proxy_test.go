@@ -10,6 +10,8 @@ import (
 
 	"gophers.dev/pkgs/loggy"
 	"gophers.dev/pkgs/semantic"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
 )
 
 func Test_mangle(t *testing.T) {
@@ -60,3 +62,46 @@ v0.3.1
 		{Major: 0, Minor: 1, Patch: 0},
 	}, versions)
 }
+
+func TestProxyClient_Info_headers(t *testing.T) {
+	httpClient := NewIHTTPClientMock(t)
+	defer httpClient.MinimockFinish()
+
+	httpClient.DoMock.Set(func(req *http.Request) (rp1 *http.Response, err error) {
+		require.Equal(t, "https://proxy.internal/go/code.internal/foo/@v/v1.0.0.info", req.URL.String())
+		require.Equal(t, "Bearer abc123", req.Header.Get("Authorization"))
+		body := `{"Version":"v1.0.0"}`
+		return &http.Response{Body: ioutil.NopCloser(strings.NewReader(body)), StatusCode: http.StatusOK}, nil
+	})
+
+	subject := &proxyClient{
+		httpClient: httpClient,
+		baseURL:    "proxy.internal/go",
+		protocol:   "https",
+		headers:    map[string]string{"Authorization": "Bearer abc123"},
+		log:        loggy.New(""),
+	}
+
+	info, err := subject.Info(coordinates.Module{
+		Source:  "code.internal/foo",
+		Version: "v1.0.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0", info.Version)
+}
+
+func TestProxyClient_uriOf(t *testing.T) {
+	try := func(baseURL, exp string) {
+		subject := &proxyClient{baseURL: baseURL, protocol: "https"}
+		result := subject.zipURIOf(coordinates.Module{
+			Source:  "github.com/Foo/bar",
+			Version: "v1.0.0",
+		})
+		require.Equal(t, exp, result)
+	}
+
+	try("proxy.golang.org", "https://proxy.golang.org/github.com/%21foo/bar/@v/v1.0.0.zip")
+	try("proxy.example.com:8080", "https://proxy.example.com:8080/github.com/%21foo/bar/@v/v1.0.0.zip")
+	try("proxy.example.com/go", "https://proxy.example.com/go/github.com/%21foo/bar/@v/v1.0.0.zip")
+	try("proxy.example.com/go/", "https://proxy.example.com/go/github.com/%21foo/bar/@v/v1.0.0.zip")
+}
@@ -1,20 +1,22 @@
 package zips
 
 import (
+	"io"
+
 	"github.com/pkg/errors"
 
-	"oss.indeed.com/go/modprox/pkg/repository"
 	"oss.indeed.com/go/modprox/pkg/upstream"
 )
 
 //go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i UpstreamClient -s _mock.go
 
 // UpstreamClient is used to download .zip files from an upstream origin
-// (e.g. github.com). The returned Blob is in a git archive format
+// (e.g. github.com). The returned archive is in a git archive format
 // that must be unpacked and repacked in the way that Go modules are
-// expected to be. This is done using Rewrite.
+// expected to be. This is done using Rewrite. The archive is streamed
+// from the upstream, and must be closed by the caller.
 type UpstreamClient interface {
-	Get(*upstream.Request) (repository.Blob, error)
+	Get(*upstream.Request) (io.ReadCloser, error)
 	Protocols() []string
 }
 
@@ -34,7 +36,7 @@ type client struct {
 	clients map[string]UpstreamClient
 }
 
-func (c *client) Get(r *upstream.Request) (repository.Blob, error) {
+func (c *client) Get(r *upstream.Request) (io.ReadCloser, error) {
 	impl, err := c.getClientFor(r.Transport)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,157 @@
+package zips
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// run runs the VCS command name in dir, returning its output.
+func run(timeout time.Duration, dir, name string, args ...string) (string, error) {
+	return runEnv(timeout, dir, nil, name, args...)
+}
+
+// runEnv is run with env added to the environment of the command.
+func runEnv(timeout time.Duration, dir string, env []string, name string, args ...string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := command(ctx, dir, env, name, args...)
+
+	stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		// not the arguments, which may include credentials
+		return "", errors.Wrapf(err, "%s: %s", name, strings.TrimSpace(stderr.String()))
+	}
+	return stdout.String(), nil
+}
+
+// stream runs the VCS command name in dir, streaming its output, and calls
+// release once the command is done.
+func stream(timeout time.Duration, release func(), dir, name string, args ...string) (io.ReadCloser, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	cmd := command(ctx, dir, nil, name, args...)
+
+	stderr := new(bytes.Buffer)
+	cmd.Stderr = stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return nil, errors.Wrapf(err, "failed to run %s", name)
+	}
+
+	return &commandReader{
+		stdout:  stdout,
+		cmd:     cmd,
+		stderr:  stderr,
+		cancel:  cancel,
+		release: release,
+	}, nil
+}
+
+func command(ctx context.Context, dir string, env []string, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	// never wait for someone to type in credentials, and never
+	// let the configuration of the user change the output
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "HGPLAIN=1")
+	if _, set := os.LookupEnv("GIT_SSH_COMMAND"); !set {
+		cmd.Env = append(cmd.Env, "GIT_SSH_COMMAND=ssh -o BatchMode=yes")
+	}
+	cmd.Env = append(cmd.Env, env...)
+	return cmd
+}
+
+// commandReader reads the output of a command, which is only complete
+// if the command exits successfully.
+type commandReader struct {
+	stdout  io.Reader
+	cmd     *exec.Cmd
+	stderr  *bytes.Buffer
+	cancel  context.CancelFunc
+	release func()
+	done    bool
+	err     error
+}
+
+func (c *commandReader) Read(p []byte) (int, error) {
+	n, err := c.stdout.Read(p)
+	if err == io.EOF {
+		if err := c.wait(); err != nil {
+			return n, err
+		}
+	}
+	return n, err
+}
+
+func (c *commandReader) Close() error {
+	// stop the command if the output was not read completely
+	c.cancel()
+	_ = c.wait()
+	return nil
+}
+
+func (c *commandReader) wait() error {
+	if !c.done {
+		c.done = true
+		if err := c.cmd.Wait(); err != nil {
+			c.err = errors.Wrapf(err, "%s failed: %s", c.cmd.Args[0], strings.TrimSpace(c.stderr.String()))
+		}
+		c.cancel()
+		c.release()
+	}
+	return c.err
+}
+
+// runZip runs the VCS command name in dir, which writes a zip into the file
+// named in the arguments, and returns the zip. The zip is kept in tmpDir, and
+// removed once closed. Since the command is done by then, release is called
+// before returning.
+func runZip(timeout time.Duration, release func(), tmpDir, dir, name string, args func(zip string) []string) (io.ReadCloser, error) {
+	tmp, err := ioutil.TempFile(tmpDir, "modprox-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	_ = tmp.Close()
+
+	if _, err := run(timeout, dir, name, args(tmp.Name())...); err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	f, err := os.Open(tmp.Name())
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	release()
+	return &tmpReader{File: f}, nil
+}
+
+// tmpReader reads a temporary file, which is removed once closed.
+type tmpReader struct {
+	*os.File
+}
+
+func (t *tmpReader) Close() error {
+	err := t.File.Close()
+	_ = os.Remove(t.Name())
+	return err
+}
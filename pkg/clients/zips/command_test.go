@@ -0,0 +1,95 @@
+package zips
+
+import (
+	"archive/zip"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommand puts a shell script in front of the PATH as the command name,
+// standing in for a VCS which is not installed, so that the commands run by
+// its client are tested all the same. Every time the script is run, its
+// arguments are logged first, and calls returns them.
+func fakeCommand(t *testing.T, dir, name, script string) (calls func() []string, restore func()) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not installed")
+	}
+
+	bin := filepath.Join(dir, "bin")
+	require.NoError(t, os.MkdirAll(bin, 0755))
+
+	log := filepath.Join(dir, name+".log")
+	content := "#!/bin/sh\necho \"$*\" >> " + log + "\n" + script + "\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(bin, name), []byte(content), 0755))
+
+	path := os.Getenv("PATH")
+	require.NoError(t, os.Setenv("PATH", bin+string(os.PathListSeparator)+path))
+
+	calls = func() []string {
+		bs, err := ioutil.ReadFile(log)
+		require.NoError(t, err)
+		return strings.Split(strings.TrimSpace(string(bs)), "\n")
+	}
+	restore = func() {
+		_ = os.Setenv("PATH", path)
+	}
+	return calls, restore
+}
+
+// writeZipFile writes a zip of files named names into file, e.g. for
+// a fakeCommand to copy wherever the zip it was asked for goes.
+func writeZipFile(t *testing.T, file string, names ...string) {
+	f, err := os.Create(file)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, f.Close()) }()
+
+	w := zip.NewWriter(f)
+	for _, name := range names {
+		entry, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = entry.Write([]byte(name))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+}
+
+func Test_runZip(t *testing.T) {
+	dir, err := ioutil.TempDir("", "run-zip-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	archive := filepath.Join(dir, "archive.zip")
+	writeZipFile(t, archive, "foo-1/go.mod")
+
+	_, restore := fakeCommand(t, dir, "vcs", `cp `+archive+` "$2"`)
+	defer restore()
+
+	tmpDir := filepath.Join(dir, "tmp")
+	require.NoError(t, os.MkdirAll(tmpDir, 0755))
+
+	released := false
+	rc, err := runZip(time.Minute, func() { released = true }, tmpDir, "", "vcs", func(zip string) []string {
+		return []string{"zip", zip}
+	})
+	require.NoError(t, err)
+	require.True(t, released)
+
+	// the zip is kept in tmpDir until closed
+	tmp := rc.(*tmpReader).Name()
+	require.Equal(t, tmpDir, filepath.Dir(tmp))
+
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, []string{"foo-1/go.mod"}, filesOf(t, bs))
+
+	require.NoError(t, rc.Close())
+	_, err = os.Stat(tmp)
+	require.True(t, os.IsNotExist(err))
+}
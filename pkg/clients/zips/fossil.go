@@ -0,0 +1,114 @@
+package zips
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+type fossilClient struct {
+	*mirrorCache
+	options  MirrorOptions
+	remoteOf func(*upstream.Request) string
+	log      loggy.Logger
+}
+
+// NewFossilClient creates an UpstreamClient which uses the fossil command to
+// clone and pull upstream Fossil repositories, e.g. those of a go-import meta
+// tag with the fossil VCS. A Fossil repository is a single file, which is kept
+// as the mirror in the .fossil directory of the directory of options.
+//
+// The transport of a request decides how the repository is cloned, e.g. the
+// request for fossil.example.com/go/foo with transport fossil+https is for the
+// repository at https://fossil.example.com/go/foo.
+func NewFossilClient(options MirrorOptions) MirrorClient {
+	options = options.withDefaults("fossil")
+	options.Directory = filepath.Join(options.Directory, ".fossil")
+	log := loggy.New("zips-fossil")
+	return &fossilClient{
+		mirrorCache: newMirrorCache(options, ".fossil", log),
+		options:     options,
+		remoteOf:    vcsRemoteOf("fossil"),
+		log:         log,
+	}
+}
+
+func (c *fossilClient) Protocols() []string {
+	return []string{"fossil+http", "fossil+https"}
+}
+
+func (c *fossilClient) Get(r *upstream.Request) (io.ReadCloser, error) {
+	if r == nil {
+		return nil, errors.New("request is nil")
+	}
+	return c.get(c, r, c.remoteOf(r))
+}
+
+func (c *fossilClient) clone(r *upstream.Request, remote, mirror string) error {
+	c.log.Infof("cloning mirror of %s into %s", remote, mirror)
+
+	_, err := c.fossil("", "clone", remote, mirror)
+	return err
+}
+
+func (c *fossilClient) fetch(r *upstream.Request, mirror string) error {
+	c.log.Infof("pulling new check-ins into %s", mirror)
+
+	_, err := c.fossil("", "pull", "-R", mirror)
+	return err
+}
+
+// resolve returns the full hash of the check-in of ref in the mirror, where
+// ref may be a tag, a branch, or an abbreviated check-in hash.
+func (c *fossilClient) resolve(mirror, ref string) (string, error) {
+	output, err := c.fossil("", "info", ref, "-R", mirror)
+	if err != nil {
+		return "", err
+	}
+	return parseFossilHash(output)
+}
+
+// archive creates the zip of the check-in hash from the mirror, with every
+// file in a single top-level directory.
+func (c *fossilClient) archive(r *upstream.Request, mirror, hash string, release func()) (io.ReadCloser, error) {
+	name := fmt.Sprintf("%s-%s", path.Base(projectOf(r)), hash)
+
+	return runZip(c.options.Timeout, release, c.options.TmpDir, "", "fossil", func(zip string) []string {
+		return []string{"zip", hash, zip, "-R", mirror, "--name", name}
+	})
+}
+
+// fossil runs the fossil command in dir, returning its output.
+func (c *fossilClient) fossil(dir string, args ...string) (string, error) {
+	return run(c.options.Timeout, dir, "fossil", args...)
+}
+
+// parseFossilHash returns the hash of a check-in from the output of fossil
+// info, which older versions of fossil call the uuid, e.g.
+//
+//	hash:         1f9d1c2e0a5b... 2020-01-02 03:04:05 UTC
+func parseFossilHash(info string) (string, error) {
+	scanner := bufio.NewScanner(strings.NewReader(info))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		if fields[0] == "hash:" || fields[0] == "uuid:" {
+			return fields[1], nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", errors.New("no hash in fossil info")
+}
@@ -0,0 +1,138 @@
+package zips
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+func fossil(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("fossil", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func TestFossilClient_Get(t *testing.T) {
+	if _, err := exec.LookPath("fossil"); err != nil {
+		t.Skip("fossil is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "fossil-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := filepath.Join(dir, "foo.fossil")
+	work := filepath.Join(dir, "work")
+	require.NoError(t, os.MkdirAll(work, 0755))
+	fossil(t, "", "init", repo)
+	fossil(t, work, "open", repo)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(work, "go.mod"), []byte("module fossil.example.com/foo\n"), 0644))
+	fossil(t, work, "add", "go.mod")
+	fossil(t, work, "commit", "--no-warnings", "-m", "first", "--tag", "v1.0.0")
+	hash, err := parseFossilHash(fossil(t, work, "info", "v1.0.0"))
+	require.NoError(t, err)
+
+	options := MirrorOptions{Directory: filepath.Join(dir, "mirrors"), Timeout: time.Minute}
+	client := NewFossilClient(options).(*fossilClient)
+	client.remoteOf = func(*upstream.Request) string { return repo }
+
+	rc, err := client.Get(&upstream.Request{
+		Transport: "fossil+https",
+		Domain:    "fossil.example.com",
+		Namespace: []string{"foo"},
+		Version:   "v1.0.0",
+	})
+	require.NoError(t, err)
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+
+	require.Equal(t, []string{"foo-" + hash + "/go.mod"}, filesOf(t, bs))
+
+	// the mirror is a single file
+	info, err := os.Stat(filepath.Join(dir, "mirrors", ".fossil", "fossil.example.com", "foo.fossil"))
+	require.NoError(t, err)
+	require.False(t, info.IsDir())
+
+	usage, err := client.Collect()
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.Mirrors)
+	require.Equal(t, info.Size(), usage.Bytes)
+}
+
+// TestFossilClient_Get_fake runs the client against a fake fossil, for when
+// fossil is not installed to run TestFossilClient_Get.
+func TestFossilClient_Get_fake(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fossil-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	hash := strings.Repeat("cd", 20)
+	archive := filepath.Join(dir, "archive.zip")
+	writeZipFile(t, archive, "foo-"+hash+"/go.mod")
+
+	calls, restore := fakeCommand(t, dir, "fossil", `case "$1" in
+clone) touch "$3" ;;
+info) echo "hash:         `+hash+` 2020-01-02 03:04:05 UTC" ;;
+zip) cp `+archive+` "$3" ;;
+esac`)
+	defer restore()
+
+	tmpDir := filepath.Join(dir, "tmp")
+	require.NoError(t, os.MkdirAll(tmpDir, 0755))
+
+	options := MirrorOptions{Directory: filepath.Join(dir, "mirrors"), TmpDir: tmpDir, Timeout: time.Minute}
+	rc, err := NewFossilClient(options).Get(&upstream.Request{
+		Transport: "fossil+https",
+		Domain:    "fossil.example.com",
+		Namespace: []string{"foo"},
+		Version:   "v1.0.0",
+	})
+	require.NoError(t, err)
+	tmp := rc.(*tmpReader).Name()
+	require.Equal(t, tmpDir, filepath.Dir(tmp))
+
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, []string{"foo-" + hash + "/go.mod"}, filesOf(t, bs))
+
+	mirror := filepath.Join(dir, "mirrors", ".fossil", "fossil.example.com", "foo.fossil")
+	require.Equal(t, []string{
+		"clone https://fossil.example.com/foo " + mirror + ".tmp",
+		"info v1.0.0 -R " + mirror,
+		"zip " + hash + " " + tmp + " -R " + mirror + " --name foo-" + hash,
+	}, calls())
+}
+
+func Test_parseFossilHash(t *testing.T) {
+	try := func(info, exp string, expErr bool) {
+		hash, err := parseFossilHash(info)
+		if expErr {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, exp, hash)
+	}
+
+	try(`hash:         1f9d1c2e0a5b6c7d8e9f 2020-01-02 03:04:05 UTC
+parent:       0a1b2c3d4e5f 2020-01-01 03:04:05 UTC
+tags:         trunk, v1.0.0
+comment:      first (user: modprox)
+`, "1f9d1c2e0a5b6c7d8e9f", false)
+	try(`uuid:         1f9d1c2e0a5b6c7d8e9f 2020-01-02 03:04:05 UTC
+tags:         trunk
+`, "1f9d1c2e0a5b6c7d8e9f", false)
+	try("project-name: <unnamed>\n", "", true)
+}
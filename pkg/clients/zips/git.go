@@ -0,0 +1,252 @@
+package zips
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+// A GitClient is a MirrorClient which is also able to look up the revisions of
+// branches, tags and abbreviated commit hashes in its mirrors.
+type GitClient interface {
+	MirrorClient
+	upstream.Revisioner
+}
+
+type gitClient struct {
+	*mirrorCache
+	options  MirrorOptions
+	remoteOf func(*upstream.Request) string
+	log      loggy.Logger
+
+	revLock   sync.Mutex
+	revisions map[string]cachedRevision // of queries, by remote and query
+}
+
+type cachedRevision struct {
+	rev     upstream.Revision
+	expires time.Time
+}
+
+// NewGitClient creates an UpstreamClient which uses the git command to clone
+// and fetch upstream repositories over the git protocol, rather than relying
+// on the upstream to provide archives to download. Repositories are kept as
+// bare mirrors, so only new commits are fetched for later versions, and the
+// archive of a version is created from the mirror. Mirrors are kept in the
+// directory of options across restarts, until removed by Collect.
+//
+// The transport of a request decides how the repository is cloned, e.g. the
+// request for github.com/pkg/errors with transport git+ssh is for the
+// repository at ssh://git@github.com/pkg/errors.
+//
+// The Revision of a request with an http transport is looked up in a mirror
+// cloned over the same transport, the same as by NewMirroringClient. The
+// Revision of a query is remembered for the QueryTTL of options.
+func NewGitClient(options MirrorOptions) GitClient {
+	options = options.withDefaults("git")
+	log := loggy.New("zips-git")
+	return &gitClient{
+		mirrorCache: newMirrorCache(options, ".git", log),
+		options:     options,
+		remoteOf:    remoteOf,
+		log:         log,
+		revisions:   make(map[string]cachedRevision),
+	}
+}
+
+func (c *gitClient) Protocols() []string {
+	return []string{"git", "git+http", "git+https", "git+ssh", "ssh"}
+}
+
+func (c *gitClient) Get(r *upstream.Request) (io.ReadCloser, error) {
+	if r == nil {
+		return nil, errors.New("request is nil")
+	}
+	return c.get(c, r, c.remoteOf(r))
+}
+
+func (c *gitClient) Revision(r *upstream.Request) (upstream.Revision, error) {
+	var rev upstream.Revision
+	if r == nil {
+		return rev, errors.New("request is nil")
+	}
+
+	mirrored := *r
+	if r.Transport == "http" || r.Transport == "https" {
+		mirrored.Transport = "git+" + r.Transport
+	}
+	remote := c.remoteOf(&mirrored)
+
+	// a query fetches into the mirror, which is not worth doing again for
+	// every request of a branch, a tag or a commit made in quick succession
+	query := repository.IsQuery(r.Version)
+	key := remote + "@" + r.Version
+	if query {
+		if cached, exists := c.cachedRevision(key); exists {
+			c.log.Tracef("using cached revision of %s in %s", r.Version, remote)
+			return cached, nil
+		}
+	}
+
+	err := c.inspect(c, &mirrored, remote, func(mirror, commit string) error {
+		var err error
+		rev, err = c.revision(mirror, commit)
+		return err
+	})
+
+	if err == nil && query && c.options.QueryTTL > 0 {
+		c.revLock.Lock()
+		c.revisions[key] = cachedRevision{
+			rev:     rev,
+			expires: time.Now().Add(c.options.QueryTTL),
+		}
+		c.revLock.Unlock()
+	}
+
+	return rev, err
+}
+
+// cachedRevision returns the revision of a query remembered by key, if it
+// has not expired yet.
+func (c *gitClient) cachedRevision(key string) (upstream.Revision, bool) {
+	c.revLock.Lock()
+	defer c.revLock.Unlock()
+
+	cached, exists := c.revisions[key]
+	if exists && time.Now().After(cached.expires) {
+		delete(c.revisions, key)
+		exists = false
+	}
+	return cached.rev, exists
+}
+
+// revision looks up what decides the version of commit in the mirror, which
+// is the same as what cmd/go looks up.
+func (c *gitClient) revision(mirror, commit string) (upstream.Revision, error) {
+	rev := upstream.Revision{Hash: commit}
+
+	output, err := c.git(mirror, "log", "-1", "--format=%ct", commit)
+	if err != nil {
+		return rev, err
+	}
+	unix, err := strconv.ParseInt(strings.TrimSpace(output), 10, 64)
+	if err != nil {
+		return rev, errors.Wrapf(err, "unexpected time of commit %s", commit)
+	}
+	rev.Time = time.Unix(unix, 0).UTC()
+
+	if rev.Tagged, err = c.tags(mirror, "--points-at", commit); err != nil {
+		return rev, err
+	}
+
+	if rev.Tags, err = c.tags(mirror, "--merged", commit); err != nil {
+		return rev, err
+	}
+
+	// fails if there is no such file, which is all that is known about
+	// the modules of the repository, other than what is in the tags
+	_, err = c.git(mirror, "cat-file", "-e", commit+":go.mod")
+	rev.GoMod = err == nil
+
+	return rev, nil
+}
+
+func (c *gitClient) tags(mirror string, args ...string) ([]string, error) {
+	output, err := c.git(mirror, append([]string{"tag", "--list"}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(output), nil
+}
+
+func (c *gitClient) clone(r *upstream.Request, remote, mirror string) error {
+	c.log.Infof("cloning mirror of %s into %s", remote, mirror)
+
+	_, err := c.gitEnv("", headerEnv(r), "clone", "--mirror", "--quiet", remote, mirror)
+	return err
+}
+
+func (c *gitClient) fetch(r *upstream.Request, mirror string) error {
+	c.log.Infof("fetching new commits into %s", mirror)
+
+	_, err := c.gitEnv(mirror, headerEnv(r), "fetch", "--prune", "--quiet", "origin")
+	return err
+}
+
+// resolve returns the full hash of the commit of ref in the mirror, where ref
+// may be a tag, a branch, or an abbreviated commit hash.
+func (c *gitClient) resolve(mirror, ref string) (string, error) {
+	output, err := c.git(mirror, "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
+
+// archive streams the zip of commit from the mirror. Like the archives of a
+// code hosting service, every file is in a single top-level directory.
+// The release function is called once git archive is done.
+func (c *gitClient) archive(r *upstream.Request, mirror, commit string, release func()) (io.ReadCloser, error) {
+	prefix := fmt.Sprintf("%s-%s/", path.Base(projectOf(r)), commit)
+
+	return stream(c.options.Timeout, release, mirror, "git", "archive", "--format=zip", "--prefix="+prefix, commit)
+}
+
+// git runs the git command in dir, returning its output.
+func (c *gitClient) git(dir string, args ...string) (string, error) {
+	return run(c.options.Timeout, dir, "git", args...)
+}
+
+// gitEnv runs the git command in dir with env added to its environment,
+// returning its output.
+func (c *gitClient) gitEnv(dir string, env []string, args ...string) (string, error) {
+	return runEnv(c.options.Timeout, dir, env, "git", args...)
+}
+
+// headerEnv passes the headers of the request (e.g. credentials) along with
+// requests made over http, the same as they are by the http client. They are
+// configured through the environment rather than with arguments, so that they
+// do not show up in the list of processes.
+func headerEnv(r *upstream.Request) []string {
+	if len(r.Headers) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(r.Headers))
+	for k := range r.Headers {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	env := []string{"GIT_CONFIG_COUNT=" + strconv.Itoa(len(keys))}
+	for i, k := range keys {
+		env = append(env,
+			fmt.Sprintf("GIT_CONFIG_KEY_%d=http.extraHeader", i),
+			fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s: %s", i, k, r.Headers[k]),
+		)
+	}
+	return env
+}
+
+// e.g. git+ssh, github.com/pkg/errors => ssh://git@github.com/pkg/errors
+// e.g. git+https, gitlab.com/group/project/v2 => https://gitlab.com/group/project
+func remoteOf(r *upstream.Request) string {
+	scheme := strings.TrimPrefix(r.Transport, "git+")
+	host := r.Domain
+	if scheme == "ssh" {
+		host = "git@" + host
+	}
+	return fmt.Sprintf("%s://%s/%s", scheme, host, projectOf(r))
+}
@@ -0,0 +1,347 @@
+package zips
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+// upstreamRepo is a bare repository standing in for an upstream, with a
+// working copy from which commits are pushed to it.
+type upstreamRepo struct {
+	t    *testing.T
+	bare string
+	work string
+}
+
+func newUpstreamRepo(t *testing.T, dir string) *upstreamRepo {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	r := &upstreamRepo{
+		t:    t,
+		bare: filepath.Join(dir, "upstream", "foo.git"),
+		work: filepath.Join(dir, "work"),
+	}
+
+	r.git("", "init", "--quiet", "--bare", r.bare)
+	r.git("", "init", "--quiet", r.work)
+	r.git(r.work, "remote", "add", "origin", r.bare)
+	return r
+}
+
+func (r *upstreamRepo) git(dir string, args ...string) string {
+	cmd := exec.Command("git", append([]string{
+		"-c", "user.name=modprox",
+		"-c", "user.email=modprox@example.com",
+	}, args...)...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(r.t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+// commit the files into the working copy, tag it and push it upstream, and
+// return the hash of the commit
+func (r *upstreamRepo) commit(tag string, files map[string]string) string {
+	for name, content := range files {
+		file := filepath.Join(r.work, name)
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(r.t, ioutil.WriteFile(file, []byte(content), 0644))
+	}
+
+	r.git(r.work, "add", "--all")
+	r.git(r.work, "commit", "--quiet", "-m", "commit "+tag)
+	if tag != "" {
+		r.git(r.work, "tag", tag)
+	}
+	r.git(r.work, "push", "--quiet", "--tags", "origin", "HEAD:refs/heads/master")
+	return r.git(r.work, "rev-parse", "HEAD")
+}
+
+func newTestGitClient(t *testing.T, dir string, repo *upstreamRepo) *gitClient {
+	options := MirrorOptions{
+		Directory: filepath.Join(dir, "mirrors"),
+		Timeout:   time.Minute,
+	}
+	log := loggy.New("zips-git-test")
+	return &gitClient{
+		mirrorCache: newMirrorCache(options, ".git", log),
+		options:     options,
+		remoteOf: func(r *upstream.Request) string {
+			return repo.bare
+		},
+		log:       log,
+		revisions: make(map[string]cachedRevision),
+	}
+}
+
+func gitRequest(version string) *upstream.Request {
+	return &upstream.Request{
+		Transport: "git+https",
+		Domain:    "code.example.com",
+		Namespace: []string{"go", "foo"},
+		Version:   version,
+	}
+}
+
+func filesOf(t *testing.T, blob repository.Blob) []string {
+	reader, err := zip.NewReader(bytes.NewReader(blob), int64(len(blob)))
+	require.NoError(t, err)
+
+	var names []string
+	for _, f := range reader.File {
+		if !strings.HasSuffix(f.Name, "/") {
+			names = append(names, f.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func getBlob(t *testing.T, client *gitClient, version string) repository.Blob {
+	rc, err := client.Get(gitRequest(version))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, rc.Close()) }()
+
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	return repository.Blob(bs)
+}
+
+func TestGitClient_Get(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := newUpstreamRepo(t, dir)
+	first := repo.commit("v1.0.0", map[string]string{
+		"go.mod": "module code.example.com/go/foo\n",
+		"foo.go": "package foo\n",
+	})
+
+	client := newTestGitClient(t, dir, repo)
+
+	// the first request clones the mirror
+	blob := getBlob(t, client, "v1.0.0")
+	require.Equal(t, []string{
+		"foo-" + first + "/foo.go",
+		"foo-" + first + "/go.mod",
+	}, filesOf(t, blob))
+
+	mirror := filepath.Join(dir, "mirrors", "code.example.com", "go", "foo.git")
+	_, err = os.Stat(mirror)
+	require.NoError(t, err)
+
+	// the archive is like those of a code hosting service, so it is
+	// rewritten into a module zip the same way
+	mod := coordinates.Module{Source: "code.example.com/go/foo", Version: "v1.0.0"}
+	rewritten, err := Rewrite(mod, blob)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"code.example.com/go/foo@v1.0.0/foo.go",
+		"code.example.com/go/foo@v1.0.0/go.mod",
+	}, filesOf(t, rewritten))
+
+	// later versions are fetched into the existing mirror
+	second := repo.commit("v1.1.0", map[string]string{
+		"bar.go": "package foo\n",
+	})
+	blob = getBlob(t, client, "v1.1.0")
+	require.Equal(t, []string{
+		"foo-" + second + "/bar.go",
+		"foo-" + second + "/foo.go",
+		"foo-" + second + "/go.mod",
+	}, filesOf(t, blob))
+
+	// as are untagged commits of pseudo-versions
+	third := repo.commit("", map[string]string{
+		"baz.go": "package foo\n",
+	})
+	pseudo := "v1.1.1-0.20200102030405-" + third[:12]
+	blob = getBlob(t, client, pseudo)
+	require.Len(t, filesOf(t, blob), 4)
+
+	// versions already in the mirror are not fetched again
+	require.NoError(t, os.RemoveAll(repo.bare))
+	blob = getBlob(t, client, "v1.0.0")
+	require.Len(t, filesOf(t, blob), 2)
+}
+
+func TestGitClient_Get_no_version(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := newUpstreamRepo(t, dir)
+	repo.commit("v1.0.0", map[string]string{
+		"go.mod": "module code.example.com/go/foo\n",
+	})
+
+	client := newTestGitClient(t, dir, repo)
+
+	_, err = client.Get(gitRequest("v2.0.0"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no revision for v2.0.0")
+
+	_, err = client.Get(gitRequest("-v1.0.0"))
+	require.EqualError(t, err, `invalid version "-v1.0.0"`)
+}
+
+func TestGitClient_Get_no_repository(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := newUpstreamRepo(t, dir)
+	require.NoError(t, os.RemoveAll(repo.bare))
+
+	client := newTestGitClient(t, dir, repo)
+
+	_, err = client.Get(gitRequest("v1.0.0"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to clone")
+
+	// nothing is left behind for the next attempt
+	_, err = os.Stat(filepath.Join(dir, "mirrors", "code.example.com", "go", "foo.git"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestGitClient_Revision(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := newUpstreamRepo(t, dir)
+	first := repo.commit("v1.0.0", map[string]string{
+		"foo.go": "package foo\n",
+	})
+	second := repo.commit("", map[string]string{
+		"go.mod": "module code.example.com/go/foo\n",
+	})
+
+	client := newTestGitClient(t, dir, repo)
+
+	// a branch
+	rev, err := client.Revision(gitRequest("master"))
+	require.NoError(t, err)
+	require.Equal(t, second, rev.Hash)
+	require.Empty(t, rev.Tagged)
+	require.Equal(t, []string{"v1.0.0"}, rev.Tags)
+	require.True(t, rev.GoMod)
+	require.Equal(t, time.UTC, rev.Time.Location())
+	require.WithinDuration(t, time.Now(), rev.Time, time.Hour)
+
+	// an abbreviated commit hash
+	rev, err = client.Revision(gitRequest(first[:7]))
+	require.NoError(t, err)
+	require.Equal(t, first, rev.Hash)
+	require.Equal(t, []string{"v1.0.0"}, rev.Tagged)
+	require.False(t, rev.GoMod)
+
+	// branches are always fetched, as they move on
+	third := repo.commit("", map[string]string{
+		"bar.go": "package foo\n",
+	})
+	rev, err = client.Revision(gitRequest("master"))
+	require.NoError(t, err)
+	require.Equal(t, third, rev.Hash)
+
+	_, err = client.Revision(gitRequest("nope"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no revision for nope")
+}
+
+func TestGitClient_Revision_cached(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := newUpstreamRepo(t, dir)
+	first := repo.commit("v1.0.0", map[string]string{
+		"go.mod": "module code.example.com/go/foo\n",
+	})
+
+	client := newTestGitClient(t, dir, repo)
+	client.options.QueryTTL = time.Hour
+
+	rev, err := client.Revision(gitRequest("master"))
+	require.NoError(t, err)
+	require.Equal(t, first, rev.Hash)
+
+	// the branch moved on, but is not fetched again for a while
+	second := repo.commit("", map[string]string{
+		"foo.go": "package foo\n",
+	})
+	rev, err = client.Revision(gitRequest("master"))
+	require.NoError(t, err)
+	require.Equal(t, first, rev.Hash)
+
+	// until the revision expires
+	for key, cached := range client.revisions {
+		cached.expires = time.Now().Add(-time.Second)
+		client.revisions[key] = cached
+	}
+	rev, err = client.Revision(gitRequest("master"))
+	require.NoError(t, err)
+	require.Equal(t, second, rev.Hash)
+}
+
+func Test_remoteOf(t *testing.T) {
+	try := func(transport string, namespace []string, exp string) {
+		remote := remoteOf(&upstream.Request{
+			Transport: transport,
+			Domain:    "code.example.com",
+			Namespace: namespace,
+			Version:   "v1.0.0",
+		})
+		require.Equal(t, exp, remote)
+	}
+
+	try("git+https", []string{"go", "foo"}, "https://code.example.com/go/foo")
+	try("git+http", []string{"go", "foo"}, "http://code.example.com/go/foo")
+	try("git+ssh", []string{"go", "foo"}, "ssh://git@code.example.com/go/foo")
+	try("ssh", []string{"go", "foo"}, "ssh://git@code.example.com/go/foo")
+	try("git", []string{"go", "foo"}, "git://code.example.com/go/foo")
+	try("git+https", []string{"group", "sub", "foo", "v2"}, "https://code.example.com/group/sub/foo")
+}
+
+func Test_headerEnv(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "zips-git-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	r := gitRequest("v1.0.0")
+	require.Empty(t, headerEnv(r))
+
+	r.Headers = map[string]string{
+		"Private-Token": "abc123",
+		"X-Other":       "other",
+	}
+	env := headerEnv(r)
+
+	// git sees the headers as configuration, without them being arguments
+	output, err := runEnv(time.Minute, dir, env, "git", "config", "--get-all", "http.extraHeader")
+	require.NoError(t, err)
+	require.Equal(t, "Private-Token: abc123\nX-Other: other\n", output)
+}
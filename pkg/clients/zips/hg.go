@@ -0,0 +1,113 @@
+package zips
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+type hgClient struct {
+	*mirrorCache
+	options  MirrorOptions
+	remoteOf func(*upstream.Request) string
+	log      loggy.Logger
+}
+
+// NewHgClient creates an UpstreamClient which uses the hg command to clone and
+// pull upstream Mercurial repositories, e.g. those of a go-import meta tag with
+// the hg VCS. Like with NewGitClient, repositories are kept as mirrors (without
+// a working copy), in the .hg directory of the directory of options.
+//
+// The transport of a request decides how the repository is cloned, e.g. the
+// request for hg.example.com/go/foo with transport hg+https is for the
+// repository at https://hg.example.com/go/foo.
+func NewHgClient(options MirrorOptions) MirrorClient {
+	options = options.withDefaults("hg")
+	options.Directory = filepath.Join(options.Directory, ".hg")
+	log := loggy.New("zips-hg")
+	return &hgClient{
+		mirrorCache: newMirrorCache(options, ".hg", log),
+		options:     options,
+		remoteOf:    vcsRemoteOf("hg"),
+		log:         log,
+	}
+}
+
+func (c *hgClient) Protocols() []string {
+	return []string{"hg+http", "hg+https", "hg+ssh"}
+}
+
+func (c *hgClient) Get(r *upstream.Request) (io.ReadCloser, error) {
+	if r == nil {
+		return nil, errors.New("request is nil")
+	}
+	return c.get(c, r, c.remoteOf(r))
+}
+
+func (c *hgClient) clone(r *upstream.Request, remote, mirror string) error {
+	c.log.Infof("cloning mirror of %s into %s", remote, mirror)
+
+	_, err := c.hg("", "clone", "--noupdate", "--quiet", remote, mirror)
+	return err
+}
+
+func (c *hgClient) fetch(r *upstream.Request, mirror string) error {
+	c.log.Infof("pulling new changesets into %s", mirror)
+
+	_, err := c.hg(mirror, "pull", "--quiet")
+	return err
+}
+
+// resolve returns the full hash of the changeset of ref in the mirror, where
+// ref may be a tag, a branch, a bookmark, or an abbreviated changeset hash.
+func (c *hgClient) resolve(mirror, ref string) (string, error) {
+	output, err := c.hg(mirror, "log", "--limit", "1", "--rev", ref, "--template", "{node}")
+	if err != nil {
+		return "", err
+	}
+
+	node := strings.TrimSpace(output)
+	if node == "" {
+		return "", errors.Errorf("no changeset for %s", ref)
+	}
+	return node, nil
+}
+
+// archive creates the zip of the changeset node from the mirror, with every
+// file in a single top-level directory. Along with the files of the changeset
+// there is a .hg_archival.txt, which Rewrite leaves out of the module zip.
+func (c *hgClient) archive(r *upstream.Request, mirror, node string, release func()) (io.ReadCloser, error) {
+	prefix := fmt.Sprintf("%s-%s/", path.Base(projectOf(r)), node)
+
+	return runZip(c.options.Timeout, release, c.options.TmpDir, mirror, "hg", func(zip string) []string {
+		return []string{"archive", "--rev", node, "--type", "zip", "--prefix", prefix, zip}
+	})
+}
+
+// hg runs the hg command in dir, returning its output.
+func (c *hgClient) hg(dir string, args ...string) (string, error) {
+	return run(c.options.Timeout, dir, "hg", args...)
+}
+
+// vcsRemoteOf returns the function creating the remote of a request with the
+// transport of the VCS named vcs, i.e. <vcs>+<scheme>.
+//
+// e.g. hg+https, hg.example.com/go/foo => https://hg.example.com/go/foo
+// e.g. svn, svn.example.com/repos/foo => svn://svn.example.com/repos/foo
+func vcsRemoteOf(vcs string) func(*upstream.Request) string {
+	return func(r *upstream.Request) string {
+		scheme := strings.TrimPrefix(r.Transport, vcs+"+")
+		if scheme == "ssh" && vcs == "svn" {
+			scheme = "svn+ssh" // svn is particular about its tunnels
+		}
+		return fmt.Sprintf("%s://%s/%s", scheme, r.Domain, projectOf(r))
+	}
+}
@@ -0,0 +1,158 @@
+package zips
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+func hg(t *testing.T, dir string, args ...string) string {
+	cmd := exec.Command("hg", append([]string{"--config", "ui.username=modprox"}, args...)...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+	return strings.TrimSpace(string(output))
+}
+
+func hgRequest(version string) *upstream.Request {
+	return &upstream.Request{
+		Transport: "hg+https",
+		Domain:    "hg.example.com",
+		Namespace: []string{"go", "foo"},
+		Version:   version,
+	}
+}
+
+func TestHgClient_Get(t *testing.T) {
+	if _, err := exec.LookPath("hg"); err != nil {
+		t.Skip("hg is not installed")
+	}
+
+	dir, err := ioutil.TempDir("", "hg-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := filepath.Join(dir, "upstream", "foo")
+	hg(t, "", "init", repo)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(repo, "go.mod"), []byte("module hg.example.com/go/foo\n"), 0644))
+	hg(t, repo, "commit", "--addremove", "--message", "first")
+	node := hg(t, repo, "log", "--limit", "1", "--template", "{node}")
+	hg(t, repo, "tag", "v1.0.0")
+
+	options := MirrorOptions{Directory: filepath.Join(dir, "mirrors"), Timeout: time.Minute}
+	client := NewHgClient(options).(*hgClient)
+	client.remoteOf = func(*upstream.Request) string { return repo }
+
+	rc, err := client.Get(hgRequest("v1.0.0"))
+	require.NoError(t, err)
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+
+	blob := repository.Blob(bs)
+	require.Equal(t, []string{
+		"foo-" + node + "/.hg_archival.txt",
+		"foo-" + node + "/go.mod",
+	}, filesOf(t, blob))
+
+	// kept apart from the mirrors of git
+	_, err = os.Stat(filepath.Join(dir, "mirrors", ".hg", "hg.example.com", "go", "foo.hg"))
+	require.NoError(t, err)
+
+	mod := coordinates.Module{Source: "hg.example.com/go/foo", Version: "v1.0.0"}
+	rewritten, err := Rewrite(mod, blob)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"hg.example.com/go/foo@v1.0.0/go.mod",
+	}, filesOf(t, rewritten))
+
+	_, err = client.Get(hgRequest("v2.0.0"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no revision for v2.0.0")
+}
+
+// TestHgClient_Get_fake runs the client against a fake hg, for when hg
+// is not installed to run TestHgClient_Get.
+func TestHgClient_Get_fake(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hg-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	node := strings.Repeat("ab", 20)
+	archive := filepath.Join(dir, "archive.zip")
+	writeZipFile(t, archive, "foo-"+node+"/.hg_archival.txt", "foo-"+node+"/go.mod")
+
+	calls, restore := fakeCommand(t, dir, "hg", `case "$1" in
+clone) mkdir -p "$5" ;;
+log) printf '`+node+`' ;;
+archive) cp `+archive+` "$8" ;;
+esac`)
+	defer restore()
+
+	tmpDir := filepath.Join(dir, "tmp")
+	require.NoError(t, os.MkdirAll(tmpDir, 0755))
+
+	options := MirrorOptions{Directory: filepath.Join(dir, "mirrors"), TmpDir: tmpDir, Timeout: time.Minute}
+	rc, err := NewHgClient(options).Get(hgRequest("v1.0.0"))
+	require.NoError(t, err)
+	tmp := rc.(*tmpReader).Name()
+	require.Equal(t, tmpDir, filepath.Dir(tmp))
+
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, []string{
+		"foo-" + node + "/.hg_archival.txt",
+		"foo-" + node + "/go.mod",
+	}, filesOf(t, bs))
+
+	mirror := filepath.Join(dir, "mirrors", ".hg", "hg.example.com", "go", "foo.hg")
+	require.Equal(t, []string{
+		"clone --noupdate --quiet https://hg.example.com/go/foo " + mirror + ".tmp",
+		"log --limit 1 --rev v1.0.0 --template {node}",
+		"archive --rev " + node + " --type zip --prefix foo-" + node + "/ " + tmp,
+	}, calls())
+}
+
+func TestHgClient_Collect_empty(t *testing.T) {
+	dir, err := ioutil.TempDir("", "hg-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	// the mirrors of git are not those of hg
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "code.example.com", "go", "foo.git"), 0755))
+
+	usage, err := NewHgClient(MirrorOptions{Directory: dir}).Collect()
+	require.NoError(t, err)
+	require.Equal(t, MirrorUsage{}, usage)
+}
+
+func Test_vcsRemoteOf(t *testing.T) {
+	try := func(vcs, transport string, exp string) {
+		remote := vcsRemoteOf(vcs)(&upstream.Request{
+			Transport: transport,
+			Domain:    "code.example.com",
+			Namespace: []string{"repos", "foo"},
+			Version:   "v1.0.0",
+		})
+		require.Equal(t, exp, remote)
+	}
+
+	try("hg", "hg+https", "https://code.example.com/repos/foo")
+	try("hg", "hg+ssh", "ssh://code.example.com/repos/foo")
+	try("fossil", "fossil+http", "http://code.example.com/repos/foo")
+	try("svn", "svn", "svn://code.example.com/repos/foo")
+	try("svn", "svn+ssh", "svn+ssh://code.example.com/repos/foo")
+	try("svn", "svn+https", "https://code.example.com/repos/foo")
+}
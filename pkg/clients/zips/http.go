@@ -1,6 +1,7 @@
 package zips
 
 import (
+	"io"
 	"io/ioutil"
 	"net/http"
 	"time"
@@ -10,7 +11,6 @@ import (
 	"gophers.dev/pkgs/ignore"
 	"gophers.dev/pkgs/loggy"
 
-	"oss.indeed.com/go/modprox/pkg/repository"
 	"oss.indeed.com/go/modprox/pkg/upstream"
 )
 
@@ -44,7 +44,7 @@ func (c *httpClient) Protocols() []string {
 
 }
 
-func (c *httpClient) Get(r *upstream.Request) (repository.Blob, error) {
+func (c *httpClient) Get(r *upstream.Request) (io.ReadCloser, error) {
 	if r == nil {
 		return nil, errors.New("request is nil")
 	}
@@ -61,10 +61,10 @@ func (c *httpClient) Get(r *upstream.Request) (repository.Blob, error) {
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not do request for %s", zipURI)
 	}
-	defer ignore.Drain(response.Body)
 
 	// if we get a bad response code, try to read the body and log it
 	if response.StatusCode >= 400 {
+		defer ignore.Drain(response.Body)
 		bs, err := ioutil.ReadAll(response.Body)
 		if err != nil {
 			return nil, errors.Wrapf(err, "could not read body of bad response (%d)", response.StatusCode)
@@ -83,15 +83,14 @@ func (c *httpClient) Get(r *upstream.Request) (repository.Blob, error) {
 				body[:maxLoggedBody],
 			)
 		}
-		return nil, errors.Wrapf(
-			err,
+		return nil, errors.Errorf(
 			"unexpected response (%d)",
 			response.StatusCode,
 		)
 	}
 
-	// response is good, read the bytes
-	return ioutil.ReadAll(response.Body)
+	// response is good, the caller streams the bytes
+	return response.Body, nil
 }
 
 func (c *httpClient) newRequest(r *upstream.Request) (*http.Request, error) {
@@ -0,0 +1,522 @@
+package zips
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+type MirrorOptions struct {
+	// Directory is where the mirrors of upstream repositories are kept.
+	Directory string
+
+	// Timeout applies to cloning or fetching a repository, and separately
+	// to creating an archive from it.
+	Timeout time.Duration
+
+	// TmpDir is where zips made from mirrors are kept until they have been
+	// read. The system temporary directory is used if empty.
+	TmpDir string
+
+	// MaxSize is the number of bytes the mirrors may take up before the
+	// least recently used are removed by Collect. Zero means no limit.
+	MaxSize int64
+
+	// MaxUnused is how long a mirror may go unused before it is removed by
+	// Collect. Zero means mirrors are kept no matter how long unused.
+	MaxUnused time.Duration
+
+	// QueryTTL is how long the revision of a query (e.g. a branch) is
+	// remembered, rather than fetching into the mirror every time to see
+	// whether the branch has moved on. Zero means it is never remembered.
+	QueryTTL time.Duration
+}
+
+// DefaultQueryTTL is how long the revision of a query is remembered, unless
+// configured otherwise.
+const DefaultQueryTTL = 1 * time.Minute
+
+func (o MirrorOptions) withDefaults(vcs string) MirrorOptions {
+	if o.Directory == "" {
+		panic("no directory set for " + vcs + " client")
+	}
+	o.Directory = filepath.Clean(o.Directory)
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Minute
+	}
+	return o
+}
+
+// A MirrorClient is an UpstreamClient which keeps a mirror of every upstream
+// repository it has been asked for.
+type MirrorClient interface {
+	UpstreamClient
+
+	// Collect removes mirrors which have not been used for longer than
+	// MaxUnused, and then the least recently used mirrors until the rest
+	// take up no more than MaxSize.
+	Collect() (MirrorUsage, error)
+}
+
+// MirrorUsage describes the mirrors kept by a MirrorClient, after a Collect.
+type MirrorUsage struct {
+	Mirrors      int   // mirrors kept
+	Bytes        int64 // taken up by the mirrors kept
+	Removed      int   // mirrors removed
+	RemovedBytes int64 // freed up by the mirrors removed
+}
+
+// A mirrorer is a VCS which keeps mirrors of upstream repositories, which
+// are all named with the same suffix.
+type mirrorer interface {
+	// clone remote into a new mirror
+	clone(r *upstream.Request, remote, mirror string) error
+
+	// fetch new revisions into an existing mirror
+	fetch(r *upstream.Request, mirror string) error
+
+	// resolve returns the full id of the revision of ref in the mirror
+	resolve(mirror, ref string) (string, error)
+
+	// archive streams the zip of a revision of the mirror, which has every
+	// file in a single top-level directory, and calls release once done
+	archive(r *upstream.Request, mirror, id string, release func()) (io.ReadCloser, error)
+}
+
+// mirrorCache keeps track of the mirrors of one VCS in a directory, e.g.
+// <directory>/github.com/pkg/errors.git for git.
+type mirrorCache struct {
+	options MirrorOptions
+	suffix  string
+	log     loggy.Logger
+
+	lock  sync.Mutex
+	locks map[string]*sync.Mutex // by path of the mirror
+}
+
+func newMirrorCache(options MirrorOptions, suffix string, log loggy.Logger) *mirrorCache {
+	return &mirrorCache{
+		options: options,
+		suffix:  suffix,
+		log:     log,
+		locks:   make(map[string]*sync.Mutex),
+	}
+}
+
+// get returns the archive of the version of r, from the mirror of remote,
+// which is cloned or fetched into first if necessary.
+func (m *mirrorCache) get(vcs mirrorer, r *upstream.Request, remote string) (io.ReadCloser, error) {
+	mirror := m.mirrorOf(r)
+
+	// held until the archive is read, so the mirror is never
+	// fetched into or removed while an archive is made from it
+	lock := m.lockOf(mirror)
+	lock.Lock()
+
+	id, err := m.sync(vcs, r, remote, mirror)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+
+	m.touch(mirror)
+
+	m.log.Tracef("making zip of %s at %s from %s", remote, id, mirror)
+	rc, err := vcs.archive(r, mirror, id, lock.Unlock)
+	if err != nil {
+		lock.Unlock()
+		return nil, err
+	}
+	return rc, nil
+}
+
+// inspect calls f with the mirror of remote and the full id of the revision of
+// the version of r, which is fetched first if necessary, while holding the
+// lock of the mirror.
+func (m *mirrorCache) inspect(vcs mirrorer, r *upstream.Request, remote string, f func(mirror, id string) error) error {
+	mirror := m.mirrorOf(r)
+
+	lock := m.lockOf(mirror)
+	lock.Lock()
+	defer lock.Unlock()
+
+	id, err := m.sync(vcs, r, remote, mirror)
+	if err != nil {
+		return err
+	}
+
+	m.touch(mirror)
+	return f(mirror, id)
+}
+
+// sync makes sure the mirror of remote has the revision of the version of r,
+// fetching from remote if necessary, and returns the full id of the revision.
+// The lock of the mirror must be held.
+func (m *mirrorCache) sync(vcs mirrorer, r *upstream.Request, remote, mirror string) (string, error) {
+	ref := r.Ref()
+	if ref == "" || strings.HasPrefix(ref, "-") {
+		return "", errors.Errorf("invalid version %q", r.Version)
+	}
+
+	_, err := os.Stat(mirror)
+	switch {
+	case os.IsNotExist(err):
+		if err := m.clone(vcs, r, remote, mirror); err != nil {
+			return "", err
+		}
+	case err != nil:
+		return "", err
+	default:
+		// a version never refers to a different revision, so there
+		// is no need to fetch if the mirror already has it, unlike
+		// a branch which may have moved on since
+		if !repository.IsQuery(r.Version) {
+			if id, err := vcs.resolve(mirror, ref); err == nil {
+				return id, nil
+			}
+		}
+		if err := vcs.fetch(r, mirror); err != nil {
+			return "", errors.Wrapf(err, "failed to fetch into %s", mirror)
+		}
+	}
+
+	id, err := vcs.resolve(mirror, ref)
+	if err != nil {
+		return "", errors.Wrapf(err, "no revision for %s in %s", ref, remote)
+	}
+	return id, nil
+}
+
+func (m *mirrorCache) clone(vcs mirrorer, r *upstream.Request, remote, mirror string) error {
+	if err := os.MkdirAll(filepath.Dir(mirror), 0755); err != nil {
+		return err
+	}
+
+	// clone next to where the mirror goes, so a failed clone never
+	// leaves behind a mirror which is incomplete
+	tmp := mirror + ".tmp"
+	_ = os.RemoveAll(tmp)
+
+	if err := vcs.clone(r, remote, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return cloneFailure{errors.Wrapf(err, "failed to clone %s", remote)}
+	}
+
+	return os.Rename(tmp, mirror)
+}
+
+// cloneFailure is the error of a repository which could not be cloned at all,
+// as opposed to one which was cloned, but does not have a version.
+type cloneFailure struct {
+	error
+}
+
+func isCloneFailure(err error) bool {
+	_, is := errors.Cause(err).(cloneFailure)
+	return is
+}
+
+// touch marks mirror as used now, by its modification time, which is kept
+// across restarts.
+func (m *mirrorCache) touch(mirror string) {
+	now := time.Now()
+	if err := os.Chtimes(mirror, now, now); err != nil {
+		m.log.Warnf("failed to mark %s as used, %v", mirror, err)
+	}
+}
+
+func (m *mirrorCache) lockOf(mirror string) *sync.Mutex {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	lock, exists := m.locks[mirror]
+	if !exists {
+		lock = new(sync.Mutex)
+		m.locks[mirror] = lock
+	}
+	return lock
+}
+
+// e.g. github.com/pkg/errors => <directory>/github.com/pkg/errors.git
+func (m *mirrorCache) mirrorOf(r *upstream.Request) string {
+	return filepath.Join(
+		m.options.Directory,
+		r.Domain,
+		filepath.FromSlash(projectOf(r))+m.suffix,
+	)
+}
+
+// a mirror in the mirrorCache
+type mirror struct {
+	cache *mirrorCache
+	path  string
+	size  int64
+	used  time.Time
+}
+
+func (m *mirrorCache) Collect() (MirrorUsage, error) {
+	return collect(m.options, []*mirrorCache{m})
+}
+
+// cache is the mirrorCache of the MirrorClient it is embedded in.
+func (m *mirrorCache) cache() *mirrorCache {
+	return m
+}
+
+// CollectAll is the Collect of every one of clients at once, so that MaxSize
+// applies to all of their mirrors together rather than to the mirrors of each.
+// The MaxSize and MaxUnused of the first of clients apply.
+func CollectAll(clients ...MirrorClient) (MirrorUsage, error) {
+	if len(clients) == 0 {
+		return MirrorUsage{}, nil
+	}
+
+	caches := make([]*mirrorCache, 0, len(clients))
+	for _, client := range clients {
+		cached, ok := client.(interface{ cache() *mirrorCache })
+		if !ok {
+			return MirrorUsage{}, errors.Errorf("%T does not keep mirrors", client)
+		}
+		caches = append(caches, cached.cache())
+	}
+
+	return collect(caches[0].options, caches)
+}
+
+func collect(options MirrorOptions, caches []*mirrorCache) (MirrorUsage, error) {
+	var usage MirrorUsage
+
+	var mirrors []mirror
+	for _, cache := range caches {
+		listed, err := cache.list()
+		if err != nil {
+			return usage, err
+		}
+		mirrors = append(mirrors, listed...)
+	}
+
+	var total int64
+	for _, mirror := range mirrors {
+		total += mirror.size
+	}
+
+	// least recently used first
+	sort.Slice(mirrors, func(x, y int) bool {
+		return mirrors[x].used.Before(mirrors[y].used)
+	})
+
+	now := time.Now()
+	for _, mirror := range mirrors {
+		unused := options.MaxUnused > 0 && now.Sub(mirror.used) > options.MaxUnused
+		over := options.MaxSize > 0 && total > options.MaxSize
+		if !unused && !over {
+			usage.Mirrors++
+			usage.Bytes += mirror.size
+			continue
+		}
+
+		log := mirror.cache.log
+		removed, err := mirror.cache.remove(mirror)
+		if err != nil {
+			log.Warnf("failed to remove mirror %s, %v", mirror.path, err)
+		}
+		if !removed {
+			usage.Mirrors++
+			usage.Bytes += mirror.size
+			continue
+		}
+
+		log.Infof("removed mirror %s, %d bytes last used %s", mirror.path, mirror.size, mirror.used)
+		usage.Removed++
+		usage.RemovedBytes += mirror.size
+		total -= mirror.size
+	}
+
+	return usage, nil
+}
+
+// list returns every mirror in the directory of the mirrorCache.
+func (m *mirrorCache) list() ([]mirror, error) {
+	var mirrors []mirror
+
+	root := m.options.Directory
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == root {
+			return nil
+		}
+
+		// domains never begin with a dot, so these are the mirrors of
+		// other VCSs, or a clone which is still going (or never finished)
+		if strings.HasPrefix(info.Name(), ".") || strings.HasSuffix(path, m.suffix+".tmp") {
+			return skip(info)
+		}
+
+		if !strings.HasSuffix(path, m.suffix) {
+			return nil
+		}
+
+		size, err := sizeOf(path)
+		if err != nil {
+			return err
+		}
+
+		mirrors = append(mirrors, mirror{
+			cache: m,
+			path:  path,
+			size:  size,
+			used:  info.ModTime(),
+		})
+		return skip(info)
+	})
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return mirrors, err
+}
+
+// skip the rest of a directory, or nothing more than a file
+func skip(info os.FileInfo) error {
+	if info.IsDir() {
+		return filepath.SkipDir
+	}
+	return nil
+}
+
+// remove removes mirror, unless it has been used since it was listed.
+func (m *mirrorCache) remove(mirror mirror) (bool, error) {
+	lock := m.lockOf(mirror.path)
+	lock.Lock()
+	defer lock.Unlock()
+
+	info, err := os.Stat(mirror.path)
+	if err != nil {
+		return false, err
+	}
+
+	if info.ModTime().After(mirror.used) {
+		return false, nil
+	}
+
+	if err := os.RemoveAll(mirror.path); err != nil {
+		return false, err
+	}
+
+	// along with the directories of the domain and namespace,
+	// once they no longer have any mirrors in them
+	for dir := filepath.Dir(mirror.path); len(dir) > len(m.options.Directory); dir = filepath.Dir(dir) {
+		if os.Remove(dir) != nil {
+			break
+		}
+	}
+
+	return true, nil
+}
+
+// sizeOf returns the bytes taken up by the files of a mirror, which is either
+// a directory or a single file.
+func sizeOf(path string) (int64, error) {
+	var size int64
+	err := filepath.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			size += info.Size()
+		}
+		return nil
+	})
+	return size, err
+}
+
+// DefaultCloneRetry is how long a repository which could not be cloned is
+// downloaded as an archive instead, before cloning it is tried again, unless
+// configured otherwise.
+const DefaultCloneRetry = 1 * time.Hour
+
+// mirroringClient downloads the archives of requests with an http transport
+// by cloning the repository over the same transport into a mirror, and only
+// downloads the archive over http if the repository could not be cloned, e.g.
+// because the upstream is not a git server.
+type mirroringClient struct {
+	mirrors    UpstreamClient
+	archives   UpstreamClient
+	retryAfter time.Duration
+	now        func() time.Time
+	log        loggy.Logger
+
+	lock   sync.Mutex
+	failed map[string]time.Time // when to retry cloning, by repository
+}
+
+// NewMirroringClient creates an UpstreamClient which handles requests with an
+// http transport using mirrors, falling back to archives. A repository which
+// could not be cloned is not tried again for retryAfter, and its archives are
+// downloaded right away instead.
+func NewMirroringClient(mirrors, archives UpstreamClient, retryAfter time.Duration) UpstreamClient {
+	return &mirroringClient{
+		mirrors:    mirrors,
+		archives:   archives,
+		retryAfter: retryAfter,
+		now:        time.Now,
+		log:        loggy.New("zips-mirroring"),
+		failed:     make(map[string]time.Time),
+	}
+}
+
+func (c *mirroringClient) Protocols() []string {
+	return []string{"http", "https"}
+}
+
+func (c *mirroringClient) Get(r *upstream.Request) (io.ReadCloser, error) {
+	repo := r.Domain + "/" + projectOf(r)
+	if c.cloneFailed(repo) {
+		c.log.Tracef("%s could not be cloned recently, downloading archive of %s", repo, r)
+		return c.archives.Get(r)
+	}
+
+	mirrored := *r
+	mirrored.Transport = "git+" + r.Transport
+
+	rc, err := c.mirrors.Get(&mirrored)
+	if err == nil {
+		return rc, nil
+	}
+
+	if isCloneFailure(err) && c.retryAfter > 0 {
+		c.lock.Lock()
+		c.failed[repo] = c.now().Add(c.retryAfter)
+		c.lock.Unlock()
+	}
+
+	c.log.Warnf("failed to get %s from mirror, downloading archive instead, %v", r, err)
+	return c.archives.Get(r)
+}
+
+// cloneFailed returns whether repo could not be cloned within retryAfter.
+func (c *mirroringClient) cloneFailed(repo string) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	retry, exists := c.failed[repo]
+	if exists && !c.now().Before(retry) {
+		delete(c.failed, repo)
+		return false
+	}
+	return exists
+}
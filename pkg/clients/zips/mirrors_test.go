@@ -0,0 +1,278 @@
+package zips
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+func mirrorRequest(project string) *upstream.Request {
+	return &upstream.Request{
+		Transport: "git+https",
+		Domain:    "code.example.com",
+		Namespace: strings.Split(project, "/"),
+		Version:   "v1.0.0",
+	}
+}
+
+func getMirror(t *testing.T, client *gitClient, project string) string {
+	rc, err := client.Get(mirrorRequest(project))
+	require.NoError(t, err)
+	_, err = ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	return client.mirrorOf(mirrorRequest(project))
+}
+
+func setUsed(t *testing.T, mirror string, used time.Time) {
+	require.NoError(t, os.Chtimes(mirror, used, used))
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func TestGitClient_Collect_unused(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-mirrors-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := newUpstreamRepo(t, dir)
+	repo.commit("v1.0.0", map[string]string{
+		"go.mod": "module code.example.com/go/foo\n",
+	})
+
+	client := newTestGitClient(t, dir, repo)
+	client.mirrorCache.options.MaxUnused = time.Hour
+
+	foo := getMirror(t, client, "go/foo")
+	bar := getMirror(t, client, "go/bar")
+	baz := getMirror(t, client, "other/baz")
+	setUsed(t, foo, time.Now().Add(-2*time.Hour))
+	setUsed(t, baz, time.Now().Add(-3*time.Hour))
+
+	usage, err := client.Collect()
+	require.NoError(t, err)
+	require.Equal(t, 1, usage.Mirrors)
+	require.Equal(t, 2, usage.Removed)
+	require.True(t, usage.Bytes > 0)
+	require.True(t, usage.RemovedBytes > usage.Bytes)
+
+	require.False(t, exists(foo))
+	require.True(t, exists(bar))
+	require.False(t, exists(baz))
+
+	// directories without any mirrors left are removed too
+	require.True(t, exists(filepath.Dir(bar)))
+	require.False(t, exists(filepath.Dir(baz)))
+	require.True(t, exists(client.mirrorCache.options.Directory))
+
+	// a removed mirror is simply cloned again
+	getMirror(t, client, "go/foo")
+	require.True(t, exists(foo))
+}
+
+func TestGitClient_Collect_size(t *testing.T) {
+	dir, err := ioutil.TempDir("", "git-mirrors-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := newUpstreamRepo(t, dir)
+	repo.commit("v1.0.0", map[string]string{
+		"go.mod": "module code.example.com/go/foo\n",
+	})
+
+	client := newTestGitClient(t, dir, repo)
+
+	foo := getMirror(t, client, "go/foo")
+	bar := getMirror(t, client, "go/bar")
+	baz := getMirror(t, client, "go/baz")
+	setUsed(t, foo, time.Now().Add(-1*time.Minute))
+	setUsed(t, bar, time.Now().Add(-3*time.Minute))
+	setUsed(t, baz, time.Now().Add(-2*time.Minute))
+
+	size, err := sizeOf(foo)
+	require.NoError(t, err)
+
+	// without limits, everything is kept
+	usage, err := client.Collect()
+	require.NoError(t, err)
+	require.Equal(t, 3, usage.Mirrors)
+	require.Equal(t, 0, usage.Removed)
+
+	// room for about two, so the least recently used goes
+	client.mirrorCache.options.MaxSize = 2*size + size/2
+	usage, err = client.Collect()
+	require.NoError(t, err)
+	require.Equal(t, 2, usage.Mirrors)
+	require.Equal(t, 1, usage.Removed)
+
+	require.True(t, exists(foo))
+	require.False(t, exists(bar))
+	require.True(t, exists(baz))
+}
+
+func TestCollectAll(t *testing.T) {
+	dir, err := ioutil.TempDir("", "mirrors-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	options := MirrorOptions{Directory: dir}
+	git := NewGitClient(options)
+	hg := NewHgClient(options)
+	fossil := NewFossilClient(options)
+
+	// a mirror of the same size for each VCS
+	mirror := func(path string, used time.Time) string {
+		path = filepath.Join(dir, filepath.FromSlash(path))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, make([]byte, 1024), 0644))
+		setUsed(t, path, used)
+		return path
+	}
+	foo := mirror("code.example.com/go/foo.git", time.Now().Add(-1*time.Minute))
+	bar := mirror(".hg/code.example.com/go/bar.hg", time.Now().Add(-3*time.Minute))
+	baz := mirror(".fossil/code.example.com/go/baz.fossil", time.Now().Add(-2*time.Minute))
+
+	// within the limit of each VCS, but not of all of them together
+	git.(*gitClient).mirrorCache.options.MaxSize = 2048
+	usage, err := CollectAll(git, hg, fossil)
+	require.NoError(t, err)
+	require.Equal(t, 2, usage.Mirrors)
+	require.Equal(t, int64(2048), usage.Bytes)
+	require.Equal(t, 1, usage.Removed)
+
+	require.True(t, exists(foo))
+	require.False(t, exists(bar))
+	require.True(t, exists(baz))
+}
+
+func TestGitClient_Collect_empty(t *testing.T) {
+	client := newMirrorCache(MirrorOptions{Directory: "/does/not/exist"}, ".git", loggy.New("zips-git-test"))
+
+	usage, err := client.Collect()
+	require.NoError(t, err)
+	require.Equal(t, MirrorUsage{}, usage)
+}
+
+func TestMirroringClient_Get(t *testing.T) {
+	mirrors := NewUpstreamClientMock(t)
+	defer mirrors.MinimockFinish()
+
+	archives := NewUpstreamClientMock(t)
+	defer archives.MinimockFinish()
+
+	request := &upstream.Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: []string{"pkg", "errors"},
+		Version:   "v0.8.1",
+		Path:      "pkg/errors/archive/v0.8.1.zip",
+	}
+
+	mirrored := *request
+	mirrored.Transport = "git+https"
+
+	mirrors.GetMock.When(&mirrored).Then(ioutil.NopCloser(strings.NewReader("mirror")), nil)
+
+	client := NewMirroringClient(mirrors, archives, DefaultCloneRetry)
+	rc, err := client.Get(request)
+	require.NoError(t, err)
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "mirror", string(bs))
+}
+
+func TestMirroringClient_Get_fallback(t *testing.T) {
+	mirrors := NewUpstreamClientMock(t)
+	defer mirrors.MinimockFinish()
+
+	archives := NewUpstreamClientMock(t)
+	defer archives.MinimockFinish()
+
+	request := &upstream.Request{
+		Transport: "https",
+		Domain:    "code.example.com",
+		Namespace: []string{"go", "foo"},
+		Version:   "v1.0.0",
+		Path:      "go/foo/-/archive/v1.0.0/foo-v1.0.0.zip",
+	}
+
+	mirrored := *request
+	mirrored.Transport = "git+https"
+
+	// e.g. not a git server
+	mirrors.GetMock.When(&mirrored).Then(nil, cloneFailure{errors.New("failed to clone")})
+	archives.GetMock.Set(func(*upstream.Request) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader("archive")), nil
+	})
+
+	now := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	client := NewMirroringClient(mirrors, archives, time.Hour).(*mirroringClient)
+	client.now = func() time.Time { return now }
+
+	get := func() {
+		rc, err := client.Get(request)
+		require.NoError(t, err)
+		bs, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.Equal(t, "archive", string(bs))
+	}
+
+	get()
+	require.Equal(t, uint64(1), mirrors.GetAfterCounter())
+
+	// the failed clone is remembered, so only the archive is downloaded
+	now = now.Add(30 * time.Minute)
+	get()
+	require.Equal(t, uint64(1), mirrors.GetAfterCounter())
+
+	// until it is time to try cloning again
+	now = now.Add(30 * time.Minute)
+	get()
+	require.Equal(t, uint64(2), mirrors.GetAfterCounter())
+}
+
+func TestMirroringClient_Get_no_version(t *testing.T) {
+	mirrors := NewUpstreamClientMock(t)
+	defer mirrors.MinimockFinish()
+
+	archives := NewUpstreamClientMock(t)
+	defer archives.MinimockFinish()
+
+	request := &upstream.Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: []string{"pkg", "errors"},
+		Version:   "v9.9.9",
+		Path:      "pkg/errors/archive/v9.9.9.zip",
+	}
+
+	mirrored := *request
+	mirrored.Transport = "git+https"
+
+	// cloned fine, the version just does not exist
+	mirrors.GetMock.When(&mirrored).Then(nil, errors.New("no revision for v9.9.9"))
+	archives.GetMock.When(request).Then(nil, errors.New("not found"))
+
+	client := NewMirroringClient(mirrors, archives, time.Hour)
+	for i := 0; i < 2; i++ {
+		_, err := client.Get(request)
+		require.Error(t, err)
+	}
+
+	// which is no reason not to use the mirror next time
+	require.Equal(t, uint64(2), mirrors.GetAfterCounter())
+}
@@ -3,6 +3,7 @@ package zips
 // Code generated by http://github.com/gojuno/minimock (dev). DO NOT EDIT.
 
 import (
+	"io"
 	"sync"
 	mm_atomic "sync/atomic"
 	mm_time "time"
@@ -17,12 +18,18 @@ import (
 type ProxyClientMock struct {
 	t minimock.Tester
 
-	funcGet          func(m1 coordinates.Module) (b1 repository.Blob, err error)
+	funcGet          func(m1 coordinates.Module) (r1 io.ReadCloser, err error)
 	inspectFuncGet   func(m1 coordinates.Module)
 	afterGetCounter  uint64
 	beforeGetCounter uint64
 	GetMock          mProxyClientMockGet
 
+	funcInfo          func(m1 coordinates.Module) (r1 repository.RevInfo, err error)
+	inspectFuncInfo   func(m1 coordinates.Module)
+	afterInfoCounter  uint64
+	beforeInfoCounter uint64
+	InfoMock          mProxyClientMockInfo
+
 	funcList          func(source string) (ta1 []semantic.Tag, err error)
 	inspectFuncList   func(source string)
 	afterListCounter  uint64
@@ -40,6 +47,9 @@ func NewProxyClientMock(t minimock.Tester) *ProxyClientMock {
 	m.GetMock = mProxyClientMockGet{mock: m}
 	m.GetMock.callArgs = []*ProxyClientMockGetParams{}
 
+	m.InfoMock = mProxyClientMockInfo{mock: m}
+	m.InfoMock.callArgs = []*ProxyClientMockInfoParams{}
+
 	m.ListMock = mProxyClientMockList{mock: m}
 	m.ListMock.callArgs = []*ProxyClientMockListParams{}
 
@@ -70,7 +80,7 @@ type ProxyClientMockGetParams struct {
 
 // ProxyClientMockGetResults contains results of the ProxyClient.Get
 type ProxyClientMockGetResults struct {
-	b1  repository.Blob
+	r1  io.ReadCloser
 	err error
 }
 
@@ -106,7 +116,7 @@ func (mmGet *mProxyClientMockGet) Inspect(f func(m1 coordinates.Module)) *mProxy
 }
 
 // Return sets up results that will be returned by ProxyClient.Get
-func (mmGet *mProxyClientMockGet) Return(b1 repository.Blob, err error) *ProxyClientMock {
+func (mmGet *mProxyClientMockGet) Return(r1 io.ReadCloser, err error) *ProxyClientMock {
 	if mmGet.mock.funcGet != nil {
 		mmGet.mock.t.Fatalf("ProxyClientMock.Get mock is already set by Set")
 	}
@@ -114,12 +124,12 @@ func (mmGet *mProxyClientMockGet) Return(b1 repository.Blob, err error) *ProxyCl
 	if mmGet.defaultExpectation == nil {
 		mmGet.defaultExpectation = &ProxyClientMockGetExpectation{mock: mmGet.mock}
 	}
-	mmGet.defaultExpectation.results = &ProxyClientMockGetResults{b1, err}
+	mmGet.defaultExpectation.results = &ProxyClientMockGetResults{r1, err}
 	return mmGet.mock
 }
 
 //Set uses given function f to mock the ProxyClient.Get method
-func (mmGet *mProxyClientMockGet) Set(f func(m1 coordinates.Module) (b1 repository.Blob, err error)) *ProxyClientMock {
+func (mmGet *mProxyClientMockGet) Set(f func(m1 coordinates.Module) (r1 io.ReadCloser, err error)) *ProxyClientMock {
 	if mmGet.defaultExpectation != nil {
 		mmGet.mock.t.Fatalf("Default expectation is already set for the ProxyClient.Get method")
 	}
@@ -148,13 +158,13 @@ func (mmGet *mProxyClientMockGet) When(m1 coordinates.Module) *ProxyClientMockGe
 }
 
 // Then sets up ProxyClient.Get return parameters for the expectation previously defined by the When method
-func (e *ProxyClientMockGetExpectation) Then(b1 repository.Blob, err error) *ProxyClientMock {
-	e.results = &ProxyClientMockGetResults{b1, err}
+func (e *ProxyClientMockGetExpectation) Then(r1 io.ReadCloser, err error) *ProxyClientMock {
+	e.results = &ProxyClientMockGetResults{r1, err}
 	return e.mock
 }
 
 // Get implements ProxyClient
-func (mmGet *ProxyClientMock) Get(m1 coordinates.Module) (b1 repository.Blob, err error) {
+func (mmGet *ProxyClientMock) Get(m1 coordinates.Module) (r1 io.ReadCloser, err error) {
 	mm_atomic.AddUint64(&mmGet.beforeGetCounter, 1)
 	defer mm_atomic.AddUint64(&mmGet.afterGetCounter, 1)
 
@@ -172,7 +182,7 @@ func (mmGet *ProxyClientMock) Get(m1 coordinates.Module) (b1 repository.Blob, er
 	for _, e := range mmGet.GetMock.expectations {
 		if minimock.Equal(e.params, mm_params) {
 			mm_atomic.AddUint64(&e.Counter, 1)
-			return e.results.b1, e.results.err
+			return e.results.r1, e.results.err
 		}
 	}
 
@@ -188,7 +198,7 @@ func (mmGet *ProxyClientMock) Get(m1 coordinates.Module) (b1 repository.Blob, er
 		if mm_results == nil {
 			mmGet.t.Fatal("No results are set for the ProxyClientMock.Get")
 		}
-		return (*mm_results).b1, (*mm_results).err
+		return (*mm_results).r1, (*mm_results).err
 	}
 	if mmGet.funcGet != nil {
 		return mmGet.funcGet(m1)
@@ -262,6 +272,222 @@ func (m *ProxyClientMock) MinimockGetInspect() {
 	}
 }
 
+type mProxyClientMockInfo struct {
+	mock               *ProxyClientMock
+	defaultExpectation *ProxyClientMockInfoExpectation
+	expectations       []*ProxyClientMockInfoExpectation
+
+	callArgs []*ProxyClientMockInfoParams
+	mutex    sync.RWMutex
+}
+
+// ProxyClientMockInfoExpectation specifies expectation struct of the ProxyClient.Info
+type ProxyClientMockInfoExpectation struct {
+	mock    *ProxyClientMock
+	params  *ProxyClientMockInfoParams
+	results *ProxyClientMockInfoResults
+	Counter uint64
+}
+
+// ProxyClientMockInfoParams contains parameters of the ProxyClient.Info
+type ProxyClientMockInfoParams struct {
+	m1 coordinates.Module
+}
+
+// ProxyClientMockInfoResults contains results of the ProxyClient.Info
+type ProxyClientMockInfoResults struct {
+	r1  repository.RevInfo
+	err error
+}
+
+// Expect sets up expected params for ProxyClient.Info
+func (mmInfo *mProxyClientMockInfo) Expect(m1 coordinates.Module) *mProxyClientMockInfo {
+	if mmInfo.mock.funcInfo != nil {
+		mmInfo.mock.t.Fatalf("ProxyClientMock.Info mock is already set by Set")
+	}
+
+	if mmInfo.defaultExpectation == nil {
+		mmInfo.defaultExpectation = &ProxyClientMockInfoExpectation{}
+	}
+
+	mmInfo.defaultExpectation.params = &ProxyClientMockInfoParams{m1}
+	for _, e := range mmInfo.expectations {
+		if minimock.Equal(e.params, mmInfo.defaultExpectation.params) {
+			mmInfo.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmInfo.defaultExpectation.params)
+		}
+	}
+
+	return mmInfo
+}
+
+// Inspect accepts an inspector function that has same arguments as the ProxyClient.Info
+func (mmInfo *mProxyClientMockInfo) Inspect(f func(m1 coordinates.Module)) *mProxyClientMockInfo {
+	if mmInfo.mock.inspectFuncInfo != nil {
+		mmInfo.mock.t.Fatalf("Inspect function is already set for ProxyClientMock.Info")
+	}
+
+	mmInfo.mock.inspectFuncInfo = f
+
+	return mmInfo
+}
+
+// Return sets up results that will be returned by ProxyClient.Info
+func (mmInfo *mProxyClientMockInfo) Return(r1 repository.RevInfo, err error) *ProxyClientMock {
+	if mmInfo.mock.funcInfo != nil {
+		mmInfo.mock.t.Fatalf("ProxyClientMock.Info mock is already set by Set")
+	}
+
+	if mmInfo.defaultExpectation == nil {
+		mmInfo.defaultExpectation = &ProxyClientMockInfoExpectation{mock: mmInfo.mock}
+	}
+	mmInfo.defaultExpectation.results = &ProxyClientMockInfoResults{r1, err}
+	return mmInfo.mock
+}
+
+//Set uses given function f to mock the ProxyClient.Info method
+func (mmInfo *mProxyClientMockInfo) Set(f func(m1 coordinates.Module) (r1 repository.RevInfo, err error)) *ProxyClientMock {
+	if mmInfo.defaultExpectation != nil {
+		mmInfo.mock.t.Fatalf("Default expectation is already set for the ProxyClient.Info method")
+	}
+
+	if len(mmInfo.expectations) > 0 {
+		mmInfo.mock.t.Fatalf("Some expectations are already set for the ProxyClient.Info method")
+	}
+
+	mmInfo.mock.funcInfo = f
+	return mmInfo.mock
+}
+
+// When sets expectation for the ProxyClient.Info which will trigger the result defined by the following
+// Then helper
+func (mmInfo *mProxyClientMockInfo) When(m1 coordinates.Module) *ProxyClientMockInfoExpectation {
+	if mmInfo.mock.funcInfo != nil {
+		mmInfo.mock.t.Fatalf("ProxyClientMock.Info mock is already set by Set")
+	}
+
+	expectation := &ProxyClientMockInfoExpectation{
+		mock:   mmInfo.mock,
+		params: &ProxyClientMockInfoParams{m1},
+	}
+	mmInfo.expectations = append(mmInfo.expectations, expectation)
+	return expectation
+}
+
+// Then sets up ProxyClient.Info return parameters for the expectation previously defined by the When method
+func (e *ProxyClientMockInfoExpectation) Then(r1 repository.RevInfo, err error) *ProxyClientMock {
+	e.results = &ProxyClientMockInfoResults{r1, err}
+	return e.mock
+}
+
+// Info implements ProxyClient
+func (mmInfo *ProxyClientMock) Info(m1 coordinates.Module) (r1 repository.RevInfo, err error) {
+	mm_atomic.AddUint64(&mmInfo.beforeInfoCounter, 1)
+	defer mm_atomic.AddUint64(&mmInfo.afterInfoCounter, 1)
+
+	if mmInfo.inspectFuncInfo != nil {
+		mmInfo.inspectFuncInfo(m1)
+	}
+
+	mm_params := &ProxyClientMockInfoParams{m1}
+
+	// Record call args
+	mmInfo.InfoMock.mutex.Lock()
+	mmInfo.InfoMock.callArgs = append(mmInfo.InfoMock.callArgs, mm_params)
+	mmInfo.InfoMock.mutex.Unlock()
+
+	for _, e := range mmInfo.InfoMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.r1, e.results.err
+		}
+	}
+
+	if mmInfo.InfoMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmInfo.InfoMock.defaultExpectation.Counter, 1)
+		mm_want := mmInfo.InfoMock.defaultExpectation.params
+		mm_got := ProxyClientMockInfoParams{m1}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmInfo.t.Errorf("ProxyClientMock.Info got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmInfo.InfoMock.defaultExpectation.results
+		if mm_results == nil {
+			mmInfo.t.Fatal("No results are set for the ProxyClientMock.Info")
+		}
+		return (*mm_results).r1, (*mm_results).err
+	}
+	if mmInfo.funcInfo != nil {
+		return mmInfo.funcInfo(m1)
+	}
+	mmInfo.t.Fatalf("Unexpected call to ProxyClientMock.Info. %v", m1)
+	return
+}
+
+// InfoAfterCounter returns a count of finished ProxyClientMock.Info invocations
+func (mmInfo *ProxyClientMock) InfoAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmInfo.afterInfoCounter)
+}
+
+// InfoBeforeCounter returns a count of ProxyClientMock.Info invocations
+func (mmInfo *ProxyClientMock) InfoBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmInfo.beforeInfoCounter)
+}
+
+// Calls returns a list of arguments used in each call to ProxyClientMock.Info.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmInfo *mProxyClientMockInfo) Calls() []*ProxyClientMockInfoParams {
+	mmInfo.mutex.RLock()
+
+	argCopy := make([]*ProxyClientMockInfoParams, len(mmInfo.callArgs))
+	copy(argCopy, mmInfo.callArgs)
+
+	mmInfo.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockInfoDone returns true if the count of the Info invocations corresponds
+// the number of defined expectations
+func (m *ProxyClientMock) MinimockInfoDone() bool {
+	for _, e := range m.InfoMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.InfoMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInfoCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcInfo != nil && mm_atomic.LoadUint64(&m.afterInfoCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockInfoInspect logs each unmet expectation
+func (m *ProxyClientMock) MinimockInfoInspect() {
+	for _, e := range m.InfoMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to ProxyClientMock.Info with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.InfoMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterInfoCounter) < 1 {
+		if m.InfoMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to ProxyClientMock.Info")
+		} else {
+			m.t.Errorf("Expected call to ProxyClientMock.Info with params: %#v", *m.InfoMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcInfo != nil && mm_atomic.LoadUint64(&m.afterInfoCounter) < 1 {
+		m.t.Error("Expected call to ProxyClientMock.Info")
+	}
+}
+
 type mProxyClientMockList struct {
 	mock               *ProxyClientMock
 	defaultExpectation *ProxyClientMockListExpectation
@@ -483,6 +709,8 @@ func (m *ProxyClientMock) MinimockFinish() {
 	if !m.minimockDone() {
 		m.MinimockGetInspect()
 
+		m.MinimockInfoInspect()
+
 		m.MinimockListInspect()
 		m.t.FailNow()
 	}
@@ -508,5 +736,6 @@ func (m *ProxyClientMock) minimockDone() bool {
 	done := true
 	return done &&
 		m.MinimockGetDone() &&
+		m.MinimockInfoDone() &&
 		m.MinimockListDone()
 }
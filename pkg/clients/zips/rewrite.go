@@ -38,18 +38,26 @@ const (
 // The zip may contain multiple modules, each with its own go.mod.  We need to prune everything
 // that isn't in our module.
 func Rewrite(mod coordinates.Module, b repository.Blob) (repository.Blob, error) {
-	in := bytes.NewReader(b)
-	unZip, err := zip.NewReader(in, int64(len(b)))
-	if err != nil {
+	out := bytes.NewBuffer([]byte{})
+	if err := RewriteTo(mod, b, out); err != nil {
 		return nil, err
 	}
+	return out.Bytes(), nil
+}
+
+// RewriteTo is the same as Rewrite, but reads the upstream zip from an archive
+// which need not be in memory, and writes the rewritten zip to out as it goes.
+func RewriteTo(mod coordinates.Module, in repository.Archive, out io.Writer) error {
+	unZip, err := zip.NewReader(in, in.Size())
+	if err != nil {
+		return err
+	}
 
 	majorVersion, err := majorVersion(mod.Version)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
-	out := bytes.NewBuffer([]byte{})
 	reZip := zip.NewWriter(out)
 
 	// everything before the first / in the top-level entry of the zip file
@@ -67,36 +75,36 @@ func Rewrite(mod coordinates.Module, b repository.Blob) (repository.Blob, error)
 		if topPrefix == "" {
 			i := strings.Index(zf.Name, "/")
 			if i < 0 {
-				return nil, errors.Errorf("upstream zip missing top-level directory prefix")
+				return errors.Errorf("upstream zip missing top-level directory prefix")
 			}
 			topPrefix = zf.Name[:i+1]
 		}
 		if !strings.HasPrefix(zf.Name, topPrefix) {
-			return nil, errors.Errorf("upstream zip contains multiple top-level directories")
+			return errors.Errorf("upstream zip contains multiple top-level directories")
 		}
 		dir, file := path.Split(zf.Name)
 		if file == goModFile {
 			rc, err := zf.Open()
 			if err != nil {
-				return nil, err
+				return err
 			}
 			goModBytes, err := ioutil.ReadAll(rc)
 			if err != nil {
-				return nil, errors.Wrapf(err, "error reading %s", zf.Name)
+				return errors.Wrapf(err, "error reading %s", zf.Name)
 			}
 			modulePath := ModulePath(goModBytes)
 			if modulePath == "" {
-				return nil, errors.Errorf("unable to extract module path from %s", zf.Name)
+				return errors.Errorf("unable to extract module path from %s", zf.Name)
 			}
 			goModPath[dir] = modulePath
 		} else if file == "LICENSE" && dir == topPrefix {
 			rc, err := zf.Open()
 			if err != nil {
-				return nil, err
+				return err
 			}
 			bs, err := ioutil.ReadAll(rc)
 			if err != nil {
-				return nil, errors.Wrapf(err, "error reading %s", zf.Name)
+				return errors.Wrapf(err, "error reading %s", zf.Name)
 			}
 			topLicenseBytes = bs
 			haveTopLicense = true
@@ -139,7 +147,7 @@ func Rewrite(mod coordinates.Module, b repository.Blob) (repository.Blob, error)
 
 		base := path.Base(name)
 		if strings.ToLower(base) == goModFile && base != goModFile {
-			return nil, errors.Errorf("upstream zip file contains %s, want all lower-case go.mod", zf.Name)
+			return errors.Errorf("upstream zip file contains %s, want all lower-case go.mod", zf.Name)
 		}
 
 		if name == "LICENSE" {
@@ -148,17 +156,17 @@ func Rewrite(mod coordinates.Module, b repository.Blob) (repository.Blob, error)
 
 		rc, err := zf.Open()
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		unversionedName := strings.TrimPrefix(name, versionPrefix)
 		w, err := reZip.Create(mod.Source + "@" + mod.Version + "/" + unversionedName) // source@version/path
 		if err != nil {
-			return nil, err
+			return err
 		}
 
 		if _, err := io.Copy(w, rc); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
@@ -166,18 +174,14 @@ func Rewrite(mod coordinates.Module, b repository.Blob) (repository.Blob, error)
 	if !haveModLicense && haveTopLicense {
 		w, err := reZip.Create(mod.Source + "@" + mod.Version + "/LICENSE")
 		if err != nil {
-			return nil, err
+			return err
 		}
 		if _, err := w.Write(topLicenseBytes); err != nil {
-			return nil, err
+			return err
 		}
 	}
 
-	if err := reZip.Close(); err != nil {
-		return nil, err
-	}
-
-	return out.Bytes(), nil
+	return reZip.Close()
 }
 
 func isVendorPath(name string) bool {
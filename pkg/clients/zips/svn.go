@@ -0,0 +1,205 @@
+package zips
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+type SVNOptions struct {
+	// Timeout applies to exporting a revision of a repository.
+	Timeout time.Duration
+
+	// TmpDir is where revisions are exported to and zipped, until the zip
+	// has been read. The system temporary directory is used if empty.
+	TmpDir string
+}
+
+type svnClient struct {
+	options  SVNOptions
+	remoteOf func(*upstream.Request) string
+	log      loggy.Logger
+}
+
+// NewSVNClient creates an UpstreamClient which uses the svn command to export
+// revisions of upstream Subversion repositories, e.g. those of a go-import
+// meta tag with the svn VCS. Unlike the other VCSs, there are no mirrors, as
+// a revision is exported without the history of the repository.
+//
+// Like cmd/go, the version of a tag is exported from the tags directory of the
+// repository, e.g. v1.0.0 from <repository>/tags/v1.0.0, and the revision of
+// a pseudo-version is the revision number, e.g. 1234 of
+// v0.0.0-20200102030405-000000001234.
+func NewSVNClient(options SVNOptions) UpstreamClient {
+	if options.Timeout <= 0 {
+		options.Timeout = 10 * time.Minute
+	}
+	return &svnClient{
+		options:  options,
+		remoteOf: vcsRemoteOf("svn"),
+		log:      loggy.New("zips-svn"),
+	}
+}
+
+func (c *svnClient) Protocols() []string {
+	return []string{"svn", "svn+ssh", "svn+http", "svn+https"}
+}
+
+func (c *svnClient) Get(r *upstream.Request) (io.ReadCloser, error) {
+	if r == nil {
+		return nil, errors.New("request is nil")
+	}
+
+	target, err := svnTargetOf(r, c.remoteOf(r))
+	if err != nil {
+		return nil, err
+	}
+
+	revision, err := c.resolve(target)
+	if err != nil {
+		return nil, errors.Wrapf(err, "no revision for %s in %s", r.Ref(), c.remoteOf(r))
+	}
+
+	tmp, err := ioutil.TempDir(c.options.TmpDir, "modprox-svn-")
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.RemoveAll(tmp) }()
+
+	// like the archives of a code hosting service, every
+	// file is in a single top-level directory
+	prefix := fmt.Sprintf("%s-%s", path.Base(projectOf(r)), revision)
+	export := filepath.Join(tmp, prefix)
+
+	c.log.Tracef("exporting %s at revision %s", target, revision)
+	if _, err := c.svn("export", "--quiet", "--force", "--non-interactive",
+		"--revision", revision, target, export); err != nil {
+		return nil, errors.Wrap(err, "failed to export")
+	}
+
+	return zipDirectory(c.options.TmpDir, tmp, prefix)
+}
+
+// resolve returns the revision in which target was last changed, which
+// is what the files of target are exported at.
+func (c *svnClient) resolve(target string) (string, error) {
+	output, err := c.svn("info", "--non-interactive", "--show-item", "last-changed-revision", target)
+	if err != nil {
+		return "", err
+	}
+
+	revision := strings.TrimSpace(output)
+	if _, err := strconv.ParseUint(revision, 10, 64); err != nil {
+		return "", errors.Errorf("unexpected revision %q", revision)
+	}
+	return revision, nil
+}
+
+// svn runs the svn command, returning its output.
+func (c *svnClient) svn(args ...string) (string, error) {
+	return run(c.options.Timeout, "", "svn", args...)
+}
+
+// svnTargetOf returns the target (the url and peg revision) of the version of
+// r in the repository at remote.
+//
+// e.g. v1.0.0 => <remote>/tags/v1.0.0@HEAD
+// e.g. v0.0.0-20200102030405-000000001234 => <remote>@1234
+func svnTargetOf(r *upstream.Request, remote string) (string, error) {
+	ref := r.Ref()
+	if ref == "" || strings.HasPrefix(ref, "-") || strings.ContainsAny(ref, "@/") {
+		return "", errors.Errorf("invalid version %q", r.Version)
+	}
+
+	// the hash of a pseudo-version is the padded revision number
+	if ref != r.Version && !strings.HasPrefix(ref, "v") {
+		revision, err := strconv.ParseUint(ref, 10, 64)
+		if err != nil {
+			return "", errors.Errorf("invalid revision in version %q", r.Version)
+		}
+		return fmt.Sprintf("%s@%d", remote, revision), nil
+	}
+
+	return fmt.Sprintf("%s/tags/%s@HEAD", remote, ref), nil
+}
+
+// zipDirectory creates the zip of the files in the directory named prefix
+// in dir. The zip is kept in a temporary file in tmpDir, which is removed
+// once closed.
+func zipDirectory(tmpDir, dir, prefix string) (io.ReadCloser, error) {
+	tmp, err := ioutil.TempFile(tmpDir, "modprox-*.zip")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := writeZip(tmp, dir, prefix); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return nil, err
+	}
+	return &tmpReader{File: tmp}, nil
+}
+
+func writeZip(w io.Writer, dir, prefix string) error {
+	zw := zip.NewWriter(w)
+
+	root := filepath.Join(dir, prefix)
+	err := filepath.Walk(root, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		name, err := filepath.Rel(dir, file)
+		if err != nil {
+			return err
+		}
+
+		header, err := zip.FileInfoHeader(info)
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		header.Method = zip.Deflate
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		_, err = io.Copy(fw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	return zw.Close()
+}
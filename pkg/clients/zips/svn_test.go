@@ -0,0 +1,156 @@
+package zips
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/upstream"
+)
+
+func svnRequest(version string) *upstream.Request {
+	return &upstream.Request{
+		Transport: "svn",
+		Domain:    "svn.example.com",
+		Namespace: []string{"repos", "foo"},
+		Version:   version,
+	}
+}
+
+func TestSVNClient_Get(t *testing.T) {
+	for _, name := range []string{"svn", "svnadmin"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skip(name + " is not installed")
+		}
+	}
+
+	dir, err := ioutil.TempDir("", "svn-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	repo := filepath.Join(dir, "repo")
+	remote := "file://" + filepath.ToSlash(repo)
+	source := filepath.Join(dir, "source", "tags", "v1.0.0")
+	require.NoError(t, os.MkdirAll(source, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(source, "go.mod"), []byte("module svn.example.com/repos/foo\n"), 0644))
+
+	for _, args := range [][]string{
+		{"svnadmin", "create", repo},
+		{"svn", "import", "--quiet", "-m", "first", filepath.Join(dir, "source"), remote},
+	} {
+		output, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	client := NewSVNClient(SVNOptions{Timeout: time.Minute}).(*svnClient)
+	client.remoteOf = func(*upstream.Request) string { return remote }
+
+	get := func(version string) []string {
+		rc, err := client.Get(svnRequest(version))
+		require.NoError(t, err)
+		bs, err := ioutil.ReadAll(rc)
+		require.NoError(t, err)
+		require.NoError(t, rc.Close())
+		return filesOf(t, bs)
+	}
+
+	require.Equal(t, []string{"foo-1/go.mod"}, get("v1.0.0"))
+	require.Equal(t, []string{"foo-1/tags/v1.0.0/go.mod"}, get("v0.0.0-20200102030405-000000000001"))
+
+	_, err = client.Get(svnRequest("v2.0.0"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no revision for v2.0.0")
+}
+
+// TestSVNClient_Get_fake runs the client against a fake svn, for when svn
+// is not installed to run TestSVNClient_Get.
+func TestSVNClient_Get_fake(t *testing.T) {
+	dir, err := ioutil.TempDir("", "svn-client-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	calls, restore := fakeCommand(t, dir, "svn", `case "$1" in
+info) echo 7 ;;
+export) mkdir -p "$8" && echo 'module svn.example.com/repos/foo' > "$8/go.mod" ;;
+esac`)
+	defer restore()
+
+	tmpDir := filepath.Join(dir, "tmp")
+	require.NoError(t, os.MkdirAll(tmpDir, 0755))
+
+	rc, err := NewSVNClient(SVNOptions{Timeout: time.Minute, TmpDir: tmpDir}).Get(svnRequest("v1.0.0"))
+	require.NoError(t, err)
+	tmp := rc.(*tmpReader).Name()
+	require.Equal(t, tmpDir, filepath.Dir(tmp))
+
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, []string{"foo-7/go.mod"}, filesOf(t, bs))
+
+	// the export is gone, and so is the zip once closed
+	left, err := ioutil.ReadDir(tmpDir)
+	require.NoError(t, err)
+	require.Empty(t, left)
+
+	target := "svn://svn.example.com/repos/foo/tags/v1.0.0@HEAD"
+	exported := calls()
+	require.Len(t, exported, 2)
+	require.Equal(t, "info --non-interactive --show-item last-changed-revision "+target, exported[0])
+	require.True(t, strings.HasPrefix(exported[1], "export --quiet --force --non-interactive --revision 7 "+target+" "+tmpDir))
+	require.True(t, strings.HasSuffix(exported[1], "/foo-7"))
+}
+
+func Test_svnTargetOf(t *testing.T) {
+	try := func(version, exp string, expErr bool) {
+		target, err := svnTargetOf(svnRequest(version), "svn://svn.example.com/repos/foo")
+		if expErr {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, exp, target)
+	}
+
+	try("v1.0.0", "svn://svn.example.com/repos/foo/tags/v1.0.0@HEAD", false)
+	try("v2.0.0+incompatible", "svn://svn.example.com/repos/foo/tags/v2.0.0@HEAD", false)
+	try("v1.0.0-rc.1", "svn://svn.example.com/repos/foo/tags/v1.0.0-rc.1@HEAD", false)
+	try("v0.0.0-20200102030405-000000001234", "svn://svn.example.com/repos/foo@1234", false)
+	try("v0.0.0-20200102030405-abcdefabcdef", "", true)
+	try("-v1.0.0", "", true)
+	try("v1.0.0@1", "", true)
+}
+
+func Test_zipDirectory(t *testing.T) {
+	dir, err := ioutil.TempDir("", "zip-directory-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	for _, name := range []string{"foo-1/go.mod", "foo-1/sub/bar.go"} {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0755))
+		require.NoError(t, ioutil.WriteFile(file, []byte(name), 0644))
+	}
+
+	tmpDir := filepath.Join(dir, "tmp")
+	require.NoError(t, os.MkdirAll(tmpDir, 0755))
+
+	rc, err := zipDirectory(tmpDir, dir, "foo-1")
+	require.NoError(t, err)
+	bs, err := ioutil.ReadAll(rc)
+	require.NoError(t, err)
+
+	tmp := rc.(*tmpReader).Name()
+	require.Equal(t, tmpDir, filepath.Dir(tmp))
+	require.NoError(t, rc.Close())
+	_, err = os.Stat(tmp)
+	require.True(t, os.IsNotExist(err))
+
+	require.Equal(t, []string{"foo-1/go.mod", "foo-1/sub/bar.go"}, filesOf(t, bs))
+}
@@ -3,12 +3,12 @@ package zips
 // Code generated by http://github.com/gojuno/minimock (dev). DO NOT EDIT.
 
 import (
+	"io"
 	"sync"
 	mm_atomic "sync/atomic"
 	mm_time "time"
 
 	"github.com/gojuno/minimock/v3"
-	"oss.indeed.com/go/modprox/pkg/repository"
 	"oss.indeed.com/go/modprox/pkg/upstream"
 )
 
@@ -16,7 +16,7 @@ import (
 type UpstreamClientMock struct {
 	t minimock.Tester
 
-	funcGet          func(rp1 *upstream.Request) (b1 repository.Blob, err error)
+	funcGet          func(rp1 *upstream.Request) (r1 io.ReadCloser, err error)
 	inspectFuncGet   func(rp1 *upstream.Request)
 	afterGetCounter  uint64
 	beforeGetCounter uint64
@@ -68,7 +68,7 @@ type UpstreamClientMockGetParams struct {
 
 // UpstreamClientMockGetResults contains results of the UpstreamClient.Get
 type UpstreamClientMockGetResults struct {
-	b1  repository.Blob
+	r1  io.ReadCloser
 	err error
 }
 
@@ -104,7 +104,7 @@ func (mmGet *mUpstreamClientMockGet) Inspect(f func(rp1 *upstream.Request)) *mUp
 }
 
 // Return sets up results that will be returned by UpstreamClient.Get
-func (mmGet *mUpstreamClientMockGet) Return(b1 repository.Blob, err error) *UpstreamClientMock {
+func (mmGet *mUpstreamClientMockGet) Return(r1 io.ReadCloser, err error) *UpstreamClientMock {
 	if mmGet.mock.funcGet != nil {
 		mmGet.mock.t.Fatalf("UpstreamClientMock.Get mock is already set by Set")
 	}
@@ -112,12 +112,12 @@ func (mmGet *mUpstreamClientMockGet) Return(b1 repository.Blob, err error) *Upst
 	if mmGet.defaultExpectation == nil {
 		mmGet.defaultExpectation = &UpstreamClientMockGetExpectation{mock: mmGet.mock}
 	}
-	mmGet.defaultExpectation.results = &UpstreamClientMockGetResults{b1, err}
+	mmGet.defaultExpectation.results = &UpstreamClientMockGetResults{r1, err}
 	return mmGet.mock
 }
 
 //Set uses given function f to mock the UpstreamClient.Get method
-func (mmGet *mUpstreamClientMockGet) Set(f func(rp1 *upstream.Request) (b1 repository.Blob, err error)) *UpstreamClientMock {
+func (mmGet *mUpstreamClientMockGet) Set(f func(rp1 *upstream.Request) (r1 io.ReadCloser, err error)) *UpstreamClientMock {
 	if mmGet.defaultExpectation != nil {
 		mmGet.mock.t.Fatalf("Default expectation is already set for the UpstreamClient.Get method")
 	}
@@ -146,13 +146,13 @@ func (mmGet *mUpstreamClientMockGet) When(rp1 *upstream.Request) *UpstreamClient
 }
 
 // Then sets up UpstreamClient.Get return parameters for the expectation previously defined by the When method
-func (e *UpstreamClientMockGetExpectation) Then(b1 repository.Blob, err error) *UpstreamClientMock {
-	e.results = &UpstreamClientMockGetResults{b1, err}
+func (e *UpstreamClientMockGetExpectation) Then(r1 io.ReadCloser, err error) *UpstreamClientMock {
+	e.results = &UpstreamClientMockGetResults{r1, err}
 	return e.mock
 }
 
 // Get implements UpstreamClient
-func (mmGet *UpstreamClientMock) Get(rp1 *upstream.Request) (b1 repository.Blob, err error) {
+func (mmGet *UpstreamClientMock) Get(rp1 *upstream.Request) (r1 io.ReadCloser, err error) {
 	mm_atomic.AddUint64(&mmGet.beforeGetCounter, 1)
 	defer mm_atomic.AddUint64(&mmGet.afterGetCounter, 1)
 
@@ -170,7 +170,7 @@ func (mmGet *UpstreamClientMock) Get(rp1 *upstream.Request) (b1 repository.Blob,
 	for _, e := range mmGet.GetMock.expectations {
 		if minimock.Equal(e.params, mm_params) {
 			mm_atomic.AddUint64(&e.Counter, 1)
-			return e.results.b1, e.results.err
+			return e.results.r1, e.results.err
 		}
 	}
 
@@ -186,7 +186,7 @@ func (mmGet *UpstreamClientMock) Get(rp1 *upstream.Request) (b1 repository.Blob,
 		if mm_results == nil {
 			mmGet.t.Fatal("No results are set for the UpstreamClientMock.Get")
 		}
-		return (*mm_results).b1, (*mm_results).err
+		return (*mm_results).r1, (*mm_results).err
 	}
 	if mmGet.funcGet != nil {
 		return mmGet.funcGet(rp1)
@@ -3,21 +3,43 @@ package repository
 import (
 	"archive/zip"
 	"bytes"
+	"fmt"
+	"io"
 	"io/ioutil"
 	"path/filepath"
 
 	"github.com/pkg/errors"
 )
 
+// An Archive is a zip archive which can be read at random, either held in
+// memory as a Blob, or kept on disk as a File.
+type Archive interface {
+	io.ReaderAt
+	Size() int64
+}
+
 // A Blob is an in-memory zip archive, representative of
 // a module that was extracted from a repository that was downloaded from upstream.
 //
 // There might not be a go.mod file, but there should not be more than one.
 type Blob []byte
 
+func (b Blob) ReadAt(p []byte, off int64) (int, error) {
+	return bytes.NewReader(b).ReadAt(p, off)
+}
+
+func (b Blob) Size() int64 {
+	return int64(len(b))
+}
+
 func (b Blob) ModFile() (string, bool, error) {
-	r := bytes.NewReader(b)
-	unzip, err := zip.NewReader(r, int64(len(b)))
+	return ModFileOf(b)
+}
+
+// ModFileOf returns the content of the go.mod file in archive, and whether
+// there was a go.mod file at all.
+func ModFileOf(archive Archive) (string, bool, error) {
+	unzip, err := zip.NewReader(archive, archive.Size())
 	if err != nil {
 		return "", false, errors.Wrap(err, "failed to open blob")
 	}
@@ -41,3 +63,9 @@ func (b Blob) ModFile() (string, bool, error) {
 
 	return "", false, nil
 }
+
+// EmptyModFile returns the go.mod file of a module whose archive has none,
+// which is the go.mod file the go command assumes for such a module.
+func EmptyModFile(source string) string {
+	return fmt.Sprintf("module %s\n", source)
+}
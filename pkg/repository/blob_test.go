@@ -3,6 +3,8 @@ package repository
 import (
 	"archive/zip"
 	"bytes"
+	"io/ioutil"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -50,3 +52,33 @@ func Test_Blob_ModFile_none(t *testing.T) {
 	require.NoError(t, err)
 	require.False(t, exists)
 }
+
+func Test_File(t *testing.T) {
+	b := createFakeZip(t, true)
+
+	f, err := Spill("", bytes.NewReader(b))
+	require.NoError(t, err)
+	require.Equal(t, int64(len(b)), f.Size())
+
+	content, exists, err := ModFileOf(f)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, "module github.com/modprox/libmodprox", content)
+
+	fileHash, err := HashOf(f)
+	require.NoError(t, err)
+	blobHash, err := Blob(b).Hash()
+	require.NoError(t, err)
+	require.Equal(t, blobHash, fileHash)
+
+	copied, err := ioutil.ReadAll(f.Reader())
+	require.NoError(t, err)
+	require.Equal(t, b, copied)
+
+	name := f.file.Name()
+	err = f.Close()
+	require.NoError(t, err)
+
+	_, err = os.Stat(name)
+	require.True(t, os.IsNotExist(err))
+}
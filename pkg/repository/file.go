@@ -0,0 +1,67 @@
+package repository
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// A File is a zip archive kept in a temporary file rather than in memory, so
+// that large modules can be downloaded, rewritten, hashed and stored without
+// holding the whole archive in RAM. Closing a File removes it.
+type File struct {
+	file *os.File
+	size int64
+}
+
+// NewFile creates an empty File in dir, ready to be written to. If dir is
+// empty, the default directory for temporary files is used.
+func NewFile(dir string) (*File, error) {
+	f, err := ioutil.TempFile(dir, "modprox-zip-")
+	if err != nil {
+		return nil, err
+	}
+	return &File{file: f}, nil
+}
+
+// Spill copies everything from r into a new File in dir.
+func Spill(dir string, r io.Reader) (*File, error) {
+	f, err := NewFile(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (f *File) Write(p []byte) (int, error) {
+	n, err := f.file.WriteAt(p, f.size)
+	f.size += int64(n)
+	return n, err
+}
+
+func (f *File) ReadAt(p []byte, off int64) (int, error) {
+	return f.file.ReadAt(p, off)
+}
+
+func (f *File) Size() int64 {
+	return f.size
+}
+
+// Reader returns a new io.Reader of the content of the File, from the start.
+func (f *File) Reader() io.Reader {
+	return io.NewSectionReader(f.file, 0, f.size)
+}
+
+func (f *File) Close() error {
+	closeErr := f.file.Close()
+	if err := os.Remove(f.file.Name()); err != nil {
+		return err
+	}
+	return closeErr
+}
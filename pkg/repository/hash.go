@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"archive/zip"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb/dirhash"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+// Hashes are the "h1:" hashes of the zip archive and go.mod file of a module,
+// which are the hashes recorded in go.sum files and the Go checksum database.
+type Hashes struct {
+	Zip string `json:"zip"`
+	Mod string `json:"mod"`
+}
+
+// GoSum returns the lines of a go.sum file for mod with these hashes.
+func (h Hashes) GoSum(mod coordinates.Module) string {
+	return mod.Source + " " + mod.Version + " " + h.Zip + "\n" +
+		mod.Source + " " + mod.Version + "/go.mod " + h.Mod + "\n"
+}
+
+// HashesOf returns the Hashes of a module with the given zip archive and
+// go.mod file content.
+func HashesOf(archive Archive, modFile string) (Hashes, error) {
+	zipHash, err := HashOf(archive)
+	if err != nil {
+		return Hashes{}, err
+	}
+
+	modHash, err := HashModFile(modFile)
+	if err != nil {
+		return Hashes{}, err
+	}
+
+	return Hashes{Zip: zipHash, Mod: modHash}, nil
+}
+
+// Hash returns the "h1:" hash of the zip archive, which is the same hash
+// recorded in go.sum files and in the Go checksum database. The file names
+// in the blob must already be in the module@version/ format, as produced
+// by the Go tooling (or by zips.Rewrite).
+func (b Blob) Hash() (string, error) {
+	return HashOf(b)
+}
+
+// HashOf returns the "h1:" hash of archive, the same as Blob.Hash does.
+func HashOf(archive Archive) (string, error) {
+	unzip, err := zip.NewReader(archive, archive.Size())
+	if err != nil {
+		return "", errors.Wrap(err, "failed to open blob")
+	}
+
+	files := make([]string, 0, len(unzip.File))
+	byName := make(map[string]*zip.File, len(unzip.File))
+	for _, f := range unzip.File {
+		if _, exists := byName[f.Name]; exists {
+			return "", errors.Errorf("blob contains duplicate file %s", f.Name)
+		}
+		files = append(files, f.Name)
+		byName[f.Name] = f
+	}
+
+	return dirhash.Hash1(files, func(name string) (io.ReadCloser, error) {
+		return byName[name].Open()
+	})
+}
+
+// HashModFile returns the "h1:" hash of the content of a go.mod file, which
+// is the hash recorded in go.sum files and the Go checksum database for the
+// module@version/go.mod entry of a module.
+func HashModFile(modFile string) (string, error) {
+	return dirhash.Hash1([]string{"go.mod"}, func(string) (io.ReadCloser, error) {
+		return ioutil.NopCloser(strings.NewReader(modFile)), nil
+	})
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+func Test_Blob_Hash(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	f, err := w.Create("example.com/a@v1.0.0/go.mod")
+	require.NoError(t, err)
+	_, err = f.Write([]byte("module example.com/a\n"))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+
+	hash, err := Blob(buf.Bytes()).Hash()
+	require.NoError(t, err)
+	// sha256sum of the one file, then sha256sum of that summary line
+	require.Equal(t, "h1:DaLgb81tB9bWlUSbzgQDlxb08dI2NyLKbjnOeG7aC8M=", hash)
+}
+
+func Test_Blob_Hash_duplicate(t *testing.T) {
+	buf := new(bytes.Buffer)
+	w := zip.NewWriter(buf)
+	for i := 0; i < 2; i++ {
+		_, err := w.Create("example.com/a@v1.0.0/go.mod")
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+
+	_, err := Blob(buf.Bytes()).Hash()
+	require.Error(t, err)
+}
+
+func Test_HashModFile(t *testing.T) {
+	// the go.sum entry of github.com/pkg/errors v0.8.1/go.mod
+	hash, err := HashModFile("module github.com/pkg/errors\n")
+	require.NoError(t, err)
+	require.Equal(t, "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=", hash)
+}
+
+func Test_Hashes_GoSum(t *testing.T) {
+	hashes := Hashes{
+		Zip: "h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=",
+		Mod: "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=",
+	}
+
+	lines := hashes.GoSum(coordinates.Module{
+		Source:  "github.com/pkg/errors",
+		Version: "v0.8.1",
+	})
+	require.Equal(t, "github.com/pkg/errors v0.8.1 h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=\n"+
+		"github.com/pkg/errors v0.8.1/go.mod h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=\n", lines)
+}
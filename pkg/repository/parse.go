@@ -21,6 +21,8 @@ var (
 //    /github.com/cpuguy83/go-md2man/@v/v1.0.6.info
 //  zip style
 //    github.com/kr/pty@v1.1.1
+//  query style
+//    github.com/kr/pty@master
 )
 
 // Parse will parse s as a module in string form.
@@ -33,6 +35,7 @@ func Parse(s string) (coordinates.Module, error) {
 	s = strings.TrimSuffix(s, ".rm")
 	s = strings.Replace(s, "/@v/", " ", -1) // in web handlers
 	s = strings.Replace(s, "@v", " v", -1)  // pasted from logs
+	s = strings.Replace(s, "@", " ", 1)     // at a branch or commit
 
 	var mod coordinates.Module
 	split := strings.Fields(s)
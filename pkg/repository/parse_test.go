@@ -39,6 +39,21 @@ func Test_Parse(t *testing.T) {
 		Source:  "github.com/cpuguy83/go-md2man",
 		Version: "v1.0.6",
 	}, false)
+
+	try("github.com/kr/pty@master", coordinates.Module{
+		Source:  "github.com/kr/pty",
+		Version: "master",
+	}, false)
+
+	try("github.com/kr/pty@fbec762", coordinates.Module{
+		Source:  "github.com/kr/pty",
+		Version: "fbec762",
+	}, false)
+
+	try("/github.com/kr/pty/@v/master.info", coordinates.Module{
+		Source:  "github.com/kr/pty",
+		Version: "master",
+	}, false)
 }
 
 // http://localhost:9000/gopkg.in/check.v1/@v/v0.0.0-20161208181325-20d25e280405.info
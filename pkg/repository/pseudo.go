@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+)
+
+// IsQuery returns whether version is not a canonical semantic version, but
+// something which refers to a commit of the underlying repository, e.g. a
+// branch, a tag or an abbreviated commit hash, which must first be resolved
+// into a version. Like cmd/go, a semantic version which is not canonical
+// (e.g. v1.2 or v1) is a query as well.
+func IsQuery(version string) bool {
+	canonical := semver.Canonical(version)
+	if canonical == "" {
+		return true
+	}
+	if semver.Build(version) == "+incompatible" {
+		canonical += "+incompatible"
+	}
+	return canonical != version
+}
+
+// IsPseudoVersion returns whether version is a pseudo-version, e.g.
+// v0.0.0-20180111040409-fbec762f837d.
+func IsPseudoVersion(version string) bool {
+	return strings.Count(version, "-") >= 2 &&
+		semver.IsValid(version) &&
+		pseudoVersion.MatchString(version)
+}
+
+// PseudoVersion returns the pseudo-version of the commit rev at time t, the
+// same as cmd/go does. The pseudo-version is of the tag older, which is the
+// most recent semantic version tag of the commit or its ancestors, if any.
+// Without older, major is the major version of the module (v0 by default).
+//
+// e.g. no older => vX.0.0-yyyymmddhhmmss-abcdefabcdef
+// e.g. older v1.2.3 => v1.2.4-0.yyyymmddhhmmss-abcdefabcdef
+// e.g. older v1.2.3-pre => v1.2.3-pre.0.yyyymmddhhmmss-abcdefabcdef
+// e.g. older v2.0.0+incompatible => v2.0.1-0.yyyymmddhhmmss-abcdefabcdef+incompatible
+func PseudoVersion(major, older string, t time.Time, rev string) string {
+	if major == "" {
+		major = "v0"
+	}
+	if len(rev) > 12 {
+		rev = rev[:12]
+	}
+	segment := fmt.Sprintf("%s-%s", t.UTC().Format("20060102150405"), rev)
+
+	build := semver.Build(older)
+	older = semver.Canonical(older)
+	if older == "" {
+		return major + ".0.0-" + segment
+	}
+
+	if semver.Prerelease(older) != "" {
+		return older + ".0." + segment + build
+	}
+
+	return incrementPatch(older) + "-0." + segment + build
+}
+
+// e.g. v1.2.3 => v1.2.4
+func incrementPatch(version string) string {
+	i := strings.LastIndex(version, ".")
+	patch, err := strconv.Atoi(version[i+1:])
+	if err != nil {
+		// canonical versions always have a numeric patch
+		panic(err)
+	}
+	return version[:i+1] + strconv.Itoa(patch+1)
+}
@@ -0,0 +1,76 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_IsQuery(t *testing.T) {
+	try := func(version string, exp bool) {
+		result := IsQuery(version)
+		require.Equal(t, exp, result, "version: %s", version)
+	}
+
+	try("v1.2.3", false)
+	try("v2.3.3+incompatible", false)
+	try("v1.2.4-pre", false)
+	try("v0.0.0-20180111040409-fbec762f837d", false)
+	try("master", true)
+	try("main", true)
+	try("fbec762", true)
+	try("fbec762f837d3c3f3b3e2f9bd0a0e0c8f4b1d3d7", true)
+	try("release-2020", true)
+	try("1.2.3", true)
+	try("v1.2", true)
+	try("v1", true)
+	try("v1.2.3+meta", true)
+	try("", true)
+}
+
+func Test_IsPseudoVersion(t *testing.T) {
+	try := func(version string, exp bool) {
+		result := IsPseudoVersion(version)
+		require.Equal(t, exp, result, "version: %s", version)
+	}
+
+	try("v1.2.3", false)
+	try("v1.2.4-pre", false)
+	try("v2.3.3+incompatible", false)
+	try("v0.0.0-20180111040409-fbec762f837d", true)
+	try("v1.2.4-0.20180111040409-fbec762f837d", true)
+	try("v1.2.4-pre.0.20180111040409-fbec762f837d", true)
+	try("v2.0.1-0.20180111040409-fbec762f837d+incompatible", true)
+	try("master", false)
+}
+
+func Test_PseudoVersion(t *testing.T) {
+	commit := time.Date(2018, 1, 11, 4, 4, 9, 0, time.UTC)
+	hash := "fbec762f837d3c3f3b3e2f9bd0a0e0c8f4b1d3d7"
+
+	try := func(major, older string, exp string) {
+		result := PseudoVersion(major, older, commit, hash)
+		require.Equal(t, exp, result)
+		require.True(t, IsPseudoVersion(result))
+		require.Equal(t, "fbec762f837d", RevInfoOf(result).Short)
+		require.Equal(t, commit, RevInfoOf(result).Time)
+	}
+
+	try("", "", "v0.0.0-20180111040409-fbec762f837d")
+	try("v2", "", "v2.0.0-20180111040409-fbec762f837d")
+	try("", "v1.2.3", "v1.2.4-0.20180111040409-fbec762f837d")
+	try("", "v0.9.9", "v0.9.10-0.20180111040409-fbec762f837d")
+	try("v2", "v2.1.0", "v2.1.1-0.20180111040409-fbec762f837d")
+	try("", "v1.2.3-pre", "v1.2.3-pre.0.20180111040409-fbec762f837d")
+	try("", "v1.2.3-rc.1", "v1.2.3-rc.1.0.20180111040409-fbec762f837d")
+	try("", "v2.0.0+incompatible", "v2.0.1-0.20180111040409-fbec762f837d+incompatible")
+	try("", "v3.1.0-beta+incompatible", "v3.1.0-beta.0.20180111040409-fbec762f837d+incompatible")
+
+	// the commit time is always in UTC
+	local := commit.In(time.FixedZone("UTC-7", -7*60*60))
+	require.Equal(t,
+		"v0.0.0-20180111040409-fbec762f837d",
+		PseudoVersion("", "", local, hash),
+	)
+}
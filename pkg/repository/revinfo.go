@@ -2,9 +2,12 @@ package repository
 
 import (
 	"encoding/json"
+	"regexp"
 	"time"
 )
 
+// RevInfo is the content of a .info file, which describes the commit
+// a version of a module refers to.
 type RevInfo struct {
 	Version string    `json:"version,omitempty"` // version string
 	Name    string    `json:"name,omitempty"`    // complete ID in underlying repository
@@ -27,3 +30,41 @@ func (ri RevInfo) Bytes() []byte {
 	}
 	return bs
 }
+
+// a pseudo-version ends in the UTC time and the 12 character prefix of the
+// hash of a commit, e.g. v0.0.0-20180111040409-fbec762f837d, optionally
+// followed by +incompatible
+var pseudoVersion = regexp.MustCompile(`[-.](\d{14})-([0-9a-f]{12})(\+incompatible)?$`)
+
+// RevInfoOf returns what can be known of the commit of version without asking
+// the underlying repository, which is the commit time and short hash of a
+// pseudo-version, and nothing but the version itself otherwise.
+func RevInfoOf(version string) RevInfo {
+	info := RevInfo{Version: version}
+
+	match := pseudoVersion.FindStringSubmatch(version)
+	if match == nil {
+		return info
+	}
+
+	t, err := time.Parse("20060102150405", match[1])
+	if err != nil {
+		return info
+	}
+
+	info.Time = t.UTC()
+	info.Short = match[2]
+	return info
+}
+
+// WithCommit returns ri with the full hash and time of the commit it refers
+// to, as looked up in the underlying repository.
+func (ri RevInfo) WithCommit(hash string, t time.Time) RevInfo {
+	ri.Name = hash
+	ri.Short = hash
+	if len(hash) > 12 {
+		ri.Short = hash[:12]
+	}
+	ri.Time = t.UTC()
+	return ri
+}
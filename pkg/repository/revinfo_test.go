@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_RevInfoOf(t *testing.T) {
+	try := func(version string, exp RevInfo) {
+		info := RevInfoOf(version)
+		require.Equal(t, exp, info)
+	}
+
+	commit := time.Date(2018, 1, 11, 4, 4, 9, 0, time.UTC)
+
+	try("v1.2.3", RevInfo{Version: "v1.2.3"})
+	try("v2.3.3+incompatible", RevInfo{Version: "v2.3.3+incompatible"})
+	try("v0.0.0-20180111040409-fbec762f837d", RevInfo{
+		Version: "v0.0.0-20180111040409-fbec762f837d",
+		Short:   "fbec762f837d",
+		Time:    commit,
+	})
+	try("v1.2.4-0.20180111040409-fbec762f837d", RevInfo{
+		Version: "v1.2.4-0.20180111040409-fbec762f837d",
+		Short:   "fbec762f837d",
+		Time:    commit,
+	})
+	try("v1.2.4-pre.0.20180111040409-fbec762f837d+incompatible", RevInfo{
+		Version: "v1.2.4-pre.0.20180111040409-fbec762f837d+incompatible",
+		Short:   "fbec762f837d",
+		Time:    commit,
+	})
+	try("v0.0.0-20181311040409-fbec762f837d", RevInfo{
+		Version: "v0.0.0-20181311040409-fbec762f837d",
+	})
+}
+
+func Test_RevInfo_WithCommit(t *testing.T) {
+	commit := time.Date(2019, 1, 3, 7, 42, 23, 0, time.FixedZone("CET", 3600))
+
+	info := RevInfo{Version: "v0.8.1"}.WithCommit("645ef00459ed84a119197bfb8d8205042c6df63d", commit)
+	require.Equal(t, RevInfo{
+		Version: "v0.8.1",
+		Name:    "645ef00459ed84a119197bfb8d8205042c6df63d",
+		Short:   "645ef00459ed",
+		Time:    time.Date(2019, 1, 3, 6, 42, 23, 0, time.UTC),
+	}, info)
+}
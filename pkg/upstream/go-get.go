@@ -1,12 +1,15 @@
 package upstream
 
 import (
-	"bufio"
 	"fmt"
+	"html"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/pkg/errors"
 
@@ -16,12 +19,84 @@ import (
 
 var maxLoggedBody = 500
 
+// DefaultGoGetTTL is how long the result of a go-get=1 request is remembered
+// for a module, unless configured otherwise.
+const DefaultGoGetTTL = 1 * time.Hour
+
+// DefaultGoGetFailureTTL is how long a go-get=1 request which failed is
+// remembered for a module, unless configured otherwise.
+const DefaultGoGetFailureTTL = 1 * time.Minute
+
 type goGetMeta struct {
 	transport string
 	domain    string
 	path      string
 }
 
+type cachedGoGetMeta struct {
+	meta    goGetMeta
+	err     error
+	expires time.Time
+}
+
+// lookup returns the goGetMeta of the module of r, which is remembered for
+// the ttl of t, so that downloading (or resolving) several versions of a
+// module does not ask its domain each time. Likewise, a failed lookup is
+// remembered for the failureTTL of t, so a domain which is down is not asked
+// again for every version.
+func (t *GoGetTransform) lookup(r *Request) (goGetMeta, error) {
+	importPath := importPathOf(r)
+
+	t.lock.Lock()
+	cached, exists := t.cache[importPath]
+	if exists && t.now().After(cached.expires) {
+		delete(t.cache, importPath)
+		exists = false
+	}
+	t.lock.Unlock()
+
+	if exists {
+		t.log.Tracef("using cached go-get meta of %s", importPath)
+		return cached.meta, cached.err
+	}
+
+	meta, err := t.doGoGetRequest(r)
+
+	ttl := t.ttl
+	if err != nil {
+		ttl = t.failureTTL
+	}
+
+	if ttl > 0 {
+		t.lock.Lock()
+		t.sweep()
+		t.cache[importPath] = cachedGoGetMeta{
+			meta:    meta,
+			err:     err,
+			expires: t.now().Add(ttl),
+		}
+		t.lock.Unlock()
+	}
+
+	return meta, err
+}
+
+// sweep removes the expired entries of the cache, so that modules which are
+// not looked up again do not stay in the cache forever. The lock must be held.
+func (t *GoGetTransform) sweep() {
+	now := t.now()
+	for importPath, cached := range t.cache {
+		if now.After(cached.expires) {
+			delete(t.cache, importPath)
+		}
+	}
+}
+
+// e.g. github.com/foo/bar
+func importPathOf(r *Request) string {
+	return strings.TrimSuffix(r.Domain+"/"+strings.Join(r.Namespace, "/"), "/")
+}
+
 func (t *GoGetTransform) doGoGetRequest(r *Request) (goGetMeta, error) {
 	var meta goGetMeta
 	uri := fmt.Sprintf("%s://%s/%s?go-get=1", r.Transport, r.Domain, strings.Join(r.Namespace, "/"))
@@ -54,62 +129,285 @@ func (t *GoGetTransform) doGoGetRequest(r *Request) (goGetMeta, error) {
 		return meta, errors.Errorf("bad response code (%d) from %s", code, uri)
 	}
 
-	return parseGoGetMetadata(body)
+	return parseGoGetMetadata(importPathOf(r), body)
 }
 
-var (
-	sourceRe = regexp.MustCompile(`(http[s]?)://([\w-.]+)/([\w-./]+)`)
-	log      = loggy.New("go-get")
-)
+var log = loggy.New("go-get")
 
-// gives us transport, domain, path
-func parseGoGetMetadata(content string) (goGetMeta, error) {
-	if ggm, exists, err := tryParseGoMetaTag("go-source", content); err != nil {
-		return ggm, err
-	} else if exists {
-		log.Infof("found go-source tag: %#v", ggm)
-		return ggm, nil
+// parseGoGetMetadata returns where to get the module of importPath from, per
+// the go-import meta tag of content whose prefix matches importPath, the same
+// as cmd/go picks it.
+//
+// A go-import tag with the mod vcs refers to another module proxy, which takes
+// precedence over any other go-import tag of the module. The repositories of
+// the hg, svn and fossil vcs are cloned from where the tag says, and so are
+// git repositories, unless the go-source tag of the module says where its
+// source is (usually github.com), where archives can be downloaded from.
+func parseGoGetMetadata(importPath, content string) (goGetMeta, error) {
+	imports, sources := parseMetaTags(content)
+
+	imp, err := matchGoImport(imports, importPath)
+	if err != nil {
+		return goGetMeta{}, err
 	}
 
-	if ggm, exists, err := tryParseGoMetaTag("go-import", content); err != nil {
-		return ggm, err
-	} else if exists {
-		log.Infof("found go-import tag %#v", ggm)
-		return ggm, nil
+	switch imp.vcs {
+	case "mod":
+		meta, err := modMeta(imp.repoRoot)
+		if err != nil {
+			return meta, err
+		}
+		log.Infof("found go-import tag for module proxy: %#v", meta)
+		return meta, nil
+
+	case "hg", "svn", "fossil":
+		meta, err := vcsMeta(imp.vcs, imp.repoRoot)
+		if err != nil {
+			return meta, err
+		}
+		log.Infof("found go-import tag for vcs: %#v", meta)
+		return meta, nil
 	}
 
-	return goGetMeta{}, errors.New("neither go-source or go-import meta tag found")
+	if source, exists := matchGoSource(sources, imp.prefix); exists {
+		if meta, err := httpMeta(source.repo()); err == nil {
+			log.Infof("found go-source tag: %#v", meta)
+			return meta, nil
+		}
+	}
+
+	if imp.vcs != "git" {
+		return goGetMeta{}, errors.Errorf("unsupported vcs %q in go-import tag of %s", imp.vcs, importPath)
+	}
+
+	meta, err := gitMeta(imp.repoRoot)
+	if err != nil {
+		return meta, err
+	}
+	log.Infof("found go-import tag %#v", meta)
+	return meta, nil
 }
 
-// ghetto hack where we look for go-source first, which is usually
-// the true github.com source
+// goImport is the content of a go-import meta tag, e.g.
 //
-// only when this does not work do we use the go-import line, which
-// may redirect to a vcs protocol.
-func tryParseGoMetaTag(tag, content string) (goGetMeta, bool, error) {
-	content = formatContent(content) // pre-process html
+//	<meta name="go-import" content="example.com/foo git https://github.com/example/foo">
+type goImport struct {
+	prefix   string
+	vcs      string
+	repoRoot string
+}
 
-	var meta goGetMeta
-	scanner := bufio.NewScanner(strings.NewReader(content))
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		metaTag := fmt.Sprintf("name=%q", tag)
-		if strings.Contains(line, metaTag) {
-			groups := sourceRe.FindStringSubmatch(line)
-			if len(groups) != 4 {
-				return meta, false, errors.Errorf("malformed meta tag: %q", line)
+// goSource is the content of a go-source meta tag, e.g.
+//
+//	<meta name="go-source" content="example.com/foo https://github.com/example/foo
+//	  https://github.com/example/foo/tree/master{/dir}
+//	  https://github.com/example/foo/blob/master{/dir}/{file}#L{line}">
+type goSource struct {
+	prefix    string
+	home      string
+	directory string
+	file      string
+}
+
+var (
+	bodyRe     = regexp.MustCompile(`(?i)<body`)
+	metaTagRe  = regexp.MustCompile(`(?is)<meta\s[^>]*>`)
+	metaAttrRe = regexp.MustCompile(`(?is)\b(name|content)\s*=\s*(?:"([^"]*)"|'([^']*)')`)
+)
+
+// parseMetaTags returns the go-import and go-source meta tags of content,
+// in the order they appear. Like cmd/go, only meta tags before the body of
+// the html count, and tags which are not well-formed are ignored.
+func parseMetaTags(content string) ([]goImport, []goSource) {
+	if loc := bodyRe.FindStringIndex(content); loc != nil {
+		content = content[:loc[0]]
+	}
+
+	var imports []goImport
+	var sources []goSource
+	for _, tag := range metaTagRe.FindAllString(content, -1) {
+		attrs := make(map[string]string, 2)
+		for _, attr := range metaAttrRe.FindAllStringSubmatch(tag, -1) {
+			attrs[strings.ToLower(attr[1])] = html.UnescapeString(attr[2] + attr[3])
+		}
+
+		fields := strings.Fields(attrs["content"])
+		switch attrs["name"] {
+		case "go-import":
+			if len(fields) == 3 {
+				imports = append(imports, goImport{
+					prefix:   fields[0],
+					vcs:      fields[1],
+					repoRoot: fields[2],
+				})
+			}
+		case "go-source":
+			if len(fields) == 4 {
+				sources = append(sources, goSource{
+					prefix:    fields[0],
+					home:      fields[1],
+					directory: fields[2],
+					file:      fields[3],
+				})
 			}
-			return goGetMeta{
-				transport: groups[1],
-				domain:    groups[2],
-				path:      cleanupPath(groups[3]),
-			}, true, nil
 		}
 	}
-	if err := scanner.Err(); err != nil {
-		return meta, false, err
+	return imports, sources
+}
+
+// matchGoImport returns the one go-import tag of imports with a prefix of
+// importPath. A tag with the mod vcs takes precedence over the others, but
+// otherwise more than one matching tag is an error, as it is for cmd/go.
+func matchGoImport(imports []goImport, importPath string) (goImport, error) {
+	// every mod tag comes before the others
+	sorted := make([]goImport, len(imports))
+	copy(sorted, imports)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].vcs == "mod" && sorted[j].vcs != "mod"
+	})
+
+	match := -1
+	for i, imp := range sorted {
+		if !hasPathPrefix(importPath, imp.prefix) {
+			continue
+		}
+		if match >= 0 {
+			if sorted[match].vcs == "mod" && imp.vcs != "mod" {
+				break // the module proxy wins
+			}
+			return goImport{}, errors.Errorf("multiple go-import tags match %s", importPath)
+		}
+		match = i
+	}
+
+	if match < 0 {
+		return goImport{}, errors.Errorf("no go-import tag matches %s", importPath)
+	}
+	return sorted[match], nil
+}
+
+// matchGoSource returns the go-source tag of sources for the same prefix as
+// that of the go-import tag, if there is one.
+func matchGoSource(sources []goSource, prefix string) (goSource, bool) {
+	for _, source := range sources {
+		if source.prefix == prefix {
+			return source, true
+		}
+	}
+	return goSource{}, false
+}
+
+// e.g. "_" home and directory https://github.com/go-yaml/yaml/tree/v2.2.1{/dir}
+// => https://github.com/go-yaml/yaml
+var sourceDirRe = regexp.MustCompile(`^(.+?)/(?:tree|blob|src|file)/[^/]+/?$`)
+
+// repo returns the url of the repository of s, which is its home, or if it
+// has none (i.e. "_"), what the url of its directories starts with.
+func (s goSource) repo() string {
+	if s.home != "_" && s.home != "" {
+		return s.home
+	}
+
+	dir := s.directory
+	if i := strings.Index(dir, "{"); i >= 0 {
+		dir = dir[:i]
 	}
-	return meta, false, nil
+	if groups := sourceDirRe.FindStringSubmatch(dir); groups != nil {
+		return groups[1]
+	}
+	return dir
+}
+
+// e.g. hasPathPrefix("example.com/foo/bar", "example.com/foo") => true
+// e.g. hasPathPrefix("example.com/foobar", "example.com/foo") => false
+func hasPathPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	return len(s) == len(prefix) || s[len(prefix)] == '/' || strings.HasSuffix(prefix, "/")
+}
+
+func parseRepoURL(repo string) (*url.URL, error) {
+	u, err := url.Parse(repo)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, errors.Errorf("malformed repository url: %q", repo)
+	}
+	return u, nil
+}
+
+// httpMeta is where to download archives of the repository at repo from,
+// e.g. https://github.com/foo/bar.
+func httpMeta(repo string) (goGetMeta, error) {
+	u, err := parseRepoURL(repo)
+	if err != nil {
+		return goGetMeta{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return goGetMeta{}, errors.Errorf("malformed repository url: %q", repo)
+	}
+	return goGetMeta{
+		transport: u.Scheme,
+		domain:    u.Host,
+		path:      cleanupPath(strings.TrimPrefix(u.Path, "/")),
+	}, nil
+}
+
+// gitMeta is where to get the git repository at repo from, which are the
+// archives for http and https, or the repository itself otherwise, e.g.
+// git+ssh for ssh://git@example.com/foo.
+func gitMeta(repo string) (goGetMeta, error) {
+	u, err := parseRepoURL(repo)
+	if err != nil {
+		return goGetMeta{}, err
+	}
+	if u.Scheme == "http" || u.Scheme == "https" {
+		return httpMeta(repo)
+	}
+	return goGetMeta{
+		transport: vcsTransport("git", u.Scheme),
+		domain:    u.Hostname(),
+		path:      cleanupPath(strings.TrimPrefix(u.Path, "/")),
+	}, nil
+}
+
+// vcsMeta is where to clone the repository at repo from, where the vcs
+// becomes the transport, e.g. hg+https.
+func vcsMeta(vcs, repo string) (goGetMeta, error) {
+	u, err := parseRepoURL(repo)
+	if err != nil {
+		return goGetMeta{}, err
+	}
+	return goGetMeta{
+		transport: vcsTransport(vcs, u.Scheme),
+		domain:    u.Hostname(),
+		path:      strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+// modMeta is the module proxy at proxy, with the mod+http or mod+https
+// transport, e.g. mod+https for https://proxy.example.com/go.
+func modMeta(proxy string) (goGetMeta, error) {
+	u, err := parseRepoURL(proxy)
+	if err != nil {
+		return goGetMeta{}, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return goGetMeta{}, errors.Errorf("malformed module proxy url: %q", proxy)
+	}
+	return goGetMeta{
+		transport: vcsTransport("mod", u.Scheme),
+		domain:    u.Host,
+		path:      strings.Trim(u.Path, "/"),
+	}, nil
+}
+
+// e.g. hg, https => hg+https
+// e.g. svn, svn+ssh => svn+ssh
+func vcsTransport(vcs, scheme string) string {
+	if scheme == vcs || strings.HasPrefix(scheme, vcs+"+") {
+		return scheme
+	}
+	return vcs + "+" + scheme
 }
 
 func cleanupPath(p string) string {
@@ -117,10 +415,3 @@ func cleanupPath(p string) string {
 	b := strings.TrimSuffix(a, ".git")
 	return b
 }
-
-// need to rewrite newlines not preceded by closing angle bracket to be spaces
-func formatContent(content string) string {
-	content = strings.Replace(content, "\n", " ", -1)
-	content = strings.Replace(content, ">", ">\n", -1)
-	return content
-}
@@ -6,20 +6,9 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
-func Test_formatContent(t *testing.T) {
-	try := func(input, exp string) {
-		result := formatContent(input)
-		require.Equal(t, exp, result)
-	}
-
-	try("foobar", "foobar")
-	try("a\nb", "a b")
-	try("<meta>\n foo\n bar</meta>", "<meta>\n  foo  bar</meta>\n")
-}
-
 func Test_parseGoGetMetadata(t *testing.T) {
-	try := func(input string, exp goGetMeta, expErr bool) {
-		output, err := parseGoGetMetadata(input)
+	try := func(importPath, input string, exp goGetMeta, expErr bool) {
+		output, err := parseGoGetMetadata(importPath, input)
 		if expErr {
 			require.Error(t, err)
 			return
@@ -27,68 +16,240 @@ func Test_parseGoGetMetadata(t *testing.T) {
 		require.Equal(t, exp, output)
 	}
 
-	try(metaHTML1, goGetMeta{
+	try("github.com/apache/thrift", metaHTML1, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "apache/thrift",
 	}, false)
 
-	try(metaHTML2, goGetMeta{
+	try("gopkg.in/yaml.v2", metaHTML2, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
-		path:      "go-yaml/yaml/tree/v2.2.1",
+		path:      "go-yaml/yaml",
 	}, false)
 
-	try(metaHTML3, goGetMeta{
+	try("golang.org/x/net", metaHTML3, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "golang/net",
 	}, false)
 
-	try(metaHTML4, goGetMeta{
+	try("cloud.google.com/go", metaHTML4, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "GoogleCloudPlatform/gcloud-golang",
 	}, false)
 
-	try(metaHTML5, goGetMeta{
+	try("contrib.go.opencensus.io/exporter/stackdriver", metaHTML5, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "census-ecosystem/opencensus-go-exporter-stackdriver",
 	}, false)
 
-	try(metaHTML6, goGetMeta{ // does this work?
+	try("dmitri.shuralyov.com/text/kebabcase", metaHTML6, goGetMeta{ // does this work?
 		transport: "https",
 		domain:    "dmitri.shuralyov.com",
 		path:      "text/kebabcase",
 	}, false)
 
-	try(metaHTML7, goGetMeta{
+	try("go.opencensus.io", metaHTML7, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "census-instrumentation/opencensus-go",
 	}, false)
 
-	try(metaHTML8, goGetMeta{
+	try("go.uber.org/atomic", metaHTML8, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "uber-go/atomic",
 	}, false)
 
-	try(metaHTML9, goGetMeta{
+	try("google.golang.org/api", metaHTML9, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "google/google-api-go-client",
 	}, false)
 
-	try(metaHTML10, goGetMeta{
+	try("k8s.io/klog", metaHTML10, goGetMeta{
 		transport: "https",
 		domain:    "github.com",
 		path:      "kubernetes/klog",
 	}, false)
+
+	try("example.com/go/foo", metaHTMLHg, goGetMeta{
+		transport: "hg+https",
+		domain:    "hg.example.com",
+		path:      "go/foo",
+	}, false)
+
+	try("example.com/foo", metaHTMLSVN, goGetMeta{
+		transport: "svn",
+		domain:    "svn.example.com",
+		path:      "repos/foo",
+	}, false)
+
+	try("example.com/foo", metaHTMLSVNSSH, goGetMeta{
+		transport: "svn+ssh",
+		domain:    "svn.example.com",
+		path:      "repos/foo",
+	}, false)
+
+	try("example.com/foo", metaHTMLFossil, goGetMeta{
+		transport: "fossil+https",
+		domain:    "fossil.example.com",
+		path:      "foo",
+	}, false)
+
+	try("example.com/foo", metaHTMLBadVCS, goGetMeta{}, true)
+
+	// the tag with the prefix of the module, among several
+	try("bazil.org/fuse", metaHTMLMulti, goGetMeta{
+		transport: "https",
+		domain:    "github.com",
+		path:      "bazil/fuse",
+	}, false)
+
+	try("bazil.org/fuse/fs", metaHTMLMulti, goGetMeta{
+		transport: "https",
+		domain:    "github.com",
+		path:      "bazil/fuse",
+	}, false)
+
+	try("bazil.org/fusebox", metaHTMLMulti, goGetMeta{}, true)
+
+	// the module proxy takes precedence over the repository
+	try("example.com/foo", metaHTMLMod, goGetMeta{
+		transport: "mod+https",
+		domain:    "proxy.example.com",
+		path:      "go",
+	}, false)
+
+	try("example.com/foo", metaHTMLModRoot, goGetMeta{
+		transport: "mod+http",
+		domain:    "proxy.example.com:8080",
+		path:      "",
+	}, false)
+
+	try("example.com/foo", metaHTMLAmbiguous, goGetMeta{}, true)
+
+	try("example.com/foo", metaHTMLGitSSH, goGetMeta{
+		transport: "git+ssh",
+		domain:    "git.example.com",
+		path:      "foo",
+	}, false)
+
+	// only meta tags before the body count
+	try("example.com/foo", metaHTMLBody, goGetMeta{}, true)
+
+	try("example.com/foo", metaHTMLSingleQuotes, goGetMeta{
+		transport: "https",
+		domain:    "git.example.com",
+		path:      "foo",
+	}, false)
+}
+
+func Test_matchGoImport(t *testing.T) {
+	git := goImport{prefix: "example.com/foo", vcs: "git", repoRoot: "https://git.example.com/foo"}
+	mod := goImport{prefix: "example.com/foo", vcs: "mod", repoRoot: "https://proxy.example.com"}
+	other := goImport{prefix: "example.com/bar", vcs: "git", repoRoot: "https://git.example.com/bar"}
+
+	try := func(imports []goImport, importPath string, exp goImport, expErr bool) {
+		result, err := matchGoImport(imports, importPath)
+		if expErr {
+			require.Error(t, err)
+			return
+		}
+		require.NoError(t, err)
+		require.Equal(t, exp, result)
+	}
+
+	try([]goImport{git, other}, "example.com/foo", git, false)
+	try([]goImport{git, other}, "example.com/bar/baz", other, false)
+	try([]goImport{git, mod}, "example.com/foo", mod, false)
+	try([]goImport{mod, git}, "example.com/foo/v2", mod, false)
+	try([]goImport{git, git}, "example.com/foo", goImport{}, true)
+	try([]goImport{git, other}, "example.com/baz", goImport{}, true)
+	try(nil, "example.com/foo", goImport{}, true)
+}
+
+func Test_goSource_repo(t *testing.T) {
+	try := func(source goSource, exp string) {
+		require.Equal(t, exp, source.repo())
+	}
+
+	try(goSource{
+		home:      "https://github.com/golang/net/",
+		directory: "https://github.com/golang/net/tree/master{/dir}",
+	}, "https://github.com/golang/net/")
+
+	try(goSource{
+		home:      "_",
+		directory: "https://github.com/go-yaml/yaml/tree/v2.2.1{/dir}",
+	}, "https://github.com/go-yaml/yaml")
+
+	try(goSource{
+		home:      "_",
+		directory: "https://hg.example.com/foo/file/tip{/dir}",
+	}, "https://hg.example.com/foo")
+
+	try(goSource{
+		home:      "_",
+		directory: "https://gotools.org/example.com/foo",
+	}, "https://gotools.org/example.com/foo")
+}
+
+func Test_hasPathPrefix(t *testing.T) {
+	try := func(s, prefix string, exp bool) {
+		require.Equal(t, exp, hasPathPrefix(s, prefix), "s: %s, prefix: %s", s, prefix)
+	}
+
+	try("example.com/foo", "example.com/foo", true)
+	try("example.com/foo/bar", "example.com/foo", true)
+	try("example.com/foobar", "example.com/foo", false)
+	try("example.com/foo", "example.com/foo/bar", false)
+	try("example.com/foo", "example.com", true)
 }
 
 const (
+	metaHTMLHg = `
+<head>
+<meta name="go-import" content="example.com/go/foo hg https://hg.example.com/go/foo">
+<meta name="go-source" content="example.com/go/foo https://hg.example.com/go/foo https://hg.example.com/go/foo/file/tip{/dir} https://hg.example.com/go/foo/file/tip{/dir}/{file}#L{line}">
+</head>
+`
+	metaHTMLSVN    = `<meta name="go-import" content="example.com/foo svn svn://svn.example.com/repos/foo/">`
+	metaHTMLSVNSSH = `<meta name="go-import" content="example.com/foo svn svn+ssh://svn.example.com/repos/foo">`
+	metaHTMLFossil = `<meta name="go-import" content="example.com/foo fossil https://fossil.example.com/foo">`
+	metaHTMLBadVCS = `<meta name="go-import" content="example.com/foo hg /not/a/url">`
+
+	metaHTMLMulti = `
+<head>
+<meta name="go-import" content="bazil.org/bazil git https://github.com/bazil/bazil">
+<meta name="go-import" content="bazil.org/fuse git https://github.com/bazil/fuse">
+<meta name="go-import" content="bazil.org/bolt-mount git https://github.com/bazil/bolt-mount">
+</head>
+`
+	metaHTMLMod = `
+<head>
+<meta name="go-import" content="example.com/foo git https://git.example.com/foo">
+<meta name="go-import" content="example.com/foo mod https://proxy.example.com/go/">
+<meta name="go-source" content="example.com/foo https://git.example.com/foo https://git.example.com/foo/tree/master{/dir} https://git.example.com/foo/blob/master{/dir}/{file}#L{line}">
+</head>
+`
+	metaHTMLModRoot   = `<meta name="go-import" content="example.com/foo mod http://proxy.example.com:8080">`
+	metaHTMLAmbiguous = `
+<meta name="go-import" content="example.com/foo git https://git.example.com/foo">
+<meta name="go-import" content="example.com/foo hg https://hg.example.com/foo">
+`
+	metaHTMLGitSSH = `<meta name="go-import" content="example.com/foo git ssh://git@git.example.com/foo.git">`
+	metaHTMLBody   = `
+<head></head>
+<body>
+<meta name="go-import" content="example.com/foo git https://git.example.com/foo">
+</body>
+`
+	metaHTMLSingleQuotes = `<meta content='example.com/foo git https://git.example.com/foo' name='go-import'/>`
+
 	metaHTML1 = `<meta name="go-import" content="github.com/apache/thrift git https://github.com/apache/thrift.git">`
 	metaHTML2 = `
 <head>
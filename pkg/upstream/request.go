@@ -3,6 +3,8 @@ package upstream
 import (
 	"fmt"
 	"strings"
+
+	"oss.indeed.com/go/modprox/pkg/repository"
 )
 
 // Namespace is the path elements leading up to the package name of a module.
@@ -90,3 +92,27 @@ func (r *Request) URI() string {
 	rPath := strings.TrimPrefix(r.Path, "/")
 	return fmt.Sprintf("%s://%s/%s", r.Transport, r.Domain, rPath)
 }
+
+// Ref returns the name by which the commit of the requested version is known
+// in the underlying repository, which is the tag of a release, the commit
+// hash of a pseudo-version, or the version itself if it is not yet resolved
+// (e.g. a branch).
+func (r *Request) Ref() string {
+	if repository.IsQuery(r.Version) {
+		return r.Version
+	}
+	return addressableVersion(r.Version)
+}
+
+// ModProxy returns the protocol and base URL of the module proxy the Request
+// is for, if the go-import meta tag of the module refers to another module
+// proxy (i.e. with the mod vcs), e.g. https and proxy.example.com/go for the
+// transport mod+https.
+func (r *Request) ModProxy() (string, string, bool) {
+	if !strings.HasPrefix(r.Transport, "mod+") {
+		return "", "", false
+	}
+	protocol := strings.TrimPrefix(r.Transport, "mod+")
+	baseURL := strings.TrimSuffix(r.Domain+"/"+strings.Join(r.Namespace, "/"), "/")
+	return protocol, baseURL, true
+}
@@ -108,3 +108,43 @@ func Test_Request_Equals_no_headers(t *testing.T) {
 	require.False(t, r1.Equals(r2))
 	require.False(t, r2.Equals(r1))
 }
+
+func Test_Request_Ref(t *testing.T) {
+	try := func(version, exp string) {
+		r := &Request{Version: version}
+		require.Equal(t, exp, r.Ref())
+	}
+
+	try("v1.2.3", "v1.2.3")
+	try("v2.0.0+incompatible", "v2.0.0")
+	try("v0.0.0-20180111040409-fbec762f837d", "fbec762f837d")
+	try("main", "main")
+	try("release-2020-01", "release-2020-01")
+	try("fbec762", "fbec762")
+}
+
+func Test_Request_ModProxy(t *testing.T) {
+	try := func(r *Request, expProtocol, expBaseURL string, expOK bool) {
+		protocol, baseURL, ok := r.ModProxy()
+		require.Equal(t, expOK, ok)
+		require.Equal(t, expProtocol, protocol)
+		require.Equal(t, expBaseURL, baseURL)
+	}
+
+	try(&Request{
+		Transport: "mod+https",
+		Domain:    "proxy.example.com",
+		Namespace: ns("go/modules"),
+	}, "https", "proxy.example.com/go/modules", true)
+
+	try(&Request{
+		Transport: "mod+http",
+		Domain:    "proxy.example.com",
+	}, "http", "proxy.example.com", true)
+
+	try(&Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: ns("foo/bar"),
+	}, "", "", false)
+}
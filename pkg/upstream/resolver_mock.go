@@ -21,6 +21,12 @@ type ResolverMock struct {
 	beforeResolveCounter uint64
 	ResolveMock          mResolverMockResolve
 
+	funcResolveVersion          func(mod coordinates.Module) (m1 coordinates.Module, err error)
+	inspectFuncResolveVersion   func(mod coordinates.Module)
+	afterResolveVersionCounter  uint64
+	beforeResolveVersionCounter uint64
+	ResolveVersionMock          mResolverMockResolveVersion
+
 	funcUseProxy          func(m1 coordinates.Module) (b1 bool, err error)
 	inspectFuncUseProxy   func(m1 coordinates.Module)
 	afterUseProxyCounter  uint64
@@ -38,6 +44,9 @@ func NewResolverMock(t minimock.Tester) *ResolverMock {
 	m.ResolveMock = mResolverMockResolve{mock: m}
 	m.ResolveMock.callArgs = []*ResolverMockResolveParams{}
 
+	m.ResolveVersionMock = mResolverMockResolveVersion{mock: m}
+	m.ResolveVersionMock.callArgs = []*ResolverMockResolveVersionParams{}
+
 	m.UseProxyMock = mResolverMockUseProxy{mock: m}
 	m.UseProxyMock.callArgs = []*ResolverMockUseProxyParams{}
 
@@ -260,6 +269,222 @@ func (m *ResolverMock) MinimockResolveInspect() {
 	}
 }
 
+type mResolverMockResolveVersion struct {
+	mock               *ResolverMock
+	defaultExpectation *ResolverMockResolveVersionExpectation
+	expectations       []*ResolverMockResolveVersionExpectation
+
+	callArgs []*ResolverMockResolveVersionParams
+	mutex    sync.RWMutex
+}
+
+// ResolverMockResolveVersionExpectation specifies expectation struct of the Resolver.ResolveVersion
+type ResolverMockResolveVersionExpectation struct {
+	mock    *ResolverMock
+	params  *ResolverMockResolveVersionParams
+	results *ResolverMockResolveVersionResults
+	Counter uint64
+}
+
+// ResolverMockResolveVersionParams contains parameters of the Resolver.ResolveVersion
+type ResolverMockResolveVersionParams struct {
+	mod coordinates.Module
+}
+
+// ResolverMockResolveVersionResults contains results of the Resolver.ResolveVersion
+type ResolverMockResolveVersionResults struct {
+	m1  coordinates.Module
+	err error
+}
+
+// Expect sets up expected params for Resolver.ResolveVersion
+func (mmResolveVersion *mResolverMockResolveVersion) Expect(mod coordinates.Module) *mResolverMockResolveVersion {
+	if mmResolveVersion.mock.funcResolveVersion != nil {
+		mmResolveVersion.mock.t.Fatalf("ResolverMock.ResolveVersion mock is already set by Set")
+	}
+
+	if mmResolveVersion.defaultExpectation == nil {
+		mmResolveVersion.defaultExpectation = &ResolverMockResolveVersionExpectation{}
+	}
+
+	mmResolveVersion.defaultExpectation.params = &ResolverMockResolveVersionParams{mod}
+	for _, e := range mmResolveVersion.expectations {
+		if minimock.Equal(e.params, mmResolveVersion.defaultExpectation.params) {
+			mmResolveVersion.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmResolveVersion.defaultExpectation.params)
+		}
+	}
+
+	return mmResolveVersion
+}
+
+// Inspect accepts an inspector function that has same arguments as the Resolver.ResolveVersion
+func (mmResolveVersion *mResolverMockResolveVersion) Inspect(f func(mod coordinates.Module)) *mResolverMockResolveVersion {
+	if mmResolveVersion.mock.inspectFuncResolveVersion != nil {
+		mmResolveVersion.mock.t.Fatalf("Inspect function is already set for ResolverMock.ResolveVersion")
+	}
+
+	mmResolveVersion.mock.inspectFuncResolveVersion = f
+
+	return mmResolveVersion
+}
+
+// Return sets up results that will be returned by Resolver.ResolveVersion
+func (mmResolveVersion *mResolverMockResolveVersion) Return(m1 coordinates.Module, err error) *ResolverMock {
+	if mmResolveVersion.mock.funcResolveVersion != nil {
+		mmResolveVersion.mock.t.Fatalf("ResolverMock.ResolveVersion mock is already set by Set")
+	}
+
+	if mmResolveVersion.defaultExpectation == nil {
+		mmResolveVersion.defaultExpectation = &ResolverMockResolveVersionExpectation{mock: mmResolveVersion.mock}
+	}
+	mmResolveVersion.defaultExpectation.results = &ResolverMockResolveVersionResults{m1, err}
+	return mmResolveVersion.mock
+}
+
+//Set uses given function f to mock the Resolver.ResolveVersion method
+func (mmResolveVersion *mResolverMockResolveVersion) Set(f func(mod coordinates.Module) (m1 coordinates.Module, err error)) *ResolverMock {
+	if mmResolveVersion.defaultExpectation != nil {
+		mmResolveVersion.mock.t.Fatalf("Default expectation is already set for the Resolver.ResolveVersion method")
+	}
+
+	if len(mmResolveVersion.expectations) > 0 {
+		mmResolveVersion.mock.t.Fatalf("Some expectations are already set for the Resolver.ResolveVersion method")
+	}
+
+	mmResolveVersion.mock.funcResolveVersion = f
+	return mmResolveVersion.mock
+}
+
+// When sets expectation for the Resolver.ResolveVersion which will trigger the result defined by the following
+// Then helper
+func (mmResolveVersion *mResolverMockResolveVersion) When(mod coordinates.Module) *ResolverMockResolveVersionExpectation {
+	if mmResolveVersion.mock.funcResolveVersion != nil {
+		mmResolveVersion.mock.t.Fatalf("ResolverMock.ResolveVersion mock is already set by Set")
+	}
+
+	expectation := &ResolverMockResolveVersionExpectation{
+		mock:   mmResolveVersion.mock,
+		params: &ResolverMockResolveVersionParams{mod},
+	}
+	mmResolveVersion.expectations = append(mmResolveVersion.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Resolver.ResolveVersion return parameters for the expectation previously defined by the When method
+func (e *ResolverMockResolveVersionExpectation) Then(m1 coordinates.Module, err error) *ResolverMock {
+	e.results = &ResolverMockResolveVersionResults{m1, err}
+	return e.mock
+}
+
+// ResolveVersion implements Resolver
+func (mmResolveVersion *ResolverMock) ResolveVersion(mod coordinates.Module) (m1 coordinates.Module, err error) {
+	mm_atomic.AddUint64(&mmResolveVersion.beforeResolveVersionCounter, 1)
+	defer mm_atomic.AddUint64(&mmResolveVersion.afterResolveVersionCounter, 1)
+
+	if mmResolveVersion.inspectFuncResolveVersion != nil {
+		mmResolveVersion.inspectFuncResolveVersion(mod)
+	}
+
+	mm_params := &ResolverMockResolveVersionParams{mod}
+
+	// Record call args
+	mmResolveVersion.ResolveVersionMock.mutex.Lock()
+	mmResolveVersion.ResolveVersionMock.callArgs = append(mmResolveVersion.ResolveVersionMock.callArgs, mm_params)
+	mmResolveVersion.ResolveVersionMock.mutex.Unlock()
+
+	for _, e := range mmResolveVersion.ResolveVersionMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.m1, e.results.err
+		}
+	}
+
+	if mmResolveVersion.ResolveVersionMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmResolveVersion.ResolveVersionMock.defaultExpectation.Counter, 1)
+		mm_want := mmResolveVersion.ResolveVersionMock.defaultExpectation.params
+		mm_got := ResolverMockResolveVersionParams{mod}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmResolveVersion.t.Errorf("ResolverMock.ResolveVersion got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmResolveVersion.ResolveVersionMock.defaultExpectation.results
+		if mm_results == nil {
+			mmResolveVersion.t.Fatal("No results are set for the ResolverMock.ResolveVersion")
+		}
+		return (*mm_results).m1, (*mm_results).err
+	}
+	if mmResolveVersion.funcResolveVersion != nil {
+		return mmResolveVersion.funcResolveVersion(mod)
+	}
+	mmResolveVersion.t.Fatalf("Unexpected call to ResolverMock.ResolveVersion. %v", mod)
+	return
+}
+
+// ResolveVersionAfterCounter returns a count of finished ResolverMock.ResolveVersion invocations
+func (mmResolveVersion *ResolverMock) ResolveVersionAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolveVersion.afterResolveVersionCounter)
+}
+
+// ResolveVersionBeforeCounter returns a count of ResolverMock.ResolveVersion invocations
+func (mmResolveVersion *ResolverMock) ResolveVersionBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolveVersion.beforeResolveVersionCounter)
+}
+
+// Calls returns a list of arguments used in each call to ResolverMock.ResolveVersion.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmResolveVersion *mResolverMockResolveVersion) Calls() []*ResolverMockResolveVersionParams {
+	mmResolveVersion.mutex.RLock()
+
+	argCopy := make([]*ResolverMockResolveVersionParams, len(mmResolveVersion.callArgs))
+	copy(argCopy, mmResolveVersion.callArgs)
+
+	mmResolveVersion.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockResolveVersionDone returns true if the count of the ResolveVersion invocations corresponds
+// the number of defined expectations
+func (m *ResolverMock) MinimockResolveVersionDone() bool {
+	for _, e := range m.ResolveVersionMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolveVersionMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolveVersionCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolveVersion != nil && mm_atomic.LoadUint64(&m.afterResolveVersionCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockResolveVersionInspect logs each unmet expectation
+func (m *ResolverMock) MinimockResolveVersionInspect() {
+	for _, e := range m.ResolveVersionMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to ResolverMock.ResolveVersion with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolveVersionMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolveVersionCounter) < 1 {
+		if m.ResolveVersionMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to ResolverMock.ResolveVersion")
+		} else {
+			m.t.Errorf("Expected call to ResolverMock.ResolveVersion with params: %#v", *m.ResolveVersionMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolveVersion != nil && mm_atomic.LoadUint64(&m.afterResolveVersionCounter) < 1 {
+		m.t.Error("Expected call to ResolverMock.ResolveVersion")
+	}
+}
+
 type mResolverMockUseProxy struct {
 	mock               *ResolverMock
 	defaultExpectation *ResolverMockUseProxyExpectation
@@ -481,6 +706,8 @@ func (m *ResolverMock) MinimockFinish() {
 	if !m.minimockDone() {
 		m.MinimockResolveInspect()
 
+		m.MinimockResolveVersionInspect()
+
 		m.MinimockUseProxyInspect()
 		m.t.FailNow()
 	}
@@ -506,5 +733,6 @@ func (m *ResolverMock) minimockDone() bool {
 	done := true
 	return done &&
 		m.MinimockResolveDone() &&
+		m.MinimockResolveVersionDone() &&
 		m.MinimockUseProxyDone()
 }
@@ -0,0 +1,106 @@
+package upstream
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// A Revision is the commit of a branch, tag or abbreviated commit hash in an
+// upstream repository, along with the tags which decide its version.
+type Revision struct {
+	Hash   string    // complete hash of the commit
+	Time   time.Time // commit time
+	Tagged []string  // tags of the commit itself
+	Tags   []string  // tags of the commit and every one of its ancestors
+	GoMod  bool      // whether the commit has a go.mod file
+}
+
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i Revisioner -s _mock.go
+
+// A Revisioner looks up the Revision of a Request in its upstream repository,
+// where the Version of the Request is a branch, tag or abbreviated commit hash
+// rather than a semantic version.
+type Revisioner interface {
+	Revision(*Request) (Revision, error)
+}
+
+// e.g. github.com/foo/bar/v2 => v2
+// e.g. gopkg.in/yaml.v2 => v2
+var pathMajorRe = regexp.MustCompile(`(?:/|^gopkg\.in/.*\.)(v\d+)$`)
+
+// pathMajor returns the major version of a module path, if it has one, e.g.
+// v2 of github.com/foo/bar/v2. Unlike other modules, a gopkg.in module may
+// have a major version of v0 or v1.
+func pathMajor(source string) string {
+	match := pathMajorRe.FindStringSubmatch(source)
+	if match == nil {
+		return ""
+	}
+	major := match[1]
+	if major == "v0" || major == "v1" {
+		if !gopkgIn(source) {
+			return "" // not a major version suffix
+		}
+	}
+	return major
+}
+
+func gopkgIn(source string) bool {
+	return strings.HasPrefix(source, "gopkg.in/")
+}
+
+// canonicalVersion returns the canonical version of rev for the module source,
+// the same as cmd/go does. The version of a commit with a tag is that of the
+// tag, and the version of any other commit is a pseudo-version of the highest
+// tag of its ancestors.
+//
+// Only tags of the major version of the module count. A module without a
+// major version in its path (and without a go.mod) may also use tags of v2 or
+// later, which makes its version +incompatible.
+func canonicalVersion(source string, rev Revision) string {
+	major := pathMajor(source)
+
+	if tag := highestTag(rev.Tagged, major, rev.GoMod); tag != "" {
+		return tag
+	}
+
+	older := highestTag(rev.Tags, major, rev.GoMod)
+	return repository.PseudoVersion(major, older, rev.Time, rev.Hash)
+}
+
+// highestTag returns the highest of tags which is a version of the module
+// of major, with +incompatible if necessary.
+func highestTag(tags []string, major string, goMod bool) string {
+	highest := ""
+	for _, tag := range tags {
+		version, ok := tagVersion(tag, major, goMod)
+		if ok && semver.Compare(version, highest) > 0 {
+			highest = version
+		}
+	}
+	return highest
+}
+
+func tagVersion(tag, major string, goMod bool) (string, bool) {
+	// a tag which is not exactly a version never counts, e.g. v1.2
+	if semver.Canonical(tag) != tag {
+		return "", false
+	}
+
+	tagMajor := semver.Major(tag)
+	switch {
+	case major != "":
+		return tag, tagMajor == major
+	case tagMajor == "v0" || tagMajor == "v1":
+		return tag, true
+	case !goMod:
+		return tag + "+incompatible", true
+	default:
+		return "", false
+	}
+}
@@ -0,0 +1,149 @@
+package upstream
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+const revHash = "fbec762f837d3c3f3b3e2f9bd0a0e0c8f4b1d3d7"
+
+var revTime = time.Date(2018, 1, 11, 4, 4, 9, 0, time.UTC)
+
+func Test_pathMajor(t *testing.T) {
+	try := func(source, exp string) {
+		result := pathMajor(source)
+		require.Equal(t, exp, result, "source: %s", source)
+	}
+
+	try("github.com/foo/bar", "")
+	try("github.com/foo/bar/v2", "v2")
+	try("github.com/foo/bar/v10", "v10")
+	try("github.com/foo/bar/v1", "")
+	try("github.com/foo/v2/bar", "")
+	try("gopkg.in/yaml.v2", "v2")
+	try("gopkg.in/check.v1", "v1")
+	try("gopkg.in/foo/bar.v0", "v0")
+	try("example.com/foo.v2", "")
+}
+
+func Test_canonicalVersion(t *testing.T) {
+	try := func(source string, rev Revision, exp string) {
+		rev.Hash = revHash
+		rev.Time = revTime
+		result := canonicalVersion(source, rev)
+		require.Equal(t, exp, result)
+	}
+
+	// no tags at all
+	try("github.com/foo/bar", Revision{}, "v0.0.0-20180111040409-fbec762f837d")
+	try("github.com/foo/bar/v2", Revision{}, "v2.0.0-20180111040409-fbec762f837d")
+	try("gopkg.in/yaml.v2", Revision{}, "v2.0.0-20180111040409-fbec762f837d")
+
+	// the commit itself is tagged
+	try("github.com/foo/bar", Revision{
+		Tagged: []string{"v1.2.3", "release"},
+		Tags:   []string{"v1.2.3", "v1.2.2"},
+	}, "v1.2.3")
+
+	// a release of an ancestor
+	try("github.com/foo/bar", Revision{
+		Tags: []string{"v1.2.2", "v1.2.3", "v1.10.0", "release", "v1.11"},
+	}, "v1.10.1-0.20180111040409-fbec762f837d")
+
+	// a pre-release of an ancestor
+	try("github.com/foo/bar", Revision{
+		Tags: []string{"v1.2.2", "v1.3.0-pre"},
+	}, "v1.3.0-pre.0.20180111040409-fbec762f837d")
+
+	// tags of other major versions do not count
+	try("github.com/foo/bar/v2", Revision{
+		Tags: []string{"v1.2.2", "v2.0.0", "v3.0.0"},
+	}, "v2.0.1-0.20180111040409-fbec762f837d")
+	try("github.com/foo/bar/v3", Revision{
+		Tags: []string{"v1.2.2", "v2.0.0"},
+	}, "v3.0.0-20180111040409-fbec762f837d")
+
+	// without a go.mod, later major versions are +incompatible
+	try("github.com/foo/bar", Revision{
+		Tags: []string{"v1.2.2", "v2.1.0"},
+	}, "v2.1.1-0.20180111040409-fbec762f837d+incompatible")
+	try("github.com/foo/bar", Revision{
+		Tagged: []string{"v2.1.0"},
+		Tags:   []string{"v2.1.0"},
+	}, "v2.1.0+incompatible")
+
+	// and with a go.mod, they do not count
+	try("github.com/foo/bar", Revision{
+		Tags:  []string{"v1.2.2", "v2.1.0"},
+		GoMod: true,
+	}, "v1.2.3-0.20180111040409-fbec762f837d")
+}
+
+func Test_Resolver_ResolveVersion(t *testing.T) {
+	revisions := NewRevisionerMock(t)
+	defer revisions.MinimockFinish()
+
+	resolver := NewRevisionResolver(
+		revisions,
+		NewStaticRedirectTransform("a.com", "b.com"),
+	)
+
+	revisions.RevisionMock.When(&Request{
+		Transport: "https",
+		Domain:    "b.com",
+		Namespace: ns("foo/bar"),
+		Version:   "main",
+	}).Then(Revision{
+		Hash: revHash,
+		Time: revTime,
+		Tags: []string{"v1.0.0"},
+	}, nil)
+
+	mod, err := resolver.ResolveVersion(coordinates.Module{
+		Source:  "a.com/foo/bar",
+		Version: "main",
+	})
+	require.NoError(t, err)
+	require.Equal(t, coordinates.Module{
+		Source:  "a.com/foo/bar",
+		Version: "v1.0.1-0.20180111040409-fbec762f837d",
+	}, mod)
+
+	// semantic versions are already resolved
+	mod, err = resolver.ResolveVersion(coordinates.Module{
+		Source:  "a.com/foo/bar",
+		Version: "v1.0.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0", mod.Version)
+}
+
+func Test_Resolver_ResolveVersion_no_revision(t *testing.T) {
+	revisions := NewRevisionerMock(t)
+	defer revisions.MinimockFinish()
+
+	resolver := NewRevisionResolver(revisions)
+
+	revisions.RevisionMock.Return(Revision{}, errors.New("no such branch"))
+
+	_, err := resolver.ResolveVersion(coordinates.Module{
+		Source:  "a.com/foo/bar",
+		Version: "nope",
+	})
+	require.EqualError(t, err, "failed to resolve version of (a.com/foo/bar @ nope): no such branch")
+}
+
+func Test_Resolver_ResolveVersion_no_revisions(t *testing.T) {
+	resolver := NewResolver()
+
+	_, err := resolver.ResolveVersion(coordinates.Module{
+		Source:  "a.com/foo/bar",
+		Version: "main",
+	})
+	require.Error(t, err)
+}
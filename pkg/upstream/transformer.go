@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/pkg/errors"
@@ -11,6 +12,7 @@ import (
 	"gophers.dev/pkgs/loggy"
 
 	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
 )
 
 //go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i Resolver -s _mock.go
@@ -39,6 +41,13 @@ type Resolver interface {
 	// - DomainTransportTransform
 	// - DomainHeaderTransform
 	UseProxy(coordinates.Module) (bool, error)
+
+	// ResolveVersion returns Module with its Version resolved into the
+	// canonical version, if it is a branch, tag or abbreviated commit hash
+	// rather than a semantic version, e.g. main may be resolved into
+	// v0.0.0-20200102030405-abcdefabcdef. The Revision of the commit is
+	// looked up in the upstream repository of the Request of Module.
+	ResolveVersion(mod coordinates.Module) (coordinates.Module, error)
 }
 
 // A Transform is one operation that is applied to a Request,
@@ -56,13 +65,23 @@ type Transform interface {
 }
 
 type resolver struct {
+	revisions  Revisioner
 	transforms []Transform
 }
 
 // NewResolver creates a Resolver which will apply the given set
-// of Transform operations in the order in which they appear.
+// of Transform operations in the order in which they appear. The
+// Resolver is not able to resolve versions.
 func NewResolver(transforms ...Transform) Resolver {
+	return NewRevisionResolver(nil, transforms...)
+}
+
+// NewRevisionResolver creates a Resolver which will apply the given set
+// of Transform operations in the order in which they appear, and which
+// resolves versions using the revisions of upstream repositories.
+func NewRevisionResolver(revisions Revisioner, transforms ...Transform) Resolver {
 	return &resolver{
+		revisions:  revisions,
 		transforms: transforms,
 	}
 }
@@ -119,6 +138,31 @@ func (r *resolver) Resolve(mod coordinates.Module) (*Request, error) {
 	return request, nil
 }
 
+func (r *resolver) ResolveVersion(mod coordinates.Module) (coordinates.Module, error) {
+	if !repository.IsQuery(mod.Version) {
+		return mod, nil
+	}
+
+	if r.revisions == nil {
+		return mod, errors.Errorf("unable to resolve version of %s", mod)
+	}
+
+	request, err := r.Resolve(mod)
+	if err != nil {
+		return mod, err
+	}
+
+	rev, err := r.revisions.Revision(request)
+	if err != nil {
+		return mod, errors.Wrapf(err, "failed to resolve version of %s", mod)
+	}
+
+	return coordinates.Module{
+		Source:  mod.Source,
+		Version: canonicalVersion(mod.Source, rev),
+	}, nil
+}
+
 // NewRequest creates a default Request from the given module. This
 // initial Request is likely useless, as it only becomes useful after
 // a set of Transform operations are applied to it, which then compute
@@ -206,25 +250,44 @@ func (t *StaticRedirectTransform) Modify(r *Request) (*Request, error) {
 // do go-get=1 requests, following the redirect as the Go documentation specifies.
 type GoGetTransform struct {
 	httpClient *http.Client
-	log        loggy.Logger
+	ttl        time.Duration
+	failureTTL time.Duration
+	now        func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]cachedGoGetMeta
+
+	log loggy.Logger
 }
 
 // NewAutomaticGoGetTransform creates a GoGetTransform where any module URI
 // will be redirected to wherever the go-get meta HTML tag in the domain
-// indicates.
+// indicates, remembering where for DefaultGoGetTTL, and failures to find out
+// for DefaultGoGetFailureTTL.
 func NewAutomaticGoGetTransform() Transform {
+	return NewGoGetTransform(DefaultGoGetTTL, DefaultGoGetFailureTTL)
+}
+
+// NewGoGetTransform creates a GoGetTransform which remembers where the go-get
+// meta HTML tag of a module redirects to for ttl, and that the tag could not be
+// found for failureTTL. Either is not remembered at all if it is not positive.
+func NewGoGetTransform(ttl, failureTTL time.Duration) Transform {
 	return &GoGetTransform{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		log: loggy.New("go-get-transform"),
+		ttl:        ttl,
+		failureTTL: failureTTL,
+		now:        time.Now,
+		cache:      make(map[string]cachedGoGetMeta),
+		log:        loggy.New("go-get-transform"),
 	}
 }
 
 func (t *GoGetTransform) Modify(r *Request) (*Request, error) {
 	t.log.Infof("doing go-get redirect lookup for domain %s", r.Domain)
 
-	meta, err := t.doGoGetRequest(r)
+	meta, err := t.lookup(r)
 	if err != nil {
 		// in theory everything should respond well to go-get=1, but in practice, nah
 		t.log.Warnf("unable to go-get domain: %s, leaving request unmodified: %v", r.Domain, err)
@@ -235,7 +298,7 @@ func (t *GoGetTransform) Modify(r *Request) (*Request, error) {
 	modified := &Request{
 		Transport: meta.transport,
 		Domain:    meta.domain,
-		Namespace: strings.Split(meta.path, "/"),
+		Namespace: namespaceOf(meta.path),
 		Version:   r.Version,
 		// Path: only set by the domain re-writer
 	}
@@ -246,6 +309,14 @@ func (t *GoGetTransform) Modify(r *Request) (*Request, error) {
 	return modified, nil
 }
 
+// e.g. "" => [] (a module proxy at the root of its domain)
+func namespaceOf(path string) Namespace {
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
 // A DomainPathTransform is used to generate or rewrite the URL path
 // of the module archive that is to be fetched per the domain of desired
 // module of the Request. Default path rewriting rules are provided for
@@ -355,6 +426,13 @@ func combinedDomainPathTransforms(
 }
 
 func (t *SetPathTransform) Modify(r *Request) (*Request, error) {
+	// only archives downloaded over http have a path, e.g. a request with
+	// a git transport is for a repository, not an archive
+	if r.Transport != "http" && r.Transport != "https" {
+		t.log.Tracef("not setting path of request with transport %q", r.Transport)
+		return r, nil
+	}
+
 	domainPathTransform, exists := t.domainPathTransforms[r.Domain]
 	if !exists {
 		return nil, errors.Errorf("no path transformation exists for domain %s", r.Domain)
@@ -1,12 +1,15 @@
 package upstream
 
 import (
+	"context"
 	"io/ioutil"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -101,8 +104,24 @@ func Test_Resolver_UseProxy_domain_header_yes(t *testing.T) {
 	require.False(t, useProxy)
 }
 
+// clientOf creates an http.Client which connects to ts for any host, so
+// that go-get=1 requests are for the real domains of modules.
+func clientOf(ts *httptest.Server) *http.Client {
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+				return new(net.Dialer).DialContext(ctx, network, ts.Listener.Addr().String())
+			},
+		},
+	}
+}
+
 func Test_NewAutomaticGoGetTransform200(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "bazil.org", r.Host)
+		require.Equal(t, "/fuse", r.URL.Path)
+		require.Equal(t, "1", r.URL.Query().Get("go-get"))
+
 		data, err := ioutil.ReadFile("../../hack/html/fuse.html")
 		require.NoError(t, err)
 
@@ -113,30 +132,172 @@ func Test_NewAutomaticGoGetTransform200(t *testing.T) {
 	defer ts.Close()
 
 	transform := &GoGetTransform{
-		httpClient: ts.Client(),
+		httpClient: clientOf(ts),
 		log:        loggy.New("log"),
 	}
 
-	uri, err := url.ParseRequestURI(ts.URL)
-	require.NoError(t, err)
-
 	request := &Request{
-		Transport: uri.Scheme,
-		Domain:    uri.Host,
+		Transport: "http",
+		Domain:    "bazil.org",
 		Namespace: ns("fuse"),
 		Version:   "latest",
 	}
 
+	// the page has a go-import tag for each of several modules
 	newRequest, err := transform.Modify(request)
 	require.NoError(t, err)
 	require.Equal(t, &Request{
 		Transport: "https",
 		Domain:    "github.com",
-		Namespace: ns("bazil/bazil"),
+		Namespace: ns("bazil/fuse"),
 		Version:   "latest",
 	}, newRequest)
 }
 
+func Test_GoGetTransform_mod(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, err := w.Write([]byte(metaHTMLMod))
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	transform := &GoGetTransform{
+		httpClient: clientOf(ts),
+		log:        loggy.New("log"),
+	}
+
+	newRequest, err := transform.Modify(&Request{
+		Transport: "http",
+		Domain:    "example.com",
+		Namespace: ns("foo"),
+		Version:   "v1.0.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, &Request{
+		Transport: "mod+https",
+		Domain:    "proxy.example.com",
+		Namespace: ns("go"),
+		Version:   "v1.0.0",
+	}, newRequest)
+}
+
+func Test_GoGetTransform_cache(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		data, err := ioutil.ReadFile("../../hack/html/fuse.html")
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	transform := NewGoGetTransform(time.Hour, time.Minute).(*GoGetTransform)
+	transform.httpClient = clientOf(ts)
+
+	modify := func(name, version string) *Request {
+		newRequest, err := transform.Modify(&Request{
+			Transport: "http",
+			Domain:    "bazil.org",
+			Namespace: ns(name),
+			Version:   version,
+		})
+		require.NoError(t, err)
+		return newRequest
+	}
+
+	// every version of a module is looked up once
+	require.Equal(t, ns("bazil/fuse"), modify("fuse", "v1.0.0").Namespace)
+	require.Equal(t, ns("bazil/fuse"), modify("fuse", "v1.1.0").Namespace)
+	require.Equal(t, 1, requests)
+
+	// but every module is looked up on its own
+	require.Equal(t, ns("bazil/bazil"), modify("bazil", "v1.0.0").Namespace)
+	require.Equal(t, 2, requests)
+
+	// and looked up again once expired
+	transform.lock.Lock()
+	for importPath, cached := range transform.cache {
+		cached.expires = time.Now().Add(-time.Second)
+		transform.cache[importPath] = cached
+	}
+	transform.lock.Unlock()
+
+	require.Equal(t, ns("bazil/fuse"), modify("fuse", "v1.2.0").Namespace)
+	require.Equal(t, 3, requests)
+}
+
+func Test_GoGetTransform_cache_sweep(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := ioutil.ReadFile("../../hack/html/fuse.html")
+		require.NoError(t, err)
+		_, err = w.Write(data)
+		require.NoError(t, err)
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	transform := NewGoGetTransform(time.Hour, time.Minute).(*GoGetTransform)
+	transform.httpClient = clientOf(ts)
+	transform.now = func() time.Time { return now }
+
+	modify := func(name string) {
+		_, err := transform.Modify(&Request{
+			Transport: "http",
+			Domain:    "bazil.org",
+			Namespace: ns(name),
+			Version:   "v1.0.0",
+		})
+		require.NoError(t, err)
+	}
+
+	modify("fuse")
+	require.Len(t, transform.cache, 1)
+
+	// expired modules are removed when another module is remembered,
+	// even if they are never looked up again
+	now = now.Add(2 * time.Hour)
+	modify("bazil")
+	require.Len(t, transform.cache, 1)
+	_, exists := transform.cache["bazil.org/bazil"]
+	require.True(t, exists)
+}
+
+func Test_GoGetTransform_cache_failure(t *testing.T) {
+	requests := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	now := time.Now()
+	transform := NewGoGetTransform(time.Hour, time.Minute).(*GoGetTransform)
+	transform.httpClient = clientOf(ts)
+	transform.now = func() time.Time { return now }
+
+	modify := func(version string) *Request {
+		newRequest, err := transform.Modify(&Request{
+			Transport: "http",
+			Domain:    "bazil.org",
+			Namespace: ns("fuse"),
+			Version:   version,
+		})
+		require.NoError(t, err)
+		return newRequest
+	}
+
+	// a failed lookup leaves requests unmodified, and is not asked again
+	require.Equal(t, ns("fuse"), modify("v1.0.0").Namespace)
+	require.Equal(t, ns("fuse"), modify("v1.1.0").Namespace)
+	require.Equal(t, 1, requests)
+
+	// until it expires, which is sooner than a successful lookup
+	now = now.Add(2 * time.Minute)
+	require.Equal(t, ns("fuse"), modify("v1.2.0").Namespace)
+	require.Equal(t, 2, requests)
+}
+
 func Test_NewAutomaticGoGetTransform404(t *testing.T) {
 	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(404)
@@ -278,3 +439,40 @@ func Test_DomainTransportTransform(t *testing.T) {
 		Version:   "v1.0.0",
 	}, transformed)
 }
+
+func Test_SetPathTransform(t *testing.T) {
+	spt := NewSetPathTransform(nil)
+
+	transformed, err := spt.Modify(&Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: ns("a/b"),
+		Version:   "v1.0.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "a/b/archive/v1.0.0.zip", transformed.Path)
+
+	_, err = spt.Modify(&Request{
+		Transport: "https",
+		Domain:    "foo.com",
+		Namespace: ns("a/b"),
+		Version:   "v1.0.0",
+	})
+	require.EqualError(t, err, "no path transformation exists for domain foo.com")
+}
+
+func Test_SetPathTransform_git(t *testing.T) {
+	request := &Request{
+		Transport: "git+ssh",
+		Domain:    "foo.com",
+		Namespace: ns("a/b"),
+		Version:   "v1.0.0",
+	}
+
+	spt := NewSetPathTransform(nil)
+
+	// a repository is cloned, rather than an archive downloaded
+	transformed, err := spt.Modify(request)
+	require.NoError(t, err)
+	require.Equal(t, request, transformed)
+}
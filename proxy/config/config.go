@@ -18,8 +18,20 @@ type Configuration struct {
 	Statsd          stats.Statsd           `json:"statsd"`
 	ModuleStorage   *Storage               `json:"module_storage,omitempty"`
 	ModuleDBStorage *setup.PersistentStore `json:"module_db_storage,omitempty"`
+	ZipS3Storage    *S3Storage             `json:"zip_s3_storage,omitempty"`
+	ZipCache        *ZipCache              `json:"zip_cache,omitempty"`
 	Transforms      Transforms             `json:"transforms"`
 	ZipProxy        ZipProxy               `json:"zip_proxy"`
+	PullThrough     PullThrough            `json:"pull_through"`
+	Prune           Prune                  `json:"prune"`
+	Downloads       Downloads              `json:"downloads"`
+	Problems        Problems               `json:"problems"`
+	ChecksumDB      ChecksumDB             `json:"checksum_db"`
+	SumDBServer     SumDBServer            `json:"sumdb_server"`
+	Integrity       Integrity              `json:"integrity"`
+	Eviction        Eviction               `json:"eviction"`
+	Fsck            Fsck                   `json:"fsck"`
+	Mirrors         Mirrors                `json:"mirrors"`
 }
 
 func (c Configuration) String() string {
@@ -31,6 +43,86 @@ type ZipProxy struct {
 	BaseURL  string `json:"base_url"` // e.g. "proxy.golang.org"
 }
 
+// PullThrough configures whether the proxy will download modules on-demand
+// when a module is requested which has not yet been downloaded. Modules
+// downloaded this way are also registered with the registry.
+type PullThrough struct {
+	Enabled bool `json:"enabled"`
+}
+
+// ChecksumDB configures whether the proxy will verify downloaded modules
+// against a Go checksum database before storing them. Modules matching one
+// of the Private patterns (in the same format as GONOSUMDB) are not verified.
+type ChecksumDB struct {
+	Enabled   bool     `json:"enabled"`
+	URL       string   `json:"url"`        // e.g. "https://sum.golang.org"
+	PublicKey string   `json:"public_key"` // e.g. "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8"
+	Private   []string `json:"private,omitempty"`
+	CachePath string   `json:"cache_path,omitempty"`
+}
+
+// SumDBServer configures whether the proxy will keep a transparency log of
+// the hashes of every module it stores, and serve that log as a checksum
+// database, so that GOSUMDB can be set to this proxy for internal modules.
+// The private key is of the form generated by golang.org/x/mod/sumdb/note,
+// and the name of the checksum database is the name in that key.
+//
+// Every instance of the proxy signing with the key must share one log, which
+// is the case when they share a module_db_storage database. A proxy with its
+// own index must be the only one with the key, and say so with SingleInstance.
+type SumDBServer struct {
+	Enabled        bool   `json:"enabled"`
+	PrivateKey     string `json:"private_key"` // e.g. "PRIVATE+KEY+sumdb.example.com+..."
+	SingleInstance bool   `json:"single_instance,omitempty"`
+}
+
+// Problems configures how long problems which have been resolved are kept
+// around, so that recently resolved problems can still be looked at.
+type Problems struct {
+	ResolvedRetentionS int `json:"resolved_retention_s"`
+}
+
+// Prune configures whether the proxy will remove modules which are no longer
+// listed by the registry. Modules are only removed after they have been missing
+// from the registry for at least the grace period.
+type Prune struct {
+	Enabled      bool `json:"enabled"`
+	DryRun       bool `json:"dry_run"`
+	GracePeriodS int  `json:"grace_period_s"`
+}
+
+// Integrity configures whether the proxy will periodically re-hash the zip
+// of every stored module, and report modules which no longer match the
+// hashes recorded when they were stored. The first check happens on startup.
+type Integrity struct {
+	Enabled   bool `json:"enabled"`
+	IntervalS int  `json:"interval_s"`
+}
+
+// Eviction configures whether the proxy will remove modules once the stored
+// zips take up more than QuotaMB megabytes, least recently served first
+// with the "lru" policy (the default), or least often served first with the
+// "lfu" policy. Modules still listed by the registry are never evicted unless
+// CacheOnly is set, in which case the proxy stores only the modules it fetches
+// on-demand (which requires pull_through), rather than every registered module.
+type Eviction struct {
+	Enabled   bool   `json:"enabled"`
+	QuotaMB   int64  `json:"quota_mb"`
+	Policy    string `json:"policy"`
+	CacheOnly bool   `json:"cache_only"`
+	IntervalS int    `json:"interval_s"`
+}
+
+// Fsck configures whether the proxy checks the consistency of its boltdb index
+// and the zips in module_storage on startup, before downloading anything. With
+// Repair set, incomplete index entries are rebuilt from their zips, and entries
+// without a zip are removed so the module is downloaded again. The proxy only
+// starts if no problems remain.
+type Fsck struct {
+	OnStartup bool `json:"on_startup"`
+	Repair    bool `json:"repair"`
+}
+
 type APIServer struct {
 	TLS struct {
 		Enabled     bool   `json:"enabled"`
@@ -85,10 +177,41 @@ func seconds(s int) time.Duration {
 	return time.Duration(s) * time.Second
 }
 
+// Storage configures the proxy to keep module zips and the index on local
+// disk. With ContentAddressed set, zips are stored once per distinct content
+// and each module links to the zip it shares with any identical modules.
 type Storage struct {
-	DataPath  string `json:"data_path"`
-	IndexPath string `json:"index_path"`
-	TmpPath   string `json:"tmp_path"`
+	DataPath         string `json:"data_path"`
+	IndexPath        string `json:"index_path"`
+	TmpPath          string `json:"tmp_path"`
+	ContentAddressed bool   `json:"content_addressed,omitempty"`
+}
+
+// ZipCache configures the proxy to keep a cache of up to SizeMB megabytes of
+// module zips on local disk, in front of wherever zips are otherwise stored.
+// Zips are read from and written through the cache, and the least recently
+// used zips are removed from the cache once it is full. Like module_storage,
+// DataPath and TmpPath should be on the same filesystem.
+type ZipCache struct {
+	DataPath string `json:"data_path"`
+	TmpPath  string `json:"tmp_path"`
+	SizeMB   int64  `json:"size_mb"`
+}
+
+// S3Storage configures the proxy to keep module zips in a bucket of an S3
+// compatible object store, e.g. AWS S3 or MinIO, instead of on local disk
+// or in the database. The index is still configured by either module_storage
+// or module_db_storage. Multiple instances of the proxy may share one bucket.
+// If the credentials are not set, they are read from the environment variables
+// AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+type S3Storage struct {
+	Endpoint        string `json:"endpoint"` // e.g. "https://s3.us-east-1.amazonaws.com"
+	Region          string `json:"region"`   // e.g. "us-east-1"
+	Bucket          string `json:"bucket"`
+	Prefix          string `json:"prefix,omitempty"`
+	AccessKeyID     string `json:"access_key_id,omitempty"`
+	SecretAccessKey string `json:"secret_access_key,omitempty"`
+	RequestTimeoutS int    `json:"request_timeout_s"`
 }
 
 type instances = []netservice.Instance
@@ -100,10 +223,56 @@ type Registry struct {
 	APIKey          string    `json:"api_key"`
 }
 
+// Downloads configures how many modules the proxy will download from upstream
+// sources at the same time, both in total and from any one domain, as well as
+// how long to wait before retrying modules which failed to download. Zips are
+// kept in files in TmpPath while they are downloaded, rewritten and hashed,
+// rather than in memory; by default the system temporary directory is used.
+type Downloads struct {
+	Parallelism       int    `json:"parallelism"`
+	DomainParallelism int    `json:"domain_parallelism"`
+	RetryInitialS     int    `json:"retry_initial_s"`
+	RetryMaxS         int    `json:"retry_max_s"`
+	TmpPath           string `json:"tmp_path,omitempty"`
+}
+
+// Mirrors configures the bare mirrors of upstream repositories, which are kept
+// in Path (by default in git-mirrors under the downloads TmpPath). Repositories
+// with a git, hg or fossil transport are always mirrored, the latter two in the
+// .hg and .fossil directories of Path. With Enabled, so are git repositories
+// with an http transport, falling back to downloading an archive of a version
+// if the repository cannot be cloned. Every IntervalS, mirrors unused for longer
+// than MaxUnusedS are removed, and then the least recently used mirrors until
+// the rest take up no more than MaxSizeMB megabytes, which applies to the mirrors
+// of each VCS separately. Zero means no limit.
+type Mirrors struct {
+	Enabled    bool   `json:"enabled"`
+	Path       string `json:"path,omitempty"`
+	MaxSizeMB  int64  `json:"max_size_mb"`
+	MaxUnusedS int    `json:"max_unused_s"`
+	IntervalS  int    `json:"interval_s"`
+	// CloneRetryS is how long a repository which could not be cloned has its
+	// archives downloaded instead, before cloning it is tried again, by
+	// default an hour. A negative value disables remembering it at all.
+	CloneRetryS int `json:"clone_retry_s,omitempty"`
+	// QueryCacheS is how long the commit a branch (or other query) resolves
+	// to is remembered, rather than fetching into its mirror every time, by
+	// default a minute. A negative value disables remembering it at all.
+	QueryCacheS int `json:"query_cache_s,omitempty"`
+}
+
 type Transforms struct {
 	// Deprecated, AutomaticRedirect is now ignored and treated as always-on
 	AutomaticRedirect bool `json:"auto_redirect"`
-	DomainRedirects   []struct {
+	// GoGetCacheS is how long the go-import meta tag of a module is remembered
+	// after a go-get=1 request, by default an hour. A negative value disables
+	// remembering it at all.
+	GoGetCacheS int `json:"go_get_cache_s,omitempty"`
+	// GoGetFailureCacheS is how long a go-get=1 request which failed is
+	// remembered, rather than asking the domain again for every version, by
+	// default a minute. A negative value disables remembering it at all.
+	GoGetFailureCacheS int `json:"go_get_failure_cache_s,omitempty"`
+	DomainRedirects    []struct {
 		Original     string `json:"original"`
 		Substitution string `json:"substitution"`
 	} `json:"domain_redirects,omitempty"`
@@ -119,4 +288,12 @@ type Transforms struct {
 		Domain    string `json:"domain"`
 		Transport string `json:"transport"`
 	} `json:"domain_transports,omitempty"`
+	// DomainAPIs are the APIs used to look up the commit a version of a module
+	// refers to, in addition to those of github.com and gitlab.com. The kind is
+	// one of "github", "gitlab" or "gitea".
+	DomainAPIs []struct {
+		Domain  string `json:"domain"`
+		Kind    string `json:"kind"`
+		BaseURL string `json:"base_url"`
+	} `json:"domain_apis,omitempty"`
 }
@@ -0,0 +1,174 @@
+package bundle
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+func setup(t *testing.T, dir, name string) (store.Index, store.ZipStore) {
+	index, err := store.NewIndex(store.IndexOptions{
+		Directory: filepath.Join(dir, name, "index"),
+	})
+	require.NoError(t, err)
+
+	zips := store.NewStore(store.Options{
+		Directory: filepath.Join(dir, name, "data"),
+	}, stats.Discard())
+
+	return index, zips
+}
+
+func put(t *testing.T, index store.Index, zips store.ZipStore, id int64, mod coordinates.Module) {
+	modFile := "module " + mod.Source + "\n"
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create(mod.Source + "@" + mod.Version + "/go.mod")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(modFile))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	blob := repository.Blob(buf.Bytes())
+	hashes, err := repository.HashesOf(blob, modFile)
+	require.NoError(t, err)
+
+	err = zips.PutZip(mod, blob)
+	require.NoError(t, err)
+
+	err = index.Put(store.ModuleAddition{
+		Mod:      mod,
+		UniqueID: id,
+		ModFile:  modFile,
+		Hashes:   hashes,
+	})
+	require.NoError(t, err)
+}
+
+func Test_ExportImport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	fromIndex, fromZips := setup(t, dir, "from")
+	toIndex, toZips := setup(t, dir, "to")
+
+	modA := coordinates.SerialModule{SerialID: 7, Module: coordinates.Module{Source: "example.com/a", Version: "v1.0.0"}}
+	modB := coordinates.SerialModule{SerialID: 3, Module: coordinates.Module{Source: "example.com/b/v2", Version: "v2.1.0"}}
+	modC := coordinates.SerialModule{SerialID: 9, Module: coordinates.Module{Source: "example.com/c", Version: "v0.1.0"}}
+
+	put(t, fromIndex, fromZips, modA.SerialID, modA.Module)
+	put(t, fromIndex, fromZips, modB.SerialID, modB.Module)
+
+	// modC is registered, but was never downloaded
+	var bundle bytes.Buffer
+	manifest, err := NewExporter(fromIndex, fromZips).Export(
+		[]coordinates.SerialModule{modA, modB, modC},
+		&bundle,
+	)
+	require.NoError(t, err)
+	require.Len(t, manifest.Modules, 2)
+	require.Equal(t, modB, manifest.Modules[0].SerialModule)
+	require.Equal(t, modA, manifest.Modules[1].SerialModule)
+
+	// modA is already in the destination
+	put(t, toIndex, toZips, modA.SerialID, modA.Module)
+
+	importer := NewImporter(toIndex, toZips, "")
+	imported, err := importer.Import(bytes.NewReader(bundle.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, Imported{Total: 2, Added: 1, Skipped: 1}, imported)
+
+	for _, mod := range []coordinates.SerialModule{modA, modB} {
+		exists, id, err := toIndex.Contains(mod.Module)
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, mod.SerialID, id)
+
+		expected, err := fromIndex.Hashes(mod.Module)
+		require.NoError(t, err)
+		hashes, err := toIndex.Hashes(mod.Module)
+		require.NoError(t, err)
+		require.Equal(t, expected, hashes)
+
+		zip, err := toZips.OpenZip(mod.Module)
+		require.NoError(t, err)
+		zipHash, err := repository.HashOf(zip)
+		require.NoError(t, err)
+		require.NoError(t, zip.Close())
+		require.Equal(t, expected.Zip, zipHash)
+	}
+
+	// importing again changes nothing
+	imported, err = importer.Import(bytes.NewReader(bundle.Bytes()))
+	require.NoError(t, err)
+	require.Equal(t, Imported{Total: 2, Skipped: 2}, imported)
+}
+
+func Test_Import_corrupt(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	fromIndex, fromZips := setup(t, dir, "from")
+	toIndex, toZips := setup(t, dir, "to")
+
+	mod := coordinates.SerialModule{SerialID: 1, Module: coordinates.Module{Source: "example.com/a", Version: "v1.0.0"}}
+	put(t, fromIndex, fromZips, mod.SerialID, mod.Module)
+
+	var bundle bytes.Buffer
+	manifest, err := NewExporter(fromIndex, fromZips).Export([]coordinates.SerialModule{mod}, &bundle)
+	require.NoError(t, err)
+
+	// the zip in the bundle does not match its manifest
+	manifest.Modules[0].Hashes.Zip = "h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA="
+	var tampered bytes.Buffer
+	tr := tar.NewReader(&bundle)
+	tw := tar.NewWriter(&tampered)
+	_, err = tr.Next()
+	require.NoError(t, err)
+	require.NoError(t, writeManifest(tw, manifest))
+	header, err := tr.Next()
+	require.NoError(t, err)
+	content, err := ioutil.ReadAll(tr)
+	require.NoError(t, err)
+	require.NoError(t, tw.WriteHeader(header))
+	_, err = tw.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, tw.Close())
+
+	_, err = NewImporter(toIndex, toZips, "").Import(&tampered)
+	require.Error(t, err)
+
+	exists, _, err := toIndex.Contains(mod.Module)
+	require.NoError(t, err)
+	require.False(t, exists)
+}
+
+func Test_zipName(t *testing.T) {
+	mod := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.8.1"}
+	name := zipName(mod)
+	require.Equal(t, "zips/github.com/pkg/errors/@v/v0.8.1.zip", name)
+
+	parsed, err := moduleOf(name)
+	require.NoError(t, err)
+	require.Equal(t, mod, parsed)
+
+	_, err = moduleOf("manifest.json")
+	require.Error(t, err)
+
+	_, err = moduleOf("zips/github.com/pkg/errors/v0.8.1.zip")
+	require.Error(t, err)
+}
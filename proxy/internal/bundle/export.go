@@ -0,0 +1,171 @@
+package bundle
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+// An Exporter writes modules from the index and zip store of a proxy
+// into a bundle.
+type Exporter struct {
+	index store.Index
+	store store.ZipStore
+	log   loggy.Logger
+}
+
+// NewExporter creates an Exporter of the modules in index and store.
+func NewExporter(index store.Index, store store.ZipStore) *Exporter {
+	return &Exporter{
+		index: index,
+		store: store,
+		log:   loggy.New("bundle-export"),
+	}
+}
+
+// Export writes a bundle of mods to w. Modules which are not in the index
+// are left out of the bundle with a warning, since the registry may list
+// modules which the proxy has not downloaded (yet).
+func (e *Exporter) Export(mods []coordinates.SerialModule, w io.Writer) (Manifest, error) {
+	manifest := Manifest{
+		Version: formatVersion,
+		Created: time.Now().UTC(),
+	}
+
+	sorted := make([]coordinates.SerialModule, len(mods))
+	copy(sorted, mods)
+	sort.Slice(sorted, func(x, y int) bool {
+		return sorted[x].SerialID < sorted[y].SerialID
+	})
+
+	for _, mod := range sorted {
+		entry, exists, err := e.entryOf(mod.Module)
+		if err != nil {
+			return manifest, errors.Wrapf(err, "failed to export %s", mod)
+		}
+		if !exists {
+			e.log.Warnf("not exporting %s, it is not stored", mod)
+			continue
+		}
+		manifest.Modules = append(manifest.Modules, entry)
+	}
+
+	e.log.Infof("exporting %d modules", len(manifest.Modules))
+
+	tw := tar.NewWriter(w)
+
+	if err := writeManifest(tw, manifest); err != nil {
+		return manifest, err
+	}
+
+	for i, entry := range manifest.Modules {
+		if err := e.writeZip(tw, entry); err != nil {
+			return manifest, errors.Wrapf(err, "failed to export %s", entry.Module)
+		}
+		e.log.Tracef("[%d/%d] exported %s", i+1, len(manifest.Modules), entry.Module)
+	}
+
+	return manifest, tw.Close()
+}
+
+// entryOf returns the manifest entry of mod, as recorded in the index.
+func (e *Exporter) entryOf(mod coordinates.Module) (Entry, bool, error) {
+	exists, id, err := e.index.Contains(mod)
+	if err != nil || !exists {
+		return Entry{}, false, err
+	}
+
+	modFile, err := e.index.Mod(mod)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	hashes, err := e.index.Hashes(mod)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	info, err := e.index.Info(mod)
+	if err != nil {
+		return Entry{}, false, err
+	}
+
+	zip, err := e.store.OpenZip(mod)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	defer ignore.Close(zip)
+
+	// modules stored before hashes were being recorded
+	if hashes.Zip == "" || hashes.Mod == "" {
+		if hashes, err = repository.HashesOf(zip, modFile); err != nil {
+			return Entry{}, false, err
+		}
+	}
+
+	return Entry{
+		SerialModule: coordinates.SerialModule{
+			Module:   mod,
+			SerialID: id,
+		},
+		ModFile: modFile,
+		Hashes:  hashes,
+		Info:    info,
+		Size:    zip.Size(),
+	}, true, nil
+}
+
+func writeManifest(tw *tar.Writer, manifest Manifest) error {
+	bs, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    manifestName,
+		Mode:    0644,
+		Size:    int64(len(bs)),
+		ModTime: manifest.Created,
+	}); err != nil {
+		return err
+	}
+
+	_, err = tw.Write(bs)
+	return err
+}
+
+func (e *Exporter) writeZip(tw *tar.Writer, entry Entry) error {
+	zip, err := e.store.OpenZip(entry.Module)
+	if err != nil {
+		return err
+	}
+	defer ignore.Close(zip)
+
+	// the size in the header must match what is written
+	if zip.Size() != entry.Size {
+		return errors.Errorf("zip changed size while exporting, from %d to %d bytes", entry.Size, zip.Size())
+	}
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    zipName(entry.Module),
+		Mode:    0644,
+		Size:    entry.Size,
+		ModTime: zip.ModTime(),
+	}); err != nil {
+		return err
+	}
+
+	_, err = io.Copy(tw, zip)
+	return err
+}
@@ -0,0 +1,191 @@
+package bundle
+
+import (
+	"archive/tar"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+// Imported counts the modules of an imported bundle.
+type Imported struct {
+	Total   int // modules in the bundle
+	Added   int // modules added to the proxy
+	Skipped int // modules the proxy already had
+}
+
+// An Importer adds the modules of a bundle to the index and zip store of
+// a proxy.
+type Importer struct {
+	index  store.Index
+	store  store.ZipStore
+	tmpDir string
+	log    loggy.Logger
+}
+
+// NewImporter creates an Importer of modules into index and store. Zips are
+// spilled into tmpDir while they are verified, or into the system temporary
+// directory if tmpDir is empty.
+func NewImporter(index store.Index, store store.ZipStore, tmpDir string) *Importer {
+	return &Importer{
+		index:  index,
+		store:  store,
+		tmpDir: tmpDir,
+		log:    loggy.New("bundle-import"),
+	}
+}
+
+// Import reads a bundle from r, adding every module which the proxy does not
+// have yet. Each zip is verified against the hashes in the manifest before it
+// is stored, and a module is only added to the index once its zip is stored,
+// so an import which fails part way may simply be run again.
+func (i *Importer) Import(r io.Reader) (Imported, error) {
+	var imported Imported
+
+	tr := tar.NewReader(r)
+
+	manifest, err := readManifest(tr)
+	if err != nil {
+		return imported, err
+	}
+
+	entries := make(map[coordinates.Module]Entry, len(manifest.Modules))
+	for _, entry := range manifest.Modules {
+		entries[entry.Module] = entry
+	}
+	imported.Total = len(entries)
+
+	i.log.Infof("importing %d modules, bundle created %s", imported.Total, manifest.Created)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return imported, errors.Wrap(err, "failed to read bundle")
+		}
+
+		mod, err := moduleOf(header.Name)
+		if err != nil {
+			return imported, err
+		}
+
+		entry, exists := entries[mod]
+		if !exists {
+			return imported, errors.Errorf("bundle contains %s, which is not in its manifest", mod)
+		}
+		delete(entries, mod)
+
+		added, err := i.importZip(entry, tr)
+		if err != nil {
+			return imported, errors.Wrapf(err, "failed to import %s", mod)
+		}
+
+		if added {
+			imported.Added++
+		} else {
+			imported.Skipped++
+		}
+
+		done := imported.Added + imported.Skipped
+		i.log.Tracef("[%d/%d] imported %s", done, imported.Total, mod)
+	}
+
+	if len(entries) > 0 {
+		return imported, errors.Errorf("bundle is missing the zips of %d modules in its manifest", len(entries))
+	}
+
+	return imported, nil
+}
+
+func readManifest(tr *tar.Reader) (Manifest, error) {
+	var manifest Manifest
+
+	header, err := tr.Next()
+	if err != nil {
+		return manifest, errors.Wrap(err, "failed to read bundle")
+	}
+
+	if header.Name != manifestName {
+		return manifest, errors.Errorf("bundle must begin with %s, not %s", manifestName, header.Name)
+	}
+
+	if err := json.NewDecoder(tr).Decode(&manifest); err != nil {
+		return manifest, errors.Wrap(err, "failed to parse bundle manifest")
+	}
+
+	if manifest.Version != formatVersion {
+		return manifest, errors.Errorf("unsupported bundle version %d", manifest.Version)
+	}
+
+	return manifest, nil
+}
+
+func (i *Importer) importZip(entry Entry, r io.Reader) (bool, error) {
+	exists, id, err := i.index.Contains(entry.Module)
+	if err != nil {
+		return false, err
+	}
+
+	if exists {
+		if id != entry.SerialID {
+			i.log.Warnf("already have %s, but with serial ID %d instead of %d", entry.Module, id, entry.SerialID)
+		}
+		return false, nil
+	}
+
+	file, err := repository.Spill(i.tmpDir, r)
+	if err != nil {
+		return false, err
+	}
+	defer ignore.Close(file)
+
+	hashes, err := repository.HashesOf(file, entry.ModFile)
+	if err != nil {
+		return false, err
+	}
+
+	if hashes != entry.Hashes {
+		return false, errors.Errorf("zip hashes to %s, manifest has %s", hashes.Zip, entry.Hashes.Zip)
+	}
+
+	if err := i.putZip(entry, file); err != nil {
+		return false, err
+	}
+
+	return true, i.index.Put(store.ModuleAddition{
+		Mod:      entry.Module,
+		UniqueID: entry.SerialID,
+		ModFile:  entry.ModFile,
+		Hashes:   hashes,
+		Info:     entry.Info,
+	})
+}
+
+func (i *Importer) putZip(entry Entry, archive repository.Archive) error {
+	// a previous import may have stored the zip without getting as
+	// far as the index, in which case the zip may be incomplete
+	if zip, err := i.store.OpenZip(entry.Module); err == nil {
+		zipHash, err := repository.HashOf(zip)
+		ignore.Close(zip)
+		if err == nil && zipHash == entry.Hashes.Zip {
+			return nil
+		}
+
+		i.log.Warnf("replacing incomplete zip of %s", entry.Module)
+		if err := i.store.DelZip(entry.Module); err != nil {
+			return err
+		}
+	}
+
+	return i.store.PutZip(entry.Module, archive)
+}
@@ -0,0 +1,69 @@
+// Package bundle exports modules stored by a proxy into a single archive, and
+// imports such archives into another proxy, e.g. one which cannot reach any
+// upstream to download modules itself.
+//
+// A bundle is a tar archive which begins with a manifest.json listing every
+// module in the bundle, followed by the zip of each of those modules.
+package bundle
+
+import (
+	"path"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+const (
+	manifestName = "manifest.json"
+	zipsDir      = "zips"
+
+	// formatVersion is incremented on incompatible changes to the layout
+	formatVersion = 1
+)
+
+// Manifest describes the content of a bundle.
+type Manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+	Modules []Entry   `json:"modules"`
+}
+
+// An Entry is everything the index of a proxy records about a module, so
+// that importing a bundle does not need to derive any of it again. The serial
+// ID is the one assigned by the registry, so a proxy populated from a bundle
+// requests exactly the same modules from its registry as the original would.
+type Entry struct {
+	coordinates.SerialModule
+	ModFile string             `json:"mod_file"`
+	Hashes  repository.Hashes  `json:"hashes"`
+	Info    repository.RevInfo `json:"info"`
+	Size    int64              `json:"size"`
+}
+
+// zipName returns the name of the zip of mod in a bundle, which follows the
+// layout of the GOPROXY protocol, e.g. zips/github.com/pkg/errors/@v/v0.8.1.zip.
+func zipName(mod coordinates.Module) string {
+	return path.Join(zipsDir, mod.Source, "@v", mod.Version+".zip")
+}
+
+// moduleOf is the inverse of zipName.
+func moduleOf(name string) (coordinates.Module, error) {
+	trimmed := strings.TrimPrefix(name, zipsDir+"/")
+	if trimmed == name || !strings.HasSuffix(trimmed, ".zip") {
+		return coordinates.Module{}, errors.Errorf("unexpected file %q in bundle", name)
+	}
+
+	parts := strings.Split(strings.TrimSuffix(trimmed, ".zip"), "/@v/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return coordinates.Module{}, errors.Errorf("unexpected file %q in bundle", name)
+	}
+
+	return coordinates.Module{
+		Source:  parts[0],
+		Version: parts[1],
+	}, nil
+}
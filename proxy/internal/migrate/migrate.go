@@ -0,0 +1,318 @@
+// Package migrate copies the modules stored by a proxy from one ZipStore and
+// Index to another, e.g. from local disk to MySQL, without downloading any of
+// them again from upstream.
+package migrate
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb/tlog"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
+)
+
+// Storage is where a proxy keeps its modules.
+type Storage struct {
+	Index store.Index
+	Store store.ZipStore
+}
+
+// Progress counts the modules of a migration.
+type Progress struct {
+	Total   int // modules in the source
+	Copied  int // modules copied by this migration
+	Skipped int // modules already in the destination
+	Failed  int // modules which could not be copied
+}
+
+// records are copied this many at a time
+const recordsBatch = 1000
+
+// A Migrator copies every module from one Storage to another.
+//
+// A module is only added to the destination index once its zip has been
+// copied and verified, so a migration which is interrupted (or which fails
+// to copy some modules) can simply be run again, and will continue with the
+// modules which have not been copied yet.
+type Migrator struct {
+	from     Storage
+	to       Storage
+	progress func(Progress)
+	log      loggy.Logger
+}
+
+// New creates a Migrator which copies modules from one Storage to another,
+// calling progress after each module.
+func New(from, to Storage, progress func(Progress)) *Migrator {
+	return &Migrator{
+		from:     from,
+		to:       to,
+		progress: progress,
+		log:      loggy.New("migrate"),
+	}
+}
+
+// Run copies every module which is not yet in the destination, and then the
+// records of the checksum database served by the proxy, if there is one.
+func (m *Migrator) Run() (Progress, error) {
+	var progress Progress
+
+	mods, err := m.from.Index.List()
+	if err != nil {
+		return progress, errors.Wrap(err, "failed to list modules to migrate")
+	}
+
+	// in the order they were registered, which is also roughly the order
+	// in which the proxy originally downloaded them
+	sort.Slice(mods, func(x, y int) bool {
+		return mods[x].SerialID < mods[y].SerialID
+	})
+
+	progress.Total = len(mods)
+	m.log.Infof("migrating %d modules", progress.Total)
+
+	for _, mod := range mods {
+		copied, err := m.migrate(mod)
+		switch {
+		case err != nil:
+			m.log.Errorf("failed to migrate %s, %v", mod, err)
+			progress.Failed++
+		case copied:
+			progress.Copied++
+		default:
+			progress.Skipped++
+		}
+		m.progress(progress)
+	}
+
+	if err := m.migrateRecords(); err != nil {
+		return progress, err
+	}
+
+	if progress.Failed > 0 {
+		return progress, errors.Errorf("failed to migrate %d of %d modules", progress.Failed, progress.Total)
+	}
+
+	return progress, nil
+}
+
+// migrate copies mod, unless it has already been copied.
+func (m *Migrator) migrate(mod coordinates.SerialModule) (bool, error) {
+	exists, _, err := m.to.Index.Contains(mod.Module)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		m.log.Tracef("already migrated %s", mod)
+		return false, nil
+	}
+
+	modFile, err := m.from.Index.Mod(mod.Module)
+	if err != nil {
+		return false, err
+	}
+
+	recorded, err := m.from.Index.Hashes(mod.Module)
+	if err != nil {
+		return false, err
+	}
+
+	info, err := m.from.Index.Info(mod.Module)
+	if err != nil {
+		return false, err
+	}
+
+	hashes, err := m.copyZip(mod.Module, modFile)
+	if err != nil {
+		return false, err
+	}
+
+	// modules stored before hashes were being recorded have nothing to
+	// compare against, so the copy is only compared against the original
+	if recorded.Zip != "" && recorded.Zip != hashes.Zip {
+		return false, errors.Errorf("stored zip hashes to %s, index has %s", hashes.Zip, recorded.Zip)
+	}
+
+	if err := m.to.Index.Put(store.ModuleAddition{
+		Mod:      mod.Module,
+		UniqueID: mod.SerialID,
+		ModFile:  modFile,
+		Hashes:   hashes,
+		Info:     info,
+	}); err != nil {
+		return false, err
+	}
+
+	m.log.Tracef("migrated %s", mod)
+	return true, nil
+}
+
+// copyZip copies the zip of mod, and returns its hashes once the copy
+// has been verified to hash the same as the original.
+func (m *Migrator) copyZip(mod coordinates.Module, modFile string) (repository.Hashes, error) {
+	zip, err := m.from.Store.OpenZip(mod)
+	if err != nil {
+		return repository.Hashes{}, err
+	}
+	defer ignore.Close(zip)
+
+	hashes, err := repository.HashesOf(zip, modFile)
+	if err != nil {
+		return repository.Hashes{}, err
+	}
+
+	// a previous migration may have copied the zip without getting as
+	// far as the index, in which case the copy may be incomplete
+	if copied, err := m.hashOf(mod); err == nil {
+		if copied == hashes.Zip {
+			return hashes, nil
+		}
+		m.log.Warnf("replacing incomplete copy of %s", mod)
+		if err := m.to.Store.DelZip(mod); err != nil {
+			return repository.Hashes{}, err
+		}
+	}
+
+	if err := m.to.Store.PutZip(mod, zip); err != nil {
+		return repository.Hashes{}, err
+	}
+
+	copied, err := m.hashOf(mod)
+	if err != nil {
+		return repository.Hashes{}, err
+	}
+
+	if copied != hashes.Zip {
+		return repository.Hashes{}, errors.Errorf("copied zip hashes to %s, original hashes to %s", copied, hashes.Zip)
+	}
+
+	return hashes, nil
+}
+
+// hashOf returns the hash of the zip of mod in the destination.
+func (m *Migrator) hashOf(mod coordinates.Module) (string, error) {
+	zip, err := m.to.Store.OpenZip(mod)
+	if err != nil {
+		return "", err
+	}
+	defer ignore.Close(zip)
+
+	return repository.HashOf(zip)
+}
+
+// migrateRecords copies the records of the checksum database, keeping their
+// ids, so that the destination serves exactly the same tree. Both indexes
+// are capable of storing a checksum database, but only those of a proxy
+// which served one actually have any records.
+func (m *Migrator) migrateRecords() error {
+	from, ok := m.from.Index.(sumdb.Store)
+	if !ok {
+		return nil
+	}
+
+	total, err := from.CountRecords()
+	if err != nil {
+		return err
+	}
+
+	to, ok := m.to.Index.(sumdb.Store)
+	if !ok {
+		if total == 0 {
+			return nil
+		}
+		return errors.New("destination index is not capable of storing a checksum database")
+	}
+
+	existing, err := to.CountRecords()
+	if err != nil {
+		return err
+	}
+
+	if total == 0 && existing == 0 {
+		return nil
+	}
+
+	if existing > total {
+		return errors.Errorf("destination checksum database has %d records, source only has %d", existing, total)
+	}
+
+	// a log is append-only, so anything already in the destination
+	// must be the same as the beginning of the source
+	for id := int64(0); id < existing; id += recordsBatch {
+		n := min(recordsBatch, existing-id)
+		expected, err := from.ReadRecords(id, n)
+		if err != nil {
+			return err
+		}
+		actual, err := to.ReadRecords(id, n)
+		if err != nil {
+			return err
+		}
+		for i := range expected {
+			if !bytes.Equal(expected[i], actual[i]) {
+				return errors.Errorf("checksum database record %d differs in destination", id+int64(i))
+			}
+		}
+	}
+
+	m.log.Infof("migrating %d of %d checksum database records", total-existing, total)
+
+	for id := existing; id < total; id += recordsBatch {
+		records, err := from.ReadRecords(id, min(recordsBatch, total-id))
+		if err != nil {
+			return err
+		}
+
+		for i, data := range records {
+			if err := appendRecord(to, id+int64(i), data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func appendRecord(to sumdb.Store, id int64, data []byte) error {
+	mod, err := moduleOf(data)
+	if err != nil {
+		return errors.Wrapf(err, "malformed checksum database record %d", id)
+	}
+
+	hashes, err := tlog.StoredHashes(id, data, to)
+	if err != nil {
+		return err
+	}
+
+	return to.AppendRecord(id, mod, data, hashes)
+}
+
+// moduleOf returns the module of a record, which is in the form of go.sum
+// lines, e.g. "github.com/pkg/errors v0.8.1 h1:...".
+func moduleOf(data []byte) (coordinates.Module, error) {
+	fields := strings.Fields(string(data))
+	if len(fields) < 3 {
+		return coordinates.Module{}, errors.New("expected go.sum lines")
+	}
+
+	return coordinates.Module{
+		Source:  fields[0],
+		Version: strings.TrimSuffix(fields[1], "/go.mod"),
+	}, nil
+}
+
+func min(a, b int64) int64 {
+	if a < b {
+		return a
+	}
+	return b
+}
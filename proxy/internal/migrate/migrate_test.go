@@ -0,0 +1,183 @@
+package migrate
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb/tlog"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
+)
+
+func setupStorage(t *testing.T, dir, name string) Storage {
+	index, err := store.NewIndex(store.IndexOptions{
+		Directory: filepath.Join(dir, name, "index"),
+	})
+	require.NoError(t, err)
+
+	zips := store.NewStore(store.Options{
+		Directory: filepath.Join(dir, name, "data"),
+	}, stats.Discard())
+
+	return Storage{Index: index, Store: zips}
+}
+
+func zipOf(t *testing.T, mod coordinates.Module) (repository.Blob, string) {
+	modFile := "module " + mod.Source + "\n"
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create(mod.Source + "@" + mod.Version + "/go.mod")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(modFile))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+
+	return repository.Blob(buf.Bytes()), modFile
+}
+
+func put(t *testing.T, s Storage, id int64, mod coordinates.Module) repository.Hashes {
+	blob, modFile := zipOf(t, mod)
+	hashes, err := repository.HashesOf(blob, modFile)
+	require.NoError(t, err)
+
+	err = s.Store.PutZip(mod, blob)
+	require.NoError(t, err)
+
+	err = s.Index.Put(store.ModuleAddition{
+		Mod:      mod,
+		UniqueID: id,
+		ModFile:  modFile,
+		Hashes:   hashes,
+	})
+	require.NoError(t, err)
+	return hashes
+}
+
+func record(t *testing.T, s Storage, id int64, mod coordinates.Module, hashes repository.Hashes) {
+	records := s.Index.(sumdb.Store)
+	data := []byte(mod.Source + " " + mod.Version + " " + hashes.Zip + "\n" +
+		mod.Source + " " + mod.Version + "/go.mod " + hashes.Mod + "\n")
+	stored, err := tlog.StoredHashes(id, data, records)
+	require.NoError(t, err)
+	err = records.AppendRecord(id, mod, data, stored)
+	require.NoError(t, err)
+}
+
+func treeHash(t *testing.T, s Storage) tlog.Hash {
+	records := s.Index.(sumdb.Store)
+	n, err := records.CountRecords()
+	require.NoError(t, err)
+	hash, err := tlog.TreeHash(n, records)
+	require.NoError(t, err)
+	return hash
+}
+
+func Test_Migrator(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	from := setupStorage(t, dir, "from")
+	to := setupStorage(t, dir, "to")
+
+	modA := coordinates.Module{Source: "example.com/a", Version: "v1.0.0"}
+	modB := coordinates.Module{Source: "example.com/b", Version: "v1.0.0"}
+	modC := coordinates.Module{Source: "example.com/c", Version: "v1.0.0"}
+
+	hashesA := put(t, from, 1, modA)
+	hashesB := put(t, from, 2, modB)
+	put(t, from, 3, modC)
+	record(t, from, 0, modA, hashesA)
+	record(t, from, 1, modB, hashesB)
+
+	// modA was migrated before, and an earlier migration was interrupted
+	// after copying the zip of modB, but with a truncated copy
+	put(t, to, 1, modA)
+	record(t, to, 0, modA, hashesA)
+	blob, _ := zipOf(t, modB)
+	err = to.Store.PutZip(modB, blob[:len(blob)/2])
+	require.NoError(t, err)
+
+	var reported []Progress
+	progress, err := New(from, to, func(p Progress) {
+		reported = append(reported, p)
+	}).Run()
+	require.NoError(t, err)
+	require.Equal(t, Progress{Total: 3, Copied: 2, Skipped: 1}, progress)
+	require.Len(t, reported, 3)
+
+	for id, mod := range []coordinates.Module{modA, modB, modC} {
+		exists, serial, err := to.Index.Contains(mod)
+		require.NoError(t, err)
+		require.True(t, exists)
+		require.Equal(t, int64(id+1), serial)
+
+		expected, err := from.Index.Hashes(mod)
+		require.NoError(t, err)
+		hashes, err := to.Index.Hashes(mod)
+		require.NoError(t, err)
+		require.Equal(t, expected, hashes)
+
+		zip, err := to.Store.OpenZip(mod)
+		require.NoError(t, err)
+		zipHash, err := repository.HashOf(zip)
+		require.NoError(t, err)
+		require.NoError(t, zip.Close())
+		require.Equal(t, expected.Zip, zipHash)
+	}
+
+	// the destination serves exactly the same checksum database
+	require.Equal(t, treeHash(t, from), treeHash(t, to))
+
+	// nothing left to do the second time around
+	progress, err = New(from, to, func(Progress) {}).Run()
+	require.NoError(t, err)
+	require.Equal(t, Progress{Total: 3, Skipped: 3}, progress)
+}
+
+func Test_Migrator_diverged(t *testing.T) {
+	dir, err := ioutil.TempDir("", "migrate-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	from := setupStorage(t, dir, "from")
+	to := setupStorage(t, dir, "to")
+
+	modA := coordinates.Module{Source: "example.com/a", Version: "v1.0.0"}
+	modB := coordinates.Module{Source: "example.com/b", Version: "v1.0.0"}
+
+	hashesA := put(t, from, 1, modA)
+	hashesB := put(t, from, 2, modB)
+	record(t, from, 0, modA, hashesA)
+
+	// the destination has a checksum database of its own
+	record(t, to, 0, modB, hashesB)
+
+	_, err = New(from, to, func(Progress) {}).Run()
+	require.Error(t, err)
+}
+
+func Test_moduleOf(t *testing.T) {
+	mod, err := moduleOf([]byte(
+		"github.com/pkg/errors v0.8.1 h1:abc=\n" +
+			"github.com/pkg/errors v0.8.1/go.mod h1:def=\n",
+	))
+	require.NoError(t, err)
+	require.Equal(t, coordinates.Module{
+		Source:  "github.com/pkg/errors",
+		Version: "v0.8.1",
+	}, mod)
+
+	_, err = moduleOf([]byte("github.com/pkg/errors\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,246 @@
+package bg
+
+import (
+	"sort"
+	"time"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+// A Policy decides which modules are evicted first.
+type Policy string
+
+const (
+	// LRU evicts the modules which were served least recently first.
+	LRU Policy = "lru"
+
+	// LFU evicts the modules which were served least often first, and of
+	// those which were served equally often, the least recently served.
+	LFU Policy = "lfu"
+)
+
+type EvictOptions struct {
+	// Enabled determines whether modules are evicted from the index and
+	// zip store when the zips take up more space than Quota.
+	Enabled bool
+
+	// Quota is the number of bytes the stored zips may take up.
+	Quota int64
+
+	// Policy determines the order in which modules are evicted.
+	Policy Policy
+
+	// CacheOnly allows evicting modules which are still listed by the
+	// registry. Otherwise only modules which the registry does not list
+	// (e.g. which are waiting to be pruned) are ever evicted.
+	CacheOnly bool
+
+	// Frequency determines how often the size of the store is checked
+	// against the quota.
+	Frequency time.Duration
+}
+
+// An evictor removes modules when the store is over its quota, in the order
+// of the configured policy.
+type evictor struct {
+	options           EvictOptions
+	index             store.Index
+	store             store.ZipStore
+	accesses          store.AccessLog
+	counter           store.AccessCounter
+	ingester          store.Ingester
+	registryRequester get.RegistryAPI
+	emitter           stats.Sender
+	log               loggy.Logger
+}
+
+func newEvictor(
+	options EvictOptions,
+	index store.Index,
+	store store.ZipStore,
+	accesses store.AccessLog,
+	counter store.AccessCounter,
+	ingester store.Ingester,
+	registryRequester get.RegistryAPI,
+	emitter stats.Sender,
+) *evictor {
+	return &evictor{
+		options:           options,
+		index:             index,
+		store:             store,
+		accesses:          accesses,
+		counter:           counter,
+		ingester:          ingester,
+		registryRequester: registryRequester,
+		emitter:           emitter,
+		log:               loggy.New("bg-evictor"),
+	}
+}
+
+func (e *evictor) evict() error {
+	// accesses are only counted in memory until they are flushed here, if
+	// that fails they are kept for next time and the order is a bit stale
+	if err := e.counter.Flush(); err != nil {
+		e.log.Warnf("failed to flush counted accesses, %v", err)
+		e.emitter.Count("evict-flush-failure", 1)
+	}
+
+	accesses, err := e.load()
+	if err != nil {
+		return err
+	}
+
+	var total int64
+	for _, access := range accesses {
+		total += access.Size
+	}
+
+	e.emitter.Gauge("evict-store-bytes", int(total))
+	if total <= e.options.Quota {
+		e.log.Tracef("store is %d bytes, within quota of %d bytes", total, e.options.Quota)
+		return nil
+	}
+
+	e.log.Infof("store is %d bytes, over quota of %d bytes", total, e.options.Quota)
+
+	candidates, err := e.candidates(accesses)
+	if err != nil {
+		return err
+	}
+
+	for _, access := range candidates {
+		if total <= e.options.Quota {
+			break
+		}
+
+		if err := e.remove(access.Module); err != nil {
+			e.log.Errorf("failed to evict %s, %v", access.Module, err)
+			e.emitter.Count("evict-mod-failure", 1)
+			continue // may as well try the others
+		}
+
+		e.log.Infof("evicted %s, %d bytes last served %s", access.Module, access.Size, access.Last)
+		e.emitter.Count("evict-mod-ok", 1)
+		e.emitter.Count("evict-bytes", int(access.Size))
+		total -= access.Size
+	}
+
+	e.emitter.Gauge("evict-store-bytes", int(total))
+	if total > e.options.Quota {
+		e.log.Warnf("store is still %d bytes, nothing else may be evicted", total)
+		e.emitter.Count("evict-quota-exceeded", 1)
+	}
+
+	return nil
+}
+
+// load returns the Access of every module in the index. Modules which have
+// not been served since they were stored (or since eviction was enabled) have
+// their size looked up now, and are treated as last served when stored.
+func (e *evictor) load() ([]store.Access, error) {
+	stored, err := e.index.List()
+	if err != nil {
+		e.log.Errorf("failed to list mods in index, %v", err)
+		return nil, err
+	}
+
+	recorded, err := e.accesses.Accesses()
+	if err != nil {
+		e.log.Errorf("failed to list accesses of mods, %v", err)
+		return nil, err
+	}
+
+	byMod := make(map[coordinates.Module]store.Access, len(recorded))
+	for _, access := range recorded {
+		byMod[access.Module] = access
+	}
+
+	accesses := make([]store.Access, 0, len(stored))
+	for _, mod := range stored {
+		access, exists := byMod[mod.Module]
+		delete(byMod, mod.Module)
+
+		if !exists || access.Size == 0 {
+			if access, err = e.backfill(mod.Module, access); err != nil {
+				e.log.Warnf("failed to find size of %s, %v", mod.Module, err)
+				continue
+			}
+		}
+
+		accesses = append(accesses, access)
+	}
+
+	// whatever is left over was removed some other way, e.g. pruned
+	for mod := range byMod {
+		if err := e.accesses.RemoveAccess(mod); err != nil {
+			e.log.Warnf("failed to remove access of %s, %v", mod, err)
+		}
+	}
+
+	return accesses, nil
+}
+
+func (e *evictor) backfill(mod coordinates.Module, access store.Access) (store.Access, error) {
+	zip, err := e.store.OpenZip(mod)
+	if err != nil {
+		return access, err
+	}
+	defer ignore.Close(zip)
+
+	access.Module = mod
+	access.Size = zip.Size()
+	if access.Last.IsZero() {
+		access.Last = zip.ModTime()
+	}
+
+	e.emitter.Count("evict-access-backfill", 1)
+	return access, e.accesses.PutAccess(access)
+}
+
+// candidates returns the modules which may be evicted, in the order in which
+// they should be evicted.
+func (e *evictor) candidates(accesses []store.Access) ([]store.Access, error) {
+	keep := make(map[coordinates.Module]bool)
+	if !e.options.CacheOnly {
+		registered, err := e.registryRequester.ModulesRegistered()
+		if err != nil {
+			// never evict anything if we cannot get an answer from the registry
+			e.log.Errorf("failed to acquire list of registered mods from registry, %v", err)
+			return nil, err
+		}
+		for _, mod := range registered {
+			keep[mod.Module] = true
+		}
+	}
+
+	candidates := make([]store.Access, 0, len(accesses))
+	for _, access := range accesses {
+		if !keep[access.Module] {
+			candidates = append(candidates, access)
+		}
+	}
+
+	sort.SliceStable(candidates, func(x, y int) bool {
+		a, b := candidates[x], candidates[y]
+		if e.options.Policy == LFU && a.Count != b.Count {
+			return a.Count < b.Count
+		}
+		return a.Last.Before(b.Last)
+	})
+
+	return candidates, nil
+}
+
+func (e *evictor) remove(mod coordinates.Module) error {
+	if err := e.ingester.Remove(mod); err != nil {
+		return err
+	}
+
+	return e.accesses.RemoveAccess(mod)
+}
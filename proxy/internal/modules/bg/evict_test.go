@@ -0,0 +1,203 @@
+package bg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+var (
+	modC = serial(3, "gopkg.in/yaml.v2", "v2.2.2")
+
+	day = time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+)
+
+func access(mod coordinates.SerialModule, size int64, last time.Time, count int64) store.Access {
+	return store.Access{
+		Module: mod.Module,
+		Size:   size,
+		Last:   last,
+		Count:  count,
+	}
+}
+
+// expectEvict records the modules which are evicted
+func expectEvict(mocks mocks) *[]coordinates.Module {
+	var evicted []coordinates.Module
+	remove := func(mod coordinates.Module) error {
+		evicted = append(evicted, mod)
+		return nil
+	}
+	mocks.ingester.RemoveMock.Set(remove)
+	mocks.accesses.RemoveAccessMock.Set(func(coordinates.Module) error { return nil })
+	return &evicted
+}
+
+func Test_evict_within_quota(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+	mocks.counter.FlushMock.Return(nil)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA, modB}, nil)
+	mocks.accesses.AccessesMock.Return([]store.Access{
+		access(modA, 100, day, 1),
+		access(modB, 100, day, 1),
+	}, nil)
+	mocks.emitter.GaugeMock.Expect("evict-store-bytes", 200).Return()
+
+	e := newEvictor(EvictOptions{
+		Enabled: true,
+		Quota:   200,
+		Policy:  LRU,
+	}, mocks.index, mocks.store, mocks.accesses, mocks.counter, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	err := e.evict()
+	require.NoError(t, err)
+}
+
+func Test_evict_lru(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+	mocks.counter.FlushMock.Return(nil)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA, modB, modC}, nil)
+	mocks.accesses.AccessesMock.Return([]store.Access{
+		access(modA, 100, day.Add(2*time.Hour), 1),
+		access(modB, 100, day, 9),
+		access(modC, 100, day.Add(1*time.Hour), 5),
+	}, nil)
+	mocks.emitter.GaugeMock.Set(func(string, int) {})
+	mocks.emitter.CountMock.Set(func(string, int) {})
+	evicted := expectEvict(mocks)
+
+	e := newEvictor(EvictOptions{
+		Enabled:   true,
+		Quota:     150,
+		Policy:    LRU,
+		CacheOnly: true,
+	}, mocks.index, mocks.store, mocks.accesses, mocks.counter, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	err := e.evict()
+	require.NoError(t, err)
+	require.Equal(t, []coordinates.Module{modB.Module, modC.Module}, *evicted)
+}
+
+func Test_evict_lfu(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+	mocks.counter.FlushMock.Return(nil)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA, modB, modC}, nil)
+	mocks.accesses.AccessesMock.Return([]store.Access{
+		access(modA, 100, day.Add(2*time.Hour), 1),
+		access(modB, 100, day, 9),
+		access(modC, 100, day.Add(1*time.Hour), 5),
+	}, nil)
+	mocks.emitter.GaugeMock.Set(func(string, int) {})
+	mocks.emitter.CountMock.Set(func(string, int) {})
+	evicted := expectEvict(mocks)
+
+	e := newEvictor(EvictOptions{
+		Enabled:   true,
+		Quota:     150,
+		Policy:    LFU,
+		CacheOnly: true,
+	}, mocks.index, mocks.store, mocks.accesses, mocks.counter, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	err := e.evict()
+	require.NoError(t, err)
+	require.Equal(t, []coordinates.Module{modA.Module, modC.Module}, *evicted)
+}
+
+func Test_evict_keep_registered(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+	mocks.counter.FlushMock.Return(nil)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA, modB, modC}, nil)
+	mocks.accesses.AccessesMock.Return([]store.Access{
+		access(modA, 100, day.Add(2*time.Hour), 1),
+		access(modB, 100, day, 9),
+		access(modC, 100, day.Add(1*time.Hour), 5),
+	}, nil)
+	mocks.registryRequester.ModulesRegisteredMock.Return([]coordinates.SerialModule{modB, modC}, nil)
+	mocks.emitter.GaugeMock.Set(func(string, int) {})
+
+	var counts []string
+	mocks.emitter.CountMock.Set(func(metric string, _ int) {
+		counts = append(counts, metric)
+	})
+	evicted := expectEvict(mocks)
+
+	e := newEvictor(EvictOptions{
+		Enabled: true,
+		Quota:   50,
+		Policy:  LRU,
+	}, mocks.index, mocks.store, mocks.accesses, mocks.counter, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	// only modA is not registered, which is not enough to get under quota
+	err := e.evict()
+	require.NoError(t, err)
+	require.Equal(t, []coordinates.Module{modA.Module}, *evicted)
+	require.Contains(t, counts, "evict-quota-exceeded")
+}
+
+func Test_evict_registry_failure(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+	mocks.counter.FlushMock.Return(nil)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.accesses.AccessesMock.Return([]store.Access{
+		access(modA, 100, day, 1),
+	}, nil)
+	mocks.registryRequester.ModulesRegisteredMock.Return(nil, errors.New("registry down"))
+	mocks.emitter.GaugeMock.Expect("evict-store-bytes", 100).Return()
+
+	e := newEvictor(EvictOptions{
+		Enabled: true,
+		Quota:   50,
+		Policy:  LRU,
+	}, mocks.index, mocks.store, mocks.accesses, mocks.counter, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	err := e.evict()
+	require.Error(t, err)
+}
+
+func Test_evict_backfill(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+	mocks.counter.FlushMock.Return(nil)
+
+	stored := zipOf(t, modA.Module, modFile)
+
+	// modA has never been served, and there is a leftover access of modB
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.accesses.AccessesMock.Return([]store.Access{
+		access(modB, 100, day, 1),
+	}, nil)
+	mocks.store.OpenZipMock.Set(func(coordinates.Module) (store.Zip, error) {
+		return openZip(stored), nil
+	})
+	mocks.accesses.PutAccessMock.Expect(store.Access{
+		Module: modA.Module,
+		Size:   int64(len(stored)),
+	}).Return(nil)
+	mocks.accesses.RemoveAccessMock.Expect(modB.Module).Return(nil)
+	mocks.emitter.CountMock.Expect("evict-access-backfill", 1).Return()
+	mocks.emitter.GaugeMock.Expect("evict-store-bytes", len(stored)).Return()
+
+	e := newEvictor(EvictOptions{
+		Enabled: true,
+		Quota:   1 << 20,
+		Policy:  LRU,
+	}, mocks.index, mocks.store, mocks.accesses, mocks.counter, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	err := e.evict()
+	require.NoError(t, err)
+}
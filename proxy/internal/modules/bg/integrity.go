@@ -0,0 +1,152 @@
+package bg
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+)
+
+type IntegrityOptions struct {
+	// Enabled determines whether the zip of every stored module is
+	// periodically re-hashed and compared with the hashes recorded in
+	// the index when the module was stored.
+	Enabled bool
+
+	// Frequency determines how often every stored module is checked. The
+	// first check happens when the proxy starts. Reading back every zip is
+	// expensive, so a typical value would be something like 24 hours.
+	Frequency time.Duration
+}
+
+// A checker re-hashes stored modules, looking for zips that no longer match
+// the hashes recorded in the index. Modules stored before hashes were being
+// recorded have their hashes filled in instead, once they pass verification.
+type checker struct {
+	index    store.Index
+	store    store.ZipStore
+	verifier checksum.Verifier
+	tracker  problems.Tracker
+	emitter  stats.Sender
+	log      loggy.Logger
+	now      func() time.Time
+}
+
+func newChecker(
+	index store.Index,
+	store store.ZipStore,
+	verifier checksum.Verifier,
+	tracker problems.Tracker,
+	emitter stats.Sender,
+) *checker {
+	return &checker{
+		index:    index,
+		store:    store,
+		verifier: verifier,
+		tracker:  tracker,
+		emitter:  emitter,
+		log:      loggy.New("bg-integrity"),
+		now:      time.Now,
+	}
+}
+
+func (c *checker) check() error {
+	mods, err := c.index.List()
+	if err != nil {
+		c.log.Errorf("failed to list mods in index, %v", err)
+		return err
+	}
+
+	c.log.Infof("checking integrity of %d stored mods", len(mods))
+
+	for _, mod := range mods {
+		if err := c.checkMod(mod.Module); err != nil {
+			c.log.Errorf("integrity check of %s failed, %v", mod.Module, err)
+			c.failed(mod.Module, err)
+			continue // may as well check the others
+		}
+		c.tracker.Resolve(mod.Module, problems.Verified)
+	}
+
+	c.emitter.Gauge("integrity-problems", len(c.tracker.Problems()))
+	return nil
+}
+
+func (c *checker) checkMod(mod coordinates.Module) error {
+	recorded, err := c.index.Hashes(mod)
+	if err != nil {
+		return err
+	}
+
+	zip, err := c.store.OpenZip(mod)
+	if err != nil {
+		return err
+	}
+	defer ignore.Close(zip)
+
+	modFile, err := c.index.Mod(mod)
+	if err != nil {
+		return err
+	}
+
+	actual, err := repository.HashesOf(zip, modFile)
+	if err != nil {
+		return err
+	}
+
+	// nothing to compare against, so trust what is stored now, as long as
+	// the checksum database agrees (which also records it in our own)
+	if recorded.Zip == "" || recorded.Mod == "" {
+		if err := c.verifier.Verify(mod, actual.Zip, actual.Mod); err != nil {
+			if checksum.IsMismatch(err) {
+				c.emitter.Count("integrity-mod-mismatch", 1)
+			}
+			return err
+		}
+
+		c.log.Infof("recording missing hashes of %s", mod)
+		c.emitter.Count("integrity-mod-backfill", 1)
+		return c.index.UpdateHashes(mod, actual)
+	}
+
+	if actual.Zip != recorded.Zip {
+		c.emitter.Count("integrity-mod-mismatch", 1)
+		return errors.Errorf("stored zip hashes to %s, index has %s", actual.Zip, recorded.Zip)
+	}
+
+	if actual.Mod != recorded.Mod {
+		c.emitter.Count("integrity-mod-mismatch", 1)
+		return errors.Errorf("stored go.mod hashes to %s, index has %s", actual.Mod, recorded.Mod)
+	}
+
+	c.emitter.Count("integrity-mod-ok", 1)
+	return nil
+}
+
+// failed records another failed check of mod, keeping track of when the
+// problem was first noticed.
+func (c *checker) failed(mod coordinates.Module, err error) {
+	now := c.now()
+
+	problem, exists := c.tracker.Problem(mod)
+	if !exists {
+		problem = problems.Problem{
+			Module:    mod,
+			FirstSeen: now,
+		}
+	}
+
+	problem.Time = now
+	problem.Message = err.Error()
+	problem.Attempts++
+	c.tracker.Set(problem)
+}
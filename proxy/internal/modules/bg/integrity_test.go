@@ -0,0 +1,175 @@
+package bg
+
+import (
+	"archive/zip"
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+)
+
+const modFile = "module github.com/pkg/errors\n"
+
+func zipOf(t *testing.T, mod coordinates.Module, content string) repository.Blob {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create(mod.AtVersion() + "/go.mod")
+	require.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return repository.Blob(buf.Bytes())
+}
+
+// zipFile is a store.Zip of an in memory zip
+type zipFile struct {
+	*bytes.Reader
+}
+
+func (zipFile) ModTime() time.Time { return time.Time{} }
+func (zipFile) Close() error       { return nil }
+
+func openZip(blob repository.Blob) store.Zip {
+	return zipFile{Reader: bytes.NewReader(blob)}
+}
+
+func hashesOf(t *testing.T, blob repository.Blob) repository.Hashes {
+	hashes, err := repository.HashesOf(blob, modFile)
+	require.NoError(t, err)
+	return hashes
+}
+
+func Test_check_ok(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	tracker := problems.New("test", 1*time.Hour)
+	blob := zipOf(t, modA.Module, modFile)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.index.HashesMock.When(modA.Module).Then(hashesOf(t, blob), nil)
+	mocks.index.ModMock.When(modA.Module).Then(modFile, nil)
+	mocks.store.OpenZipMock.Set(func(coordinates.Module) (store.Zip, error) {
+		return openZip(blob), nil
+	})
+	mocks.emitter.CountMock.Expect("integrity-mod-ok", 1).Return()
+	mocks.emitter.GaugeMock.Expect("integrity-problems", 0).Return()
+
+	c := newChecker(mocks.index, mocks.store, mocks.verifier, tracker, mocks.emitter)
+	err := c.check()
+	require.NoError(t, err)
+	require.Empty(t, tracker.Problems())
+}
+
+func Test_check_backfill(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	tracker := problems.New("test", 1*time.Hour)
+	blob := zipOf(t, modA.Module, modFile)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.index.HashesMock.When(modA.Module).Then(repository.Hashes{}, nil)
+	mocks.index.ModMock.When(modA.Module).Then(modFile, nil)
+	mocks.store.OpenZipMock.Set(func(coordinates.Module) (store.Zip, error) {
+		return openZip(blob), nil
+	})
+	hashes := hashesOf(t, blob)
+	mocks.verifier.VerifyMock.When(modA.Module, hashes.Zip, hashes.Mod).Then(nil)
+	mocks.index.UpdateHashesMock.When(modA.Module, hashes).Then(nil)
+	mocks.emitter.CountMock.Expect("integrity-mod-backfill", 1).Return()
+	mocks.emitter.GaugeMock.Expect("integrity-problems", 0).Return()
+
+	c := newChecker(mocks.index, mocks.store, mocks.verifier, tracker, mocks.emitter)
+	err := c.check()
+	require.NoError(t, err)
+	require.Empty(t, tracker.Problems())
+}
+
+func Test_check_backfill_mismatch(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	tracker := problems.New("test", 1*time.Hour)
+	blob := zipOf(t, modA.Module, modFile)
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.index.HashesMock.When(modA.Module).Then(repository.Hashes{}, nil)
+	mocks.index.ModMock.When(modA.Module).Then(modFile, nil)
+	mocks.store.OpenZipMock.Set(func(coordinates.Module) (store.Zip, error) {
+		return openZip(blob), nil
+	})
+
+	// the checksum database disagrees, so the hashes are not recorded
+	hashes := hashesOf(t, blob)
+	mocks.verifier.VerifyMock.When(modA.Module, hashes.Zip, hashes.Mod).Then(&checksum.Mismatch{
+		Module:   modA.Module,
+		File:     "zip",
+		Expected: "h1:expected=",
+		Actual:   hashes.Zip,
+	})
+	mocks.emitter.CountMock.Expect("integrity-mod-mismatch", 1).Return()
+	mocks.emitter.GaugeMock.Expect("integrity-problems", 1).Return()
+
+	c := newChecker(mocks.index, mocks.store, mocks.verifier, tracker, mocks.emitter)
+	err := c.check()
+	require.NoError(t, err)
+
+	problem, exists := tracker.Problem(modA.Module)
+	require.True(t, exists)
+	require.Contains(t, problem.Message, "h1:expected=")
+}
+
+func Test_check_mismatch(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	tracker := problems.New("test", 1*time.Hour)
+	original := zipOf(t, modA.Module, modFile)
+	corrupted := zipOf(t, modA.Module, "module github.com/pkg/errors2\n")
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.index.HashesMock.When(modA.Module).Then(hashesOf(t, original), nil)
+	mocks.index.ModMock.When(modA.Module).Then(modFile, nil)
+	mocks.emitter.CountMock.Set(func(string, int) {})
+	mocks.emitter.GaugeMock.Set(func(string, int) {})
+
+	stored := corrupted
+	mocks.store.OpenZipMock.Set(func(coordinates.Module) (store.Zip, error) {
+		return openZip(stored), nil
+	})
+
+	c := newChecker(mocks.index, mocks.store, mocks.verifier, tracker, mocks.emitter)
+	err := c.check()
+	require.NoError(t, err)
+
+	problem, exists := tracker.Problem(modA.Module)
+	require.True(t, exists)
+	require.Equal(t, 1, problem.Attempts)
+	require.Contains(t, problem.Message, "stored zip hashes to")
+
+	// still corrupted on the next check
+	err = c.check()
+	require.NoError(t, err)
+
+	problem, exists = tracker.Problem(modA.Module)
+	require.True(t, exists)
+	require.Equal(t, 2, problem.Attempts)
+
+	// and fixed by the one after that
+	stored = original
+	err = c.check()
+	require.NoError(t, err)
+
+	_, exists = tracker.Problem(modA.Module)
+	require.False(t, exists)
+	require.Len(t, tracker.Resolved(), 1)
+	require.Equal(t, problems.Verified, tracker.Resolved()[0].Resolution)
+}
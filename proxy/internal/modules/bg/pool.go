@@ -0,0 +1,143 @@
+package bg
+
+import (
+	"container/heap"
+	"sort"
+	"strings"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+// A pool runs a function over a set of modules concurrently, limiting both
+// the total number of modules being worked on at once, and the number of
+// modules from any one domain being worked on at once.
+type pool struct {
+	parallelism       int
+	domainParallelism int
+}
+
+func newPool(parallelism, domainParallelism int) *pool {
+	if parallelism <= 0 {
+		parallelism = 1
+	}
+
+	return &pool{
+		parallelism:       parallelism,
+		domainParallelism: domainParallelism,
+	}
+}
+
+// run calls f for every module in mods, starting with the lowest serial ID.
+// When a domain is already at its limit, modules of other domains are started
+// ahead of it, so one slow upstream does not hold up everything else. The
+// progress function is called after each module completes, with the number
+// of modules not yet completed.
+//
+// Modules are queued per domain, and only domains with a free slot are ever
+// looked at, so a domain at its limit costs nothing however many of its
+// modules are waiting.
+func (p *pool) run(
+	mods []coordinates.SerialModule,
+	f func(coordinates.SerialModule),
+	progress func(remaining int),
+) {
+	sorted := make([]coordinates.SerialModule, len(mods))
+	copy(sorted, mods)
+	sort.Slice(sorted, func(x, y int) bool {
+		return sorted[x].SerialID < sorted[y].SerialID
+	})
+
+	queues := make(map[string]*domainQueue)
+	for _, mod := range sorted {
+		domain := domainOf(mod.Module)
+		q, exists := queues[domain]
+		if !exists {
+			q = &domainQueue{domain: domain}
+			queues[domain] = q
+		}
+		q.pending = append(q.pending, mod)
+	}
+
+	// the domains with both a module waiting and a free slot
+	ready := make(readyQueues, 0, len(queues))
+	for _, q := range queues {
+		ready = append(ready, q)
+	}
+	heap.Init(&ready)
+
+	remaining := len(sorted)
+	inflight := 0
+	done := make(chan string)
+
+	for remaining > 0 {
+		for inflight < p.parallelism && ready.Len() > 0 {
+			q := heap.Pop(&ready).(*domainQueue)
+			mod := q.pending[0]
+			q.pending = q.pending[1:]
+
+			inflight++
+			q.inflight++
+			go func(mod coordinates.SerialModule, domain string) {
+				f(mod)
+				done <- domain
+			}(mod, q.domain)
+
+			if len(q.pending) > 0 && !p.full(q) {
+				heap.Push(&ready, q)
+			}
+		}
+
+		// wait for something to finish before trying to start more
+		domain := <-done
+		q := queues[domain]
+		blocked := len(q.pending) > 0 && p.full(q)
+		inflight--
+		q.inflight--
+		remaining--
+		if blocked {
+			heap.Push(&ready, q)
+		}
+		progress(remaining)
+	}
+}
+
+// full returns whether q is at the limit of modules of one domain.
+func (p *pool) full(q *domainQueue) bool {
+	return p.domainParallelism > 0 && q.inflight >= p.domainParallelism
+}
+
+// A domainQueue is the modules of one domain waiting to be started, in order
+// of serial ID.
+type domainQueue struct {
+	domain   string
+	pending  []coordinates.SerialModule
+	inflight int
+}
+
+// readyQueues is a heap of domainQueues, ordered by the serial ID of the next
+// module of each.
+type readyQueues []*domainQueue
+
+func (r readyQueues) Len() int { return len(r) }
+
+func (r readyQueues) Less(x, y int) bool {
+	return r[x].pending[0].SerialID < r[y].pending[0].SerialID
+}
+
+func (r readyQueues) Swap(x, y int) { r[x], r[y] = r[y], r[x] }
+
+func (r *readyQueues) Push(q interface{}) { *r = append(*r, q.(*domainQueue)) }
+
+func (r *readyQueues) Pop() interface{} {
+	old := *r
+	q := old[len(old)-1]
+	*r = old[:len(old)-1]
+	return q
+}
+
+func domainOf(mod coordinates.Module) string {
+	if idx := strings.Index(mod.Source, "/"); idx >= 0 {
+		return mod.Source[:idx]
+	}
+	return mod.Source
+}
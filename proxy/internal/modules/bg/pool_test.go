@@ -0,0 +1,124 @@
+package bg
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+func Test_pool_ordered(t *testing.T) {
+	mods := []coordinates.SerialModule{
+		serial(3, "github.com/pkg/errors", "v0.8.2"),
+		serial(1, "github.com/pkg/errors", "v0.8.0"),
+		serial(2, "gopkg.in/yaml.v2", "v2.2.2"),
+	}
+
+	var ids []int64
+	var remaining []int
+	p := newPool(1, 0)
+	p.run(mods, func(mod coordinates.SerialModule) {
+		ids = append(ids, mod.SerialID)
+	}, func(n int) {
+		remaining = append(remaining, n)
+	})
+
+	require.Equal(t, []int64{1, 2, 3}, ids)
+	require.Equal(t, []int{2, 1, 0}, remaining)
+}
+
+// limits records the maximum number of concurrent calls seen,
+// both in total and per domain
+type limits struct {
+	lock      sync.Mutex
+	current   int
+	max       int
+	perDomain map[string]int
+	maxDomain map[string]int
+}
+
+func (l *limits) call(mod coordinates.SerialModule) {
+	domain := domainOf(mod.Module)
+
+	l.lock.Lock()
+	l.current++
+	l.perDomain[domain]++
+	if l.current > l.max {
+		l.max = l.current
+	}
+	if l.perDomain[domain] > l.maxDomain[domain] {
+		l.maxDomain[domain] = l.perDomain[domain]
+	}
+	l.lock.Unlock()
+
+	time.Sleep(5 * time.Millisecond)
+
+	l.lock.Lock()
+	l.current--
+	l.perDomain[domain]--
+	l.lock.Unlock()
+}
+
+func Test_pool_limits(t *testing.T) {
+	var mods []coordinates.SerialModule
+	for i := 0; i < 20; i++ {
+		mods = append(mods, serial(int64(2*i), "github.com/pkg/errors", "v0.8.0"))
+		mods = append(mods, serial(int64(2*i+1), "gopkg.in/yaml.v2", "v2.2.2"))
+	}
+
+	l := &limits{
+		perDomain: make(map[string]int),
+		maxDomain: make(map[string]int),
+	}
+
+	p := newPool(3, 2)
+	p.run(mods, l.call, func(int) {})
+
+	require.Equal(t, 0, l.current)
+	require.True(t, l.max <= 3, "max was %d", l.max)
+	require.True(t, l.maxDomain["github.com"] <= 2)
+	require.True(t, l.maxDomain["gopkg.in"] <= 2)
+}
+
+func Test_pool_blocked_domain(t *testing.T) {
+	mods := []coordinates.SerialModule{
+		serial(1, "github.com/pkg/errors", "v0.8.0"),
+		serial(2, "github.com/pkg/errors", "v0.8.1"),
+		serial(3, "gopkg.in/yaml.v2", "v2.2.2"),
+	}
+
+	var lock sync.Mutex
+	var started []int64
+	var remaining []int
+
+	// github.com is at its limit while 1 is running, so 3 goes ahead of 2
+	p := newPool(2, 1)
+	p.run(mods, func(mod coordinates.SerialModule) {
+		lock.Lock()
+		started = append(started, mod.SerialID)
+		lock.Unlock()
+		if mod.SerialID == 1 {
+			time.Sleep(20 * time.Millisecond)
+		}
+	}, func(n int) {
+		remaining = append(remaining, n)
+	})
+
+	require.Len(t, started, 3)
+	require.Equal(t, int64(2), started[2])
+	require.Equal(t, []int{2, 1, 0}, remaining)
+}
+
+func Test_domainOf(t *testing.T) {
+	try := func(source, exp string) {
+		result := domainOf(coordinates.Module{Source: source})
+		require.Equal(t, exp, result)
+	}
+
+	try("github.com/pkg/errors", "github.com")
+	try("gopkg.in/yaml.v2", "gopkg.in")
+	try("example.com", "example.com")
+}
@@ -0,0 +1,132 @@
+package bg
+
+import (
+	"time"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+type PruneOptions struct {
+	// Enabled determines whether the worker will remove modules from
+	// the index and zip store that are no longer listed by the registry.
+	Enabled bool
+
+	// DryRun causes the worker to only log the modules that would be
+	// pruned, without actually removing anything.
+	DryRun bool
+
+	// GracePeriod is how long a module must be continuously missing
+	// from the registry before it is pruned. This protects the cache
+	// from a registry that is temporarily returning an incomplete list.
+	GracePeriod time.Duration
+}
+
+// A pruner removes modules which are no longer listed by the registry.
+type pruner struct {
+	options           PruneOptions
+	index             store.Index
+	ingester          store.Ingester
+	registryRequester get.RegistryAPI
+	emitter           stats.Sender
+	log               loggy.Logger
+
+	// missing tracks when each module was first noticed to be missing from
+	// the registry; this is only kept in memory, so restarting the proxy
+	// restarts the grace period of every module
+	missing map[coordinates.Module]time.Time
+	now     func() time.Time
+}
+
+func newPruner(
+	options PruneOptions,
+	index store.Index,
+	ingester store.Ingester,
+	registryRequester get.RegistryAPI,
+	emitter stats.Sender,
+) *pruner {
+	return &pruner{
+		options:           options,
+		index:             index,
+		ingester:          ingester,
+		registryRequester: registryRequester,
+		emitter:           emitter,
+		log:               loggy.New("bg-pruner"),
+		missing:           make(map[coordinates.Module]time.Time),
+		now:               time.Now,
+	}
+}
+
+func (p *pruner) prune() error {
+	registered, err := p.registryRequester.ModulesRegistered()
+	if err != nil {
+		// never prune anything if we cannot get an answer from the registry
+		p.log.Errorf("failed to acquire list of registered mods from registry, %v", err)
+		return err
+	}
+
+	stored, err := p.index.List()
+	if err != nil {
+		p.log.Errorf("failed to list mods in index, %v", err)
+		return err
+	}
+
+	keep := make(map[coordinates.Module]bool, len(registered))
+	for _, mod := range registered {
+		keep[mod.Module] = true
+	}
+
+	now := p.now()
+	stillMissing := make(map[coordinates.Module]bool)
+	for _, mod := range stored {
+		if keep[mod.Module] {
+			continue
+		}
+		stillMissing[mod.Module] = true
+
+		since, tracked := p.missing[mod.Module]
+		if !tracked {
+			p.log.Infof("%s is no longer registered, will prune after %s", mod.Module, p.options.GracePeriod)
+			p.missing[mod.Module] = now
+			since = now
+		}
+
+		if now.Sub(since) < p.options.GracePeriod {
+			continue // still within the grace period
+		}
+
+		if p.options.DryRun {
+			p.log.Infof("dry-run: would prune %s", mod.Module)
+			p.emitter.Count("prune-mod-dry-run", 1)
+			continue
+		}
+
+		if err := p.remove(mod.Module); err != nil {
+			p.log.Errorf("failed to prune %s, %v", mod.Module, err)
+			p.emitter.Count("prune-mod-failure", 1)
+			continue // may as well try the others
+		}
+
+		delete(p.missing, mod.Module)
+		p.emitter.Count("prune-mod-ok", 1)
+	}
+
+	// forget about modules that have since been registered again
+	for mod := range p.missing {
+		if !stillMissing[mod] {
+			delete(p.missing, mod)
+		}
+	}
+
+	return nil
+}
+
+func (p *pruner) remove(mod coordinates.Module) error {
+	p.log.Infof("pruning %s", mod)
+
+	return p.ingester.Remove(mod)
+}
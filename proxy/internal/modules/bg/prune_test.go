@@ -0,0 +1,175 @@
+package bg
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+type mocks struct {
+	index             *store.IndexMock
+	store             *store.ZipStoreMock
+	accesses          *store.AccessLogMock
+	counter           *store.AccessCounterMock
+	ingester          *store.IngesterMock
+	verifier          *checksum.VerifierMock
+	registryRequester *get.RegistryAPIMock
+	downloader        *get.DownloaderMock
+	emitter           *stats.SenderMock
+}
+
+func (m mocks) assertions() {
+	m.index.MinimockFinish()
+	m.store.MinimockFinish()
+	m.accesses.MinimockFinish()
+	m.counter.MinimockFinish()
+	m.ingester.MinimockFinish()
+	m.verifier.MinimockFinish()
+	m.registryRequester.MinimockFinish()
+	m.downloader.MinimockFinish()
+	m.emitter.MinimockFinish()
+}
+
+func newMocks(t *testing.T) mocks {
+	return mocks{
+		index:             store.NewIndexMock(t),
+		store:             store.NewZipStoreMock(t),
+		accesses:          store.NewAccessLogMock(t),
+		counter:           store.NewAccessCounterMock(t),
+		ingester:          store.NewIngesterMock(t),
+		verifier:          checksum.NewVerifierMock(t),
+		registryRequester: get.NewRegistryAPIMock(t),
+		downloader:        get.NewDownloaderMock(t),
+		emitter:           stats.NewSenderMock(t),
+	}
+}
+
+func serial(id int64, source, version string) coordinates.SerialModule {
+	return coordinates.SerialModule{
+		SerialID: id,
+		Module: coordinates.Module{
+			Source:  source,
+			Version: version,
+		},
+	}
+}
+
+var (
+	modA = serial(1, "github.com/pkg/errors", "v0.8.0")
+	modB = serial(2, "github.com/pkg/errors", "v0.8.1")
+)
+
+func Test_prune_grace_period(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.registryRequester.ModulesRegisteredMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA, modB}, nil)
+	mocks.ingester.RemoveMock.When(modB.Module).Then(nil)
+	mocks.emitter.CountMock.Expect("prune-mod-ok", 1).Return()
+
+	p := newPruner(PruneOptions{
+		Enabled:     true,
+		GracePeriod: 1 * time.Hour,
+	}, mocks.index, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// first noticed missing, nothing is removed yet
+	p.now = func() time.Time { return start }
+	err := p.prune()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), mocks.ingester.RemoveAfterCounter())
+
+	// still within the grace period, nothing is removed yet
+	p.now = func() time.Time { return start.Add(59 * time.Minute) }
+	err = p.prune()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), mocks.ingester.RemoveAfterCounter())
+
+	// the grace period has elapsed, so the module is removed
+	p.now = func() time.Time { return start.Add(1 * time.Hour) }
+	err = p.prune()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), mocks.ingester.RemoveAfterCounter())
+	require.Empty(t, p.missing)
+}
+
+func Test_prune_re_registered(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA, modB}, nil)
+
+	p := newPruner(PruneOptions{
+		Enabled:     true,
+		GracePeriod: 1 * time.Hour,
+	}, mocks.index, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	// modB goes missing
+	mocks.registryRequester.ModulesRegisteredMock.Return([]coordinates.SerialModule{modA}, nil)
+	p.now = func() time.Time { return start }
+	err := p.prune()
+	require.NoError(t, err)
+	require.Len(t, p.missing, 1)
+
+	// modB comes back before the grace period elapses
+	mocks.registryRequester.ModulesRegisteredMock.Return([]coordinates.SerialModule{modA, modB}, nil)
+	p.now = func() time.Time { return start.Add(2 * time.Hour) }
+	err = p.prune()
+	require.NoError(t, err)
+	require.Empty(t, p.missing)
+}
+
+func Test_prune_dry_run(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.registryRequester.ModulesRegisteredMock.Return(nil, nil)
+	mocks.index.ListMock.Return([]coordinates.SerialModule{modA}, nil)
+	mocks.emitter.CountMock.Expect("prune-mod-dry-run", 1).Return()
+
+	p := newPruner(PruneOptions{
+		Enabled:     true,
+		DryRun:      true,
+		GracePeriod: 1 * time.Minute,
+	}, mocks.index, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	p.now = func() time.Time { return start }
+	err := p.prune()
+	require.NoError(t, err)
+
+	// past the grace period, but nothing is removed in dry-run mode
+	p.now = func() time.Time { return start.Add(1 * time.Hour) }
+	err = p.prune()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), mocks.emitter.CountAfterCounter())
+}
+
+func Test_prune_registry_failure(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.registryRequester.ModulesRegisteredMock.Return(nil, errors.New("registry down"))
+
+	p := newPruner(PruneOptions{
+		Enabled:     true,
+		GracePeriod: 1 * time.Minute,
+	}, mocks.index, mocks.ingester, mocks.registryRequester, mocks.emitter)
+
+	err := p.prune()
+	require.Error(t, err)
+	require.Empty(t, p.missing)
+}
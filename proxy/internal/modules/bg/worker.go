@@ -1,11 +1,15 @@
 package bg
 
 import (
+	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+
 	"gophers.dev/pkgs/loggy"
 	"gophers.dev/pkgs/repeat/x"
 
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
 	"oss.indeed.com/go/modprox/pkg/clients/registry"
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
@@ -21,6 +25,33 @@ type Options struct {
 	// would be something like 30 seconds - not too slow, but also
 	// not spamming the network with polling traffic.
 	Frequency time.Duration
+
+	// Parallelism determines how many modules may be downloaded at
+	// the same time. A value of 0 or 1 downloads modules one at a time.
+	Parallelism int
+
+	// DomainParallelism determines how many modules from any one domain
+	// (e.g. github.com) may be downloaded at the same time, so that
+	// a single upstream is not overwhelmed. A value of 0 means the only
+	// limit is Parallelism.
+	DomainParallelism int
+
+	// Backoff determines how long the worker waits before trying to
+	// download a module again after a failed attempt.
+	Backoff problems.Backoff
+
+	// Prune configures the removal of modules which are no longer
+	// listed by the registry.
+	Prune PruneOptions
+
+	// Integrity configures the periodic re-hashing of stored modules.
+	Integrity IntegrityOptions
+
+	// Evict configures the removal of modules when the store is over
+	// its quota. With Evict.CacheOnly set, modules are only ever stored
+	// when fetched on-demand, so evicted modules are not downloaded again
+	// until they are requested again.
+	Evict EvictOptions
 }
 
 // A Worker runs in the background, polling the registry for new
@@ -28,39 +59,137 @@ type Options struct {
 // as needed.
 type Worker interface {
 	Start(options Options)
+
+	// Retry queues an immediate attempt at downloading a module which
+	// previously failed to download, ignoring any backoff. The outcome
+	// is recorded as a download problem, or its resolution.
+	Retry(mod coordinates.Module) error
 }
 
+var (
+	// ErrNoProblem is the cause of the error returned by Retry when the
+	// module has not failed to download.
+	ErrNoProblem = errors.New("no download problem")
+
+	// ErrAlreadyIndexed is the cause of the error returned by Retry when
+	// the module has been downloaded since it failed to download.
+	ErrAlreadyIndexed = errors.New("already downloaded")
+)
+
 type worker struct {
 	registryClient    registry.Client
 	emitter           stats.Sender
 	dlTracker         problems.Tracker
+	integrity         problems.Tracker
 	index             store.Index
 	store             store.ZipStore
+	accesses          store.AccessLog
+	counter           store.AccessCounter
+	ingester          store.Ingester
+	verifier          checksum.Verifier
 	downloader        get.Downloader
 	registryRequester get.RegistryAPI
+	pool              *pool
+	pruner            *pruner
+	checker           *checker
+	evictor           *evictor
+	cacheOnly         bool
+	backoff           problems.Backoff
+	now               func() time.Time
 	log               loggy.Logger
+
+	// retries are the downloads queued by Retry which are in progress
+	retries sync.WaitGroup
 }
 
 func New(
 	emitter stats.Sender,
 	dlTracker problems.Tracker,
+	integrity problems.Tracker,
 	index store.Index,
 	store store.ZipStore,
+	accesses store.AccessLog,
+	counter store.AccessCounter,
+	ingester store.Ingester,
+	verifier checksum.Verifier,
 	registryRequester get.RegistryAPI,
 	downloader get.Downloader,
 ) Worker {
 	return &worker{
 		emitter:           emitter,
 		dlTracker:         dlTracker,
+		integrity:         integrity,
 		index:             index,
 		store:             store,
+		accesses:          accesses,
+		counter:           counter,
+		ingester:          ingester,
+		verifier:          verifier,
 		downloader:        downloader,
 		registryRequester: registryRequester,
+		now:               time.Now,
 		log:               loggy.New("bg-worker"),
 	}
 }
 
 func (w *worker) Start(options Options) {
+	w.pool = newPool(options.Parallelism, options.DomainParallelism)
+	w.backoff = options.Backoff
+
+	if options.Prune.Enabled {
+		w.pruner = newPruner(
+			options.Prune,
+			w.index,
+			w.ingester,
+			w.registryRequester,
+			w.emitter,
+		)
+	}
+
+	if options.Integrity.Enabled {
+		w.checker = newChecker(
+			w.index,
+			w.store,
+			w.verifier,
+			w.integrity,
+			w.emitter,
+		)
+
+		// checking every stored module takes a while, so it runs on
+		// its own schedule rather than as part of the worker loop
+		go func() {
+			_ = x.Interval(options.Integrity.Frequency, func() error {
+				if err := w.checker.check(); err != nil {
+					w.log.Errorf("integrity check had error: %v", err)
+				}
+				return nil
+			})
+		}()
+	}
+
+	if options.Evict.Enabled {
+		w.evictor = newEvictor(
+			options.Evict,
+			w.index,
+			w.store,
+			w.accesses,
+			w.counter,
+			w.ingester,
+			w.registryRequester,
+			w.emitter,
+		)
+		w.cacheOnly = options.Evict.CacheOnly
+
+		go func() {
+			_ = x.Interval(options.Evict.Frequency, func() error {
+				if err := w.evictor.evict(); err != nil {
+					w.log.Errorf("eviction had error: %v", err)
+				}
+				return nil
+			})
+		}()
+	}
+
 	go func() {
 		_ = x.Interval(options.Frequency, func() error {
 			if err := w.loop(); err != nil {
@@ -76,21 +205,20 @@ func (w *worker) Start(options Options) {
 func (w *worker) loop() error {
 	w.log.Infof("worker loop starting")
 
-	mods, err := w.acquireMods()
-	if err != nil {
-		return err
+	// a cache-only proxy stores modules as they are fetched on-demand,
+	// rather than downloading everything the registry lists
+	if !w.cacheOnly {
+		if _, err := w.acquireMods(); err != nil {
+			return err
+		}
 	}
 
-	_ = mods
+	if w.pruner != nil {
+		if err := w.pruner.prune(); err != nil {
+			return err
+		}
+	}
 
-	// we have a list of modules already downloaded to fs
-	// we have a list of modules from registry that we want
-	// do a diff, finding:
-	// - modules we have but do not need anymore
-	// - modules we need but to not have yet
-	// then prune modules we do not want
-	// then DL and save modules we do want
-	// also, take into account redirects and such
 	return nil
 }
 
@@ -111,38 +239,102 @@ func (w *worker) acquireMods() ([]coordinates.SerialModule, error) {
 		w.log.Tracef("- %s @ %s", mod.Source, mod.Version)
 	}
 
-	for _, mod := range mods {
-		// only download mod if we do not already have it
-		exists, indexID, err := w.index.Contains(mod.Module)
-		if err != nil {
-			w.log.Errorf("problem with index lookups: %v", err)
-			continue // may as well try the others
-		}
+	w.pool.run(mods, w.acquireMod, func(remaining int) {
+		w.emitter.Gauge("download-mods-remaining", remaining)
+	})
 
-		if exists {
-			w.log.Tracef("already have %s, not going to download it again", mod)
-			// set indexID to newID if they do not match
-			if indexID != mod.SerialID {
-				w.log.Infof(
-					"indexed ID of %d for %s does not match ID %d, will update",
-					indexID,
-					mod,
-					mod.SerialID,
-				)
-				if err = w.index.UpdateID(mod); err != nil {
-					w.log.Errorf("problem updating index ID: %v", err)
-				}
+	return mods, nil
+}
+
+func (w *worker) acquireMod(mod coordinates.SerialModule) {
+	// only download mod if we do not already have it
+	exists, indexID, err := w.index.Contains(mod.Module)
+	if err != nil {
+		w.log.Errorf("problem with index lookups: %v", err)
+		return // may as well try the others
+	}
+
+	if exists {
+		w.log.Tracef("already have %s, not going to download it again", mod)
+		// set indexID to newID if they do not match
+		if indexID != mod.SerialID {
+			w.log.Infof(
+				"indexed ID of %d for %s does not match ID %d, will update",
+				indexID,
+				mod,
+				mod.SerialID,
+			)
+			if err = w.index.UpdateID(mod); err != nil {
+				w.log.Errorf("problem updating index ID: %v", err)
 			}
-			continue // move on to the next one
 		}
+		return // move on to the next one
+	}
 
-		if err := w.downloader.Download(mod); err != nil {
-			w.log.Errorf("failed to download %s, %v", mod, err)
-			w.dlTracker.Set(problems.Create(mod.Module, err))
-			continue // may as well try the others
+	// do not try again until the backoff of a previous failure has elapsed
+	if problem, exists := w.dlTracker.Problem(mod.Module); exists {
+		if w.now().Before(problem.NextAttempt) {
+			w.log.Tracef("backing off %s until %s", mod, problem.NextAttempt)
+			return
 		}
-		w.log.Tracef("downloaded %s!", mod)
 	}
 
-	return mods, nil
+	_ = w.download(mod) // may as well try the others
+}
+
+func (w *worker) download(mod coordinates.SerialModule) error {
+	if err := w.downloader.Download(mod); err != nil {
+		w.log.Errorf("failed to download %s, %v", mod, err)
+		w.failed(mod.Module, err)
+		w.emitter.Count("download-mod-failure", 1)
+		return err
+	}
+	w.log.Tracef("downloaded %s!", mod)
+	w.emitter.Count("download-mod-ok", 1)
+	return nil
+}
+
+// failed records another failed attempt at downloading mod, along with
+// the time at which the next attempt should be made.
+func (w *worker) failed(mod coordinates.Module, err error) {
+	previous, exists := w.dlTracker.Problem(mod)
+	if !exists {
+		previous = problems.Problem{Module: mod}
+	}
+
+	problem := w.backoff.Again(previous, err, w.now())
+	w.log.Infof(
+		"download of %s has failed %d times, next attempt after %s",
+		mod,
+		problem.Attempts,
+		problem.NextAttempt,
+	)
+	w.dlTracker.Set(problem)
+}
+
+func (w *worker) Retry(mod coordinates.Module) error {
+	if _, exists := w.dlTracker.Problem(mod); !exists {
+		return errors.Wrap(ErrNoProblem, mod.String())
+	}
+
+	exists, _, err := w.index.Contains(mod)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return errors.Wrap(ErrAlreadyIndexed, mod.String())
+	}
+
+	w.log.Infof("forcing retry of download for %s", mod)
+
+	// the download takes as long as the upstream does, so rather than
+	// making the caller wait, failures are recorded as problems as usual
+	w.retries.Add(1)
+	go func() {
+		defer w.retries.Done()
+		// the registry ID of the module is not known here, the next iteration
+		// of the worker loop will update it to the ID from the registry
+		_ = w.download(coordinates.SerialModule{Module: mod})
+	}()
+	return nil
 }
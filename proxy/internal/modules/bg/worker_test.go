@@ -0,0 +1,122 @@
+package bg
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+)
+
+func newWorker(mocks mocks, tracker problems.Tracker) *worker {
+	w := New(
+		mocks.emitter,
+		tracker,
+		problems.New("integrity", 1*time.Hour),
+		mocks.index,
+		mocks.store,
+		mocks.accesses,
+		mocks.counter,
+		mocks.ingester,
+		mocks.verifier,
+		mocks.registryRequester,
+		mocks.downloader,
+	).(*worker)
+	w.backoff = problems.Backoff{
+		Initial: 1 * time.Minute,
+		Max:     1 * time.Hour,
+	}
+	return w
+}
+
+func Test_acquireMod_backoff(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	tracker := problems.New("test", 1*time.Hour)
+	w := newWorker(mocks, tracker)
+
+	start := time.Now()
+	w.now = func() time.Time { return start }
+
+	mocks.index.ContainsMock.When(modA.Module).Then(false, 0, nil)
+	mocks.downloader.DownloadMock.When(modA).Then(errors.New("upstream is down"))
+	mocks.emitter.CountMock.Expect("download-mod-failure", 1).Return()
+
+	// the first attempt fails
+	w.acquireMod(modA)
+	problem, exists := tracker.Problem(modA.Module)
+	require.True(t, exists)
+	require.Equal(t, 1, problem.Attempts)
+	require.Equal(t, "upstream is down", problem.Message)
+	require.Equal(t, start, problem.Time)
+	require.Equal(t, start.Add(1*time.Minute), problem.NextAttempt)
+	require.Equal(t, uint64(1), mocks.downloader.DownloadAfterCounter())
+
+	// within the backoff period, no attempt is made
+	w.acquireMod(modA)
+	require.Equal(t, uint64(1), mocks.downloader.DownloadAfterCounter())
+
+	// after the backoff period, another attempt is made
+	w.now = func() time.Time { return problem.NextAttempt }
+	w.acquireMod(modA)
+	require.Equal(t, uint64(2), mocks.downloader.DownloadAfterCounter())
+
+	problem, exists = tracker.Problem(modA.Module)
+	require.True(t, exists)
+	require.Equal(t, 2, problem.Attempts)
+	require.Equal(t, problem.Time.Add(2*time.Minute), problem.NextAttempt)
+}
+
+func Test_Retry(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	tracker := problems.New("test", 1*time.Hour)
+	w := newWorker(mocks, tracker)
+
+	// no problem for the module, nothing to retry
+	err := w.Retry(modA.Module)
+	require.Equal(t, ErrNoProblem, errors.Cause(err))
+
+	tracker.Set(problems.Problem{
+		Module:      modA.Module,
+		Time:        time.Now(),
+		Message:     "upstream is down",
+		Attempts:    3,
+		NextAttempt: time.Now().Add(1 * time.Hour),
+	})
+
+	mocks.index.ContainsMock.When(modA.Module).Then(false, 0, nil)
+	mocks.downloader.DownloadMock.Return(nil)
+	mocks.emitter.CountMock.Expect("download-mod-ok", 1).Return()
+
+	// retry ignores the backoff
+	err = w.Retry(modA.Module)
+	require.NoError(t, err)
+	w.retries.Wait()
+	require.Equal(t, uint64(1), mocks.downloader.DownloadAfterCounter())
+}
+
+func Test_Retry_indexed(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	tracker := problems.New("test", 1*time.Hour)
+	w := newWorker(mocks, tracker)
+
+	tracker.Set(problems.Problem{
+		Module:   modA.Module,
+		Time:     time.Now(),
+		Message:  "upstream is down",
+		Attempts: 1,
+	})
+
+	// downloaded by the worker loop since the problem
+	mocks.index.ContainsMock.When(modA.Module).Then(true, 1, nil)
+
+	err := w.Retry(modA.Module)
+	require.Equal(t, ErrAlreadyIndexed, errors.Cause(err))
+}
@@ -0,0 +1,158 @@
+package fetch
+
+import (
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+)
+
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i Fetcher -s _mock.go
+
+// A Fetcher is used to acquire a module on-demand, when a request is made
+// to the proxy for a module that has not yet been downloaded. Modules that
+// are fetched are also registered with the registry, so that other instances
+// of the proxy will eventually download them as well.
+type Fetcher interface {
+	Fetch(mod coordinates.Module) error
+}
+
+// New creates a Fetcher which downloads missing modules synchronously,
+// and then registers those modules with the registry.
+func New(
+	downloader get.Downloader,
+	registryAPI get.RegistryAPI,
+	index store.Index,
+	dlTracker problems.Tracker,
+	emitter stats.Sender,
+) Fetcher {
+	return &fetcher{
+		downloader:  downloader,
+		registryAPI: registryAPI,
+		index:       index,
+		dlTracker:   dlTracker,
+		emitter:     emitter,
+		inflight:    make(map[coordinates.Module]*call),
+		log:         loggy.New("fetcher"),
+	}
+}
+
+// Disabled creates a Fetcher which never fetches anything, for use when
+// the proxy is not configured to fetch modules on-demand.
+func Disabled() Fetcher {
+	return disabled{}
+}
+
+type disabled struct{}
+
+func (disabled) Fetch(mod coordinates.Module) error {
+	return errors.Errorf("on-demand fetch is disabled, %s not fetched", mod)
+}
+
+type fetcher struct {
+	downloader  get.Downloader
+	registryAPI get.RegistryAPI
+	index       store.Index
+	dlTracker   problems.Tracker
+	emitter     stats.Sender
+	log         loggy.Logger
+
+	lock     sync.Mutex
+	inflight map[coordinates.Module]*call
+}
+
+// call tracks one in-progress fetch, so that concurrent requests
+// for the same module wait on the same result.
+type call struct {
+	wg  sync.WaitGroup
+	err error
+}
+
+func (f *fetcher) Fetch(mod coordinates.Module) error {
+	// a branch, tag or commit hash would be stored (and registered) as if
+	// it were a version, which it is not, and whatever it refers to changes
+	if repository.IsQuery(mod.Version) {
+		f.emitter.Count("fetch-query-rejected", 1)
+		return errors.Errorf("%s is not a canonical version, not fetched", mod)
+	}
+
+	f.lock.Lock()
+	if inProgress, exists := f.inflight[mod]; exists {
+		f.lock.Unlock()
+		f.log.Tracef("waiting on in-progress fetch of %s", mod)
+		inProgress.wg.Wait()
+		return inProgress.err
+	}
+
+	current := new(call)
+	current.wg.Add(1)
+	f.inflight[mod] = current
+	f.lock.Unlock()
+
+	current.err = f.fetch(mod)
+
+	f.lock.Lock()
+	delete(f.inflight, mod)
+	f.lock.Unlock()
+	current.wg.Done()
+
+	return current.err
+}
+
+func (f *fetcher) fetch(mod coordinates.Module) error {
+	// the module may have been stored since the caller last looked
+	exists, _, err := f.index.Contains(mod)
+	if err != nil {
+		return errors.Wrapf(err, "failed to lookup %s in index", mod)
+	}
+	if exists {
+		return nil
+	}
+
+	f.log.Infof("fetching %s on-demand", mod)
+
+	// the module does not have a serial ID yet, the bg worker will
+	// update the ID once the registry has given the module one
+	if err := f.downloader.Download(coordinates.SerialModule{
+		Module: mod,
+	}); err != nil {
+		f.log.Warnf("failed to fetch %s on-demand, %v", mod, err)
+		f.emitter.Count("fetch-download-failure", 1)
+		if checksum.IsMismatch(err) {
+			// unlike a version that does not exist, a download that does not
+			// match the checksum database is something an operator should see
+			f.mismatched(mod, err)
+		}
+		return errors.Wrapf(err, "failed to fetch %s", mod)
+	}
+	f.emitter.Count("fetch-download-ok", 1)
+
+	// failure to register is not fatal, the module is already available
+	// from this proxy; the registry just will not know about it yet
+	if err := f.registryAPI.Register([]coordinates.Module{mod}); err != nil {
+		f.log.Warnf("failed to register fetched module %s, %v", mod, err)
+		f.emitter.Count("fetch-register-failure", 1)
+		return nil
+	}
+	f.emitter.Count("fetch-register-ok", 1)
+
+	return nil
+}
+
+func (f *fetcher) mismatched(mod coordinates.Module, err error) {
+	previous, exists := f.dlTracker.Problem(mod)
+	if !exists {
+		previous = problems.Problem{Module: mod}
+	}
+	f.dlTracker.Set(problems.Backoff{}.Again(previous, err, time.Now()))
+}
@@ -0,0 +1,165 @@
+package fetch
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+)
+
+type mocks struct {
+	downloader  *get.DownloaderMock
+	registryAPI *get.RegistryAPIMock
+	index       *store.IndexMock
+	dlTracker   *problems.TrackerMock
+	emitter     *stats.SenderMock
+}
+
+func (m mocks) assertions() {
+	m.downloader.MinimockFinish()
+	m.registryAPI.MinimockFinish()
+	m.index.MinimockFinish()
+	m.dlTracker.MinimockFinish()
+	m.emitter.MinimockFinish()
+}
+
+func newMocks(t *testing.T) mocks {
+	return mocks{
+		downloader:  get.NewDownloaderMock(t),
+		registryAPI: get.NewRegistryAPIMock(t),
+		index:       store.NewIndexMock(t),
+		dlTracker:   problems.NewTrackerMock(t),
+		emitter:     stats.NewSenderMock(t),
+	}
+}
+
+var fetchedModule = coordinates.Module{
+	Source:  "github.com/pkg/errors",
+	Version: "v1.2.3",
+}
+
+func Test_Fetch_ok(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.index.ContainsMock.When(fetchedModule).Then(false, 0, nil)
+	mocks.downloader.DownloadMock.When(coordinates.SerialModule{
+		Module: fetchedModule,
+	}).Then(nil)
+	mocks.registryAPI.RegisterMock.When([]coordinates.Module{fetchedModule}).Then(nil)
+
+	var metrics []string
+	mocks.emitter.CountMock.Set(func(metric string, n int) {
+		metrics = append(metrics, metric)
+	})
+
+	f := New(mocks.downloader, mocks.registryAPI, mocks.index, mocks.dlTracker, mocks.emitter)
+	err := f.Fetch(fetchedModule)
+	require.NoError(t, err)
+	require.Equal(t, []string{"fetch-download-ok", "fetch-register-ok"}, metrics)
+}
+
+func Test_Fetch_query(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.emitter.CountMock.Expect("fetch-query-rejected", 1).Return()
+
+	// neither downloaded nor registered
+	f := New(mocks.downloader, mocks.registryAPI, mocks.index, mocks.dlTracker, mocks.emitter)
+	err := f.Fetch(coordinates.Module{Source: "github.com/pkg/errors", Version: "master"})
+	require.Error(t, err)
+}
+
+func Test_Fetch_already_exists(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.index.ContainsMock.When(fetchedModule).Then(true, 3, nil)
+
+	f := New(mocks.downloader, mocks.registryAPI, mocks.index, mocks.dlTracker, mocks.emitter)
+	err := f.Fetch(fetchedModule)
+	require.NoError(t, err)
+}
+
+func Test_Fetch_download_fails(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.index.ContainsMock.When(fetchedModule).Then(false, 0, nil)
+	mocks.downloader.DownloadMock.When(coordinates.SerialModule{
+		Module: fetchedModule,
+	}).Then(errors.New("no such version"))
+
+	var metrics []string
+	mocks.emitter.CountMock.Set(func(metric string, n int) {
+		metrics = append(metrics, metric)
+	})
+
+	f := New(mocks.downloader, mocks.registryAPI, mocks.index, mocks.dlTracker, mocks.emitter)
+	err := f.Fetch(fetchedModule)
+	require.Error(t, err)
+	require.Equal(t, []string{"fetch-download-failure"}, metrics)
+}
+
+func Test_Fetch_checksum_mismatch(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.index.ContainsMock.When(fetchedModule).Then(false, 0, nil)
+	mocks.downloader.DownloadMock.When(coordinates.SerialModule{
+		Module: fetchedModule,
+	}).Then(&checksum.Mismatch{
+		Module:   fetchedModule,
+		File:     "zip",
+		Expected: "h1:expected=",
+		Actual:   "h1:actual=",
+	})
+	mocks.dlTracker.ProblemMock.When(fetchedModule).Then(problems.Problem{}, false)
+	mocks.dlTracker.SetMock.Set(func(problem problems.Problem) {
+		require.Equal(t, fetchedModule, problem.Module)
+		require.Equal(t, 1, problem.Attempts)
+		require.Contains(t, problem.Message, "checksum mismatch")
+	})
+	mocks.emitter.CountMock.Expect("fetch-download-failure", 1).Return()
+
+	f := New(mocks.downloader, mocks.registryAPI, mocks.index, mocks.dlTracker, mocks.emitter)
+	err := f.Fetch(fetchedModule)
+	require.Error(t, err)
+	require.Equal(t, uint64(1), mocks.dlTracker.SetAfterCounter())
+}
+
+func Test_Fetch_register_fails(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	mocks.index.ContainsMock.When(fetchedModule).Then(false, 0, nil)
+	mocks.downloader.DownloadMock.When(coordinates.SerialModule{
+		Module: fetchedModule,
+	}).Then(nil)
+	mocks.registryAPI.RegisterMock.When([]coordinates.Module{fetchedModule}).Then(errors.New("registry down"))
+
+	var metrics []string
+	mocks.emitter.CountMock.Set(func(metric string, n int) {
+		metrics = append(metrics, metric)
+	})
+
+	// the module was still downloaded, so the fetch is a success
+	f := New(mocks.downloader, mocks.registryAPI, mocks.index, mocks.dlTracker, mocks.emitter)
+	err := f.Fetch(fetchedModule)
+	require.NoError(t, err)
+	require.Equal(t, []string{"fetch-download-ok", "fetch-register-failure"}, metrics)
+}
+
+func Test_Fetch_disabled(t *testing.T) {
+	f := Disabled()
+	err := f.Fetch(fetchedModule)
+	require.Error(t, err)
+}
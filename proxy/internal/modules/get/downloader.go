@@ -1,126 +1,272 @@
 package get
 
 import (
-	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
 	"gophers.dev/pkgs/loggy"
 
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
 	"oss.indeed.com/go/modprox/pkg/clients/zips"
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
 	"oss.indeed.com/go/modprox/pkg/repository"
 	"oss.indeed.com/go/modprox/pkg/upstream"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
 )
 
 //go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i Downloader -s _mock.go
 
 type Downloader interface {
 	Download(module coordinates.SerialModule) error
+
+	// Resolve returns module with its version resolved into a canonical
+	// version, if it is a branch, tag or abbreviated commit hash, from
+	// wherever the module would be downloaded from.
+	Resolve(module coordinates.Module) (coordinates.Module, error)
 }
 
 func New(
 	proxyClient zips.ProxyClient,
 	upstreamClient zips.UpstreamClient,
+	commitClient zips.CommitClient,
+	revisions upstream.Revisioner,
 	resolver upstream.Resolver,
-	store store.ZipStore,
-	index store.Index,
+	ingester store.Ingester,
+	verifier checksum.Verifier,
+	dlTracker problems.Tracker,
+	tmpDir string,
 	emitter stats.Sender,
 ) Downloader {
 	return &downloader{
 		proxyClient:    proxyClient,
 		upstreamClient: upstreamClient,
+		commitClient:   commitClient,
+		revisions:      revisions,
 		resolver:       resolver,
-		store:          store,
-		index:          index,
+		ingester:       ingester,
+		verifier:       verifier,
+		dlTracker:      dlTracker,
+		tmpDir:         tmpDir,
 		emitter:        emitter,
+		modProxies:     newModProxyClient,
+		proxies:        make(map[string]zips.ProxyClient),
 		log:            loggy.New("downloader"),
 	}
 }
 
+// newModProxyClient creates a ProxyClient for the module proxy a go-import
+// meta tag with the mod vcs refers to, which sends headers along with every
+// request, e.g. those of the domain_headers of the module proxy.
+func newModProxyClient(protocol, baseURL string, headers map[string]string) zips.ProxyClient {
+	return zips.NewProxyClient(zips.ProxyClientOptions{
+		Protocol: protocol,
+		BaseURL:  baseURL,
+		Timeout:  1 * time.Minute,
+		Headers:  headers,
+	})
+}
+
 type downloader struct {
 	proxyClient    zips.ProxyClient
 	upstreamClient zips.UpstreamClient
+	commitClient   zips.CommitClient
+	revisions      upstream.Revisioner
 	resolver       upstream.Resolver
-	store          store.ZipStore
-	index          store.Index
+	ingester       store.Ingester
+	verifier       checksum.Verifier
+	dlTracker      problems.Tracker
+	tmpDir         string // zips are kept here while being downloaded
 	emitter        stats.Sender
+	modProxies     func(protocol, baseURL string, headers map[string]string) zips.ProxyClient
+	proxiesLock    sync.Mutex
+	proxies        map[string]zips.ProxyClient // by protocol and base url
 	log            loggy.Logger
 }
 
-func (d *downloader) downloadFromProxy(mod coordinates.SerialModule) (repository.Blob, error) {
+// modProxyOf returns a ProxyClient for the module proxy request is for, if
+// the go-import meta tag of the module refers to another module proxy rather
+// than to a repository.
+func (d *downloader) modProxyOf(request *upstream.Request) (zips.ProxyClient, bool) {
+	protocol, baseURL, ok := request.ModProxy()
+	if !ok {
+		return nil, false
+	}
+
+	// the same module proxy serves many modules, and its headers are those
+	// of its own domain, so one client with its pool of connections will do
+	d.proxiesLock.Lock()
+	defer d.proxiesLock.Unlock()
+
+	key := protocol + "://" + baseURL
+	client, exists := d.proxies[key]
+	if !exists {
+		client = d.modProxies(protocol, baseURL, request.Headers)
+		d.proxies[key] = client
+	}
+	return client, true
+}
+
+func (d *downloader) downloadFromProxy(proxyClient zips.ProxyClient, mod coordinates.SerialModule) (*repository.File, repository.RevInfo, error) {
 	d.log.Infof("going to download from proxy: %s", mod.String())
 
 	// download the well-formed zip from the proxy
 	start := time.Now()
-	blob, err := d.proxyClient.Get(mod.Module)
+	rc, err := proxyClient.Get(mod.Module)
 	if err != nil {
-		return nil, err
+		return nil, repository.RevInfo{}, err
+	}
+	defer ignore.Close(rc)
+
+	file, err := repository.Spill(d.tmpDir, rc)
+	if err != nil {
+		return nil, repository.RevInfo{}, err
 	}
 
 	d.emitter.GaugeMS("download-mod-elapsed-ms", start)
-	d.log.Infof("downloaded upstream blob of size: %d", len(blob))
+	d.log.Infof("downloaded upstream blob of size: %d", file.Size())
+
+	// the proxy already knows which commit the version refers to
+	info, err := proxyClient.Info(mod.Module)
+	if err != nil {
+		info = d.revInfoFailed(mod, err)
+	}
+	info.Version = mod.Module.Version
 
 	// no need to re-write, this is already a correctly formatted zip
-	return blob, nil
+	return file, info, nil
 }
 
-func (d *downloader) downloadFromUpstream(mod coordinates.SerialModule) (repository.Blob, error) {
+func (d *downloader) downloadFromUpstream(mod coordinates.SerialModule) (*repository.File, repository.RevInfo, error) {
 	d.log.Infof("going to download from upstream: %s", mod.String())
 
 	request, err := d.resolver.Resolve(mod.Module)
 	if err != nil {
-		return nil, err
+		return nil, repository.RevInfo{}, err
 	}
 
+	// the module is served by the module proxy of its go-import meta tag
+	if modProxy, ok := d.modProxyOf(request); ok {
+		return d.downloadFromProxy(modProxy, mod)
+	}
+
+	file, err := d.downloadRequest(mod, request)
+	if err != nil {
+		return nil, repository.RevInfo{}, err
+	}
+
+	info, err := d.commitOf(mod, request)
+	if err != nil {
+		info = d.revInfoFailed(mod, err)
+	}
+
+	return file, info, nil
+}
+
+// commitOf returns the RevInfo of the commit the version of mod refers to.
+func (d *downloader) commitOf(mod coordinates.SerialModule, request *upstream.Request) (repository.RevInfo, error) {
+	// a repository with a git transport was cloned into a mirror, which
+	// knows the commit without asking anyone
+	if strings.HasPrefix(request.Transport, "git") {
+		rev, err := d.revisions.Revision(request)
+		if err != nil {
+			return repository.RevInfo{}, err
+		}
+		return repository.RevInfoOf(mod.Module.Version).WithCommit(rev.Hash, rev.Time), nil
+	}
+
+	// otherwise the code hosting service of the upstream knows, which is
+	// not included in the archive it serves
+	return d.commitClient.Commit(request)
+}
+
+// revInfoFailed returns what can be known of the commit of mod without the
+// upstream, when the upstream failed to say.
+func (d *downloader) revInfoFailed(mod coordinates.SerialModule, err error) repository.RevInfo {
+	d.log.Warnf("failed to get commit of %s, %v", mod, err)
+	d.emitter.Count("download-mod-info-failure", 1)
+	return repository.RevInfoOf(mod.Module.Version)
+}
+
+// downloadRequest downloads the git archive of request, and rewrites it into
+// a module zip.
+func (d *downloader) downloadRequest(mod coordinates.SerialModule, request *upstream.Request) (*repository.File, error) {
 	// download the raw-zip from the upstream source
 	start := time.Now()
-	blob, err := d.upstreamClient.Get(request)
+	rc, err := d.upstreamClient.Get(request)
 	if err != nil {
 		return nil, err
 	}
+	defer ignore.Close(rc)
+
+	raw, err := repository.Spill(d.tmpDir, rc)
+	if err != nil {
+		return nil, err
+	}
+	defer ignore.Close(raw)
 
 	d.emitter.GaugeMS("download-mod-elapsed-ms", start)
-	d.log.Infof("downloaded upstream blob of size: %d", len(blob))
+	d.log.Infof("downloaded upstream blob of size: %d", raw.Size())
 
-	rewritten, err := zips.Rewrite(mod.Module, blob)
+	rewritten, err := repository.NewFile(d.tmpDir)
 	if err != nil {
+		return nil, err
+	}
+
+	if err := zips.RewriteTo(mod.Module, raw, rewritten); err != nil {
 		d.log.Errorf("failed to rewrite blob for %s, %v", mod, err)
+		ignore.Close(rewritten)
 		return nil, err
 	}
 
 	return rewritten, nil
 }
 
-func (d *downloader) storeBlob(mod coordinates.SerialModule, blob repository.Blob) error {
-
-	if err := d.store.PutZip(mod.Module, blob); err != nil {
-		d.log.Errorf("failed to save blob to zip store for %s, %v", mod, err)
+func (d *downloader) storeBlob(mod coordinates.SerialModule, archive repository.Archive, info repository.RevInfo) error {
+	modFile, exists, err := repository.ModFileOf(archive)
+	if err != nil {
+		d.log.Errorf("failed to re-read re-written zip file for %s, %v", mod, err)
 		return err
 	}
+	if !exists {
+		modFile = repository.EmptyModFile(mod.Module.Source)
+	}
 
-	modFile, exists, err := blob.ModFile()
+	hashes, err := repository.HashesOf(archive, modFile)
 	if err != nil {
-		d.log.Errorf("failed to re-read re-written zip file for %s, %v", mod, err)
+		d.log.Errorf("failed to hash %s, %v", mod, err)
 		return err
 	}
-	if !exists {
-		modFile = emptyModFile(mod)
+
+	// never store anything that does not match the checksum database
+	if err := d.verifier.Verify(mod.Module, hashes.Zip, hashes.Mod); err != nil {
+		d.log.Errorf("failed to verify %s, %v", mod, err)
+		if checksum.IsMismatch(err) {
+			d.emitter.Count("download-mod-checksum-mismatch", 1)
+		}
+		return err
 	}
 
 	ma := store.ModuleAddition{
 		Mod:      mod.Module,
 		UniqueID: mod.SerialID,
 		ModFile:  modFile,
+		Hashes:   hashes,
+		Info:     info,
 	}
 
-	if err := d.index.Put(ma); err != nil {
-		d.log.Errorf("failed to updated index for %s, %v", mod, err)
+	// the zip and index entry are stored together, or not at all
+	if err := d.ingester.Ingest(ma, archive); err != nil {
+		d.log.Errorf("failed to store %s, %v", mod, err)
 		return err
 	}
 
-	d.log.Tracef("stored %s, was %d bytes", mod, len(blob))
+	d.log.Tracef("stored %s, was %d bytes", mod, archive.Size())
 
 	return nil
 }
@@ -133,25 +279,83 @@ func (d *downloader) Download(mod coordinates.SerialModule) error {
 	}
 	{
 		var (
-			blob repository.Blob
+			file *repository.File
+			info repository.RevInfo
 			err  error
 		)
 		switch useProxy {
 		case true:
-			blob, err = d.downloadFromProxy(mod)
+			file, info, err = d.downloadFromProxy(d.proxyClient, mod)
 		default:
-			blob, err = d.downloadFromUpstream(mod)
+			file, info, err = d.downloadFromUpstream(mod)
 		}
 
 		if err != nil {
 			d.log.Errorf("failed to download %s: %v", mod, err)
 			return err
 		}
+		defer ignore.Close(file)
+
+		if err := d.storeBlob(mod, file, info); err != nil {
+			return err
+		}
+
+		// any previous problem downloading this module is now resolved
+		d.dlTracker.Resolve(mod.Module, problems.Downloaded)
+		return nil
+	}
+}
+
+func (d *downloader) Resolve(mod coordinates.Module) (coordinates.Module, error) {
+	if !repository.IsQuery(mod.Version) {
+		return mod, nil
+	}
+
+	useProxy, err := d.resolver.UseProxy(mod)
+	if err != nil {
+		return mod, err
+	}
 
-		return d.storeBlob(mod, blob)
+	if useProxy {
+		// the proxy resolves the version the same way
+		return d.resolveFromProxy(d.proxyClient, mod)
+	}
+
+	request, err := d.resolver.Resolve(mod)
+	if err != nil {
+		d.emitter.Count("resolve-mod-failure", 1)
+		return mod, err
 	}
+
+	// and so does the module proxy of the go-import meta tag of the module
+	if modProxy, ok := d.modProxyOf(request); ok {
+		return d.resolveFromProxy(modProxy, mod)
+	}
+
+	resolved, err := d.resolver.ResolveVersion(mod)
+	if err != nil {
+		d.emitter.Count("resolve-mod-failure", 1)
+		return mod, err
+	}
+	d.log.Infof("resolved %s to version %s", mod, resolved.Version)
+	d.emitter.Count("resolve-mod-ok", 1)
+	return resolved, nil
 }
 
-func emptyModFile(mod coordinates.SerialModule) string {
-	return fmt.Sprintf("module %s\n", mod.Module.Source)
+func (d *downloader) resolveFromProxy(proxyClient zips.ProxyClient, mod coordinates.Module) (coordinates.Module, error) {
+	info, err := proxyClient.Info(mod)
+	if err != nil {
+		d.emitter.Count("resolve-mod-failure", 1)
+		return mod, err
+	}
+	if repository.IsQuery(info.Version) {
+		return mod, errors.Errorf("proxy resolved %s to invalid version %q", mod, info.Version)
+	}
+
+	d.log.Infof("resolved %s to version %s from proxy", mod, info.Version)
+	d.emitter.Count("resolve-mod-ok", 1)
+	return coordinates.Module{
+		Source:  mod.Source,
+		Version: info.Version,
+	}, nil
 }
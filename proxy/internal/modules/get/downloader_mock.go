@@ -20,6 +20,12 @@ type DownloaderMock struct {
 	afterDownloadCounter  uint64
 	beforeDownloadCounter uint64
 	DownloadMock          mDownloaderMockDownload
+
+	funcResolve          func(module coordinates.Module) (m1 coordinates.Module, err error)
+	inspectFuncResolve   func(module coordinates.Module)
+	afterResolveCounter  uint64
+	beforeResolveCounter uint64
+	ResolveMock          mDownloaderMockResolve
 }
 
 // NewDownloaderMock returns a mock for Downloader
@@ -32,6 +38,9 @@ func NewDownloaderMock(t minimock.Tester) *DownloaderMock {
 	m.DownloadMock = mDownloaderMockDownload{mock: m}
 	m.DownloadMock.callArgs = []*DownloaderMockDownloadParams{}
 
+	m.ResolveMock = mDownloaderMockResolve{mock: m}
+	m.ResolveMock.callArgs = []*DownloaderMockResolveParams{}
+
 	return m
 }
 
@@ -250,10 +259,228 @@ func (m *DownloaderMock) MinimockDownloadInspect() {
 	}
 }
 
+type mDownloaderMockResolve struct {
+	mock               *DownloaderMock
+	defaultExpectation *DownloaderMockResolveExpectation
+	expectations       []*DownloaderMockResolveExpectation
+
+	callArgs []*DownloaderMockResolveParams
+	mutex    sync.RWMutex
+}
+
+// DownloaderMockResolveExpectation specifies expectation struct of the Downloader.Resolve
+type DownloaderMockResolveExpectation struct {
+	mock    *DownloaderMock
+	params  *DownloaderMockResolveParams
+	results *DownloaderMockResolveResults
+	Counter uint64
+}
+
+// DownloaderMockResolveParams contains parameters of the Downloader.Resolve
+type DownloaderMockResolveParams struct {
+	module coordinates.Module
+}
+
+// DownloaderMockResolveResults contains results of the Downloader.Resolve
+type DownloaderMockResolveResults struct {
+	m1  coordinates.Module
+	err error
+}
+
+// Expect sets up expected params for Downloader.Resolve
+func (mmResolve *mDownloaderMockResolve) Expect(module coordinates.Module) *mDownloaderMockResolve {
+	if mmResolve.mock.funcResolve != nil {
+		mmResolve.mock.t.Fatalf("DownloaderMock.Resolve mock is already set by Set")
+	}
+
+	if mmResolve.defaultExpectation == nil {
+		mmResolve.defaultExpectation = &DownloaderMockResolveExpectation{}
+	}
+
+	mmResolve.defaultExpectation.params = &DownloaderMockResolveParams{module}
+	for _, e := range mmResolve.expectations {
+		if minimock.Equal(e.params, mmResolve.defaultExpectation.params) {
+			mmResolve.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmResolve.defaultExpectation.params)
+		}
+	}
+
+	return mmResolve
+}
+
+// Inspect accepts an inspector function that has same arguments as the Downloader.Resolve
+func (mmResolve *mDownloaderMockResolve) Inspect(f func(module coordinates.Module)) *mDownloaderMockResolve {
+	if mmResolve.mock.inspectFuncResolve != nil {
+		mmResolve.mock.t.Fatalf("Inspect function is already set for DownloaderMock.Resolve")
+	}
+
+	mmResolve.mock.inspectFuncResolve = f
+
+	return mmResolve
+}
+
+// Return sets up results that will be returned by Downloader.Resolve
+func (mmResolve *mDownloaderMockResolve) Return(m1 coordinates.Module, err error) *DownloaderMock {
+	if mmResolve.mock.funcResolve != nil {
+		mmResolve.mock.t.Fatalf("DownloaderMock.Resolve mock is already set by Set")
+	}
+
+	if mmResolve.defaultExpectation == nil {
+		mmResolve.defaultExpectation = &DownloaderMockResolveExpectation{mock: mmResolve.mock}
+	}
+	mmResolve.defaultExpectation.results = &DownloaderMockResolveResults{m1, err}
+	return mmResolve.mock
+}
+
+//Set uses given function f to mock the Downloader.Resolve method
+func (mmResolve *mDownloaderMockResolve) Set(f func(module coordinates.Module) (m1 coordinates.Module, err error)) *DownloaderMock {
+	if mmResolve.defaultExpectation != nil {
+		mmResolve.mock.t.Fatalf("Default expectation is already set for the Downloader.Resolve method")
+	}
+
+	if len(mmResolve.expectations) > 0 {
+		mmResolve.mock.t.Fatalf("Some expectations are already set for the Downloader.Resolve method")
+	}
+
+	mmResolve.mock.funcResolve = f
+	return mmResolve.mock
+}
+
+// When sets expectation for the Downloader.Resolve which will trigger the result defined by the following
+// Then helper
+func (mmResolve *mDownloaderMockResolve) When(module coordinates.Module) *DownloaderMockResolveExpectation {
+	if mmResolve.mock.funcResolve != nil {
+		mmResolve.mock.t.Fatalf("DownloaderMock.Resolve mock is already set by Set")
+	}
+
+	expectation := &DownloaderMockResolveExpectation{
+		mock:   mmResolve.mock,
+		params: &DownloaderMockResolveParams{module},
+	}
+	mmResolve.expectations = append(mmResolve.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Downloader.Resolve return parameters for the expectation previously defined by the When method
+func (e *DownloaderMockResolveExpectation) Then(m1 coordinates.Module, err error) *DownloaderMock {
+	e.results = &DownloaderMockResolveResults{m1, err}
+	return e.mock
+}
+
+// Resolve implements Downloader
+func (mmResolve *DownloaderMock) Resolve(module coordinates.Module) (m1 coordinates.Module, err error) {
+	mm_atomic.AddUint64(&mmResolve.beforeResolveCounter, 1)
+	defer mm_atomic.AddUint64(&mmResolve.afterResolveCounter, 1)
+
+	if mmResolve.inspectFuncResolve != nil {
+		mmResolve.inspectFuncResolve(module)
+	}
+
+	mm_params := &DownloaderMockResolveParams{module}
+
+	// Record call args
+	mmResolve.ResolveMock.mutex.Lock()
+	mmResolve.ResolveMock.callArgs = append(mmResolve.ResolveMock.callArgs, mm_params)
+	mmResolve.ResolveMock.mutex.Unlock()
+
+	for _, e := range mmResolve.ResolveMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.m1, e.results.err
+		}
+	}
+
+	if mmResolve.ResolveMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmResolve.ResolveMock.defaultExpectation.Counter, 1)
+		mm_want := mmResolve.ResolveMock.defaultExpectation.params
+		mm_got := DownloaderMockResolveParams{module}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmResolve.t.Errorf("DownloaderMock.Resolve got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmResolve.ResolveMock.defaultExpectation.results
+		if mm_results == nil {
+			mmResolve.t.Fatal("No results are set for the DownloaderMock.Resolve")
+		}
+		return (*mm_results).m1, (*mm_results).err
+	}
+	if mmResolve.funcResolve != nil {
+		return mmResolve.funcResolve(module)
+	}
+	mmResolve.t.Fatalf("Unexpected call to DownloaderMock.Resolve. %v", module)
+	return
+}
+
+// ResolveAfterCounter returns a count of finished DownloaderMock.Resolve invocations
+func (mmResolve *DownloaderMock) ResolveAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolve.afterResolveCounter)
+}
+
+// ResolveBeforeCounter returns a count of DownloaderMock.Resolve invocations
+func (mmResolve *DownloaderMock) ResolveBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolve.beforeResolveCounter)
+}
+
+// Calls returns a list of arguments used in each call to DownloaderMock.Resolve.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmResolve *mDownloaderMockResolve) Calls() []*DownloaderMockResolveParams {
+	mmResolve.mutex.RLock()
+
+	argCopy := make([]*DownloaderMockResolveParams, len(mmResolve.callArgs))
+	copy(argCopy, mmResolve.callArgs)
+
+	mmResolve.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockResolveDone returns true if the count of the Resolve invocations corresponds
+// the number of defined expectations
+func (m *DownloaderMock) MinimockResolveDone() bool {
+	for _, e := range m.ResolveMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolveMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolve != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockResolveInspect logs each unmet expectation
+func (m *DownloaderMock) MinimockResolveInspect() {
+	for _, e := range m.ResolveMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to DownloaderMock.Resolve with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolveMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		if m.ResolveMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to DownloaderMock.Resolve")
+		} else {
+			m.t.Errorf("Expected call to DownloaderMock.Resolve with params: %#v", *m.ResolveMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolve != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		m.t.Error("Expected call to DownloaderMock.Resolve")
+	}
+}
+
 // MinimockFinish checks that all mocked methods have been called the expected number of times
 func (m *DownloaderMock) MinimockFinish() {
 	if !m.minimockDone() {
 		m.MinimockDownloadInspect()
+
+		m.MinimockResolveInspect()
 		m.t.FailNow()
 	}
 }
@@ -277,5 +504,6 @@ func (m *DownloaderMock) MinimockWait(timeout mm_time.Duration) {
 func (m *DownloaderMock) minimockDone() bool {
 	done := true
 	return done &&
-		m.MinimockDownloadDone()
+		m.MinimockDownloadDone() &&
+		m.MinimockResolveDone()
 }
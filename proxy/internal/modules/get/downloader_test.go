@@ -3,34 +3,45 @@ package get
 import (
 	"archive/zip"
 	"bytes"
+	"io"
+	"io/ioutil"
 	"testing"
 	"time"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
 	"oss.indeed.com/go/modprox/pkg/clients/zips"
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
 	"oss.indeed.com/go/modprox/pkg/repository"
 	"oss.indeed.com/go/modprox/pkg/upstream"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
 )
 
 type mocks struct {
 	resolver       *upstream.ResolverMock
 	proxyClient    *zips.ProxyClientMock
 	upstreamClient *zips.UpstreamClientMock
-	zipStore       *store.ZipStoreMock
-	index          *store.IndexMock
+	commitClient   *zips.CommitClientMock
+	revisions      *upstream.RevisionerMock
+	ingester       *store.IngesterMock
+	verifier       *checksum.VerifierMock
+	dlTracker      *problems.TrackerMock
 	emitter        *stats.SenderMock
 }
 
 func (m mocks) assertions() {
 	m.proxyClient.MinimockFinish()
 	m.upstreamClient.MinimockFinish()
+	m.commitClient.MinimockFinish()
+	m.revisions.MinimockFinish()
 	m.resolver.MinimockFinish()
-	m.zipStore.MinimockFinish()
-	m.index.MinimockFinish()
+	m.ingester.MinimockFinish()
+	m.verifier.MinimockFinish()
+	m.dlTracker.MinimockFinish()
 	m.emitter.MinimockFinish()
 }
 
@@ -38,9 +49,12 @@ func newMocks(t *testing.T) mocks {
 	return mocks{
 		proxyClient:    zips.NewProxyClientMock(t),
 		upstreamClient: zips.NewUpstreamClientMock(t),
+		commitClient:   zips.NewCommitClientMock(t),
+		revisions:      upstream.NewRevisionerMock(t),
 		resolver:       upstream.NewResolverMock(t),
-		zipStore:       store.NewZipStoreMock(t),
-		index:          store.NewIndexMock(t),
+		ingester:       store.NewIngesterMock(t),
+		verifier:       checksum.NewVerifierMock(t),
+		dlTracker:      problems.NewTrackerMock(t),
 		emitter:        stats.NewSenderMock(t),
 	}
 }
@@ -76,6 +90,48 @@ func dummyZip(t *testing.T) repository.Blob {
 	return repository.Blob(buf.Bytes())
 }
 
+func hashesOf(t *testing.T, blob repository.Blob) repository.Hashes {
+	hashes, err := repository.HashesOf(blob, "module github.com/pkg/errors\n")
+	require.NoError(t, err)
+	return hashes
+}
+
+func expectVerify(t *testing.T, mocks mocks, mod coordinates.Module, blob repository.Blob, err error) {
+	hashes := hashesOf(t, blob)
+	mocks.verifier.VerifyMock.When(mod, hashes.Zip, hashes.Mod).Then(err)
+}
+
+func readCloser(blob repository.Blob) io.ReadCloser {
+	return ioutil.NopCloser(bytes.NewReader(blob))
+}
+
+// expectIngest expects blob to be stored for mod, which the downloader passes
+// along as a temporary file, so compare content rather than the archive itself
+func expectIngest(t *testing.T, mocks mocks, mod coordinates.SerialModule, blob repository.Blob, info repository.RevInfo) {
+	mocks.ingester.IngestMock.Set(func(addition store.ModuleAddition, archive repository.Archive) error {
+		require.Equal(t, store.ModuleAddition{
+			Mod:      mod.Module,
+			UniqueID: mod.SerialID,
+			ModFile:  "module github.com/pkg/errors\n",
+			Hashes:   hashesOf(t, blob),
+			Info:     info,
+		}, addition)
+		content, err := ioutil.ReadAll(io.NewSectionReader(archive, 0, archive.Size()))
+		require.NoError(t, err)
+		require.Equal(t, []byte(blob), content)
+		return nil
+	})
+}
+
+func commitOf(mod coordinates.Module) repository.RevInfo {
+	return repository.RevInfo{
+		Version: mod.Version,
+		Name:    "645ef00459ed84a119197bfb8d8205042c6df63d",
+		Short:   "645ef00459ed",
+		Time:    time.Date(2019, 1, 3, 6, 42, 23, 0, time.UTC),
+	}
+}
+
 func Test_Download_upstream_ok(t *testing.T) {
 	mocks := newMocks(t)
 	defer mocks.assertions()
@@ -107,27 +163,160 @@ func Test_Download_upstream_ok(t *testing.T) {
 	mocks.resolver.ResolveMock.When(serialModule.Module).Then(upstreamRequest, nil)
 
 	// return the original raw archive blob from upstream
-	mocks.upstreamClient.GetMock.When(upstreamRequest).Then(originalBlob, nil)
+	mocks.upstreamClient.GetMock.When(upstreamRequest).Then(readCloser(originalBlob), nil)
 
 	mocks.emitter.GaugeMSMock.Set(func(metric string, now time.Time) {
 		require.Equal(t, "download-mod-elapsed-ms", metric)
 		_ = now // ignore
 	})
 
-	mocks.zipStore.PutZipMock.When(serialModule.Module, rewrittenBlob).Then(nil)
+	// the commit of the version is looked up with the same request
+	info := commitOf(serialModule.Module)
+	mocks.commitClient.CommitMock.When(upstreamRequest).Then(info, nil)
 
-	mocks.index.PutMock.When(store.ModuleAddition{
-		Mod:      serialModule.Module,
-		UniqueID: 16,
-		ModFile:  "module github.com/pkg/errors\n",
-	}).Then(nil)
+	expectVerify(t, mocks, serialModule.Module, rewrittenBlob, nil)
+
+	expectIngest(t, mocks, serialModule, rewrittenBlob, info)
+
+	mocks.dlTracker.ResolveMock.When(serialModule.Module, problems.Downloaded).Then(false)
 
 	dl := New(
 		mocks.proxyClient,
 		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
 		mocks.resolver,
-		mocks.zipStore,
-		mocks.index,
+		mocks.ingester,
+		mocks.verifier,
+		mocks.dlTracker,
+		"",
+		mocks.emitter,
+	)
+
+	err = dl.Download(serialModule)
+	require.NoError(t, err)
+}
+
+func Test_Download_upstream_no_commit(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	serialModule := coordinates.SerialModule{
+		Module: coordinates.Module{
+			Source:  "github.com/pkg/errors",
+			Version: "v0.0.0-20180111040409-fbec762f837d",
+		},
+		SerialID: 16,
+	}
+
+	upstreamRequest := &upstream.Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: []string{"pkg", "errors"},
+		Version:   "v0.0.0-20180111040409-fbec762f837d",
+	}
+
+	originalBlob := dummyZip(t)
+
+	rewrittenBlob, err := zips.Rewrite(serialModule.Module, originalBlob)
+	require.NoError(t, err)
+
+	mocks.resolver.UseProxyMock.When(serialModule.Module).Then(false, nil)
+	mocks.resolver.ResolveMock.When(serialModule.Module).Then(upstreamRequest, nil)
+	mocks.upstreamClient.GetMock.When(upstreamRequest).Then(readCloser(originalBlob), nil)
+
+	mocks.emitter.GaugeMSMock.Set(func(metric string, now time.Time) {
+		require.Equal(t, "download-mod-elapsed-ms", metric)
+		_ = now // ignore
+	})
+
+	// the module is stored anyway, with what the pseudo-version says
+	mocks.commitClient.CommitMock.When(upstreamRequest).Then(repository.RevInfo{}, errors.New("rate limited"))
+	mocks.emitter.CountMock.Expect("download-mod-info-failure", 1).Return()
+
+	expectVerify(t, mocks, serialModule.Module, rewrittenBlob, nil)
+
+	expectIngest(t, mocks, serialModule, rewrittenBlob, repository.RevInfo{
+		Version: "v0.0.0-20180111040409-fbec762f837d",
+		Short:   "fbec762f837d",
+		Time:    time.Date(2018, 1, 11, 4, 4, 9, 0, time.UTC),
+	})
+
+	mocks.dlTracker.ResolveMock.When(serialModule.Module, problems.Downloaded).Then(false)
+
+	dl := New(
+		mocks.proxyClient,
+		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
+		mocks.resolver,
+		mocks.ingester,
+		mocks.verifier,
+		mocks.dlTracker,
+		"",
+		mocks.emitter,
+	)
+
+	err = dl.Download(serialModule)
+	require.NoError(t, err)
+}
+
+func Test_Download_upstream_git(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	serialModule := coordinates.SerialModule{
+		Module: coordinates.Module{
+			Source:  "github.com/pkg/errors",
+			Version: "v1.2.3",
+		},
+		SerialID: 16,
+	}
+
+	upstreamRequest := &upstream.Request{
+		Transport: "git+https",
+		Domain:    "github.com",
+		Namespace: []string{"pkg", "errors"},
+		Version:   "v1.2.3",
+	}
+
+	originalBlob := dummyZip(t)
+
+	rewrittenBlob, err := zips.Rewrite(serialModule.Module, originalBlob)
+	require.NoError(t, err)
+
+	mocks.resolver.UseProxyMock.When(serialModule.Module).Then(false, nil)
+	mocks.resolver.ResolveMock.When(serialModule.Module).Then(upstreamRequest, nil)
+	mocks.upstreamClient.GetMock.When(upstreamRequest).Then(readCloser(originalBlob), nil)
+
+	mocks.emitter.GaugeMSMock.Set(func(metric string, now time.Time) {
+		require.Equal(t, "download-mod-elapsed-ms", metric)
+		_ = now // ignore
+	})
+
+	// the commit is read from the mirror, not asked of the commit client
+	info := commitOf(serialModule.Module)
+	mocks.revisions.RevisionMock.When(upstreamRequest).Then(upstream.Revision{
+		Hash: info.Name,
+		Time: info.Time,
+	}, nil)
+
+	expectVerify(t, mocks, serialModule.Module, rewrittenBlob, nil)
+
+	expectIngest(t, mocks, serialModule, rewrittenBlob, info)
+
+	mocks.dlTracker.ResolveMock.When(serialModule.Module, problems.Downloaded).Then(false)
+
+	dl := New(
+		mocks.proxyClient,
+		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
+		mocks.resolver,
+		mocks.ingester,
+		mocks.verifier,
+		mocks.dlTracker,
+		"",
 		mocks.emitter,
 	)
 
@@ -155,27 +344,33 @@ func Test_Download_proxy_ok(t *testing.T) {
 	mocks.resolver.UseProxyMock.When(serialModule.Module).Then(true, nil)
 
 	// return the well-formed zip in response
-	mocks.proxyClient.GetMock.When(serialModule.Module).Then(originalBlob, nil)
+	mocks.proxyClient.GetMock.When(serialModule.Module).Then(readCloser(originalBlob), nil)
 
 	mocks.emitter.GaugeMSMock.Set(func(metric string, now time.Time) {
 		require.Equal(t, "download-mod-elapsed-ms", metric)
 		_ = now // ignore
 	})
 
-	mocks.zipStore.PutZipMock.When(serialModule.Module, originalBlob).Then(nil)
+	// the proxy also knows the commit of the version
+	info := commitOf(serialModule.Module)
+	mocks.proxyClient.InfoMock.When(serialModule.Module).Then(info, nil)
 
-	mocks.index.PutMock.When(store.ModuleAddition{
-		Mod:      serialModule.Module,
-		UniqueID: 16,
-		ModFile:  "module github.com/pkg/errors\n",
-	}).Then(nil)
+	expectVerify(t, mocks, serialModule.Module, originalBlob, nil)
+
+	expectIngest(t, mocks, serialModule, originalBlob, info)
+
+	mocks.dlTracker.ResolveMock.When(serialModule.Module, problems.Downloaded).Then(false)
 
 	dl := New(
 		mocks.proxyClient,
 		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
 		mocks.resolver,
-		mocks.zipStore,
-		mocks.index,
+		mocks.ingester,
+		mocks.verifier,
+		mocks.dlTracker,
+		"",
 		mocks.emitter,
 	)
 
@@ -183,6 +378,104 @@ func Test_Download_proxy_ok(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func Test_Download_mod_proxy_ok(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	modProxy := zips.NewProxyClientMock(t)
+	defer modProxy.MinimockFinish()
+
+	serialModule := coordinates.SerialModule{
+		Module: coordinates.Module{
+			Source:  "github.com/pkg/errors",
+			Version: "v1.2.3",
+		},
+		SerialID: 16,
+	}
+
+	// the module proxy of the go-import meta tag serves well-formed zips too
+	originalBlob, err := zips.Rewrite(serialModule.Module, dummyZip(t))
+	require.NoError(t, err)
+
+	mocks.resolver.UseProxyMock.When(serialModule.Module).Then(false, nil)
+	mocks.resolver.ResolveMock.When(serialModule.Module).Then(modProxyRequest("v1.2.3"), nil)
+
+	// so neither the upstream client nor the global proxy are used
+	modProxy.GetMock.When(serialModule.Module).Then(readCloser(originalBlob), nil)
+
+	mocks.emitter.GaugeMSMock.Set(func(metric string, now time.Time) {
+		require.Equal(t, "download-mod-elapsed-ms", metric)
+		_ = now // ignore
+	})
+
+	info := commitOf(serialModule.Module)
+	modProxy.InfoMock.When(serialModule.Module).Then(info, nil)
+
+	expectVerify(t, mocks, serialModule.Module, originalBlob, nil)
+
+	expectIngest(t, mocks, serialModule, originalBlob, info)
+
+	mocks.dlTracker.ResolveMock.When(serialModule.Module, problems.Downloaded).Then(false)
+
+	dl := withModProxy(t, newTestDownloader(mocks), modProxy)
+
+	err = dl.Download(serialModule)
+	require.NoError(t, err)
+}
+
+func Test_Download_checksum_mismatch(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	serialModule := coordinates.SerialModule{
+		Module: coordinates.Module{
+			Source:  "github.com/pkg/errors",
+			Version: "v1.2.3",
+		},
+		SerialID: 16,
+	}
+
+	originalBlob, err := zips.Rewrite(serialModule.Module, dummyZip(t))
+	require.NoError(t, err)
+
+	mocks.resolver.UseProxyMock.When(serialModule.Module).Then(true, nil)
+
+	mocks.proxyClient.GetMock.When(serialModule.Module).Then(readCloser(originalBlob), nil)
+	mocks.proxyClient.InfoMock.When(serialModule.Module).Then(commitOf(serialModule.Module), nil)
+
+	mocks.emitter.GaugeMSMock.Set(func(metric string, now time.Time) {
+		require.Equal(t, "download-mod-elapsed-ms", metric)
+		_ = now // ignore
+	})
+
+	// the checksum database disagrees, so nothing is stored
+	expectVerify(t, mocks, serialModule.Module, originalBlob, &checksum.Mismatch{
+		Module:   serialModule.Module,
+		File:     "zip",
+		Expected: "h1:expected=",
+		Actual:   "h1:actual=",
+	})
+
+	mocks.emitter.CountMock.Expect("download-mod-checksum-mismatch", 1).Return()
+
+	dl := New(
+		mocks.proxyClient,
+		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
+		mocks.resolver,
+		mocks.ingester,
+		mocks.verifier,
+		mocks.dlTracker,
+		"",
+		mocks.emitter,
+	)
+
+	err = dl.Download(serialModule)
+	require.Error(t, err)
+	require.True(t, checksum.IsMismatch(err))
+}
+
 /*
 func Test_Download_err_Resolve(t *testing.T) {
 	mocks := newMocks()
@@ -217,6 +510,8 @@ func Test_Download_err_Resolve(t *testing.T) {
 
 	dl := New(
 		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
 		mocks.resolver,
 		mocks.zipStore,
 		mocks.index,
@@ -245,6 +540,8 @@ func Test_Download_err_Get(t *testing.T) {
 
 	dl := New(
 		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
 		mocks.resolver,
 		mocks.zipStore,
 		mocks.index,
@@ -291,6 +588,8 @@ func Test_Download_err_Rewrite(t *testing.T) {
 
 	dl := New(
 		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
 		mocks.resolver,
 		mocks.zipStore,
 		mocks.index,
@@ -343,6 +642,8 @@ func Test_Download_err_PutZip(t *testing.T) {
 
 	dl := New(
 		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
 		mocks.resolver,
 		mocks.zipStore,
 		mocks.index,
@@ -401,6 +702,8 @@ func Test_Download_err_Put(t *testing.T) {
 
 	dl := New(
 		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
 		mocks.resolver,
 		mocks.zipStore,
 		mocks.index,
@@ -411,3 +714,184 @@ func Test_Download_err_Put(t *testing.T) {
 	require.EqualError(t, err, "put failure")
 }
 */
+
+func newTestDownloader(mocks mocks) Downloader {
+	return New(
+		mocks.proxyClient,
+		mocks.upstreamClient,
+		mocks.commitClient,
+		mocks.revisions,
+		mocks.resolver,
+		mocks.ingester,
+		mocks.verifier,
+		mocks.dlTracker,
+		"",
+		mocks.emitter,
+	)
+}
+
+func Test_Resolve_upstream(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	branch := coordinates.Module{Source: "code.internal/go/foo", Version: "master"}
+	pseudo := coordinates.Module{Source: "code.internal/go/foo", Version: "v0.0.0-20180111040409-fbec762f837d"}
+
+	mocks.resolver.UseProxyMock.When(branch).Then(false, nil)
+	mocks.resolver.ResolveMock.When(branch).Then(&upstream.Request{
+		Transport: "https",
+		Domain:    "code.internal",
+		Namespace: []string{"go", "foo"},
+		Version:   "master",
+	}, nil)
+	mocks.resolver.ResolveVersionMock.When(branch).Then(pseudo, nil)
+	mocks.emitter.CountMock.Expect("resolve-mod-ok", 1).Return()
+
+	resolved, err := newTestDownloader(mocks).Resolve(branch)
+	require.NoError(t, err)
+	require.Equal(t, pseudo, resolved)
+}
+
+func Test_Resolve_proxy(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	branch := coordinates.Module{Source: "github.com/pkg/errors", Version: "master"}
+
+	mocks.resolver.UseProxyMock.When(branch).Then(true, nil)
+	mocks.proxyClient.InfoMock.When(branch).Then(repository.RevInfo{
+		Version: "v0.9.2-0.20200102030405-fbec762f837d",
+	}, nil)
+	mocks.emitter.CountMock.Expect("resolve-mod-ok", 1).Return()
+
+	resolved, err := newTestDownloader(mocks).Resolve(branch)
+	require.NoError(t, err)
+	require.Equal(t, coordinates.Module{
+		Source:  "github.com/pkg/errors",
+		Version: "v0.9.2-0.20200102030405-fbec762f837d",
+	}, resolved)
+}
+
+// modProxyRequest is the request of a module whose go-import meta tag refers
+// to another module proxy
+func modProxyRequest(version string) *upstream.Request {
+	return &upstream.Request{
+		Transport: "mod+https",
+		Domain:    "proxy.internal",
+		Namespace: []string{"go"},
+		Version:   version,
+	}
+}
+
+// withModProxy has dl use modProxy for the module proxy at proxy.internal/go
+func withModProxy(t *testing.T, dl Downloader, modProxy zips.ProxyClient) Downloader {
+	dl.(*downloader).modProxies = func(protocol, baseURL string, headers map[string]string) zips.ProxyClient {
+		require.Equal(t, "https", protocol)
+		require.Equal(t, "proxy.internal/go", baseURL)
+		return modProxy
+	}
+	return dl
+}
+
+func Test_Resolve_mod_proxy(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	modProxy := zips.NewProxyClientMock(t)
+	defer modProxy.MinimockFinish()
+
+	branch := coordinates.Module{Source: "code.internal/go/foo", Version: "master"}
+
+	mocks.resolver.UseProxyMock.When(branch).Then(false, nil)
+	mocks.resolver.ResolveMock.When(branch).Then(modProxyRequest("master"), nil)
+	modProxy.InfoMock.When(branch).Then(repository.RevInfo{
+		Version: "v0.0.0-20180111040409-fbec762f837d",
+	}, nil)
+	mocks.emitter.CountMock.Expect("resolve-mod-ok", 1).Return()
+
+	resolved, err := withModProxy(t, newTestDownloader(mocks), modProxy).Resolve(branch)
+	require.NoError(t, err)
+	require.Equal(t, coordinates.Module{
+		Source:  "code.internal/go/foo",
+		Version: "v0.0.0-20180111040409-fbec762f837d",
+	}, resolved)
+}
+
+func Test_modProxyOf(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	dl := newTestDownloader(mocks).(*downloader)
+
+	created := 0
+	dl.modProxies = func(protocol, baseURL string, headers map[string]string) zips.ProxyClient {
+		created++
+		require.Equal(t, map[string]string{"Authorization": "Bearer abc123"}, headers)
+		return zips.NewProxyClientMock(t)
+	}
+
+	request := modProxyRequest("v1.2.3")
+	request.Headers = map[string]string{"Authorization": "Bearer abc123"}
+
+	first, ok := dl.modProxyOf(request)
+	require.True(t, ok)
+
+	// every module of the same module proxy shares its client
+	second, ok := dl.modProxyOf(request)
+	require.True(t, ok)
+	require.True(t, first == second)
+	require.Equal(t, 1, created)
+
+	// and a repository is no module proxy at all
+	_, ok = dl.modProxyOf(&upstream.Request{
+		Transport: "https",
+		Domain:    "github.com",
+		Namespace: []string{"pkg", "errors"},
+		Version:   "v1.2.3",
+	})
+	require.False(t, ok)
+}
+
+func Test_Resolve_proxy_invalid(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	branch := coordinates.Module{Source: "github.com/pkg/errors", Version: "master"}
+
+	mocks.resolver.UseProxyMock.When(branch).Then(true, nil)
+	mocks.proxyClient.InfoMock.When(branch).Then(repository.RevInfo{Version: "master"}, nil)
+
+	_, err := newTestDownloader(mocks).Resolve(branch)
+	require.Error(t, err)
+}
+
+func Test_Resolve_failure(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	branch := coordinates.Module{Source: "code.internal/go/foo", Version: "nope"}
+
+	mocks.resolver.UseProxyMock.When(branch).Then(false, nil)
+	mocks.resolver.ResolveMock.When(branch).Then(&upstream.Request{
+		Transport: "https",
+		Domain:    "code.internal",
+		Namespace: []string{"go", "foo"},
+		Version:   "nope",
+	}, nil)
+	mocks.resolver.ResolveVersionMock.When(branch).Then(branch, errors.New("no such branch"))
+	mocks.emitter.CountMock.Expect("resolve-mod-failure", 1).Return()
+
+	_, err := newTestDownloader(mocks).Resolve(branch)
+	require.EqualError(t, err, "no such branch")
+}
+
+func Test_Resolve_version(t *testing.T) {
+	mocks := newMocks(t)
+	defer mocks.assertions()
+
+	// nothing to resolve, so nothing is asked
+	mod := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.9.1"}
+	resolved, err := newTestDownloader(mocks).Resolve(mod)
+	require.NoError(t, err)
+	require.Equal(t, mod, resolved)
+}
@@ -14,9 +14,13 @@ import (
 // Range is an alias of coordinates.RangeIDs for brevity.
 type Ranges = coordinates.RangeIDs
 
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i RegistryAPI -s _mock.go
+
 // RegistryAPI is used to issue API request from the registry
 type RegistryAPI interface {
 	ModulesNeeded(Ranges) ([]coordinates.SerialModule, error)
+	ModulesRegistered() ([]coordinates.SerialModule, error)
+	Register([]coordinates.Module) error
 }
 
 type registryAPI struct {
@@ -67,3 +71,39 @@ func (r *registryAPI) ModulesNeeded(excludeIDs Ranges) ([]coordinates.SerialModu
 
 	return response.Mods, nil
 }
+
+func (r *registryAPI) ModulesRegistered() ([]coordinates.SerialModule, error) {
+	var buf bytes.Buffer
+	if err := r.registryClient.Get("/v1/registry/sources/list", &buf); err != nil {
+		return nil, err
+	}
+
+	var response registry.ReqModsResp
+	if err := json.NewDecoder(&buf).Decode(&response); err != nil {
+		return nil, err
+	}
+
+	return response.Mods, nil
+}
+
+func (r *registryAPI) Register(mods []coordinates.Module) error {
+	bs, err := json.Marshal(mods)
+	if err != nil {
+		return err
+	}
+
+	reader := bytes.NewReader(bs)
+
+	var buf bytes.Buffer
+	if err := r.registryClient.Post("/v1/registry/sources/new", reader, &buf); err != nil {
+		return err
+	}
+
+	var msg string
+	if err := json.NewDecoder(&buf).Decode(&msg); err != nil {
+		return err
+	}
+
+	r.log.Infof("registered %d modules with registry: %s", len(mods), msg)
+	return nil
+}
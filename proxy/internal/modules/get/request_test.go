@@ -1,6 +1,7 @@
 package get
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -64,3 +65,42 @@ func Test_ModulesNeeded(t *testing.T) {
 		},
 	}, serialModules)
 }
+
+func Test_Register(t *testing.T) {
+	index := store.NewIndexMock(t)
+	defer index.MinimockFinish()
+
+	ts := httptest.NewServer(
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			require.Equal(t, "/v1/registry/sources/new", r.URL.Path)
+
+			var mods []coordinates.Module
+			err := json.NewDecoder(r.Body).Decode(&mods)
+			require.NoError(t, err)
+			require.Equal(t, []coordinates.Module{{
+				Source:  "github.com/pkg/errors",
+				Version: "v0.8.0",
+			}}, mods)
+
+			_, _ = w.Write([]byte(`"added 1 new modules"`))
+		}),
+	)
+	defer ts.Close()
+
+	address, port := webutil.ParseURL(t, ts.URL)
+	client := registry.NewClient(registry.Options{
+		Timeout: 10 * time.Second,
+		Instances: []netservice.Instance{{
+			Address: address,
+			Port:    port,
+		}},
+	})
+
+	apiClient := NewRegistryAPI(client, index)
+
+	err := apiClient.Register([]coordinates.Module{{
+		Source:  "github.com/pkg/errors",
+		Version: "v0.8.0",
+	}})
+	require.NoError(t, err)
+}
@@ -0,0 +1,130 @@
+package store
+
+import (
+	"sync"
+	"time"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+// An Access records how much space a stored module takes up, and how often
+// and how recently its zip has been served. Accesses decide which modules
+// are evicted first when the store is over its quota.
+type Access struct {
+	Module coordinates.Module `json:"module"`
+	Size   int64              `json:"size"`  // bytes of the zip, 0 if unknown
+	Last   time.Time          `json:"last"`  // most recent time the zip was served
+	Count  int64              `json:"count"` // number of times the zip was served
+}
+
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i AccessLog -s _mock.go
+
+// An AccessLog keeps track of the Access of each stored module. Both the
+// boltdb and mysql indexes are capable of keeping an AccessLog.
+type AccessLog interface {
+	// AddAccesses adds the Count of each Access to the one recorded for its
+	// module, moving Last forward, and creating an Access of unknown size if
+	// there is not one yet. All of them are added in one batch.
+	AddAccesses([]Access) error
+	PutAccess(Access) error
+	RemoveAccess(coordinates.Module) error
+	Accesses() ([]Access, error)
+}
+
+// DiscardAccesses creates an AccessLog which does not keep anything, for
+// use when nothing is ever evicted.
+func DiscardAccesses() AccessLog {
+	return discard{}
+}
+
+type discard struct{}
+
+func (discard) AddAccesses([]Access) error            { return nil }
+func (discard) PutAccess(Access) error                { return nil }
+func (discard) RemoveAccess(coordinates.Module) error { return nil }
+func (discard) Accesses() ([]Access, error)           { return nil, nil }
+func (discard) Count(coordinates.Module, time.Time)   {}
+func (discard) Flush() error                          { return nil }
+
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i AccessCounter -s _mock.go
+
+// An AccessCounter counts the times the zip of each module is served in
+// memory, so that serving a zip does not have to write to the index. The
+// counts are added to an AccessLog whenever the AccessCounter is flushed.
+type AccessCounter interface {
+	// Count records that the zip of a module was served at a time.
+	Count(coordinates.Module, time.Time)
+
+	// Flush adds everything counted since the last Flush to the AccessLog.
+	Flush() error
+}
+
+// NewAccessCounter creates an AccessCounter which flushes into accesses.
+func NewAccessCounter(accesses AccessLog) AccessCounter {
+	return &counter{
+		accesses: accesses,
+		pending:  make(map[coordinates.Module]Access),
+	}
+}
+
+// DiscardCounts creates an AccessCounter which does not count anything, for
+// use when nothing is ever evicted (and so nothing is ever flushed).
+func DiscardCounts() AccessCounter {
+	return discard{}
+}
+
+type counter struct {
+	accesses AccessLog
+
+	lock    sync.Mutex
+	pending map[coordinates.Module]Access
+}
+
+func (c *counter) Count(mod coordinates.Module, at time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.add(Access{Module: mod, Last: at, Count: 1})
+}
+
+func (c *counter) Flush() error {
+	c.lock.Lock()
+	pending := c.pending
+	c.pending = make(map[coordinates.Module]Access)
+	c.lock.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	batch := make([]Access, 0, len(pending))
+	for _, access := range pending {
+		batch = append(batch, access)
+	}
+
+	if err := c.accesses.AddAccesses(batch); err != nil {
+		// keep the counts around for the next flush
+		c.lock.Lock()
+		for _, access := range batch {
+			c.add(access)
+		}
+		c.lock.Unlock()
+		return err
+	}
+
+	return nil
+}
+
+// add merges access into the pending counts, the lock must be held
+func (c *counter) add(access Access) {
+	existing, exists := c.pending[access.Module]
+	if !exists {
+		c.pending[access.Module] = access
+		return
+	}
+
+	existing.Count += access.Count
+	if access.Last.After(existing.Last) {
+		existing.Last = access.Last
+	}
+	c.pending[access.Module] = existing
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AccessCounter(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	accesses := index.(AccessLog)
+	counter := NewAccessCounter(accesses)
+	modA := newMod("github.com/pkg/errors", "v0.8.1")
+	modB := newMod("gopkg.in/yaml.v2", "v2.2.2")
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	counter.Count(modA, first.Add(time.Hour))
+	counter.Count(modA, first)
+	counter.Count(modB, first)
+
+	// nothing is written until flushed
+	list, err := accesses.Accesses()
+	require.NoError(t, err)
+	require.Empty(t, list)
+
+	err = counter.Flush()
+	require.NoError(t, err)
+
+	list, err = accesses.Accesses()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Access{
+		{Module: modA, Last: first.Add(time.Hour), Count: 2},
+		{Module: modB, Last: first, Count: 1},
+	}, list)
+
+	// flushed counts are not added again
+	err = counter.Flush()
+	require.NoError(t, err)
+
+	list, err = accesses.Accesses()
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+}
+
+func Test_AccessCounter_failure(t *testing.T) {
+	accesses := NewAccessLogMock(t)
+	defer accesses.MinimockFinish()
+
+	counter := NewAccessCounter(accesses)
+	mod := newMod("github.com/pkg/errors", "v0.8.1")
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	// the first flush fails, the second gets what both were meant to add
+	var flushed [][]Access
+	accesses.AddAccessesMock.Set(func(added []Access) error {
+		flushed = append(flushed, added)
+		if len(flushed) == 1 {
+			return errors.New("index is broken")
+		}
+		return nil
+	})
+
+	counter.Count(mod, first)
+	err := counter.Flush()
+	require.Error(t, err)
+
+	counter.Count(mod, first.Add(time.Hour))
+	err = counter.Flush()
+	require.NoError(t, err)
+
+	require.Len(t, flushed, 2)
+	require.Equal(t, []Access{{Module: mod, Last: first.Add(time.Hour), Count: 2}}, flushed[1])
+}
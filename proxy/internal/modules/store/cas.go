@@ -0,0 +1,239 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/atomicfs"
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// blobsDir is where the casStore keeps one copy of each distinct zip. Module
+// paths may not begin with a dot, so it never collides with a module.
+const blobsDir = ".blobs"
+
+// casStore is a content-addressed ZipStore. Every distinct zip is written
+// once into the blobs directory, named after its hash, and each module is a
+// hard link to the blob of its zip, using the same layout as the fsStore.
+//
+// A blob is removed once the index no longer has any modules referencing its
+// hash. A module whose zip hash is not known yet (e.g. one stored before hashes
+// were recorded) might reference any blob, so none are removed until the
+// integrity check has recorded its hash. Since each module is a link of its
+// own, removing a blob never breaks a module which is still stored, it only
+// means the next module with the same zip writes a new blob.
+type casStore struct {
+	options Options
+	index   Index
+	emitter stats.Sender
+	writer  atomicfs.FileWriter
+	log     loggy.Logger
+
+	// held while linking and unlinking, so a blob is never removed
+	// between deciding to link to it and creating the link
+	lock sync.Mutex
+}
+
+// NewContentStore creates a ZipStore which keeps only one copy of identical
+// zips in options.Directory, using index to count references to each zip.
+// Like the fsStore, options.TmpDirectory must be on the same filesystem.
+func NewContentStore(options Options, index Index, emitter stats.Sender) ZipStore {
+	if options.Directory == "" {
+		panic("no directory set for store")
+	}
+
+	writer := atomicfs.NewFileWriter(atomicfs.Options{
+		TmpDirectory: options.TmpDirectory,
+		Mode:         filePerm,
+	})
+
+	return &casStore{
+		options: options,
+		index:   index,
+		emitter: emitter,
+		writer:  writer,
+		log:     loggy.New("cas-store"),
+	}
+}
+
+func (s *casStore) OpenZip(mod coordinates.Module) (Zip, error) {
+	s.log.Tracef("retrieving module %s", mod)
+
+	start := time.Now()
+	zip, err := openFileZip(s.zipFileOf(mod))
+	if err != nil {
+		s.emitter.Count("casstore-getzip-failure", 1)
+		return nil, err
+	}
+
+	s.emitter.GaugeMS("casstore-getzip-elapsed-ms", start)
+	return zip, nil
+}
+
+func (s *casStore) DelZip(mod coordinates.Module) error {
+	s.log.Tracef("removing module %s", mod)
+
+	start := time.Now()
+	if err := s.removeZip(mod); err != nil {
+		s.emitter.Count("casstore-rmzip-failure", 1)
+		return err
+	}
+
+	s.emitter.GaugeMS("casstore-rmzip-elapsed-ms", start)
+	return nil
+}
+
+func (s *casStore) removeZip(mod coordinates.Module) error {
+	zipFile := s.zipFileOf(mod)
+
+	zipHash, err := s.hashOf(zipFile)
+	if err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if err := os.Remove(zipFile); err != nil {
+		return err
+	}
+
+	// modules are removed from the index before their zip is removed,
+	// so any remaining references are from other modules
+	refs, err := s.index.ZipRefs(zipHash)
+	if err != nil {
+		return err
+	}
+
+	if refs > 0 {
+		s.log.Tracef("keeping blob of %s, still referenced by %d modules", mod, refs)
+		return nil
+	}
+
+	blobFile, err := s.blobFileOf(zipHash)
+	if err != nil {
+		return err
+	}
+
+	s.log.Tracef("removing blob of %s, no longer referenced", mod)
+	if err := os.Remove(blobFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (s *casStore) hashOf(zipFile string) (string, error) {
+	zip, err := openFileZip(zipFile)
+	if err != nil {
+		return "", err
+	}
+	defer ignore.Close(zip)
+
+	return repository.HashOf(zip)
+}
+
+func (s *casStore) PutZip(mod coordinates.Module, archive repository.Archive) error {
+	s.log.Infof("will save %s to disk, %d bytes", mod, archive.Size())
+
+	start := time.Now()
+	if err := s.putZip(mod, archive); err != nil {
+		s.emitter.Count("casstore-putzip-failure", 1)
+		return err
+	}
+
+	s.emitter.GaugeMS("casstore-putzip-elapsed-ms", start)
+	return nil
+}
+
+func (s *casStore) putZip(mod coordinates.Module, archive repository.Archive) error {
+	zipHash, err := repository.HashOf(archive)
+	if err != nil {
+		return err
+	}
+
+	blobFile, err := s.blobFileOf(zipHash)
+	if err != nil {
+		return err
+	}
+
+	zipFile := s.zipFileOf(mod)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, err := os.Stat(zipFile); err == nil {
+		s.log.Warnf("not saving %s because we already have it @ %s", mod, zipFile)
+		return errors.Errorf("already have a copy of %s", mod)
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	if _, err := os.Stat(blobFile); os.IsNotExist(err) {
+		if err := s.writeBlob(blobFile, archive); err != nil {
+			s.log.Errorf("failed to write blob for %s, %v", mod, err)
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else {
+		s.log.Infof("already have a zip identical to %s, linking to it", mod)
+		s.emitter.Count("casstore-putzip-dedup", 1)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(zipFile), directoryPerm); err != nil {
+		return err
+	}
+	return os.Link(blobFile, zipFile)
+}
+
+func (s *casStore) writeBlob(blobFile string, archive repository.Archive) error {
+	if err := os.MkdirAll(filepath.Dir(blobFile), directoryPerm); err != nil {
+		return err
+	}
+
+	reader := io.NewSectionReader(archive, 0, archive.Size())
+	return s.writer.Write(reader, blobFile)
+}
+
+func (s *casStore) zipFileOf(mod coordinates.Module) string {
+	return filepath.Join(
+		s.options.Directory,
+		pathOf(mod),
+		zipName(mod),
+	)
+}
+
+// blobFileOf returns the path of the blob of the zip with zipHash, e.g.
+// .blobs/89/8914546b1...a7c87772.zip, spread over directories by the
+// first byte of the hash to keep directories small.
+func (s *casStore) blobFileOf(zipHash string) (string, error) {
+	if !strings.HasPrefix(zipHash, "h1:") {
+		return "", errors.Errorf("unsupported zip hash %q", zipHash)
+	}
+
+	sum, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(zipHash, "h1:"))
+	if err != nil || len(sum) == 0 {
+		return "", errors.Errorf("malformed zip hash %q", zipHash)
+	}
+
+	name := hex.EncodeToString(sum)
+	return filepath.Join(
+		s.options.Directory,
+		blobsDir,
+		name[:2],
+		name+".zip",
+	), nil
+}
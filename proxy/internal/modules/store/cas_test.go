@@ -0,0 +1,107 @@
+package store
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+func zipOf(t *testing.T, name, content string) repository.Blob {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	f, err := zw.Create(name)
+	require.NoError(t, err)
+	_, err = f.Write([]byte(content))
+	require.NoError(t, err)
+	require.NoError(t, zw.Close())
+	return repository.Blob(buf.Bytes())
+}
+
+func blobsIn(t *testing.T, dir string) int {
+	blobs, err := filepath.Glob(filepath.Join(dir, blobsDir, "*", "*.zip"))
+	require.NoError(t, err)
+	return len(blobs)
+}
+
+func Test_casStore(t *testing.T) {
+	indexDir, index := setupIndex(t)
+	defer cleanupIndex(t, indexDir)
+
+	dataDir, err := ioutil.TempDir("", "cas-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dataDir) }()
+
+	zips := NewContentStore(Options{Directory: dataDir}, index, stats.Discard())
+
+	blob := zipOf(t, "example.com/a@v1.0.0/go.mod", "module example.com/a\n")
+	other := zipOf(t, "example.com/b@v1.0.0/go.mod", "module example.com/b\n")
+	hashes, err := repository.HashesOf(blob, "module example.com/a\n")
+	require.NoError(t, err)
+
+	modA := newMod("example.com/a", "v1.0.0")
+	modB := newMod("example.com/a", "v1.0.1")
+	modC := newMod("example.com/b", "v1.0.0")
+
+	// the same zip stored twice is only written once
+	put := func(id int64, mod coordinates.Module, content repository.Blob) {
+		err := zips.PutZip(mod, content)
+		require.NoError(t, err)
+		h, err := repository.HashesOf(content, "")
+		require.NoError(t, err)
+		err = index.Put(ModuleAddition{Mod: mod, UniqueID: id, Hashes: h})
+		require.NoError(t, err)
+	}
+	put(1, modA, blob)
+	put(2, modB, blob)
+	put(3, modC, other)
+	require.Equal(t, 2, blobsIn(t, dataDir))
+
+	refs, err := index.ZipRefs(hashes.Zip)
+	require.NoError(t, err)
+	require.Equal(t, 2, refs)
+
+	err = zips.PutZip(modA, blob)
+	require.Error(t, err)
+
+	read := func(mod coordinates.Module) repository.Blob {
+		zip, err := zips.OpenZip(mod)
+		require.NoError(t, err)
+		defer zip.Close()
+		content, err := ioutil.ReadAll(zip)
+		require.NoError(t, err)
+		return content
+	}
+	require.Equal(t, blob, read(modA))
+	require.Equal(t, blob, read(modB))
+	require.Equal(t, other, read(modC))
+
+	// still referenced by modB
+	remove := func(mod coordinates.Module) {
+		err := index.Remove(mod)
+		require.NoError(t, err)
+		err = zips.DelZip(mod)
+		require.NoError(t, err)
+	}
+	remove(modA)
+	require.Equal(t, 2, blobsIn(t, dataDir))
+	require.Equal(t, blob, read(modB))
+
+	_, err = zips.OpenZip(modA)
+	require.Error(t, err)
+
+	// no longer referenced at all
+	remove(modB)
+	require.Equal(t, 1, blobsIn(t, dataDir))
+
+	err = zips.DelZip(modB)
+	require.Error(t, err)
+}
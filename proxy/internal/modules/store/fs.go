@@ -1,8 +1,7 @@
 package store
 
 import (
-	"bytes"
-	"io/ioutil"
+	"io"
 	"os"
 	"path/filepath"
 	"time"
@@ -52,26 +51,41 @@ func NewStore(options Options, emitter stats.Sender) ZipStore {
 	}
 }
 
-func (s *fsStore) GetZip(mod coordinates.Module) (repository.Blob, error) {
+func (s *fsStore) OpenZip(mod coordinates.Module) (Zip, error) {
 	s.log.Tracef("retrieving module %s", mod)
 
 	start := time.Now()
-	blob, err := s.getZip(mod)
+	zip, err := s.openZip(mod)
 	if err != nil {
 		s.emitter.Count("fsstore-getzip-failure", 1)
 		return nil, err
 	}
 
 	s.emitter.GaugeMS("fsstore-getzip-elapsed-ms", start)
-	return blob, nil
+	return zip, nil
 }
 
-func (s *fsStore) getZip(mod coordinates.Module) (repository.Blob, error) {
+func (s *fsStore) openZip(mod coordinates.Module) (Zip, error) {
 	zipFile := filepath.Join(
 		s.fullPathOf(mod),
 		zipName(mod),
 	)
-	return ioutil.ReadFile(zipFile)
+	return openFileZip(zipFile)
+}
+
+func openFileZip(zipFile string) (Zip, error) {
+	f, err := os.Open(zipFile)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return &fileZip{File: f, info: info}, nil
 }
 
 func (s *fsStore) DelZip(mod coordinates.Module) error {
@@ -96,11 +110,11 @@ func (s *fsStore) removeZip(mod coordinates.Module) error {
 	return os.Remove(zipFile)
 }
 
-func (s *fsStore) PutZip(mod coordinates.Module, blob repository.Blob) error {
-	s.log.Infof("will save %s to disk, %d bytes", mod, len(blob))
+func (s *fsStore) PutZip(mod coordinates.Module, archive repository.Archive) error {
+	s.log.Infof("will save %s to disk, %d bytes", mod, archive.Size())
 
 	start := time.Now()
-	if err := s.putZip(mod, blob); err != nil {
+	if err := s.putZip(mod, archive); err != nil {
 		s.emitter.Count("fsstore-putzip-failure", 1)
 		return err
 	}
@@ -109,7 +123,7 @@ func (s *fsStore) PutZip(mod coordinates.Module, blob repository.Blob) error {
 	return nil
 }
 
-func (s *fsStore) putZip(mod coordinates.Module, blob repository.Blob) error {
+func (s *fsStore) putZip(mod coordinates.Module, archive repository.Archive) error {
 	exists, err := s.exists(mod)
 	if err != nil {
 		return err
@@ -120,7 +134,7 @@ func (s *fsStore) putZip(mod coordinates.Module, blob repository.Blob) error {
 		return errors.Errorf("already have a copy of %s", mod)
 	}
 
-	if err := s.safeWriteZip(mod, blob); err != nil {
+	if err := s.safeWriteZip(mod, archive); err != nil {
 		s.log.Errorf("failed to write zip for %s, %v", mod, err)
 		return err
 	}
@@ -128,7 +142,7 @@ func (s *fsStore) putZip(mod coordinates.Module, blob repository.Blob) error {
 	return nil
 }
 
-func (s *fsStore) safeWriteZip(mod coordinates.Module, blob repository.Blob) error {
+func (s *fsStore) safeWriteZip(mod coordinates.Module, archive repository.Archive) error {
 	modPath := s.fullPathOf(mod)
 	s.log.Tracef("writing module zip into path: %s", modPath)
 
@@ -138,7 +152,7 @@ func (s *fsStore) safeWriteZip(mod coordinates.Module, blob repository.Blob) err
 	}
 
 	zipFile := filepath.Join(modPath, zipName(mod))
-	reader := bytes.NewReader(blob)
+	reader := io.NewSectionReader(archive, 0, archive.Size())
 	return s.writer.Write(reader, zipFile)
 }
 
@@ -0,0 +1,391 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/boltdb/bolt"
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// FsckReport is what a Checker found, and what it repaired.
+type FsckReport struct {
+	// Checked is the number of zips in the data directory.
+	Checked int
+
+	// Rebuilt are modules whose index entries were missing in part or in
+	// whole, and were derived again from their zip.
+	Rebuilt []coordinates.Module
+
+	// Backfill are modules stored before hashes were being recorded. They
+	// are not a problem, the integrity check records their hashes once it
+	// has verified them against the checksum database.
+	Backfill []coordinates.Module
+
+	// OrphanZips are modules with a zip but no index entry, and no known
+	// serial ID to rebuild the index entry with.
+	OrphanZips []coordinates.Module
+
+	// Unregistered are modules with a zip but no index entry, which could
+	// not be told apart from OrphanZips because the modules registered with
+	// the registry were not known.
+	Unregistered []coordinates.Module
+
+	// OrphanEntries are modules with an index entry but no zip. If repaired,
+	// the entry is removed so that the module is downloaded again.
+	OrphanEntries []coordinates.Module
+
+	// Mismatched are modules whose zip does not hash to the hashes
+	// recorded in the index. These are never repaired, since there is
+	// no telling which of the two is right.
+	Mismatched []coordinates.Module
+
+	// Strays are leftover parts of index entries of modules which are
+	// not otherwise in the index. If repaired, they are removed.
+	Strays []coordinates.Module
+
+	// Repaired is whether the problems found were repaired.
+	Repaired bool
+}
+
+// Problems is the number of problems which remain.
+func (r FsckReport) Problems() int {
+	n := len(r.OrphanZips) + len(r.Mismatched)
+	if !r.Repaired {
+		n += len(r.Rebuilt) + len(r.OrphanEntries) + len(r.Strays)
+	}
+	return n
+}
+
+func (r FsckReport) String() string {
+	return fmt.Sprintf(
+		"checked %d zips: %d rebuilt, %d to backfill, %d orphan zips, %d unregistered, %d orphan entries, %d mismatched, %d strays (repaired: %t)",
+		r.Checked,
+		len(r.Rebuilt),
+		len(r.Backfill),
+		len(r.OrphanZips),
+		len(r.Unregistered),
+		len(r.OrphanEntries),
+		len(r.Mismatched),
+		len(r.Strays),
+		r.Repaired,
+	)
+}
+
+// A Checker checks the consistency of a boltdb index and a data directory of
+// zips, i.e. the layout of the fsStore or the casStore. Index entries are
+// derived from their zip the same way the downloader would have done when
+// storing the module, except for the serial ID, which only the registry knows.
+type Checker struct {
+	index   *boltIndex
+	dir     string
+	emitter stats.Sender
+	log     loggy.Logger
+}
+
+// NewChecker creates a Checker of index and the zips in dir. Only the boltdb
+// index can be checked, the index of the mysqlStore is kept consistent with
+// its zips by the database.
+func NewChecker(index Index, dir string, emitter stats.Sender) (*Checker, error) {
+	bolted, ok := index.(*boltIndex)
+	if !ok {
+		return nil, errors.New("only a boltdb index can be checked")
+	}
+
+	if dir == "" {
+		return nil, errors.New("no data directory to check")
+	}
+
+	return &Checker{
+		index:   bolted,
+		dir:     dir,
+		emitter: emitter,
+		log:     loggy.New("fsck"),
+	}, nil
+}
+
+// entry is which parts of an index entry exist.
+type entry struct {
+	id     int64
+	hasID  bool
+	hasMod bool
+	hasRev bool
+	info   repository.RevInfo
+	hashes repository.Hashes
+}
+
+// Check walks the data directory and compares every zip to its index entry,
+// then looks for index entries without a zip. If repair is set, what can be
+// repaired is repaired. The serial IDs in registered are used to rebuild the
+// entries of zips which are missing from the index entirely. If registered is
+// nil, i.e. the registry could not be asked, such zips are only reported as
+// Unregistered, since there is no telling whether they are orphans.
+func (c *Checker) Check(repair bool, registered []coordinates.SerialModule) (FsckReport, error) {
+	report := FsckReport{Repaired: repair}
+
+	var ids map[coordinates.Module]int64
+	if registered != nil {
+		ids = make(map[coordinates.Module]int64, len(registered))
+		for _, mod := range registered {
+			ids[mod.Module] = mod.SerialID
+		}
+	}
+
+	zips, err := c.walk()
+	if err != nil {
+		return report, errors.Wrap(err, "failed to walk data directory")
+	}
+	report.Checked = len(zips)
+
+	seen := make(map[coordinates.Module]bool, len(zips))
+	for _, mod := range zips {
+		seen[mod] = true
+		if err := c.checkZip(mod, ids, repair, &report); err != nil {
+			return report, errors.Wrapf(err, "failed to check %s", mod)
+		}
+	}
+
+	if err := c.checkEntries(seen, repair, &report); err != nil {
+		return report, errors.Wrap(err, "failed to check index entries")
+	}
+
+	c.emitter.Gauge("fsck-zips-checked", report.Checked)
+	c.emitter.Gauge("fsck-problems", report.Problems())
+	return report, nil
+}
+
+// walk returns the module of every zip in the data directory.
+func (c *Checker) walk() ([]coordinates.Module, error) {
+	var mods []coordinates.Module
+
+	err := filepath.Walk(c.dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() {
+			// the blobs of the casStore are linked to by the modules
+			if path == filepath.Join(c.dir, blobsDir) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if !strings.HasSuffix(info.Name(), ".zip") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(c.dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		mods = append(mods, coordinates.Module{
+			Source:  filepath.ToSlash(rel),
+			Version: strings.TrimSuffix(info.Name(), ".zip"),
+		})
+		return nil
+	})
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return mods, err
+}
+
+func (c *Checker) checkZip(mod coordinates.Module, ids map[coordinates.Module]int64, repair bool, report *FsckReport) error {
+	existing, err := c.entryOf(mod)
+	if err != nil {
+		return err
+	}
+
+	id := existing.id
+	if !existing.hasID {
+		if ids == nil {
+			c.log.Warnf("zip of %s is not in the index, and the registered modules are unknown", mod)
+			report.Unregistered = append(report.Unregistered, mod)
+			return nil
+		}
+
+		registeredID, exists := ids[mod]
+		if !exists {
+			c.log.Warnf("zip of %s is not in the index, and is not registered", mod)
+			report.OrphanZips = append(report.OrphanZips, mod)
+			return nil
+		}
+		id = registeredID
+	}
+
+	modFile, hashes, err := c.derive(mod)
+	if err != nil {
+		// a zip which cannot be read cannot be rebuilt either
+		c.log.Warnf("zip of %s is unreadable, %v", mod, err)
+		report.Mismatched = append(report.Mismatched, mod)
+		return nil
+	}
+
+	recorded := existing.hashes
+	if recorded.Zip != "" && recorded != hashes {
+		c.log.Warnf("zip of %s hashes to %s, index has %s", mod, hashes.Zip, recorded.Zip)
+		report.Mismatched = append(report.Mismatched, mod)
+		return nil
+	}
+
+	if existing.hasID && existing.hasMod && existing.hasRev {
+		if recorded.Zip == "" {
+			// left to the integrity check, which verifies them first
+			c.log.Tracef("index entry of %s has no hashes yet", mod)
+			report.Backfill = append(report.Backfill, mod)
+		}
+		return nil // all good
+	}
+
+	c.log.Warnf("index entry of %s is incomplete (id: %t, go.mod: %t, info: %t, hashes: %t)",
+		mod, existing.hasID, existing.hasMod, existing.hasRev, recorded.Zip != "")
+	report.Rebuilt = append(report.Rebuilt, mod)
+
+	if !repair {
+		return nil
+	}
+
+	c.log.Infof("rebuilding index entry of %s with serial ID %d", mod, id)
+	return c.index.Put(ModuleAddition{
+		Mod:      mod,
+		UniqueID: id,
+		ModFile:  modFile,
+		Hashes:   hashes,
+		Info:     existing.info, // keep the commit, if it was known
+	})
+}
+
+// derive returns the go.mod file and hashes of the zip of mod, the same
+// way the downloader derives them before storing a module.
+func (c *Checker) derive(mod coordinates.Module) (string, repository.Hashes, error) {
+	zip, err := openFileZip(filepath.Join(c.dir, pathOf(mod), zipName(mod)))
+	if err != nil {
+		return "", repository.Hashes{}, err
+	}
+	defer ignore.Close(zip)
+
+	modFile, exists, err := repository.ModFileOf(zip)
+	if err != nil {
+		return "", repository.Hashes{}, err
+	}
+	if !exists {
+		modFile = repository.EmptyModFile(mod.Source)
+	}
+
+	hashes, err := repository.HashesOf(zip, modFile)
+	return modFile, hashes, err
+}
+
+func (c *Checker) entryOf(mod coordinates.Module) (entry, error) {
+	key := mod.Bytes()
+	var e entry
+
+	err := c.index.db.View(func(tx *bolt.Tx) error {
+		if bs := tx.Bucket(idBktLbl).Get(key); bs != nil {
+			e.hasID = true
+			e.id = decodeID(bs)
+		}
+		e.hasMod = tx.Bucket(modsBktLbl).Get(key) != nil
+		if bs := tx.Bucket(infoBktLbl).Get(key); bs != nil {
+			e.hasRev = true
+			if err := json.Unmarshal(bs, &e.info); err != nil {
+				return err
+			}
+		}
+
+		bs := tx.Bucket(sumsBktLbl).Get(key)
+		if bs == nil {
+			return nil
+		}
+		return json.Unmarshal(bs, &e.hashes)
+	})
+
+	return e, err
+}
+
+// checkEntries looks for index entries of modules without a zip, and for
+// parts of index entries of modules which have no serial ID.
+func (c *Checker) checkEntries(seen map[coordinates.Module]bool, repair bool, report *FsckReport) error {
+	stored, err := c.index.List()
+	if err != nil {
+		return err
+	}
+
+	for _, mod := range stored {
+		if seen[mod.Module] {
+			continue
+		}
+
+		c.log.Warnf("index entry of %s has no zip", mod)
+		report.OrphanEntries = append(report.OrphanEntries, mod.Module)
+
+		if repair {
+			// the registry lists it, and the index no longer does,
+			// so the module will be downloaded again
+			if err := c.index.Remove(mod.Module); err != nil {
+				return err
+			}
+		}
+	}
+
+	strays, err := c.strays()
+	if err != nil {
+		return err
+	}
+
+	for _, mod := range strays {
+		c.log.Warnf("index has parts of an entry of %s, without a serial ID", mod)
+		report.Strays = append(report.Strays, mod)
+
+		if repair {
+			if err := c.index.Remove(mod); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// strays returns the modules which are in any of the buckets making up
+// an index entry, but not in the ids bucket, which marks a complete entry.
+func (c *Checker) strays() ([]coordinates.Module, error) {
+	var strays []coordinates.Module
+	found := make(map[string]bool)
+
+	err := c.index.db.View(func(tx *bolt.Tx) error {
+		idBkt := tx.Bucket(idBktLbl)
+		for _, lbl := range [][]byte{modsBktLbl, infoBktLbl, sumsBktLbl} {
+			if err := tx.Bucket(lbl).ForEach(func(k, _ []byte) error {
+				if idBkt.Get(k) != nil || found[string(k)] {
+					return nil
+				}
+				found[string(k)] = true
+				source, version := splitOnAT(k)
+				strays = append(strays, coordinates.Module{
+					Source:  source,
+					Version: version,
+				})
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return strays, err
+}
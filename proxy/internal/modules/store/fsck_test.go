@@ -0,0 +1,156 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/boltdb/bolt"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+func Test_Checker(t *testing.T) {
+	indexDir, index := setupIndex(t)
+	defer cleanupIndex(t, indexDir)
+
+	dataDir, err := ioutil.TempDir("", "fsck-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dataDir) }()
+
+	zips := NewStore(Options{Directory: dataDir}, stats.Discard())
+
+	storeZip := func(mod coordinates.Module) (string, repository.Hashes) {
+		modFile := "module " + mod.Source + "\n"
+		blob := zipOf(t, mod.Source+"@"+mod.Version+"/go.mod", modFile)
+		err := zips.PutZip(mod, blob)
+		require.NoError(t, err)
+		hashes, err := repository.HashesOf(blob, modFile)
+		require.NoError(t, err)
+		return modFile, hashes
+	}
+
+	put := func(id int64, mod coordinates.Module, modFile string, hashes repository.Hashes) {
+		err := index.Put(ModuleAddition{Mod: mod, UniqueID: id, ModFile: modFile, Hashes: hashes})
+		require.NoError(t, err)
+	}
+
+	bolted := index.(*boltIndex)
+	drop := func(lbl []byte, mod coordinates.Module) {
+		err := bolted.db.Update(func(tx *bolt.Tx) error {
+			return tx.Bucket(lbl).Delete(mod.Bytes())
+		})
+		require.NoError(t, err)
+	}
+
+	// complete
+	modA := newMod("example.com/a", "v1.0.0")
+	modFile, hashes := storeZip(modA)
+	put(1, modA, modFile, hashes)
+
+	// index entry lost its go.mod and .info
+	modB := newMod("example.com/b", "v1.0.0")
+	modFile, hashes = storeZip(modB)
+	put(2, modB, modFile, hashes)
+	drop(modsBktLbl, modB)
+	drop(infoBktLbl, modB)
+
+	// zip stored, but crashed before the index entry was put
+	modC := newMod("example.com/c", "v1.0.0")
+	storeZip(modC)
+
+	// zip stored, but no longer registered
+	modD := newMod("example.com/d", "v1.0.0")
+	storeZip(modD)
+
+	// index entry, but the zip is gone
+	modE := newMod("example.com/e", "v1.0.0")
+	put(5, modE, "module example.com/e\n", repository.Hashes{})
+
+	// only the serial ID was lost
+	modF := newMod("example.com/f", "v1.0.0")
+	modFile, hashes = storeZip(modF)
+	put(6, modF, modFile, hashes)
+	drop(idBktLbl, modF)
+
+	// zip does not match the index
+	modG := newMod("example.com/g", "v1.0.0")
+	modFile, _ = storeZip(modG)
+	_, hashes = storeZip(newMod("example.com/other", "v1.0.0"))
+	put(7, modG, modFile, hashes)
+	err = zips.DelZip(newMod("example.com/other", "v1.0.0"))
+	require.NoError(t, err)
+
+	// stored before hashes were being recorded
+	modH := newMod("example.com/h", "v1.0.0")
+	modFile, _ = storeZip(modH)
+	put(8, modH, modFile, repository.Hashes{})
+
+	checker, err := NewChecker(index, dataDir, stats.Discard())
+	require.NoError(t, err)
+
+	// registry could not be asked, so zips missing from the index are
+	// not reported as orphans
+	report, err := checker.Check(false, nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []coordinates.Module{modC, modD, modF}, report.Unregistered)
+	require.Empty(t, report.OrphanZips)
+	require.Equal(t, []coordinates.Module{modB}, report.Rebuilt)
+	require.Equal(t, []coordinates.Module{modH}, report.Backfill)
+
+	registered := []coordinates.SerialModule{
+		{Module: modC, SerialID: 3},
+	}
+
+	// only reports
+	report, err = checker.Check(false, registered)
+	require.NoError(t, err)
+	require.Equal(t, 7, report.Checked)
+	require.ElementsMatch(t, []coordinates.Module{modB, modC}, report.Rebuilt)
+	require.ElementsMatch(t, []coordinates.Module{modD, modF}, report.OrphanZips)
+	require.Equal(t, []coordinates.Module{modE}, report.OrphanEntries)
+	require.Equal(t, []coordinates.Module{modG}, report.Mismatched)
+	require.Equal(t, []coordinates.Module{modF}, report.Strays)
+	require.Equal(t, []coordinates.Module{modH}, report.Backfill)
+	require.Empty(t, report.Unregistered)
+	require.Equal(t, 7, report.Problems())
+
+	// nothing was changed
+	exists, _, err := index.Contains(modC)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// repairs
+	report, err = checker.Check(true, registered)
+	require.NoError(t, err)
+	require.Equal(t, 3, report.Problems())
+
+	content, err := index.Mod(modB)
+	require.NoError(t, err)
+	require.Equal(t, "module example.com/b\n", content)
+
+	info, err := index.Info(modB)
+	require.NoError(t, err)
+	require.Equal(t, "v1.0.0", info.Version)
+
+	exists, id, err := index.Contains(modC)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, int64(3), id)
+
+	exists, _, err = index.Contains(modE)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// what can be repaired has been repaired
+	report, err = checker.Check(false, registered)
+	require.NoError(t, err)
+	require.Empty(t, report.Rebuilt)
+	require.Empty(t, report.OrphanEntries)
+	require.Empty(t, report.Strays)
+	require.ElementsMatch(t, []coordinates.Module{modD, modF}, report.OrphanZips)
+	require.Equal(t, []coordinates.Module{modG}, report.Mismatched)
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/binary"
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -13,12 +14,15 @@ import (
 	"github.com/boltdb/bolt"
 
 	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb/tlog"
 
 	"gophers.dev/pkgs/loggy"
 	"gophers.dev/pkgs/semantic"
 
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
 )
 
 // Ranges is an alias of coordinates.RangeIDs for brevity.
@@ -35,6 +39,8 @@ type Range = coordinates.RangeID
 //  - boolean whether a module@version exists in the store
 //  - list of versions of a given module that exist in the store
 //  - list of version intervals for all modules in the store
+//  - h1 hashes of the zip and go.mod file of a module@version
+//  - number of module@versions with a given zip hash
 //
 // The real implementation is an index backed by boltdb, so
 // we get better performance than keeping actual files on disk.
@@ -47,13 +53,19 @@ type Index interface {
 	Remove(coordinates.Module) error
 	Put(ModuleAddition) error
 	IDs() (Ranges, error)
+	List() ([]coordinates.SerialModule, error)
 	Summary() (int, int, error)
+	Hashes(coordinates.Module) (repository.Hashes, error)
+	UpdateHashes(coordinates.Module, repository.Hashes) error
+	ZipRefs(zipHash string) (int, error)
 }
 
 type ModuleAddition struct {
 	Mod      coordinates.Module
 	UniqueID int64
 	ModFile  string
+	Hashes   repository.Hashes
+	Info     repository.RevInfo // of the commit, if known
 }
 
 func (m ModuleAddition) String() string {
@@ -101,9 +113,22 @@ func NewIndex(options IndexOptions) (Index, error) {
 }
 
 var (
-	modsBktLbl = []byte("mods")
-	infoBktLbl = []byte("info")
-	idBktLbl   = []byte("ids")
+	modsBktLbl     = []byte("mods")
+	infoBktLbl     = []byte("info")
+	idBktLbl       = []byte("ids")
+	problemsBktLbl = []byte("problems")
+	resolvedBktLbl = []byte("resolved")
+	recordsBktLbl  = []byte("sumdb-records")
+	lookupBktLbl   = []byte("sumdb-lookup")
+	hashesBktLbl   = []byte("sumdb-hashes")
+	sumsBktLbl     = []byte("sums")
+	zipRefsBktLbl  = []byte("zip-refs")
+	accessBktLbl   = []byte("accesses")
+	journalBktLbl  = []byte("journal")
+
+	// unknownZipRef counts the modules whose zip hash is not known yet,
+	// which is never a zip hash itself, since those begin with h1:
+	unknownZipRef = []byte("unknown")
 )
 
 func setupDirs(indexPath string) error {
@@ -123,10 +148,70 @@ func initDB(db *bolt.DB) error {
 		if _, err := tx.CreateBucketIfNotExists([]byte(idBktLbl)); err != nil {
 			return err
 		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(problemsBktLbl)); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(resolvedBktLbl)); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(recordsBktLbl)); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(lookupBktLbl)); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(hashesBktLbl)); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(sumsBktLbl)); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(accessBktLbl)); err != nil {
+			return err
+		}
+
+		if _, err := tx.CreateBucketIfNotExists([]byte(journalBktLbl)); err != nil {
+			return err
+		}
+
+		if tx.Bucket(zipRefsBktLbl) == nil {
+			if _, err := tx.CreateBucket(zipRefsBktLbl); err != nil {
+				return err
+			}
+			// count the references of modules stored before counting began
+			if err := countZipRefs(tx); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 }
 
+func countZipRefs(tx *bolt.Tx) error {
+	sumsBkt := tx.Bucket(sumsBktLbl)
+	return tx.Bucket(idBktLbl).ForEach(func(k, _ []byte) error {
+		var hashes repository.Hashes
+		if bs := sumsBkt.Get(k); bs != nil {
+			if err := json.Unmarshal(bs, &hashes); err != nil {
+				return err
+			}
+		}
+		return addZipRef(tx, hashes.Zip, 1)
+	})
+}
+
+var _ problems.Store = (*boltIndex)(nil)
+var _ sumdb.Store = (*boltIndex)(nil)
+var _ AccessLog = (*boltIndex)(nil)
+var _ Journal = (*boltIndex)(nil)
+
 type boltIndex struct {
 	options IndexOptions
 	db      *bolt.DB
@@ -250,7 +335,13 @@ func (i *boltIndex) Remove(mod coordinates.Module) error {
 			return err
 		}
 
-		// 3) remove from ids bucket
+		// 3) remove from sums bucket, while the id still says whether
+		// the module is stored at all
+		if err := i.removeFromSums(key, tx); err != nil {
+			return err
+		}
+
+		// 4) remove from ids bucket
 		if err := i.removeFromIDs(key, tx); err != nil {
 			return err
 		}
@@ -274,10 +365,18 @@ func (i *boltIndex) removeFromIDs(key []byte, tx *bolt.Tx) error {
 	return idBkt.Delete(key)
 }
 
+func (i *boltIndex) removeFromSums(key []byte, tx *bolt.Tx) error {
+	if err := releaseZipRef(tx, key); err != nil {
+		return err
+	}
+	sumsBkt := tx.Bucket(sumsBktLbl)
+	return sumsBkt.Delete(key)
+}
+
 func (i *boltIndex) Put(add ModuleAddition) error {
 	key := add.Mod.Bytes()
 
-	// update the three buckets with the new information
+	// update the four buckets with the new information
 	return i.db.Update(func(tx *bolt.Tx) error {
 		// insert the .mod file
 		{
@@ -290,13 +389,21 @@ func (i *boltIndex) Put(add ModuleAddition) error {
 
 		// insert the .info file
 		{
-			infoFile := newRevInfo(add.Mod).Bytes()
+			infoFile := newRevInfo(add).Bytes()
 			infoBkt := tx.Bucket(infoBktLbl)
 			if err := infoBkt.Put(key, infoFile); err != nil {
 				return err
 			}
 		}
 
+		// insert the hashes, before the uniqueID says whether the module
+		// was already stored
+		{
+			if err := putSums(tx, key, add.Hashes); err != nil {
+				return err
+			}
+		}
+
 		// insert the uniqueID
 		{
 			encodedID := encodeID(add.UniqueID)
@@ -310,6 +417,64 @@ func (i *boltIndex) Put(add ModuleAddition) error {
 	})
 }
 
+func putSums(tx *bolt.Tx, key []byte, hashes repository.Hashes) error {
+	bs, err := json.Marshal(hashes)
+	if err != nil {
+		return err
+	}
+
+	// the zip previously recorded for key (if any) is no longer referenced
+	if err := releaseZipRef(tx, key); err != nil {
+		return err
+	}
+	if err := addZipRef(tx, hashes.Zip, 1); err != nil {
+		return err
+	}
+
+	sumsBkt := tx.Bucket(sumsBktLbl)
+	return sumsBkt.Put(key, bs)
+}
+
+// releaseZipRef drops the reference to the zip hash recorded for key.
+func releaseZipRef(tx *bolt.Tx, key []byte) error {
+	bs := tx.Bucket(sumsBktLbl).Get(key)
+	if bs == nil {
+		// a module stored before hashes were recorded has none at all,
+		// and references an unknown zip
+		if tx.Bucket(idBktLbl).Get(key) == nil {
+			return nil
+		}
+		return addZipRef(tx, "", -1)
+	}
+
+	var hashes repository.Hashes
+	if err := json.Unmarshal(bs, &hashes); err != nil {
+		return err
+	}
+	return addZipRef(tx, hashes.Zip, -1)
+}
+
+// addZipRef adjusts the number of references to zipHash by delta, keeping
+// only hashes which are referenced at all.
+func addZipRef(tx *bolt.Tx, zipHash string, delta int64) error {
+	key := []byte(zipHash)
+	if zipHash == "" {
+		key = unknownZipRef
+	}
+	refsBkt := tx.Bucket(zipRefsBktLbl)
+
+	var refs int64
+	if bs := refsBkt.Get(key); bs != nil {
+		refs = decodeID(bs)
+	}
+
+	refs += delta
+	if refs <= 0 {
+		return refsBkt.Delete(key)
+	}
+	return refsBkt.Put(key, encodeID(refs))
+}
+
 func encodeID(id int64) []byte {
 	var encodedID = make([]byte, 8) // 8 bytes in uint64
 	binary.BigEndian.PutUint64(encodedID, uint64(id))
@@ -334,11 +499,13 @@ func (i *boltIndex) UpdateID(mod coordinates.SerialModule) error {
 	})
 }
 
-func newRevInfo(mod coordinates.Module) repository.RevInfo {
-	// todo: ... what goes in the revinfo?
-	return repository.RevInfo{
-		Version: mod.Version,
+// newRevInfo returns the commit information of add, which is whatever could
+// be found out about its commit when it was downloaded, if anything.
+func newRevInfo(add ModuleAddition) repository.RevInfo {
+	if add.Info.Version != "" {
+		return add.Info
 	}
+	return repository.RevInfoOf(add.Mod.Version)
 }
 
 func (i *boltIndex) IDs() (Ranges, error) {
@@ -358,6 +525,79 @@ func (i *boltIndex) IDs() (Ranges, error) {
 	return ranges(ids), err
 }
 
+func (i *boltIndex) List() ([]coordinates.SerialModule, error) {
+	var mods []coordinates.SerialModule
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		idBkt := tx.Bucket(idBktLbl)
+		return idBkt.ForEach(func(k, v []byte) error {
+			source, version := splitOnAT(k)
+			mods = append(mods, coordinates.SerialModule{
+				Module: coordinates.Module{
+					Source:  source,
+					Version: version,
+				},
+				SerialID: decodeID(v),
+			})
+			return nil
+		})
+	})
+
+	return mods, err
+}
+
+// Hashes returns the hashes recorded for mod, which are empty if the
+// module was stored before hashes were being recorded.
+func (i *boltIndex) Hashes(mod coordinates.Module) (repository.Hashes, error) {
+	key := mod.Bytes()
+	var hashes repository.Hashes
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		idBkt := tx.Bucket(idBktLbl)
+		if idBkt.Get(key) == nil {
+			return errors.New("module not in index")
+		}
+
+		sumsBkt := tx.Bucket(sumsBktLbl)
+		bs := sumsBkt.Get(key)
+		if bs == nil {
+			return nil
+		}
+		return json.Unmarshal(bs, &hashes)
+	})
+
+	return hashes, err
+}
+
+func (i *boltIndex) UpdateHashes(mod coordinates.Module, hashes repository.Hashes) error {
+	key := mod.Bytes()
+	return i.db.Update(func(tx *bolt.Tx) error {
+		idBkt := tx.Bucket(idBktLbl)
+		if idBkt.Get(key) == nil {
+			return errors.New("module not in index")
+		}
+		return putSums(tx, key, hashes)
+	})
+}
+
+// ZipRefs returns the number of modules in the index whose recorded zip
+// hash is zipHash, or whose zip hash is not known yet.
+func (i *boltIndex) ZipRefs(zipHash string) (int, error) {
+	var refs int64
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		refsBkt := tx.Bucket(zipRefsBktLbl)
+		for _, key := range [][]byte{[]byte(zipHash), unknownZipRef} {
+			if bs := refsBkt.Get(key); bs != nil {
+				refs += decodeID(bs)
+			}
+		}
+		return nil
+	})
+
+	return int(refs), err
+}
+
 func ranges(ids []int64) Ranges {
 	var cuts Ranges
 
@@ -425,3 +665,326 @@ func count(m map[string]int) (int, int) {
 	}
 	return len(m), count
 }
+
+func (i *boltIndex) PutProblem(problem problems.Problem) error {
+	bs, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	return i.db.Update(func(tx *bolt.Tx) error {
+		problemsBkt := tx.Bucket(problemsBktLbl)
+		return problemsBkt.Put(problem.Module.Bytes(), bs)
+	})
+}
+
+func (i *boltIndex) DeleteProblem(mod coordinates.Module) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		problemsBkt := tx.Bucket(problemsBktLbl)
+		return problemsBkt.Delete(mod.Bytes())
+	})
+}
+
+func (i *boltIndex) ListProblems() ([]problems.Problem, error) {
+	var list []problems.Problem
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		problemsBkt := tx.Bucket(problemsBktLbl)
+		return problemsBkt.ForEach(func(_, v []byte) error {
+			var problem problems.Problem
+			if err := json.Unmarshal(v, &problem); err != nil {
+				return err
+			}
+			list = append(list, problem)
+			return nil
+		})
+	})
+
+	return list, err
+}
+
+// a module may be resolved more than once, so the key of a resolved problem
+// also includes the time it was resolved
+func resolvedKey(problem problems.Problem) []byte {
+	key := problem.Module.Bytes()
+	key = append(key, '@')
+	return append(key, encodeID(problem.ResolvedAt().UnixNano())...)
+}
+
+func (i *boltIndex) PutResolved(problem problems.Problem) error {
+	bs, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	return i.db.Update(func(tx *bolt.Tx) error {
+		resolvedBkt := tx.Bucket(resolvedBktLbl)
+		return resolvedBkt.Put(resolvedKey(problem), bs)
+	})
+}
+
+func (i *boltIndex) DeleteResolved(before time.Time) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		resolvedBkt := tx.Bucket(resolvedBktLbl)
+
+		var expired [][]byte
+		if err := resolvedBkt.ForEach(func(k, v []byte) error {
+			var problem problems.Problem
+			if err := json.Unmarshal(v, &problem); err != nil {
+				return err
+			}
+			if problem.ResolvedAt().Before(before) {
+				expired = append(expired, k)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		for _, k := range expired {
+			if err := resolvedBkt.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (i *boltIndex) ListResolved() ([]problems.Problem, error) {
+	var list []problems.Problem
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		resolvedBkt := tx.Bucket(resolvedBktLbl)
+		return resolvedBkt.ForEach(func(_, v []byte) error {
+			var problem problems.Problem
+			if err := json.Unmarshal(v, &problem); err != nil {
+				return err
+			}
+			list = append(list, problem)
+			return nil
+		})
+	})
+
+	return list, err
+}
+
+func (i *boltIndex) AddAccesses(added []Access) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		accessBkt := tx.Bucket(accessBktLbl)
+
+		for _, add := range added {
+			access := Access{Module: add.Module}
+			if bs := accessBkt.Get(add.Module.Bytes()); bs != nil {
+				if err := json.Unmarshal(bs, &access); err != nil {
+					return err
+				}
+			}
+
+			if add.Last.After(access.Last) {
+				access.Last = add.Last
+			}
+			access.Count += add.Count
+			if err := putAccess(tx, access); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (i *boltIndex) PutAccess(access Access) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		return putAccess(tx, access)
+	})
+}
+
+func putAccess(tx *bolt.Tx, access Access) error {
+	bs, err := json.Marshal(access)
+	if err != nil {
+		return err
+	}
+	accessBkt := tx.Bucket(accessBktLbl)
+	return accessBkt.Put(access.Module.Bytes(), bs)
+}
+
+func (i *boltIndex) RemoveAccess(mod coordinates.Module) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		accessBkt := tx.Bucket(accessBktLbl)
+		return accessBkt.Delete(mod.Bytes())
+	})
+}
+
+func (i *boltIndex) Accesses() ([]Access, error) {
+	var list []Access
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		accessBkt := tx.Bucket(accessBktLbl)
+		return accessBkt.ForEach(func(_, v []byte) error {
+			var access Access
+			if err := json.Unmarshal(v, &access); err != nil {
+				return err
+			}
+			list = append(list, access)
+			return nil
+		})
+	})
+
+	return list, err
+}
+
+func (i *boltIndex) BeginChange(change Change) error {
+	bs, err := json.Marshal(change)
+	if err != nil {
+		return err
+	}
+
+	return i.db.Update(func(tx *bolt.Tx) error {
+		journalBkt := tx.Bucket(journalBktLbl)
+		return journalBkt.Put(change.Module.Bytes(), bs)
+	})
+}
+
+func (i *boltIndex) EndChange(mod coordinates.Module) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		journalBkt := tx.Bucket(journalBktLbl)
+		return journalBkt.Delete(mod.Bytes())
+	})
+}
+
+func (i *boltIndex) Changes() ([]Change, error) {
+	var list []Change
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		journalBkt := tx.Bucket(journalBktLbl)
+		return journalBkt.ForEach(func(_, v []byte) error {
+			var change Change
+			if err := json.Unmarshal(v, &change); err != nil {
+				return err
+			}
+			list = append(list, change)
+			return nil
+		})
+	})
+
+	return list, err
+}
+
+// the sumdb.Server only reports a missing record or hash as not found (rather
+// than as an internal error) if the error is recognized by os.IsNotExist
+func missing(kind string, id int64) error {
+	return &os.PathError{
+		Op:   "read",
+		Path: fmt.Sprintf("%s %d", kind, id),
+		Err:  os.ErrNotExist,
+	}
+}
+
+func (i *boltIndex) AppendRecord(id int64, mod coordinates.Module, data []byte, hashes []tlog.Hash) error {
+	return i.db.Update(func(tx *bolt.Tx) error {
+		recordsBkt := tx.Bucket(recordsBktLbl)
+		lookupBkt := tx.Bucket(lookupBktLbl)
+		hashesBkt := tx.Bucket(hashesBktLbl)
+
+		// the log must only ever be appended to
+		if n := countRecords(recordsBkt); id != n {
+			return &sumdb.Conflict{ID: id, Records: n}
+		}
+
+		if lookupBkt.Get(mod.Bytes()) != nil {
+			return errors.Errorf("log already contains a record of %s", mod)
+		}
+
+		if err := recordsBkt.Put(encodeID(id), data); err != nil {
+			return err
+		}
+
+		if err := lookupBkt.Put(mod.Bytes(), encodeID(id)); err != nil {
+			return err
+		}
+
+		index := tlog.StoredHashIndex(0, id)
+		for _, hash := range hashes {
+			if err := hashesBkt.Put(encodeID(index), hash[:]); err != nil {
+				return err
+			}
+			index++
+		}
+		return nil
+	})
+}
+
+func (i *boltIndex) LookupRecord(mod coordinates.Module) (int64, bool, error) {
+	var id int64
+	var exists bool
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		lookupBkt := tx.Bucket(lookupBktLbl)
+		if bs := lookupBkt.Get(mod.Bytes()); bs != nil {
+			id = decodeID(bs)
+			exists = true
+		}
+		return nil
+	})
+
+	return id, exists, err
+}
+
+func (i *boltIndex) ReadRecords(id, n int64) ([][]byte, error) {
+	records := make([][]byte, 0, n)
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		recordsBkt := tx.Bucket(recordsBktLbl)
+		for j := id; j < id+n; j++ {
+			bs := recordsBkt.Get(encodeID(j))
+			if bs == nil {
+				return missing("record", j)
+			}
+			// bolt values are only valid during the transaction
+			records = append(records, append([]byte(nil), bs...))
+		}
+		return nil
+	})
+
+	return records, err
+}
+
+func (i *boltIndex) CountRecords() (int64, error) {
+	var n int64
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		n = countRecords(tx.Bucket(recordsBktLbl))
+		return nil
+	})
+
+	return n, err
+}
+
+// records are keyed by their id, so the count is one more than the last id
+func countRecords(recordsBkt *bolt.Bucket) int64 {
+	last, _ := recordsBkt.Cursor().Last()
+	if last == nil {
+		return 0
+	}
+	return decodeID(last) + 1
+}
+
+func (i *boltIndex) ReadHashes(indexes []int64) ([]tlog.Hash, error) {
+	hashes := make([]tlog.Hash, 0, len(indexes))
+
+	err := i.db.View(func(tx *bolt.Tx) error {
+		hashesBkt := tx.Bucket(hashesBktLbl)
+		for _, index := range indexes {
+			bs := hashesBkt.Get(encodeID(index))
+			if len(bs) != tlog.HashSize {
+				return missing("hash", index)
+			}
+			var hash tlog.Hash
+			copy(hash[:], bs)
+			hashes = append(hashes, hash)
+		}
+		return nil
+	})
+
+	return hashes, err
+}
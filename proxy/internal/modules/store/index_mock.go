@@ -22,6 +22,12 @@ type IndexMock struct {
 	beforeContainsCounter uint64
 	ContainsMock          mIndexMockContains
 
+	funcHashes          func(m1 coordinates.Module) (h1 repository.Hashes, err error)
+	inspectFuncHashes   func(m1 coordinates.Module)
+	afterHashesCounter  uint64
+	beforeHashesCounter uint64
+	HashesMock          mIndexMockHashes
+
 	funcIDs          func() (r1 Ranges, err error)
 	inspectFuncIDs   func()
 	afterIDsCounter  uint64
@@ -34,6 +40,12 @@ type IndexMock struct {
 	beforeInfoCounter uint64
 	InfoMock          mIndexMockInfo
 
+	funcList          func() (sa1 []coordinates.SerialModule, err error)
+	inspectFuncList   func()
+	afterListCounter  uint64
+	beforeListCounter uint64
+	ListMock          mIndexMockList
+
 	funcMod          func(m1 coordinates.Module) (s1 string, err error)
 	inspectFuncMod   func(m1 coordinates.Module)
 	afterModCounter  uint64
@@ -58,6 +70,12 @@ type IndexMock struct {
 	beforeSummaryCounter uint64
 	SummaryMock          mIndexMockSummary
 
+	funcUpdateHashes          func(m1 coordinates.Module, h1 repository.Hashes) (err error)
+	inspectFuncUpdateHashes   func(m1 coordinates.Module, h1 repository.Hashes)
+	afterUpdateHashesCounter  uint64
+	beforeUpdateHashesCounter uint64
+	UpdateHashesMock          mIndexMockUpdateHashes
+
 	funcUpdateID          func(s1 coordinates.SerialModule) (err error)
 	inspectFuncUpdateID   func(s1 coordinates.SerialModule)
 	afterUpdateIDCounter  uint64
@@ -69,6 +87,12 @@ type IndexMock struct {
 	afterVersionsCounter  uint64
 	beforeVersionsCounter uint64
 	VersionsMock          mIndexMockVersions
+
+	funcZipRefs          func(zipHash string) (i1 int, err error)
+	inspectFuncZipRefs   func(zipHash string)
+	afterZipRefsCounter  uint64
+	beforeZipRefsCounter uint64
+	ZipRefsMock          mIndexMockZipRefs
 }
 
 // NewIndexMock returns a mock for Index
@@ -81,11 +105,16 @@ func NewIndexMock(t minimock.Tester) *IndexMock {
 	m.ContainsMock = mIndexMockContains{mock: m}
 	m.ContainsMock.callArgs = []*IndexMockContainsParams{}
 
+	m.HashesMock = mIndexMockHashes{mock: m}
+	m.HashesMock.callArgs = []*IndexMockHashesParams{}
+
 	m.IDsMock = mIndexMockIDs{mock: m}
 
 	m.InfoMock = mIndexMockInfo{mock: m}
 	m.InfoMock.callArgs = []*IndexMockInfoParams{}
 
+	m.ListMock = mIndexMockList{mock: m}
+
 	m.ModMock = mIndexMockMod{mock: m}
 	m.ModMock.callArgs = []*IndexMockModParams{}
 
@@ -97,12 +126,18 @@ func NewIndexMock(t minimock.Tester) *IndexMock {
 
 	m.SummaryMock = mIndexMockSummary{mock: m}
 
+	m.UpdateHashesMock = mIndexMockUpdateHashes{mock: m}
+	m.UpdateHashesMock.callArgs = []*IndexMockUpdateHashesParams{}
+
 	m.UpdateIDMock = mIndexMockUpdateID{mock: m}
 	m.UpdateIDMock.callArgs = []*IndexMockUpdateIDParams{}
 
 	m.VersionsMock = mIndexMockVersions{mock: m}
 	m.VersionsMock.callArgs = []*IndexMockVersionsParams{}
 
+	m.ZipRefsMock = mIndexMockZipRefs{mock: m}
+	m.ZipRefsMock.callArgs = []*IndexMockZipRefsParams{}
+
 	return m
 }
 
@@ -323,6 +358,222 @@ func (m *IndexMock) MinimockContainsInspect() {
 	}
 }
 
+type mIndexMockHashes struct {
+	mock               *IndexMock
+	defaultExpectation *IndexMockHashesExpectation
+	expectations       []*IndexMockHashesExpectation
+
+	callArgs []*IndexMockHashesParams
+	mutex    sync.RWMutex
+}
+
+// IndexMockHashesExpectation specifies expectation struct of the Index.Hashes
+type IndexMockHashesExpectation struct {
+	mock    *IndexMock
+	params  *IndexMockHashesParams
+	results *IndexMockHashesResults
+	Counter uint64
+}
+
+// IndexMockHashesParams contains parameters of the Index.Hashes
+type IndexMockHashesParams struct {
+	m1 coordinates.Module
+}
+
+// IndexMockHashesResults contains results of the Index.Hashes
+type IndexMockHashesResults struct {
+	h1  repository.Hashes
+	err error
+}
+
+// Expect sets up expected params for Index.Hashes
+func (mmHashes *mIndexMockHashes) Expect(m1 coordinates.Module) *mIndexMockHashes {
+	if mmHashes.mock.funcHashes != nil {
+		mmHashes.mock.t.Fatalf("IndexMock.Hashes mock is already set by Set")
+	}
+
+	if mmHashes.defaultExpectation == nil {
+		mmHashes.defaultExpectation = &IndexMockHashesExpectation{}
+	}
+
+	mmHashes.defaultExpectation.params = &IndexMockHashesParams{m1}
+	for _, e := range mmHashes.expectations {
+		if minimock.Equal(e.params, mmHashes.defaultExpectation.params) {
+			mmHashes.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmHashes.defaultExpectation.params)
+		}
+	}
+
+	return mmHashes
+}
+
+// Inspect accepts an inspector function that has same arguments as the Index.Hashes
+func (mmHashes *mIndexMockHashes) Inspect(f func(m1 coordinates.Module)) *mIndexMockHashes {
+	if mmHashes.mock.inspectFuncHashes != nil {
+		mmHashes.mock.t.Fatalf("Inspect function is already set for IndexMock.Hashes")
+	}
+
+	mmHashes.mock.inspectFuncHashes = f
+
+	return mmHashes
+}
+
+// Return sets up results that will be returned by Index.Hashes
+func (mmHashes *mIndexMockHashes) Return(h1 repository.Hashes, err error) *IndexMock {
+	if mmHashes.mock.funcHashes != nil {
+		mmHashes.mock.t.Fatalf("IndexMock.Hashes mock is already set by Set")
+	}
+
+	if mmHashes.defaultExpectation == nil {
+		mmHashes.defaultExpectation = &IndexMockHashesExpectation{mock: mmHashes.mock}
+	}
+	mmHashes.defaultExpectation.results = &IndexMockHashesResults{h1, err}
+	return mmHashes.mock
+}
+
+//Set uses given function f to mock the Index.Hashes method
+func (mmHashes *mIndexMockHashes) Set(f func(m1 coordinates.Module) (h1 repository.Hashes, err error)) *IndexMock {
+	if mmHashes.defaultExpectation != nil {
+		mmHashes.mock.t.Fatalf("Default expectation is already set for the Index.Hashes method")
+	}
+
+	if len(mmHashes.expectations) > 0 {
+		mmHashes.mock.t.Fatalf("Some expectations are already set for the Index.Hashes method")
+	}
+
+	mmHashes.mock.funcHashes = f
+	return mmHashes.mock
+}
+
+// When sets expectation for the Index.Hashes which will trigger the result defined by the following
+// Then helper
+func (mmHashes *mIndexMockHashes) When(m1 coordinates.Module) *IndexMockHashesExpectation {
+	if mmHashes.mock.funcHashes != nil {
+		mmHashes.mock.t.Fatalf("IndexMock.Hashes mock is already set by Set")
+	}
+
+	expectation := &IndexMockHashesExpectation{
+		mock:   mmHashes.mock,
+		params: &IndexMockHashesParams{m1},
+	}
+	mmHashes.expectations = append(mmHashes.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Index.Hashes return parameters for the expectation previously defined by the When method
+func (e *IndexMockHashesExpectation) Then(h1 repository.Hashes, err error) *IndexMock {
+	e.results = &IndexMockHashesResults{h1, err}
+	return e.mock
+}
+
+// Hashes implements Index
+func (mmHashes *IndexMock) Hashes(m1 coordinates.Module) (h1 repository.Hashes, err error) {
+	mm_atomic.AddUint64(&mmHashes.beforeHashesCounter, 1)
+	defer mm_atomic.AddUint64(&mmHashes.afterHashesCounter, 1)
+
+	if mmHashes.inspectFuncHashes != nil {
+		mmHashes.inspectFuncHashes(m1)
+	}
+
+	mm_params := &IndexMockHashesParams{m1}
+
+	// Record call args
+	mmHashes.HashesMock.mutex.Lock()
+	mmHashes.HashesMock.callArgs = append(mmHashes.HashesMock.callArgs, mm_params)
+	mmHashes.HashesMock.mutex.Unlock()
+
+	for _, e := range mmHashes.HashesMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.h1, e.results.err
+		}
+	}
+
+	if mmHashes.HashesMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmHashes.HashesMock.defaultExpectation.Counter, 1)
+		mm_want := mmHashes.HashesMock.defaultExpectation.params
+		mm_got := IndexMockHashesParams{m1}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmHashes.t.Errorf("IndexMock.Hashes got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmHashes.HashesMock.defaultExpectation.results
+		if mm_results == nil {
+			mmHashes.t.Fatal("No results are set for the IndexMock.Hashes")
+		}
+		return (*mm_results).h1, (*mm_results).err
+	}
+	if mmHashes.funcHashes != nil {
+		return mmHashes.funcHashes(m1)
+	}
+	mmHashes.t.Fatalf("Unexpected call to IndexMock.Hashes. %v", m1)
+	return
+}
+
+// HashesAfterCounter returns a count of finished IndexMock.Hashes invocations
+func (mmHashes *IndexMock) HashesAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmHashes.afterHashesCounter)
+}
+
+// HashesBeforeCounter returns a count of IndexMock.Hashes invocations
+func (mmHashes *IndexMock) HashesBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmHashes.beforeHashesCounter)
+}
+
+// Calls returns a list of arguments used in each call to IndexMock.Hashes.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmHashes *mIndexMockHashes) Calls() []*IndexMockHashesParams {
+	mmHashes.mutex.RLock()
+
+	argCopy := make([]*IndexMockHashesParams, len(mmHashes.callArgs))
+	copy(argCopy, mmHashes.callArgs)
+
+	mmHashes.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockHashesDone returns true if the count of the Hashes invocations corresponds
+// the number of defined expectations
+func (m *IndexMock) MinimockHashesDone() bool {
+	for _, e := range m.HashesMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.HashesMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterHashesCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcHashes != nil && mm_atomic.LoadUint64(&m.afterHashesCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockHashesInspect logs each unmet expectation
+func (m *IndexMock) MinimockHashesInspect() {
+	for _, e := range m.HashesMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to IndexMock.Hashes with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.HashesMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterHashesCounter) < 1 {
+		if m.HashesMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to IndexMock.Hashes")
+		} else {
+			m.t.Errorf("Expected call to IndexMock.Hashes with params: %#v", *m.HashesMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcHashes != nil && mm_atomic.LoadUint64(&m.afterHashesCounter) < 1 {
+		m.t.Error("Expected call to IndexMock.Hashes")
+	}
+}
+
 type mIndexMockIDs struct {
 	mock               *IndexMock
 	defaultExpectation *IndexMockIDsExpectation
@@ -683,6 +934,150 @@ func (m *IndexMock) MinimockInfoInspect() {
 	}
 }
 
+type mIndexMockList struct {
+	mock               *IndexMock
+	defaultExpectation *IndexMockListExpectation
+	expectations       []*IndexMockListExpectation
+}
+
+// IndexMockListExpectation specifies expectation struct of the Index.List
+type IndexMockListExpectation struct {
+	mock *IndexMock
+
+	results *IndexMockListResults
+	Counter uint64
+}
+
+// IndexMockListResults contains results of the Index.List
+type IndexMockListResults struct {
+	sa1 []coordinates.SerialModule
+	err error
+}
+
+// Expect sets up expected params for Index.List
+func (mmList *mIndexMockList) Expect() *mIndexMockList {
+	if mmList.mock.funcList != nil {
+		mmList.mock.t.Fatalf("IndexMock.List mock is already set by Set")
+	}
+
+	if mmList.defaultExpectation == nil {
+		mmList.defaultExpectation = &IndexMockListExpectation{}
+	}
+
+	return mmList
+}
+
+// Inspect accepts an inspector function that has same arguments as the Index.List
+func (mmList *mIndexMockList) Inspect(f func()) *mIndexMockList {
+	if mmList.mock.inspectFuncList != nil {
+		mmList.mock.t.Fatalf("Inspect function is already set for IndexMock.List")
+	}
+
+	mmList.mock.inspectFuncList = f
+
+	return mmList
+}
+
+// Return sets up results that will be returned by Index.List
+func (mmList *mIndexMockList) Return(sa1 []coordinates.SerialModule, err error) *IndexMock {
+	if mmList.mock.funcList != nil {
+		mmList.mock.t.Fatalf("IndexMock.List mock is already set by Set")
+	}
+
+	if mmList.defaultExpectation == nil {
+		mmList.defaultExpectation = &IndexMockListExpectation{mock: mmList.mock}
+	}
+	mmList.defaultExpectation.results = &IndexMockListResults{sa1, err}
+	return mmList.mock
+}
+
+//Set uses given function f to mock the Index.List method
+func (mmList *mIndexMockList) Set(f func() (sa1 []coordinates.SerialModule, err error)) *IndexMock {
+	if mmList.defaultExpectation != nil {
+		mmList.mock.t.Fatalf("Default expectation is already set for the Index.List method")
+	}
+
+	if len(mmList.expectations) > 0 {
+		mmList.mock.t.Fatalf("Some expectations are already set for the Index.List method")
+	}
+
+	mmList.mock.funcList = f
+	return mmList.mock
+}
+
+// List implements Index
+func (mmList *IndexMock) List() (sa1 []coordinates.SerialModule, err error) {
+	mm_atomic.AddUint64(&mmList.beforeListCounter, 1)
+	defer mm_atomic.AddUint64(&mmList.afterListCounter, 1)
+
+	if mmList.inspectFuncList != nil {
+		mmList.inspectFuncList()
+	}
+
+	if mmList.ListMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmList.ListMock.defaultExpectation.Counter, 1)
+
+		mm_results := mmList.ListMock.defaultExpectation.results
+		if mm_results == nil {
+			mmList.t.Fatal("No results are set for the IndexMock.List")
+		}
+		return (*mm_results).sa1, (*mm_results).err
+	}
+	if mmList.funcList != nil {
+		return mmList.funcList()
+	}
+	mmList.t.Fatalf("Unexpected call to IndexMock.List.")
+	return
+}
+
+// ListAfterCounter returns a count of finished IndexMock.List invocations
+func (mmList *IndexMock) ListAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmList.afterListCounter)
+}
+
+// ListBeforeCounter returns a count of IndexMock.List invocations
+func (mmList *IndexMock) ListBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmList.beforeListCounter)
+}
+
+// MinimockListDone returns true if the count of the List invocations corresponds
+// the number of defined expectations
+func (m *IndexMock) MinimockListDone() bool {
+	for _, e := range m.ListMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ListMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcList != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockListInspect logs each unmet expectation
+func (m *IndexMock) MinimockListInspect() {
+	for _, e := range m.ListMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Error("Expected call to IndexMock.List")
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ListMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		m.t.Error("Expected call to IndexMock.List")
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcList != nil && mm_atomic.LoadUint64(&m.afterListCounter) < 1 {
+		m.t.Error("Expected call to IndexMock.List")
+	}
+}
+
 type mIndexMockMod struct {
 	mock               *IndexMock
 	defaultExpectation *IndexMockModExpectation
@@ -1474,6 +1869,222 @@ func (m *IndexMock) MinimockSummaryInspect() {
 	}
 }
 
+type mIndexMockUpdateHashes struct {
+	mock               *IndexMock
+	defaultExpectation *IndexMockUpdateHashesExpectation
+	expectations       []*IndexMockUpdateHashesExpectation
+
+	callArgs []*IndexMockUpdateHashesParams
+	mutex    sync.RWMutex
+}
+
+// IndexMockUpdateHashesExpectation specifies expectation struct of the Index.UpdateHashes
+type IndexMockUpdateHashesExpectation struct {
+	mock    *IndexMock
+	params  *IndexMockUpdateHashesParams
+	results *IndexMockUpdateHashesResults
+	Counter uint64
+}
+
+// IndexMockUpdateHashesParams contains parameters of the Index.UpdateHashes
+type IndexMockUpdateHashesParams struct {
+	m1 coordinates.Module
+	h1 repository.Hashes
+}
+
+// IndexMockUpdateHashesResults contains results of the Index.UpdateHashes
+type IndexMockUpdateHashesResults struct {
+	err error
+}
+
+// Expect sets up expected params for Index.UpdateHashes
+func (mmUpdateHashes *mIndexMockUpdateHashes) Expect(m1 coordinates.Module, h1 repository.Hashes) *mIndexMockUpdateHashes {
+	if mmUpdateHashes.mock.funcUpdateHashes != nil {
+		mmUpdateHashes.mock.t.Fatalf("IndexMock.UpdateHashes mock is already set by Set")
+	}
+
+	if mmUpdateHashes.defaultExpectation == nil {
+		mmUpdateHashes.defaultExpectation = &IndexMockUpdateHashesExpectation{}
+	}
+
+	mmUpdateHashes.defaultExpectation.params = &IndexMockUpdateHashesParams{m1, h1}
+	for _, e := range mmUpdateHashes.expectations {
+		if minimock.Equal(e.params, mmUpdateHashes.defaultExpectation.params) {
+			mmUpdateHashes.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmUpdateHashes.defaultExpectation.params)
+		}
+	}
+
+	return mmUpdateHashes
+}
+
+// Inspect accepts an inspector function that has same arguments as the Index.UpdateHashes
+func (mmUpdateHashes *mIndexMockUpdateHashes) Inspect(f func(m1 coordinates.Module, h1 repository.Hashes)) *mIndexMockUpdateHashes {
+	if mmUpdateHashes.mock.inspectFuncUpdateHashes != nil {
+		mmUpdateHashes.mock.t.Fatalf("Inspect function is already set for IndexMock.UpdateHashes")
+	}
+
+	mmUpdateHashes.mock.inspectFuncUpdateHashes = f
+
+	return mmUpdateHashes
+}
+
+// Return sets up results that will be returned by Index.UpdateHashes
+func (mmUpdateHashes *mIndexMockUpdateHashes) Return(err error) *IndexMock {
+	if mmUpdateHashes.mock.funcUpdateHashes != nil {
+		mmUpdateHashes.mock.t.Fatalf("IndexMock.UpdateHashes mock is already set by Set")
+	}
+
+	if mmUpdateHashes.defaultExpectation == nil {
+		mmUpdateHashes.defaultExpectation = &IndexMockUpdateHashesExpectation{mock: mmUpdateHashes.mock}
+	}
+	mmUpdateHashes.defaultExpectation.results = &IndexMockUpdateHashesResults{err}
+	return mmUpdateHashes.mock
+}
+
+//Set uses given function f to mock the Index.UpdateHashes method
+func (mmUpdateHashes *mIndexMockUpdateHashes) Set(f func(m1 coordinates.Module, h1 repository.Hashes) (err error)) *IndexMock {
+	if mmUpdateHashes.defaultExpectation != nil {
+		mmUpdateHashes.mock.t.Fatalf("Default expectation is already set for the Index.UpdateHashes method")
+	}
+
+	if len(mmUpdateHashes.expectations) > 0 {
+		mmUpdateHashes.mock.t.Fatalf("Some expectations are already set for the Index.UpdateHashes method")
+	}
+
+	mmUpdateHashes.mock.funcUpdateHashes = f
+	return mmUpdateHashes.mock
+}
+
+// When sets expectation for the Index.UpdateHashes which will trigger the result defined by the following
+// Then helper
+func (mmUpdateHashes *mIndexMockUpdateHashes) When(m1 coordinates.Module, h1 repository.Hashes) *IndexMockUpdateHashesExpectation {
+	if mmUpdateHashes.mock.funcUpdateHashes != nil {
+		mmUpdateHashes.mock.t.Fatalf("IndexMock.UpdateHashes mock is already set by Set")
+	}
+
+	expectation := &IndexMockUpdateHashesExpectation{
+		mock:   mmUpdateHashes.mock,
+		params: &IndexMockUpdateHashesParams{m1, h1},
+	}
+	mmUpdateHashes.expectations = append(mmUpdateHashes.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Index.UpdateHashes return parameters for the expectation previously defined by the When method
+func (e *IndexMockUpdateHashesExpectation) Then(err error) *IndexMock {
+	e.results = &IndexMockUpdateHashesResults{err}
+	return e.mock
+}
+
+// UpdateHashes implements Index
+func (mmUpdateHashes *IndexMock) UpdateHashes(m1 coordinates.Module, h1 repository.Hashes) (err error) {
+	mm_atomic.AddUint64(&mmUpdateHashes.beforeUpdateHashesCounter, 1)
+	defer mm_atomic.AddUint64(&mmUpdateHashes.afterUpdateHashesCounter, 1)
+
+	if mmUpdateHashes.inspectFuncUpdateHashes != nil {
+		mmUpdateHashes.inspectFuncUpdateHashes(m1, h1)
+	}
+
+	mm_params := &IndexMockUpdateHashesParams{m1, h1}
+
+	// Record call args
+	mmUpdateHashes.UpdateHashesMock.mutex.Lock()
+	mmUpdateHashes.UpdateHashesMock.callArgs = append(mmUpdateHashes.UpdateHashesMock.callArgs, mm_params)
+	mmUpdateHashes.UpdateHashesMock.mutex.Unlock()
+
+	for _, e := range mmUpdateHashes.UpdateHashesMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.err
+		}
+	}
+
+	if mmUpdateHashes.UpdateHashesMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmUpdateHashes.UpdateHashesMock.defaultExpectation.Counter, 1)
+		mm_want := mmUpdateHashes.UpdateHashesMock.defaultExpectation.params
+		mm_got := IndexMockUpdateHashesParams{m1, h1}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmUpdateHashes.t.Errorf("IndexMock.UpdateHashes got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmUpdateHashes.UpdateHashesMock.defaultExpectation.results
+		if mm_results == nil {
+			mmUpdateHashes.t.Fatal("No results are set for the IndexMock.UpdateHashes")
+		}
+		return (*mm_results).err
+	}
+	if mmUpdateHashes.funcUpdateHashes != nil {
+		return mmUpdateHashes.funcUpdateHashes(m1, h1)
+	}
+	mmUpdateHashes.t.Fatalf("Unexpected call to IndexMock.UpdateHashes. %v %v", m1, h1)
+	return
+}
+
+// UpdateHashesAfterCounter returns a count of finished IndexMock.UpdateHashes invocations
+func (mmUpdateHashes *IndexMock) UpdateHashesAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpdateHashes.afterUpdateHashesCounter)
+}
+
+// UpdateHashesBeforeCounter returns a count of IndexMock.UpdateHashes invocations
+func (mmUpdateHashes *IndexMock) UpdateHashesBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmUpdateHashes.beforeUpdateHashesCounter)
+}
+
+// Calls returns a list of arguments used in each call to IndexMock.UpdateHashes.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmUpdateHashes *mIndexMockUpdateHashes) Calls() []*IndexMockUpdateHashesParams {
+	mmUpdateHashes.mutex.RLock()
+
+	argCopy := make([]*IndexMockUpdateHashesParams, len(mmUpdateHashes.callArgs))
+	copy(argCopy, mmUpdateHashes.callArgs)
+
+	mmUpdateHashes.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockUpdateHashesDone returns true if the count of the UpdateHashes invocations corresponds
+// the number of defined expectations
+func (m *IndexMock) MinimockUpdateHashesDone() bool {
+	for _, e := range m.UpdateHashesMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.UpdateHashesMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpdateHashesCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcUpdateHashes != nil && mm_atomic.LoadUint64(&m.afterUpdateHashesCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockUpdateHashesInspect logs each unmet expectation
+func (m *IndexMock) MinimockUpdateHashesInspect() {
+	for _, e := range m.UpdateHashesMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to IndexMock.UpdateHashes with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.UpdateHashesMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterUpdateHashesCounter) < 1 {
+		if m.UpdateHashesMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to IndexMock.UpdateHashes")
+		} else {
+			m.t.Errorf("Expected call to IndexMock.UpdateHashes with params: %#v", *m.UpdateHashesMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcUpdateHashes != nil && mm_atomic.LoadUint64(&m.afterUpdateHashesCounter) < 1 {
+		m.t.Error("Expected call to IndexMock.UpdateHashes")
+	}
+}
+
 type mIndexMockUpdateID struct {
 	mock               *IndexMock
 	defaultExpectation *IndexMockUpdateIDExpectation
@@ -1905,15 +2516,235 @@ func (m *IndexMock) MinimockVersionsInspect() {
 	}
 }
 
+type mIndexMockZipRefs struct {
+	mock               *IndexMock
+	defaultExpectation *IndexMockZipRefsExpectation
+	expectations       []*IndexMockZipRefsExpectation
+
+	callArgs []*IndexMockZipRefsParams
+	mutex    sync.RWMutex
+}
+
+// IndexMockZipRefsExpectation specifies expectation struct of the Index.ZipRefs
+type IndexMockZipRefsExpectation struct {
+	mock    *IndexMock
+	params  *IndexMockZipRefsParams
+	results *IndexMockZipRefsResults
+	Counter uint64
+}
+
+// IndexMockZipRefsParams contains parameters of the Index.ZipRefs
+type IndexMockZipRefsParams struct {
+	zipHash string
+}
+
+// IndexMockZipRefsResults contains results of the Index.ZipRefs
+type IndexMockZipRefsResults struct {
+	i1  int
+	err error
+}
+
+// Expect sets up expected params for Index.ZipRefs
+func (mmZipRefs *mIndexMockZipRefs) Expect(zipHash string) *mIndexMockZipRefs {
+	if mmZipRefs.mock.funcZipRefs != nil {
+		mmZipRefs.mock.t.Fatalf("IndexMock.ZipRefs mock is already set by Set")
+	}
+
+	if mmZipRefs.defaultExpectation == nil {
+		mmZipRefs.defaultExpectation = &IndexMockZipRefsExpectation{}
+	}
+
+	mmZipRefs.defaultExpectation.params = &IndexMockZipRefsParams{zipHash}
+	for _, e := range mmZipRefs.expectations {
+		if minimock.Equal(e.params, mmZipRefs.defaultExpectation.params) {
+			mmZipRefs.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmZipRefs.defaultExpectation.params)
+		}
+	}
+
+	return mmZipRefs
+}
+
+// Inspect accepts an inspector function that has same arguments as the Index.ZipRefs
+func (mmZipRefs *mIndexMockZipRefs) Inspect(f func(zipHash string)) *mIndexMockZipRefs {
+	if mmZipRefs.mock.inspectFuncZipRefs != nil {
+		mmZipRefs.mock.t.Fatalf("Inspect function is already set for IndexMock.ZipRefs")
+	}
+
+	mmZipRefs.mock.inspectFuncZipRefs = f
+
+	return mmZipRefs
+}
+
+// Return sets up results that will be returned by Index.ZipRefs
+func (mmZipRefs *mIndexMockZipRefs) Return(i1 int, err error) *IndexMock {
+	if mmZipRefs.mock.funcZipRefs != nil {
+		mmZipRefs.mock.t.Fatalf("IndexMock.ZipRefs mock is already set by Set")
+	}
+
+	if mmZipRefs.defaultExpectation == nil {
+		mmZipRefs.defaultExpectation = &IndexMockZipRefsExpectation{mock: mmZipRefs.mock}
+	}
+	mmZipRefs.defaultExpectation.results = &IndexMockZipRefsResults{i1, err}
+	return mmZipRefs.mock
+}
+
+//Set uses given function f to mock the Index.ZipRefs method
+func (mmZipRefs *mIndexMockZipRefs) Set(f func(zipHash string) (i1 int, err error)) *IndexMock {
+	if mmZipRefs.defaultExpectation != nil {
+		mmZipRefs.mock.t.Fatalf("Default expectation is already set for the Index.ZipRefs method")
+	}
+
+	if len(mmZipRefs.expectations) > 0 {
+		mmZipRefs.mock.t.Fatalf("Some expectations are already set for the Index.ZipRefs method")
+	}
+
+	mmZipRefs.mock.funcZipRefs = f
+	return mmZipRefs.mock
+}
+
+// When sets expectation for the Index.ZipRefs which will trigger the result defined by the following
+// Then helper
+func (mmZipRefs *mIndexMockZipRefs) When(zipHash string) *IndexMockZipRefsExpectation {
+	if mmZipRefs.mock.funcZipRefs != nil {
+		mmZipRefs.mock.t.Fatalf("IndexMock.ZipRefs mock is already set by Set")
+	}
+
+	expectation := &IndexMockZipRefsExpectation{
+		mock:   mmZipRefs.mock,
+		params: &IndexMockZipRefsParams{zipHash},
+	}
+	mmZipRefs.expectations = append(mmZipRefs.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Index.ZipRefs return parameters for the expectation previously defined by the When method
+func (e *IndexMockZipRefsExpectation) Then(i1 int, err error) *IndexMock {
+	e.results = &IndexMockZipRefsResults{i1, err}
+	return e.mock
+}
+
+// ZipRefs implements Index
+func (mmZipRefs *IndexMock) ZipRefs(zipHash string) (i1 int, err error) {
+	mm_atomic.AddUint64(&mmZipRefs.beforeZipRefsCounter, 1)
+	defer mm_atomic.AddUint64(&mmZipRefs.afterZipRefsCounter, 1)
+
+	if mmZipRefs.inspectFuncZipRefs != nil {
+		mmZipRefs.inspectFuncZipRefs(zipHash)
+	}
+
+	mm_params := &IndexMockZipRefsParams{zipHash}
+
+	// Record call args
+	mmZipRefs.ZipRefsMock.mutex.Lock()
+	mmZipRefs.ZipRefsMock.callArgs = append(mmZipRefs.ZipRefsMock.callArgs, mm_params)
+	mmZipRefs.ZipRefsMock.mutex.Unlock()
+
+	for _, e := range mmZipRefs.ZipRefsMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.i1, e.results.err
+		}
+	}
+
+	if mmZipRefs.ZipRefsMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmZipRefs.ZipRefsMock.defaultExpectation.Counter, 1)
+		mm_want := mmZipRefs.ZipRefsMock.defaultExpectation.params
+		mm_got := IndexMockZipRefsParams{zipHash}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmZipRefs.t.Errorf("IndexMock.ZipRefs got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmZipRefs.ZipRefsMock.defaultExpectation.results
+		if mm_results == nil {
+			mmZipRefs.t.Fatal("No results are set for the IndexMock.ZipRefs")
+		}
+		return (*mm_results).i1, (*mm_results).err
+	}
+	if mmZipRefs.funcZipRefs != nil {
+		return mmZipRefs.funcZipRefs(zipHash)
+	}
+	mmZipRefs.t.Fatalf("Unexpected call to IndexMock.ZipRefs. %v", zipHash)
+	return
+}
+
+// ZipRefsAfterCounter returns a count of finished IndexMock.ZipRefs invocations
+func (mmZipRefs *IndexMock) ZipRefsAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmZipRefs.afterZipRefsCounter)
+}
+
+// ZipRefsBeforeCounter returns a count of IndexMock.ZipRefs invocations
+func (mmZipRefs *IndexMock) ZipRefsBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmZipRefs.beforeZipRefsCounter)
+}
+
+// Calls returns a list of arguments used in each call to IndexMock.ZipRefs.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmZipRefs *mIndexMockZipRefs) Calls() []*IndexMockZipRefsParams {
+	mmZipRefs.mutex.RLock()
+
+	argCopy := make([]*IndexMockZipRefsParams, len(mmZipRefs.callArgs))
+	copy(argCopy, mmZipRefs.callArgs)
+
+	mmZipRefs.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockZipRefsDone returns true if the count of the ZipRefs invocations corresponds
+// the number of defined expectations
+func (m *IndexMock) MinimockZipRefsDone() bool {
+	for _, e := range m.ZipRefsMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ZipRefsMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterZipRefsCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcZipRefs != nil && mm_atomic.LoadUint64(&m.afterZipRefsCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockZipRefsInspect logs each unmet expectation
+func (m *IndexMock) MinimockZipRefsInspect() {
+	for _, e := range m.ZipRefsMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to IndexMock.ZipRefs with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ZipRefsMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterZipRefsCounter) < 1 {
+		if m.ZipRefsMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to IndexMock.ZipRefs")
+		} else {
+			m.t.Errorf("Expected call to IndexMock.ZipRefs with params: %#v", *m.ZipRefsMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcZipRefs != nil && mm_atomic.LoadUint64(&m.afterZipRefsCounter) < 1 {
+		m.t.Error("Expected call to IndexMock.ZipRefs")
+	}
+}
+
 // MinimockFinish checks that all mocked methods have been called the expected number of times
 func (m *IndexMock) MinimockFinish() {
 	if !m.minimockDone() {
 		m.MinimockContainsInspect()
 
+		m.MinimockHashesInspect()
+
 		m.MinimockIDsInspect()
 
 		m.MinimockInfoInspect()
 
+		m.MinimockListInspect()
+
 		m.MinimockModInspect()
 
 		m.MinimockPutInspect()
@@ -1922,9 +2753,13 @@ func (m *IndexMock) MinimockFinish() {
 
 		m.MinimockSummaryInspect()
 
+		m.MinimockUpdateHashesInspect()
+
 		m.MinimockUpdateIDInspect()
 
 		m.MinimockVersionsInspect()
+
+		m.MinimockZipRefsInspect()
 		m.t.FailNow()
 	}
 }
@@ -1949,12 +2784,16 @@ func (m *IndexMock) minimockDone() bool {
 	done := true
 	return done &&
 		m.MinimockContainsDone() &&
+		m.MinimockHashesDone() &&
 		m.MinimockIDsDone() &&
 		m.MinimockInfoDone() &&
+		m.MinimockListDone() &&
 		m.MinimockModDone() &&
 		m.MinimockPutDone() &&
 		m.MinimockRemoveDone() &&
 		m.MinimockSummaryDone() &&
+		m.MinimockUpdateHashesDone() &&
 		m.MinimockUpdateIDDone() &&
-		m.MinimockVersionsDone()
+		m.MinimockVersionsDone() &&
+		m.MinimockZipRefsDone()
 }
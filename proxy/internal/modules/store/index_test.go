@@ -5,13 +5,18 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
+	"github.com/boltdb/bolt"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb/tlog"
 
 	"gophers.dev/pkgs/semantic"
 
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
 )
 
 func setupIndex(t *testing.T) (string, Index) {
@@ -40,6 +45,11 @@ func newMod(source, version string) coordinates.Module {
 	}
 }
 
+// timeOf returns a pointer to t, e.g. for when a problem was resolved
+func timeOf(t time.Time) *time.Time {
+	return &t
+}
+
 func Test_Index_empty(t *testing.T) {
 	tmpDir, index := setupIndex(t)
 	defer cleanupIndex(t, tmpDir)
@@ -170,6 +180,106 @@ func Test_IDs(t *testing.T) {
 	}, ids)
 }
 
+func Test_List(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	mods, err := index.List()
+	require.NoError(t, err)
+	require.Empty(t, mods)
+
+	insert(t, index, "github.com/pkg/errors", 1)
+	insert(t, index, "github.com/pkg/toolkit", 2)
+
+	mods, err = index.List()
+	require.NoError(t, err)
+	require.Equal(t, []coordinates.SerialModule{
+		{
+			SerialID: 1,
+			Module:   newMod("github.com/pkg/errors", "v0.0.1"),
+		},
+		{
+			SerialID: 2,
+			Module:   newMod("github.com/pkg/toolkit", "v0.0.2"),
+		},
+	}, mods)
+}
+
+func Test_Problems(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	problemStore := index.(problems.Store)
+
+	list, err := problemStore.ListProblems()
+	require.NoError(t, err)
+	require.Empty(t, list)
+
+	problem := problems.Problem{
+		Module:    newMod("github.com/pkg/errors", "v0.8.0"),
+		FirstSeen: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+		Time:      time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC),
+		Message:   "broken",
+		Attempts:  2,
+	}
+
+	err = problemStore.PutProblem(problem)
+	require.NoError(t, err)
+
+	list, err = problemStore.ListProblems()
+	require.NoError(t, err)
+	require.Equal(t, []problems.Problem{problem}, list)
+
+	err = problemStore.DeleteProblem(problem.Module)
+	require.NoError(t, err)
+
+	list, err = problemStore.ListProblems()
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func Test_Resolved(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	problemStore := index.(problems.Store)
+
+	list, err := problemStore.ListResolved()
+	require.NoError(t, err)
+	require.Empty(t, list)
+
+	mod := newMod("github.com/pkg/errors", "v0.8.0")
+	older := problems.Problem{
+		Module:     mod,
+		Message:    "broken",
+		Resolved:   timeOf(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)),
+		Resolution: problems.Downloaded,
+	}
+	newer := problems.Problem{
+		Module:     mod,
+		Message:    "broken again",
+		Resolved:   timeOf(time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC)),
+		Resolution: problems.Acknowledged,
+	}
+
+	// the same module may be resolved more than once
+	err = problemStore.PutResolved(older)
+	require.NoError(t, err)
+	err = problemStore.PutResolved(newer)
+	require.NoError(t, err)
+
+	list, err = problemStore.ListResolved()
+	require.NoError(t, err)
+	require.Equal(t, []problems.Problem{older, newer}, list)
+
+	err = problemStore.DeleteResolved(time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	list, err = problemStore.ListResolved()
+	require.NoError(t, err)
+	require.Equal(t, []problems.Problem{newer}, list)
+}
+
 func Test_ranges(t *testing.T) {
 	try := func(input []int64, exp Ranges) {
 		output := ranges(input)
@@ -316,6 +426,222 @@ func Test_Remove(t *testing.T) {
 	checkSummary(t, index, 2, 8)
 }
 
+func Test_Hashes(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	mod := newMod("github.com/pkg/errors", "v0.8.1")
+	hashes := repository.Hashes{
+		Zip: "h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=",
+		Mod: "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=",
+	}
+
+	// not in the index
+	_, err := index.Hashes(mod)
+	require.Error(t, err)
+	err = index.UpdateHashes(mod, hashes)
+	require.Error(t, err)
+
+	// stored without hashes
+	err = index.Put(ModuleAddition{
+		Mod:      mod,
+		ModFile:  "module github.com/pkg/errors\n",
+		UniqueID: 1,
+	})
+	require.NoError(t, err)
+
+	result, err := index.Hashes(mod)
+	require.NoError(t, err)
+	require.Equal(t, repository.Hashes{}, result)
+
+	// hashes filled in later
+	err = index.UpdateHashes(mod, hashes)
+	require.NoError(t, err)
+
+	result, err = index.Hashes(mod)
+	require.NoError(t, err)
+	require.Equal(t, hashes, result)
+
+	// removed along with the module
+	err = index.Remove(mod)
+	require.NoError(t, err)
+
+	err = index.Put(ModuleAddition{
+		Mod:      mod,
+		ModFile:  "module github.com/pkg/errors\n",
+		UniqueID: 1,
+	})
+	require.NoError(t, err)
+
+	result, err = index.Hashes(mod)
+	require.NoError(t, err)
+	require.Equal(t, repository.Hashes{}, result)
+}
+
+func Test_ZipRefs(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	hashes := repository.Hashes{
+		Zip: "h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=",
+		Mod: "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=",
+	}
+
+	refs := func(exp int) {
+		n, err := index.ZipRefs(hashes.Zip)
+		require.NoError(t, err)
+		require.Equal(t, exp, n)
+	}
+
+	put := func(id int64, mod coordinates.Module, hashes repository.Hashes) {
+		err := index.Put(ModuleAddition{
+			Mod:      mod,
+			ModFile:  "module github.com/pkg/errors\n",
+			UniqueID: id,
+			Hashes:   hashes,
+		})
+		require.NoError(t, err)
+	}
+
+	modA := newMod("github.com/pkg/errors", "v0.8.1")
+	modB := newMod("github.com/pkg/errors", "v0.8.2")
+	refs(0)
+
+	put(1, modA, hashes)
+	refs(1)
+
+	put(2, modB, hashes)
+	refs(2)
+
+	// storing the same module again does not add a reference
+	put(1, modA, hashes)
+	refs(2)
+
+	// neither does recording the same hashes again
+	err := index.UpdateHashes(modB, hashes)
+	require.NoError(t, err)
+	refs(2)
+
+	// recording different hashes moves the reference
+	err = index.UpdateHashes(modB, repository.Hashes{Zip: "h1:other=", Mod: hashes.Mod})
+	require.NoError(t, err)
+	refs(1)
+
+	err = index.Remove(modA)
+	require.NoError(t, err)
+	refs(0)
+
+	// a module whose zip hash is not known yet might reference any zip
+	modC := newMod("github.com/pkg/errors", "v0.8.3")
+	put(3, modC, repository.Hashes{})
+	refs(1)
+
+	// until its hashes are recorded
+	err = index.UpdateHashes(modC, repository.Hashes{Zip: "h1:other=", Mod: hashes.Mod})
+	require.NoError(t, err)
+	refs(0)
+}
+
+func Test_ZipRefs_recount(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	hashes := repository.Hashes{
+		Zip: "h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=",
+		Mod: "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=",
+	}
+
+	refs := func(exp int) {
+		n, err := index.ZipRefs(hashes.Zip)
+		require.NoError(t, err)
+		require.Equal(t, exp, n)
+	}
+
+	modA := newMod("github.com/pkg/errors", "v0.8.1")
+	modB := newMod("github.com/pkg/errors", "v0.8.2")
+	for i, mod := range []coordinates.Module{modA, modB} {
+		err := index.Put(ModuleAddition{Mod: mod, UniqueID: int64(i + 1), Hashes: hashes})
+		require.NoError(t, err)
+	}
+
+	// make modB look like it was stored before hashes were recorded, and
+	// the index like it was opened before references were counted
+	db := index.(*boltIndex).db
+	err := db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(sumsBktLbl).Delete(modB.Bytes()); err != nil {
+			return err
+		}
+		return tx.DeleteBucket(zipRefsBktLbl)
+	})
+	require.NoError(t, err)
+
+	err = initDB(db)
+	require.NoError(t, err)
+	refs(2)
+
+	// removing the module of unknown hash releases its reference too
+	err = index.Remove(modB)
+	require.NoError(t, err)
+	refs(1)
+
+	err = index.Remove(modA)
+	require.NoError(t, err)
+	refs(0)
+}
+
+func Test_AccessLog(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	accesses := index.(AccessLog)
+	mod := newMod("github.com/pkg/errors", "v0.8.1")
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	list, err := accesses.Accesses()
+	require.NoError(t, err)
+	require.Empty(t, list)
+
+	// served before the size is known
+	err = accesses.AddAccesses([]Access{{Module: mod, Last: first.Add(time.Hour), Count: 2}})
+	require.NoError(t, err)
+
+	list, err = accesses.Accesses()
+	require.NoError(t, err)
+	require.Equal(t, []Access{{
+		Module: mod,
+		Last:   first.Add(time.Hour),
+		Count:  2,
+	}}, list)
+
+	// size filled in later
+	list[0].Size = 1024
+	err = accesses.PutAccess(list[0])
+	require.NoError(t, err)
+
+	err = accesses.AddAccesses([]Access{{Module: mod, Last: first.Add(2 * time.Hour), Count: 1}})
+	require.NoError(t, err)
+
+	// an older batch does not move last backwards
+	err = accesses.AddAccesses([]Access{{Module: mod, Last: first, Count: 1}})
+	require.NoError(t, err)
+
+	list, err = accesses.Accesses()
+	require.NoError(t, err)
+	require.Equal(t, []Access{{
+		Module: mod,
+		Size:   1024,
+		Last:   first.Add(2 * time.Hour),
+		Count:  4,
+	}}, list)
+
+	err = accesses.RemoveAccess(mod)
+	require.NoError(t, err)
+
+	list, err = accesses.Accesses()
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
 func Test_Versions_multi(t *testing.T) {
 	tmpDir, index := setupIndex(t)
 	defer cleanupIndex(t, tmpDir)
@@ -390,3 +716,56 @@ func Test_Versions_sort(t *testing.T) {
 		"v1.100.0",
 	}, versions)
 }
+
+func Test_Records(t *testing.T) {
+	tmpDir, index := setupIndex(t)
+	defer cleanupIndex(t, tmpDir)
+
+	recordStore := index.(sumdb.Store)
+
+	n, err := recordStore.CountRecords()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), n)
+
+	mod := newMod("github.com/pkg/errors", "v0.8.0")
+	_, exists, err := recordStore.LookupRecord(mod)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// the first record has a single stored hash
+	data := []byte("github.com/pkg/errors v0.8.0 h1:abc=\n")
+	hash := tlog.RecordHash(data)
+	err = recordStore.AppendRecord(0, mod, data, []tlog.Hash{hash})
+	require.NoError(t, err)
+
+	n, err = recordStore.CountRecords()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	id, exists, err := recordStore.LookupRecord(mod)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, int64(0), id)
+
+	records, err := recordStore.ReadRecords(0, 1)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{data}, records)
+
+	hashes, err := recordStore.ReadHashes([]int64{0})
+	require.NoError(t, err)
+	require.Equal(t, []tlog.Hash{hash}, hashes)
+
+	// only ever appended to
+	err = recordStore.AppendRecord(5, newMod("github.com/pkg/errors", "v0.8.1"), data, nil)
+	require.Error(t, err)
+
+	err = recordStore.AppendRecord(1, mod, data, nil)
+	require.Error(t, err)
+
+	// missing records and hashes are reported as not existing
+	_, err = recordStore.ReadRecords(1, 1)
+	require.True(t, os.IsNotExist(err))
+
+	_, err = recordStore.ReadHashes([]int64{1})
+	require.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,247 @@
+package store
+
+import (
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// A Change is a module being added to or removed from the ZipStore and Index.
+type Change struct {
+	Module  coordinates.Module `json:"module"`
+	Kind    ChangeKind         `json:"kind"`
+	Started time.Time          `json:"started"`
+}
+
+// ChangeKind is whether a Change is adding or removing a module.
+type ChangeKind string
+
+const (
+	Adding   ChangeKind = "adding"
+	Removing ChangeKind = "removing"
+)
+
+// A Journal is a write-ahead log of the changes being made to the ZipStore
+// and Index. A change is begun before anything is written, and ended once
+// both are written, so the changes which remain after a crash are exactly
+// those which may have been left half done. Both the boltdb and mysql indexes
+// are capable of keeping a Journal.
+type Journal interface {
+	BeginChange(Change) error
+	EndChange(coordinates.Module) error
+	Changes() ([]Change, error)
+}
+
+//go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i Ingester -s _mock.go
+
+// An Ingester adds modules to and removes modules from the ZipStore and the
+// Index together, such that a module is either fully in both or in neither.
+type Ingester interface {
+	// Ingest stores the zip of a module and adds it to the index.
+	Ingest(ModuleAddition, repository.Archive) error
+
+	// Remove removes a module from the index and removes its zip.
+	Remove(coordinates.Module) error
+}
+
+// the number of locks changes to modules are spread over
+const ingestLocks = 64
+
+type ingester struct {
+	index   Index
+	store   ZipStore
+	journal Journal
+	emitter stats.Sender
+	log     loggy.Logger
+
+	// a module is only ever changed by one goroutine at a time, e.g.
+	// so a removal cannot interleave with the module being added
+	locks [ingestLocks]sync.Mutex
+}
+
+// NewIngester creates an Ingester of modules into index and store, first
+// finishing or undoing whatever changes were left in journal, e.g. by a
+// crash part way through adding a module.
+//
+// The index is the source of truth: a module being added is only complete
+// once it is in the index, which happens after its zip is stored, and a
+// module being removed is removed from the index before its zip is removed.
+// So a module is never served without its zip, and recovering either removes
+// a zip which never made it into the index, or one which was being removed.
+func NewIngester(index Index, store ZipStore, journal Journal, emitter stats.Sender) (Ingester, error) {
+	i := &ingester{
+		index:   index,
+		store:   store,
+		journal: journal,
+		emitter: emitter,
+		log:     loggy.New("ingester"),
+	}
+
+	if err := i.recover(); err != nil {
+		return nil, errors.Wrap(err, "failed to recover unfinished changes")
+	}
+
+	return i, nil
+}
+
+func (i *ingester) lock(mod coordinates.Module) *sync.Mutex {
+	h := fnv.New32a()
+	_, _ = h.Write(mod.Bytes())
+	lock := &i.locks[h.Sum32()%ingestLocks]
+	lock.Lock()
+	return lock
+}
+
+func (i *ingester) Ingest(addition ModuleAddition, archive repository.Archive) error {
+	mod := addition.Mod
+	defer i.lock(mod).Unlock()
+
+	if err := i.journal.BeginChange(Change{
+		Module:  mod,
+		Kind:    Adding,
+		Started: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	if err := i.store.PutZip(mod, archive); err != nil {
+		// nothing was stored, stores write zips atomically
+		i.end(mod)
+		return err
+	}
+
+	if err := i.index.Put(addition); err != nil {
+		i.emitter.Count("ingest-rollback", 1)
+		i.rollback(mod)
+		return err
+	}
+
+	i.end(mod)
+	return nil
+}
+
+// rollback removes the zip of a module which could not be added to the
+// index, leaving the change in the journal if that fails too.
+func (i *ingester) rollback(mod coordinates.Module) {
+	i.log.Warnf("rolling back adding %s", mod)
+	if err := i.delZip(mod); err != nil {
+		i.log.Errorf("failed to roll back adding %s, will recover on restart, %v", mod, err)
+		return
+	}
+	i.end(mod)
+}
+
+func (i *ingester) Remove(mod coordinates.Module) error {
+	defer i.lock(mod).Unlock()
+
+	if err := i.journal.BeginChange(Change{
+		Module:  mod,
+		Kind:    Removing,
+		Started: time.Now(),
+	}); err != nil {
+		return err
+	}
+
+	if err := i.index.Remove(mod); err != nil {
+		i.end(mod)
+		return err
+	}
+
+	// the module is no longer served, if removing the zip fails
+	// the change stays in the journal and is finished on restart
+	if err := i.delZip(mod); err != nil {
+		return err
+	}
+
+	i.end(mod)
+	return nil
+}
+
+func (i *ingester) end(mod coordinates.Module) {
+	if err := i.journal.EndChange(mod); err != nil {
+		// only means the change is looked at again on restart
+		i.log.Warnf("failed to end change of %s, %v", mod, err)
+	}
+}
+
+// delZip removes the zip of a module, which may not have been stored at all.
+func (i *ingester) delZip(mod coordinates.Module) error {
+	err := i.store.DelZip(mod)
+	if err == nil {
+		return nil
+	}
+
+	zip, openErr := i.store.OpenZip(mod)
+	if openErr != nil {
+		return nil // was never stored, or is already removed
+	}
+	ignore.Close(zip)
+	return err
+}
+
+func (i *ingester) recover() error {
+	changes, err := i.journal.Changes()
+	if err != nil {
+		return err
+	}
+
+	for _, change := range changes {
+		if err := i.recoverChange(change); err != nil {
+			i.emitter.Count("ingest-recover-failure", 1)
+			return errors.Wrapf(err, "failed to recover %s %s", change.Kind, change.Module)
+		}
+		i.emitter.Count("ingest-recover-ok", 1)
+	}
+
+	if len(changes) > 0 {
+		i.log.Infof("recovered %d unfinished changes", len(changes))
+	}
+	return nil
+}
+
+func (i *ingester) recoverChange(change Change) error {
+	mod := change.Module
+
+	switch change.Kind {
+	case Adding:
+		exists, _, err := i.index.Contains(mod)
+		if err != nil {
+			return err
+		}
+		if exists {
+			i.log.Infof("recovered adding %s, which was complete", mod)
+			break
+		}
+		i.log.Infof("recovered adding %s, which was not complete, removing its zip", mod)
+		if err := i.delZip(mod); err != nil {
+			return err
+		}
+
+	case Removing:
+		i.log.Infof("recovered removing %s, finishing removal", mod)
+		if exists, _, err := i.index.Contains(mod); err != nil {
+			return err
+		} else if exists {
+			if err := i.index.Remove(mod); err != nil {
+				return err
+			}
+		}
+		if err := i.delZip(mod); err != nil {
+			return err
+		}
+
+	default:
+		return errors.Errorf("unknown kind of change %q", change.Kind)
+	}
+
+	return i.journal.EndChange(mod)
+}
@@ -0,0 +1,157 @@
+package store
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// brokenIndex fails to add any module
+type brokenIndex struct {
+	Index
+}
+
+func (brokenIndex) Put(ModuleAddition) error {
+	return errors.New("index is broken")
+}
+
+func Test_Ingester(t *testing.T) {
+	indexDir, index := setupIndex(t)
+	defer cleanupIndex(t, indexDir)
+
+	dataDir, err := ioutil.TempDir("", "ingest-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dataDir) }()
+
+	zips := NewStore(Options{Directory: dataDir}, stats.Discard())
+	journal := index.(Journal)
+
+	addition := func(id int64, mod coordinates.Module) (ModuleAddition, repository.Blob) {
+		modFile := "module " + mod.Source + "\n"
+		blob := zipOf(t, mod.Source+"@"+mod.Version+"/go.mod", modFile)
+		hashes, err := repository.HashesOf(blob, modFile)
+		require.NoError(t, err)
+		return ModuleAddition{Mod: mod, UniqueID: id, ModFile: modFile, Hashes: hashes}, blob
+	}
+
+	stored := func(mod coordinates.Module) (bool, bool) {
+		inIndex, _, err := index.Contains(mod)
+		require.NoError(t, err)
+		zip, err := zips.OpenZip(mod)
+		if err == nil {
+			require.NoError(t, zip.Close())
+		}
+		return inIndex, err == nil
+	}
+
+	ingester, err := NewIngester(index, zips, journal, stats.Discard())
+	require.NoError(t, err)
+
+	// added to both
+	modA := newMod("example.com/a", "v1.0.0")
+	add, blob := addition(1, modA)
+	err = ingester.Ingest(add, blob)
+	require.NoError(t, err)
+	inIndex, inStore := stored(modA)
+	require.True(t, inIndex)
+	require.True(t, inStore)
+
+	// removed from both
+	err = ingester.Remove(modA)
+	require.NoError(t, err)
+	inIndex, inStore = stored(modA)
+	require.False(t, inIndex)
+	require.False(t, inStore)
+
+	// added to neither, the zip is rolled back
+	broken, err := NewIngester(brokenIndex{Index: index}, zips, journal, stats.Discard())
+	require.NoError(t, err)
+	err = broken.Ingest(add, blob)
+	require.Error(t, err)
+	inIndex, inStore = stored(modA)
+	require.False(t, inIndex)
+	require.False(t, inStore)
+
+	changes, err := journal.Changes()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
+func Test_Ingester_recover(t *testing.T) {
+	indexDir, index := setupIndex(t)
+	defer cleanupIndex(t, indexDir)
+
+	dataDir, err := ioutil.TempDir("", "ingest-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(dataDir) }()
+
+	zips := NewStore(Options{Directory: dataDir}, stats.Discard())
+	journal := index.(Journal)
+
+	put := func(id int64, mod coordinates.Module, indexed bool) {
+		modFile := "module " + mod.Source + "\n"
+		blob := zipOf(t, mod.Source+"@"+mod.Version+"/go.mod", modFile)
+		require.NoError(t, zips.PutZip(mod, blob))
+		if indexed {
+			require.NoError(t, index.Put(ModuleAddition{Mod: mod, UniqueID: id, ModFile: modFile}))
+		}
+	}
+
+	begin := func(mod coordinates.Module, kind ChangeKind) {
+		require.NoError(t, journal.BeginChange(Change{Module: mod, Kind: kind, Started: time.Now()}))
+	}
+
+	// crashed after the zip was stored, before the index entry
+	modA := newMod("example.com/a", "v1.0.0")
+	put(1, modA, false)
+	begin(modA, Adding)
+
+	// crashed after both were stored, before ending the change
+	modB := newMod("example.com/b", "v1.0.0")
+	put(2, modB, true)
+	begin(modB, Adding)
+
+	// crashed before anything was stored
+	modC := newMod("example.com/c", "v1.0.0")
+	begin(modC, Adding)
+
+	// crashed part way through removing
+	modD := newMod("example.com/d", "v1.0.0")
+	put(4, modD, true)
+	begin(modD, Removing)
+
+	_, err = NewIngester(index, zips, journal, stats.Discard())
+	require.NoError(t, err)
+
+	for _, expect := range []struct {
+		mod    coordinates.Module
+		stored bool
+	}{
+		{mod: modA, stored: false},
+		{mod: modB, stored: true},
+		{mod: modC, stored: false},
+		{mod: modD, stored: false},
+	} {
+		inIndex, _, err := index.Contains(expect.mod)
+		require.NoError(t, err)
+		require.Equal(t, expect.stored, inIndex, expect.mod.String())
+
+		zip, err := zips.OpenZip(expect.mod)
+		require.Equal(t, expect.stored, err == nil, expect.mod.String())
+		if err == nil {
+			require.NoError(t, zip.Close())
+		}
+	}
+
+	changes, err := journal.Changes()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
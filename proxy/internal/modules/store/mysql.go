@@ -1,19 +1,25 @@
 package store
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"encoding/json"
 	"io"
+	"io/ioutil"
 	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/pkg/errors"
+	"golang.org/x/mod/sumdb/tlog"
 
 	"gophers.dev/pkgs/loggy"
 
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
 	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
 )
 
 type mysqlStore struct {
@@ -25,15 +31,28 @@ type mysqlStore struct {
 
 var _ ZipStore = (*mysqlStore)(nil)
 var _ Index = (*mysqlStore)(nil)
+var _ problems.Store = (*mysqlStore)(nil)
+var _ sumdb.Store = (*mysqlStore)(nil)
+var _ AccessLog = (*mysqlStore)(nil)
+var _ Journal = (*mysqlStore)(nil)
 
 const dbTimeout = 10 * time.Second
 
+// error numbers of the mysql server, for errors which are worth telling apart
+const (
+	mysqlDuplicateKey = 1062
+	mysqlDeadlock     = 1213
+)
+
 // PutZip implements ZipStore.PutZip
-func (m *mysqlStore) PutZip(mod coordinates.Module, blob repository.Blob) error {
+//
+// The zip is stored in a single column, so unlike the other ZipStore
+// implementations the whole zip is held in memory while it is stored.
+func (m *mysqlStore) PutZip(mod coordinates.Module, archive repository.Archive) error {
 	m.log.Tracef("put module zip %s", mod)
 	start := time.Now()
 
-	if err := m.insertModulesZip(mod, blob); err != nil {
+	if err := m.insertModulesZip(mod, archive); err != nil {
 		m.emitter.Count("db-put-zip-failure", 1)
 		return err
 	}
@@ -42,8 +61,11 @@ func (m *mysqlStore) PutZip(mod coordinates.Module, blob repository.Blob) error
 	return nil
 }
 
-// GetZip implements ZipStore.GetZip
-func (m *mysqlStore) GetZip(mod coordinates.Module) (repository.Blob, error) {
+// OpenZip implements ZipStore.OpenZip
+//
+// The zip is stored in a single column, so unlike the other ZipStore
+// implementations the whole zip is read into memory to be opened.
+func (m *mysqlStore) OpenZip(mod coordinates.Module) (Zip, error) {
 	m.log.Tracef("get module zip %s", mod)
 	start := time.Now()
 
@@ -54,7 +76,7 @@ func (m *mysqlStore) GetZip(mod coordinates.Module) (repository.Blob, error) {
 	}
 
 	m.emitter.GaugeMS("db-get-zip-elapsed-ms", start)
-	return blob, nil
+	return &memZip{Reader: bytes.NewReader(blob)}, nil
 }
 
 // DelZip implements ZipStore.DelZip
@@ -190,8 +212,10 @@ func (m *mysqlStore) Put(add ModuleAddition) error {
 		add.Mod.Source,
 		add.Mod.Version,
 		[]byte(add.ModFile),
-		newRevInfo(add.Mod).Bytes(),
+		newRevInfo(add).Bytes(),
 		add.UniqueID,
+		add.Hashes.Zip,
+		add.Hashes.Mod,
 	)
 	if err != nil {
 		m.emitter.Count("db-insert-mod-failure", 1)
@@ -216,12 +240,468 @@ func (m *mysqlStore) IDs() (Ranges, error) {
 	return ranges(ids), nil
 }
 
+// List implements Index.List
+func (m *mysqlStore) List() ([]coordinates.SerialModule, error) {
+	m.log.Tracef("retrieving all modules")
+	start := time.Now()
+
+	mods, err := m.listModules()
+	if err != nil {
+		return nil, err
+	}
+
+	m.emitter.GaugeMS("db-list-modules-elapsed-ms", start)
+	return mods, nil
+}
+
 // Summary implements Index.Summary
 func (m *mysqlStore) Summary() (int, int, error) {
 	m.log.Tracef("retrieving all sources and computing summary")
 	return m.countSourcesAndVersions()
 }
 
+// Hashes implements Index.Hashes
+func (m *mysqlStore) Hashes(mod coordinates.Module) (repository.Hashes, error) {
+	m.log.Tracef("retrieving hashes for module %s", mod)
+	start := time.Now()
+
+	hashes, err := m.getHashes(mod)
+	if err != nil {
+		m.emitter.Count("db-get-hashes-failure", 1)
+		return repository.Hashes{}, err
+	}
+
+	m.emitter.GaugeMS("db-get-hashes-elapsed-ms", start)
+	return hashes, nil
+}
+
+// UpdateHashes implements Index.UpdateHashes
+func (m *mysqlStore) UpdateHashes(mod coordinates.Module, hashes repository.Hashes) error {
+	m.log.Tracef("updating hashes for module %s", mod)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	exists, _, err := m.getRegistryID(mod)
+	if err != nil {
+		m.emitter.Count("db-update-hashes-failure", 1)
+		return err
+	}
+	if !exists {
+		return errors.New("module not in index")
+	}
+
+	if _, err := m.statements[updateModuleHashesSQL].ExecContext(
+		ctx,
+		hashes.Zip,
+		hashes.Mod,
+		mod.Source,
+		mod.Version,
+	); err != nil {
+		m.emitter.Count("db-update-hashes-failure", 1)
+		return err
+	}
+
+	m.emitter.GaugeMS("db-update-hashes-elapsed-ms", start)
+	return nil
+}
+
+// ZipRefs implements Index.ZipRefs
+func (m *mysqlStore) ZipRefs(zipHash string) (int, error) {
+	m.log.Tracef("counting references to zip %s", zipHash)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var refs int
+	row := m.statements[countZipRefsSQL].QueryRowContext(ctx, zipHash)
+	if err := row.Scan(&refs); err != nil {
+		m.emitter.Count("db-zip-refs-failure", 1)
+		return 0, errors.Wrapf(err, "failed to read row for sql: %+v", m.statements[countZipRefsSQL])
+	}
+
+	m.emitter.GaugeMS("db-zip-refs-elapsed-ms", start)
+	return refs, nil
+}
+
+// PutProblem implements problems.Store.PutProblem
+func (m *mysqlStore) PutProblem(problem problems.Problem) error {
+	m.log.Tracef("put problem for module %s", problem.Module)
+	start := time.Now()
+
+	bs, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err = m.statements[upsertProblemSQL].ExecContext(
+		ctx,
+		problem.Module.Source,
+		problem.Module.Version,
+		bs,
+		bs,
+	)
+	if err != nil {
+		m.emitter.Count("db-put-problem-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-put-problem-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// DeleteProblem implements problems.Store.DeleteProblem
+func (m *mysqlStore) DeleteProblem(mod coordinates.Module) error {
+	m.log.Tracef("delete problem for module %s", mod)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err := m.statements[deleteProblemSQL].ExecContext(
+		ctx,
+		mod.Source,
+		mod.Version,
+	)
+	if err != nil {
+		m.emitter.Count("db-delete-problem-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-delete-problem-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// ListProblems implements problems.Store.ListProblems
+func (m *mysqlStore) ListProblems() ([]problems.Problem, error) {
+	m.log.Tracef("retrieving all problems")
+	start := time.Now()
+
+	list, err := m.queryProblems(selectAllProblemsSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	m.emitter.GaugeMS("db-list-problems-elapsed-ms", start)
+	return list, nil
+}
+
+// PutResolved implements problems.Store.PutResolved
+func (m *mysqlStore) PutResolved(problem problems.Problem) error {
+	m.log.Tracef("put resolved problem for module %s", problem.Module)
+	start := time.Now()
+
+	bs, err := json.Marshal(problem)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err = m.statements[insertResolvedSQL].ExecContext(
+		ctx,
+		problem.Module.Source,
+		problem.Module.Version,
+		problem.ResolvedAt().UnixNano(),
+		bs,
+	)
+	if err != nil {
+		m.emitter.Count("db-put-resolved-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-put-resolved-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// DeleteResolved implements problems.Store.DeleteResolved
+func (m *mysqlStore) DeleteResolved(before time.Time) error {
+	m.log.Tracef("delete problems resolved before %s", before)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err := m.statements[deleteResolvedSQL].ExecContext(ctx, before.UnixNano())
+	if err != nil {
+		m.emitter.Count("db-delete-resolved-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-delete-resolved-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// ListResolved implements problems.Store.ListResolved
+func (m *mysqlStore) ListResolved() ([]problems.Problem, error) {
+	m.log.Tracef("retrieving all resolved problems")
+	start := time.Now()
+
+	list, err := m.queryProblems(selectAllResolvedSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	m.emitter.GaugeMS("db-list-resolved-elapsed-ms", start)
+	return list, nil
+}
+
+// AddAccesses implements AccessLog.AddAccesses
+func (m *mysqlStore) AddAccesses(added []Access) error {
+	m.log.Tracef("add accesses for %d modules", len(added))
+	start := time.Now()
+
+	if err := m.addAccesses(added); err != nil {
+		m.emitter.Count("db-add-accesses-failure", 1)
+		return err
+	}
+
+	m.emitter.GaugeMS("db-add-accesses-elapsed-ms", start)
+	return nil
+}
+
+func (m *mysqlStore) addAccesses(added []Access) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	addAccess := tx.StmtContext(ctx, m.statements[addAccessSQL])
+	for _, add := range added {
+		if _, err := addAccess.ExecContext(
+			ctx,
+			add.Module.Source,
+			add.Module.Version,
+			add.Last.UnixNano(),
+			add.Count,
+			add.Last.UnixNano(),
+			add.Count,
+		); err != nil {
+			_ = tx.Rollback()
+			return errors.Wrapf(err, "failed to add access of %s", add.Module)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// PutAccess implements AccessLog.PutAccess
+func (m *mysqlStore) PutAccess(access Access) error {
+	m.log.Tracef("put access for module %s", access.Module)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err := m.statements[upsertAccessSQL].ExecContext(
+		ctx,
+		access.Module.Source,
+		access.Module.Version,
+		access.Size,
+		access.Last.UnixNano(),
+		access.Count,
+		access.Size,
+		access.Last.UnixNano(),
+		access.Count,
+	)
+	if err != nil {
+		m.emitter.Count("db-put-access-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-put-access-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// RemoveAccess implements AccessLog.RemoveAccess
+func (m *mysqlStore) RemoveAccess(mod coordinates.Module) error {
+	m.log.Tracef("remove access for module %s", mod)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err := m.statements[deleteAccessSQL].ExecContext(
+		ctx,
+		mod.Source,
+		mod.Version,
+	)
+	if err != nil {
+		m.emitter.Count("db-remove-access-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-remove-access-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// Accesses implements AccessLog.Accesses
+func (m *mysqlStore) Accesses() ([]Access, error) {
+	m.log.Tracef("retrieving all accesses")
+	start := time.Now()
+
+	list, err := m.queryAccesses()
+	if err != nil {
+		m.emitter.Count("db-list-accesses-failure", 1)
+		return nil, err
+	}
+
+	m.emitter.GaugeMS("db-list-accesses-elapsed-ms", start)
+	return list, nil
+}
+
+// BeginChange implements Journal.BeginChange
+func (m *mysqlStore) BeginChange(change Change) error {
+	m.log.Tracef("begin change %s of module %s", change.Kind, change.Module)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err := m.statements[upsertChangeSQL].ExecContext(
+		ctx,
+		change.Module.Source,
+		change.Module.Version,
+		string(change.Kind),
+		change.Started.UnixNano(),
+		string(change.Kind),
+		change.Started.UnixNano(),
+	)
+	if err != nil {
+		m.emitter.Count("db-begin-change-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-begin-change-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// EndChange implements Journal.EndChange
+func (m *mysqlStore) EndChange(mod coordinates.Module) error {
+	m.log.Tracef("end change of module %s", mod)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	_, err := m.statements[deleteChangeSQL].ExecContext(
+		ctx,
+		mod.Source,
+		mod.Version,
+	)
+	if err != nil {
+		m.emitter.Count("db-end-change-failure", 1)
+	} else {
+		m.emitter.GaugeMS("db-end-change-elapsed-ms", start)
+	}
+
+	return err
+}
+
+// Changes implements Journal.Changes
+func (m *mysqlStore) Changes() ([]Change, error) {
+	m.log.Tracef("retrieving all changes")
+	start := time.Now()
+
+	list, err := m.queryChanges()
+	if err != nil {
+		m.emitter.Count("db-list-changes-failure", 1)
+		return nil, err
+	}
+
+	m.emitter.GaugeMS("db-list-changes-elapsed-ms", start)
+	return list, nil
+}
+
+// AppendRecord implements sumdb.Store.AppendRecord
+func (m *mysqlStore) AppendRecord(id int64, mod coordinates.Module, data []byte, hashes []tlog.Hash) error {
+	m.log.Tracef("append checksum database record %d for module %s", id, mod)
+	start := time.Now()
+
+	if err := m.insertRecord(id, mod, data, hashes); err != nil {
+		m.emitter.Count("db-append-record-failure", 1)
+		return err
+	}
+
+	m.emitter.GaugeMS("db-append-record-elapsed-ms", start)
+	return nil
+}
+
+// LookupRecord implements sumdb.Store.LookupRecord
+func (m *mysqlStore) LookupRecord(mod coordinates.Module) (int64, bool, error) {
+	m.log.Tracef("lookup checksum database record for module %s", mod)
+	start := time.Now()
+
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var id int64
+	err := m.statements[selectRecordIDSQL].QueryRowContext(ctx, mod.Source, mod.Version).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		m.emitter.Count("db-lookup-record-failure", 1)
+		return 0, false, errors.Wrapf(err, "failed to read row for sql: %+v", m.statements[selectRecordIDSQL])
+	}
+
+	m.emitter.GaugeMS("db-lookup-record-elapsed-ms", start)
+	return id, true, nil
+}
+
+// ReadRecords implements sumdb.Store.ReadRecords
+func (m *mysqlStore) ReadRecords(id, n int64) ([][]byte, error) {
+	m.log.Tracef("read %d checksum database records starting at %d", n, id)
+	start := time.Now()
+
+	records, err := m.selectRecords(id, n)
+	if err != nil {
+		m.emitter.Count("db-read-records-failure", 1)
+		return nil, err
+	}
+
+	m.emitter.GaugeMS("db-read-records-elapsed-ms", start)
+	return records, nil
+}
+
+// CountRecords implements sumdb.Store.CountRecords
+func (m *mysqlStore) CountRecords() (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	var n int64
+	if err := m.statements[countRecordsSQL].QueryRowContext(ctx).Scan(&n); err != nil {
+		m.emitter.Count("db-count-records-failure", 1)
+		return 0, errors.Wrapf(err, "failed to read row for sql: %+v", m.statements[countRecordsSQL])
+	}
+
+	return n, nil
+}
+
+// ReadHashes implements sumdb.Store.ReadHashes
+func (m *mysqlStore) ReadHashes(indexes []int64) ([]tlog.Hash, error) {
+	m.log.Tracef("read %d checksum database hashes", len(indexes))
+	start := time.Now()
+
+	hashes, err := m.selectHashes(indexes)
+	if err != nil {
+		m.emitter.Count("db-read-hashes-failure", 1)
+		return nil, err
+	}
+
+	m.emitter.GaugeMS("db-read-hashes-elapsed-ms", start)
+	return hashes, nil
+}
+
 const (
 	insertModuleZipSQL = iota
 	selectModuleZipSQL
@@ -229,12 +709,36 @@ const (
 	insertModuleSQL
 	selectRegistryIDSQL
 	selectAllRegistryIDsSQL
+	selectAllModulesSQL
 	countVersionsSQL
 	selectModuleVersionInfoSQL
 	selectGoModFileSQL
 	selectModuleVersionsSQL
 	updateRegistryIDSQL
 	deleteModuleSQL
+	selectModuleHashesSQL
+	updateModuleHashesSQL
+	countZipRefsSQL
+	upsertProblemSQL
+	deleteProblemSQL
+	selectAllProblemsSQL
+	insertResolvedSQL
+	deleteResolvedSQL
+	selectAllResolvedSQL
+	addAccessSQL
+	upsertAccessSQL
+	deleteAccessSQL
+	selectAllAccessesSQL
+	upsertChangeSQL
+	deleteChangeSQL
+	selectAllChangesSQL
+	insertRecordSQL
+	insertHashSQL
+	selectRecordIDSQL
+	selectRecordsSQL
+	countRecordsSQL
+	lockRecordsSQL
+	selectHashesSQL
 )
 
 type statements map[int]*sql.Stmt
@@ -263,7 +767,8 @@ var (
 		// proxy_modules_index tables, because the proxy itself is designed to
 		// keep these two discrete data-stores eventually consistent. This is
 		// a historical feature due to the boltDB + filesystem implementation
-		// that came first.
+		// that came first. Modules are added to and removed from both through
+		// the Ingester, which keeps them consistent using the Journal instead.
 
 		// Table proxy_module_zips used to implement ZipStore.
 		insertModuleZipSQL: `insert into proxy_module_zips(s_at_v, zip) values (?, ?)`,
@@ -271,26 +776,65 @@ var (
 		deleteModuleZipSQL: `delete from proxy_module_zips where s_at_v=?`,
 
 		// Table proxy_modules_index used to implement Index.
-		insertModuleSQL:            `insert into proxy_modules_index(source, version, go_mod_file, version_info, registry_mod_id) values (?, ?, ?, ?, ?)`,
+		insertModuleSQL:            `insert into proxy_modules_index(source, version, go_mod_file, version_info, registry_mod_id, zip_hash, go_mod_hash) values (?, ?, ?, ?, ?, ?, ?)`,
 		selectRegistryIDSQL:        `select registry_mod_id from proxy_modules_index where source=? and version=?`,
 		selectAllRegistryIDsSQL:    `select registry_mod_id from proxy_modules_index`,
+		selectAllModulesSQL:        `select source, version, registry_mod_id from proxy_modules_index`,
 		countVersionsSQL:           `select count(version) from proxy_modules_index group by source`,
 		selectModuleVersionInfoSQL: `select version_info from proxy_modules_index where source=? and version=?`,
 		selectGoModFileSQL:         `select go_mod_file from proxy_modules_index where source=? and version=?`,
 		selectModuleVersionsSQL:    `select version from proxy_modules_index where source=?`,
 		updateRegistryIDSQL:        `update proxy_modules_index set registry_mod_id=? where source=? and version=?`,
 		deleteModuleSQL:            `delete from proxy_modules_index where source=? and version=?`,
+		selectModuleHashesSQL:      `select zip_hash, go_mod_hash from proxy_modules_index where source=? and version=?`,
+		updateModuleHashesSQL:      `update proxy_modules_index set zip_hash=?, go_mod_hash=? where source=? and version=?`,
+		countZipRefsSQL:            `select count(id) from proxy_modules_index where zip_hash=? or zip_hash=''`,
+
+		// Table proxy_problems used to implement problems.Store.
+		upsertProblemSQL:     `insert into proxy_problems(source, version, problem) values (?, ?, ?) on duplicate key update problem=?`,
+		deleteProblemSQL:     `delete from proxy_problems where source=? and version=?`,
+		selectAllProblemsSQL: `select problem from proxy_problems`,
+
+		// Table proxy_resolved_problems used to implement problems.Store.
+		insertResolvedSQL:    `insert into proxy_resolved_problems(source, version, resolved, problem) values (?, ?, ?, ?)`,
+		deleteResolvedSQL:    `delete from proxy_resolved_problems where resolved < ?`,
+		selectAllResolvedSQL: `select problem from proxy_resolved_problems`,
+
+		// Table proxy_module_accesses used to implement AccessLog.
+		addAccessSQL:         `insert into proxy_module_accesses(source, version, zip_size, last_access, access_count) values (?, ?, 0, ?, ?) on duplicate key update last_access=greatest(last_access, ?), access_count=access_count+?`,
+		upsertAccessSQL:      `insert into proxy_module_accesses(source, version, zip_size, last_access, access_count) values (?, ?, ?, ?, ?) on duplicate key update zip_size=?, last_access=?, access_count=?`,
+		deleteAccessSQL:      `delete from proxy_module_accesses where source=? and version=?`,
+		selectAllAccessesSQL: `select source, version, zip_size, last_access, access_count from proxy_module_accesses`,
+
+		// Table proxy_module_journal used to implement Journal.
+		upsertChangeSQL:     `insert into proxy_module_journal(source, version, kind, started) values (?, ?, ?, ?) on duplicate key update kind=?, started=?`,
+		deleteChangeSQL:     `delete from proxy_module_journal where source=? and version=?`,
+		selectAllChangesSQL: `select source, version, kind, started from proxy_module_journal`,
+
+		// Tables proxy_sumdb_records and proxy_sumdb_hashes used to implement sumdb.Store.
+		insertRecordSQL:   `insert into proxy_sumdb_records(id, source, version, data) values (?, ?, ?, ?)`,
+		insertHashSQL:     `insert into proxy_sumdb_hashes(id, hash) values (?, ?)`,
+		selectRecordIDSQL: `select id from proxy_sumdb_records where source=? and version=?`,
+		selectRecordsSQL:  `select id, data from proxy_sumdb_records where id >= ? and id < ? order by id`,
+		countRecordsSQL:   `select count(id) from proxy_sumdb_records`,
+		lockRecordsSQL:    `select count(id) from proxy_sumdb_records for update`,
+		selectHashesSQL:   `select id, hash from proxy_sumdb_hashes where id >= ? and id <= ?`,
 	}
 )
 
 // for ZipStore.PutZip
-func (m *mysqlStore) insertModulesZip(mod coordinates.Module, blob repository.Blob) error {
+func (m *mysqlStore) insertModulesZip(mod coordinates.Module, archive repository.Archive) error {
 	sAtV := mod.AtVersion()
 
+	blob, err := ioutil.ReadAll(io.NewSectionReader(archive, 0, archive.Size()))
+	if err != nil {
+		return err
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
 	defer cancel()
 
-	if _, err := m.statements[insertModuleZipSQL].ExecContext(ctx, sAtV, []byte(blob)); err != nil {
+	if _, err := m.statements[insertModuleZipSQL].ExecContext(ctx, sAtV, blob); err != nil {
 		m.emitter.Count("db-insert-module-failure", 1)
 		m.log.Errorf("failed to write zip for %s, %+v", mod, err)
 		return err
@@ -298,7 +842,7 @@ func (m *mysqlStore) insertModulesZip(mod coordinates.Module, blob repository.Bl
 	return nil
 }
 
-// for ZipStore.GetZip
+// for ZipStore.OpenZip
 func (m *mysqlStore) getModuleZip(mod coordinates.Module) (repository.Blob, error) {
 	sAtV := mod.AtVersion()
 
@@ -431,6 +975,24 @@ func (m *mysqlStore) getGoMod(mod coordinates.Module) (string, error) {
 	return string(contents), nil
 }
 
+// for Index.Hashes
+func (m *mysqlStore) getHashes(mod coordinates.Module) (repository.Hashes, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	row := m.statements[selectModuleHashesSQL].QueryRowContext(ctx, mod.Source, mod.Version)
+
+	var hashes repository.Hashes
+	if err := row.Scan(&hashes.Zip, &hashes.Mod); err != nil {
+		if err == sql.ErrNoRows {
+			return hashes, errors.New("module not in index")
+		}
+		return hashes, errors.Wrapf(err, "failed to read row for sql: %+v", m.statements[selectModuleHashesSQL])
+	}
+
+	return hashes, nil
+}
+
 // for Index
 func (m *mysqlStore) ids() ([]int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
@@ -461,6 +1023,36 @@ func (m *mysqlStore) ids() ([]int64, error) {
 	return ids, nil
 }
 
+// for Index
+func (m *mysqlStore) listModules() ([]coordinates.SerialModule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	rows, err := m.statements[selectAllModulesSQL].QueryContext(ctx)
+	if err != nil {
+		m.emitter.Count("db-select-modules-failure", 1)
+		return nil, errors.Wrapf(err, "failed to query modules")
+	}
+	defer ignoreClose(rows)
+
+	mods := make([]coordinates.SerialModule, 0, 10)
+	for rows.Next() {
+		var mod coordinates.SerialModule
+		if err := rows.Scan(&mod.Source, &mod.Version, &mod.SerialID); err != nil {
+			m.emitter.Count("db-select-modules-failure", 1)
+			return nil, errors.Wrapf(err, "failed to scan row for sql: %+v", m.statements[selectAllModulesSQL])
+		}
+		mods = append(mods, mod)
+	}
+
+	if err := rows.Err(); err != nil {
+		m.emitter.Count("db-select-modules-failure", 1)
+		return nil, errors.Wrapf(err, "got error from rows")
+	}
+
+	return mods, nil
+}
+
 // for Index
 func (m *mysqlStore) countSourcesAndVersions() (int, int, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
@@ -493,6 +1085,262 @@ func (m *mysqlStore) countSourcesAndVersions() (int, int, error) {
 	return totalSources, totalVersions, nil
 }
 
+// for AccessLog.Accesses
+func (m *mysqlStore) queryAccesses() ([]Access, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	rows, err := m.statements[selectAllAccessesSQL].QueryContext(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query accesses")
+	}
+	defer ignoreClose(rows)
+
+	list := make([]Access, 0, 10)
+	for rows.Next() {
+		var access Access
+		var last int64
+		if err := rows.Scan(
+			&access.Module.Source,
+			&access.Module.Version,
+			&access.Size,
+			&last,
+			&access.Count,
+		); err != nil {
+			return nil, errors.Wrapf(err, "failed to scan row for sql: %+v", m.statements[selectAllAccessesSQL])
+		}
+		access.Last = time.Unix(0, last)
+		list = append(list, access)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "got error from rows")
+	}
+
+	return list, nil
+}
+
+// for Journal.Changes
+func (m *mysqlStore) queryChanges() ([]Change, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	rows, err := m.statements[selectAllChangesSQL].QueryContext(ctx)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query changes")
+	}
+	defer ignoreClose(rows)
+
+	list := make([]Change, 0, 10)
+	for rows.Next() {
+		var change Change
+		var kind string
+		var started int64
+		if err := rows.Scan(
+			&change.Module.Source,
+			&change.Module.Version,
+			&kind,
+			&started,
+		); err != nil {
+			return nil, errors.Wrapf(err, "failed to scan row for sql: %+v", m.statements[selectAllChangesSQL])
+		}
+		change.Kind = ChangeKind(kind)
+		change.Started = time.Unix(0, started)
+		list = append(list, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "got error from rows")
+	}
+
+	return list, nil
+}
+
+// for problems.Store
+func (m *mysqlStore) queryProblems(statement int) ([]problems.Problem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	rows, err := m.statements[statement].QueryContext(ctx)
+	if err != nil {
+		m.emitter.Count("db-select-problems-failure", 1)
+		return nil, errors.Wrapf(err, "failed to query problems")
+	}
+	defer ignoreClose(rows)
+
+	list := make([]problems.Problem, 0, 10)
+	for rows.Next() {
+		var contents []byte
+		if err := rows.Scan(&contents); err != nil {
+			m.emitter.Count("db-select-problems-failure", 1)
+			return nil, errors.Wrapf(err, "failed to scan row for sql: %+v", m.statements[statement])
+		}
+
+		var problem problems.Problem
+		if err := json.Unmarshal(contents, &problem); err != nil {
+			return nil, errors.Wrap(err, "failed to decode problem")
+		}
+		list = append(list, problem)
+	}
+
+	if err := rows.Err(); err != nil {
+		m.emitter.Count("db-select-problems-failure", 1)
+		return nil, errors.Wrapf(err, "got error from rows")
+	}
+
+	return list, nil
+}
+
+// for sumdb.Store
+func (m *mysqlStore) insertRecord(id int64, mod coordinates.Module, data []byte, hashes []tlog.Hash) error {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// unlike the zips and the index, the record and its hashes must be stored
+	// together, otherwise the log would be corrupt
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+
+	// the count is a locking read, so other instances of the proxy sharing
+	// the database append one at a time
+	var n int64
+	if err := tx.StmtContext(ctx, m.statements[lockRecordsSQL]).QueryRowContext(ctx).Scan(&n); err != nil {
+		_ = tx.Rollback()
+		return recordConflict(id, errors.Wrap(err, "failed to count records"))
+	}
+
+	// the log must only ever be appended to
+	if id != n {
+		_ = tx.Rollback()
+		return &sumdb.Conflict{ID: id, Records: n}
+	}
+
+	if _, err := tx.StmtContext(ctx, m.statements[insertRecordSQL]).ExecContext(
+		ctx, id, mod.Source, mod.Version, data,
+	); err != nil {
+		_ = tx.Rollback()
+		return recordConflict(id, errors.Wrapf(err, "failed to insert record of %s", mod))
+	}
+
+	insertHash := tx.StmtContext(ctx, m.statements[insertHashSQL])
+	index := tlog.StoredHashIndex(0, id)
+	for _, hash := range hashes {
+		if _, err := insertHash.ExecContext(ctx, index, hash[:]); err != nil {
+			_ = tx.Rollback()
+			return recordConflict(id, errors.Wrapf(err, "failed to insert hash %d", index))
+		}
+		index++
+	}
+
+	return recordConflict(id, tx.Commit())
+}
+
+// recordConflict returns a sumdb.Conflict in place of err when appending
+// record id failed because another instance of the proxy appended a record
+// at the same time, which is either a duplicate key or a deadlock.
+func recordConflict(id int64, err error) error {
+	if driverErr, ok := errors.Cause(err).(*mysql.MySQLError); ok {
+		switch driverErr.Number {
+		case mysqlDuplicateKey, mysqlDeadlock:
+			return &sumdb.Conflict{ID: id, Records: -1}
+		}
+	}
+	return err
+}
+
+// for sumdb.Store
+func (m *mysqlStore) selectRecords(id, n int64) ([][]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	rows, err := m.statements[selectRecordsSQL].QueryContext(ctx, id, id+n)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query records")
+	}
+	defer ignoreClose(rows)
+
+	records := make([][]byte, 0, n)
+	for rows.Next() {
+		var recordID int64
+		var data []byte
+		if err := rows.Scan(&recordID, &data); err != nil {
+			return nil, errors.Wrapf(err, "failed to scan row for sql: %+v", m.statements[selectRecordsSQL])
+		}
+		if next := id + int64(len(records)); recordID != next {
+			return nil, missing("record", next)
+		}
+		records = append(records, data)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrapf(err, "got error from rows")
+	}
+
+	if int64(len(records)) != n {
+		return nil, missing("record", id+int64(len(records)))
+	}
+
+	return records, nil
+}
+
+// for sumdb.Store
+func (m *mysqlStore) selectHashes(indexes []int64) ([]tlog.Hash, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), dbTimeout)
+	defer cancel()
+
+	// the indexes of a tile are contiguous, but the indexes needed to compute
+	// the hash of the whole tree are spread out, so read each contiguous run
+	// of indexes with its own query
+	sorted := make([]int64, len(indexes))
+	copy(sorted, indexes)
+
+	found := make(map[int64]tlog.Hash, len(indexes))
+	for _, run := range ranges(sorted) {
+		if err := m.selectHashRange(ctx, run[0], run[1], found); err != nil {
+			return nil, err
+		}
+	}
+
+	hashes := make([]tlog.Hash, 0, len(indexes))
+	for _, index := range indexes {
+		hash, exists := found[index]
+		if !exists {
+			return nil, missing("hash", index)
+		}
+		hashes = append(hashes, hash)
+	}
+
+	return hashes, nil
+}
+
+// for sumdb.Store
+func (m *mysqlStore) selectHashRange(ctx context.Context, low, high int64, found map[int64]tlog.Hash) error {
+	rows, err := m.statements[selectHashesSQL].QueryContext(ctx, low, high)
+	if err != nil {
+		return errors.Wrapf(err, "failed to query hashes")
+	}
+	defer ignoreClose(rows)
+
+	for rows.Next() {
+		var index int64
+		var bs []byte
+		if err := rows.Scan(&index, &bs); err != nil {
+			return errors.Wrapf(err, "failed to scan row for sql: %+v", m.statements[selectHashesSQL])
+		}
+		var hash tlog.Hash
+		copy(hash[:], bs)
+		found[index] = hash
+	}
+
+	if err := rows.Err(); err != nil {
+		return errors.Wrapf(err, "got error from rows")
+	}
+
+	return nil
+}
+
 func ignoreClose(c io.Closer) {
 	_ = c.Close()
 }
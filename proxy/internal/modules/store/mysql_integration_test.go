@@ -14,10 +14,12 @@ import (
 
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
+	"golang.org/x/mod/sumdb/tlog"
 
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
 	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/problems"
 )
 
 type testSuite struct {
@@ -28,7 +30,7 @@ type testSuite struct {
 	subject *mysqlStore
 }
 
-func (s *testSuite) Test_ZipStore_PutZip_GetZip() {
+func (s *testSuite) Test_ZipStore_PutZip_OpenZip() {
 	t := s.T()
 
 	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
@@ -36,9 +38,11 @@ func (s *testSuite) Test_ZipStore_PutZip_GetZip() {
 	err := s.subject.PutZip(module, blob)
 	require.NoError(t, err)
 
-	actual, err := s.subject.GetZip(module)
+	zip, err := s.subject.OpenZip(module)
 	require.NoError(t, err)
-	require.Equal(t, blob, actual)
+	actual, err := ioutil.ReadAll(zip)
+	require.NoError(t, err)
+	require.Equal(t, []byte(blob), actual)
 }
 
 func (s *testSuite) Test_ZipStore_PutZip_exists() {
@@ -53,11 +57,11 @@ func (s *testSuite) Test_ZipStore_PutZip_exists() {
 	require.Error(t, err)
 }
 
-func (s *testSuite) Test_ZipStore_GetZip_NotFound() {
+func (s *testSuite) Test_ZipStore_OpenZip_NotFound() {
 	t := s.T()
 
 	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
-	_, err := s.subject.GetZip(module)
+	_, err := s.subject.OpenZip(module)
 	require.Error(t, err)
 }
 
@@ -68,13 +72,13 @@ func (s *testSuite) Test_ZipStore_DelZip() {
 	blob := repository.Blob([]byte(string("hello")))
 	err := s.subject.PutZip(module, blob)
 	require.NoError(t, err)
-	_, err = s.subject.GetZip(module)
+	_, err = s.subject.OpenZip(module)
 	require.NoError(t, err)
 
 	err = s.subject.DelZip(module)
 	require.NoError(t, err)
 
-	_, err = s.subject.GetZip(module)
+	_, err = s.subject.OpenZip(module)
 	require.Error(t, err)
 }
 
@@ -129,6 +133,130 @@ func (s *testSuite) Test_Index_InfoNotFound() {
 	require.Equal(t, "module not in index", err.Error())
 }
 
+func (s *testSuite) Test_Index_Hashes() {
+	t := s.T()
+
+	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
+	hashes := repository.Hashes{
+		Zip: "h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=",
+		Mod: "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=",
+	}
+
+	_, err := s.subject.Hashes(module)
+	require.Error(t, err)
+	require.Equal(t, "module not in index", err.Error())
+
+	addition := ModuleAddition{Mod: module, UniqueID: int64(1234), ModFile: "foobar"}
+	err = s.subject.Put(addition)
+	require.NoError(t, err)
+
+	actual, err := s.subject.Hashes(module)
+	require.NoError(t, err)
+	require.Equal(t, repository.Hashes{}, actual)
+
+	err = s.subject.UpdateHashes(module, hashes)
+	require.NoError(t, err)
+
+	actual, err = s.subject.Hashes(module)
+	require.NoError(t, err)
+	require.Equal(t, hashes, actual)
+
+	err = s.subject.UpdateHashes(coordinates.Module{Source: "src1", Version: "v1.2.4"}, hashes)
+	require.Error(t, err)
+}
+
+func (s *testSuite) Test_Index_ZipRefs() {
+	t := s.T()
+
+	hashes := repository.Hashes{
+		Zip: "h1:iURUrRGxPUNPdy5/HRSm+Yj6okJ6UtLINN0Q9M4+h3I=",
+		Mod: "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0=",
+	}
+
+	refs, err := s.subject.ZipRefs(hashes.Zip)
+	require.NoError(t, err)
+	require.Equal(t, 0, refs)
+
+	for i, version := range []string{"v1.2.3", "v1.2.4"} {
+		module := coordinates.Module{Source: "src1", Version: version}
+		addition := ModuleAddition{Mod: module, UniqueID: int64(i), ModFile: "foobar", Hashes: hashes}
+		err = s.subject.Put(addition)
+		require.NoError(t, err)
+	}
+
+	refs, err = s.subject.ZipRefs(hashes.Zip)
+	require.NoError(t, err)
+	require.Equal(t, 2, refs)
+
+	// a module whose zip hash is not known yet might reference any zip
+	module := coordinates.Module{Source: "src1", Version: "v1.2.5"}
+	err = s.subject.Put(ModuleAddition{Mod: module, UniqueID: 2, ModFile: "foobar"})
+	require.NoError(t, err)
+
+	refs, err = s.subject.ZipRefs(hashes.Zip)
+	require.NoError(t, err)
+	require.Equal(t, 3, refs)
+}
+
+func (s *testSuite) Test_AccessLog() {
+	t := s.T()
+
+	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
+	first := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	err := s.subject.AddAccesses([]Access{{Module: module, Last: first, Count: 1}})
+	require.NoError(t, err)
+	err = s.subject.AddAccesses([]Access{{Module: module, Last: first.Add(time.Hour), Count: 1}})
+	require.NoError(t, err)
+
+	accesses, err := s.subject.Accesses()
+	require.NoError(t, err)
+	require.Len(t, accesses, 1)
+	require.Equal(t, int64(2), accesses[0].Count)
+	require.Equal(t, int64(0), accesses[0].Size)
+	require.True(t, first.Add(time.Hour).Equal(accesses[0].Last))
+
+	accesses[0].Size = 1024
+	err = s.subject.PutAccess(accesses[0])
+	require.NoError(t, err)
+
+	accesses, err = s.subject.Accesses()
+	require.NoError(t, err)
+	require.Equal(t, int64(1024), accesses[0].Size)
+
+	err = s.subject.RemoveAccess(module)
+	require.NoError(t, err)
+
+	accesses, err = s.subject.Accesses()
+	require.NoError(t, err)
+	require.Empty(t, accesses)
+}
+
+func (s *testSuite) Test_Journal() {
+	t := s.T()
+
+	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
+	started := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	err := s.subject.BeginChange(Change{Module: module, Kind: Adding, Started: started})
+	require.NoError(t, err)
+	err = s.subject.BeginChange(Change{Module: module, Kind: Removing, Started: started})
+	require.NoError(t, err)
+
+	changes, err := s.subject.Changes()
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, Removing, changes[0].Kind)
+	require.True(t, started.Equal(changes[0].Started))
+
+	err = s.subject.EndChange(module)
+	require.NoError(t, err)
+
+	changes, err = s.subject.Changes()
+	require.NoError(t, err)
+	require.Empty(t, changes)
+}
+
 func (s *testSuite) Test_Index_Contains() {
 	t := s.T()
 
@@ -163,6 +291,125 @@ func (s *testSuite) Test_Index_IDs() {
 	require.Equal(t, Ranges([][2]int64{{1, 4}, {8, 8}, {11, 13}}), actualIDs)
 }
 
+func (s *testSuite) Test_Index_List() {
+	t := s.T()
+
+	for _, id := range []int64{1, 2, 3} {
+		module := coordinates.Module{Source: "src1", Version: fmt.Sprintf("v1.2.%d", id)}
+		addition := ModuleAddition{Mod: module, UniqueID: id, ModFile: "foobar"}
+		err := s.subject.Put(addition)
+		require.NoError(t, err)
+	}
+
+	mods, err := s.subject.List()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []coordinates.SerialModule{
+		{SerialID: 1, Module: coordinates.Module{Source: "src1", Version: "v1.2.1"}},
+		{SerialID: 2, Module: coordinates.Module{Source: "src1", Version: "v1.2.2"}},
+		{SerialID: 3, Module: coordinates.Module{Source: "src1", Version: "v1.2.3"}},
+	}, mods)
+}
+
+func (s *testSuite) Test_Problems() {
+	t := s.T()
+
+	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
+	problem := problems.Problem{
+		Module:    module,
+		FirstSeen: time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC),
+		Time:      time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC),
+		Message:   "broken",
+		Attempts:  1,
+	}
+	err := s.subject.PutProblem(problem)
+	require.NoError(t, err)
+
+	// overwrite with a newer occurrence
+	problem.Attempts = 2
+	err = s.subject.PutProblem(problem)
+	require.NoError(t, err)
+
+	list, err := s.subject.ListProblems()
+	require.NoError(t, err)
+	require.Equal(t, []problems.Problem{problem}, list)
+
+	err = s.subject.DeleteProblem(module)
+	require.NoError(t, err)
+
+	list, err = s.subject.ListProblems()
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
+
+func (s *testSuite) Test_Resolved() {
+	t := s.T()
+
+	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
+	older := problems.Problem{
+		Module:     module,
+		Message:    "broken",
+		Resolved:   timeOf(time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)),
+		Resolution: problems.Downloaded,
+	}
+	newer := problems.Problem{
+		Module:     module,
+		Message:    "broken again",
+		Resolved:   timeOf(time.Date(2019, 6, 3, 0, 0, 0, 0, time.UTC)),
+		Resolution: problems.Acknowledged,
+	}
+
+	err := s.subject.PutResolved(older)
+	require.NoError(t, err)
+	err = s.subject.PutResolved(newer)
+	require.NoError(t, err)
+
+	list, err := s.subject.ListResolved()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []problems.Problem{older, newer}, list)
+
+	err = s.subject.DeleteResolved(time.Date(2019, 6, 2, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	list, err = s.subject.ListResolved()
+	require.NoError(t, err)
+	require.Equal(t, []problems.Problem{newer}, list)
+}
+
+func (s *testSuite) Test_Records() {
+	t := s.T()
+
+	module := coordinates.Module{Source: "src1", Version: "v1.2.3"}
+	data := []byte("src1 v1.2.3 h1:abc=\n")
+	hash := tlog.RecordHash(data)
+
+	err := s.subject.AppendRecord(0, module, data, []tlog.Hash{hash})
+	require.NoError(t, err)
+
+	n, err := s.subject.CountRecords()
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+
+	id, exists, err := s.subject.LookupRecord(module)
+	require.NoError(t, err)
+	require.True(t, exists)
+	require.Equal(t, int64(0), id)
+
+	records, err := s.subject.ReadRecords(0, 1)
+	require.NoError(t, err)
+	require.Equal(t, [][]byte{data}, records)
+
+	hashes, err := s.subject.ReadHashes([]int64{0})
+	require.NoError(t, err)
+	require.Equal(t, []tlog.Hash{hash}, hashes)
+
+	// only ever appended to
+	err = s.subject.AppendRecord(5, coordinates.Module{Source: "src1", Version: "v1.2.4"}, data, nil)
+	require.Error(t, err)
+
+	_, err = s.subject.ReadHashes([]int64{1})
+	require.True(t, os.IsNotExist(err))
+}
+
 func (s *testSuite) Test_Index_UpdateID() {
 	t := s.T()
 
@@ -276,6 +523,12 @@ func dropTables(t *testing.T, db *sql.DB) {
 	tables := []string{
 		"proxy_module_zips",
 		"proxy_modules_index",
+		"proxy_problems",
+		"proxy_resolved_problems",
+		"proxy_module_accesses",
+		"proxy_module_journal",
+		"proxy_sumdb_records",
+		"proxy_sumdb_hashes",
 	}
 	for _, table := range tables {
 		if _, err := db.Exec("DROP TABLE IF EXISTS " + table); err != nil {
@@ -0,0 +1,242 @@
+package store
+
+import (
+	"io"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/clients/s3"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// s3Store is a ZipStore which keeps zips in a bucket of an S3 compatible
+// object store, so that many instances of the proxy can share one copy.
+// Zips are stored with the same layout as the fsStore, underneath prefix.
+type s3Store struct {
+	client  s3.Client
+	prefix  string
+	tmpDir  string
+	emitter stats.Sender
+	log     loggy.Logger
+}
+
+// NewS3Store creates a ZipStore backed by client. Zips which need to be read
+// at random (rather than streamed) are first copied into tmpDir.
+func NewS3Store(client s3.Client, prefix, tmpDir string, emitter stats.Sender) ZipStore {
+	return &s3Store{
+		client:  client,
+		prefix:  strings.Trim(prefix, "/"),
+		tmpDir:  tmpDir,
+		emitter: emitter,
+		log:     loggy.New("s3-store"),
+	}
+}
+
+func (s *s3Store) OpenZip(mod coordinates.Module) (Zip, error) {
+	s.log.Tracef("retrieving module %s", mod)
+
+	start := time.Now()
+	key := s.keyOf(mod)
+	object, err := s.client.Stat(key)
+	if err != nil {
+		s.emitter.Count("s3store-getzip-failure", 1)
+		return nil, err
+	}
+
+	s.emitter.GaugeMS("s3store-getzip-elapsed-ms", start)
+	return &s3Zip{
+		client: s.client,
+		key:    key,
+		object: object,
+		tmpDir: s.tmpDir,
+	}, nil
+}
+
+func (s *s3Store) DelZip(mod coordinates.Module) error {
+	s.log.Tracef("removing module %s", mod)
+
+	start := time.Now()
+	if err := s.removeZip(mod); err != nil {
+		s.emitter.Count("s3store-rmzip-failure", 1)
+		return err
+	}
+
+	s.emitter.GaugeMS("s3store-rmzip-elapsed-ms", start)
+	return nil
+}
+
+func (s *s3Store) removeZip(mod coordinates.Module) error {
+	key := s.keyOf(mod)
+
+	// deleting an object that does not exist is not an error in s3,
+	// but it is for every other ZipStore
+	if _, err := s.client.Stat(key); err != nil {
+		return err
+	}
+
+	return s.client.Delete(key)
+}
+
+func (s *s3Store) PutZip(mod coordinates.Module, archive repository.Archive) error {
+	s.log.Infof("will save %s to s3, %d bytes", mod, archive.Size())
+
+	start := time.Now()
+	if err := s.putZip(mod, archive); err != nil {
+		s.emitter.Count("s3store-putzip-failure", 1)
+		return err
+	}
+
+	s.emitter.GaugeMS("s3store-putzip-elapsed-ms", start)
+	return nil
+}
+
+func (s *s3Store) putZip(mod coordinates.Module, archive repository.Archive) error {
+	key := s.keyOf(mod)
+
+	_, err := s.client.Stat(key)
+	switch {
+	case err == nil:
+		s.log.Warnf("not saving %s because we already have it @ %s", mod, key)
+		return errors.Errorf("already have a copy of %s", mod)
+	case !s3.IsNotFound(err):
+		return err
+	}
+
+	if err := s.client.Put(key, archive, archive.Size()); err != nil {
+		s.log.Errorf("failed to write zip for %s, %v", mod, err)
+		return err
+	}
+
+	return nil
+}
+
+func (s *s3Store) keyOf(mod coordinates.Module) string {
+	return path.Join(s.prefix, mod.Source, zipName(mod))
+}
+
+// s3Zip is a Zip of an object in s3. Reading streams the object from the
+// current offset, so serving a range of the zip only transfers that range.
+// Reading at random would mean a request for every read, so instead the
+// first ReadAt copies the whole object into a temporary file.
+type s3Zip struct {
+	client s3.Client
+	key    string
+	object s3.Object
+	tmpDir string
+
+	offset int64
+	body   io.ReadCloser // streaming from offset, if open
+
+	lock    sync.Mutex
+	spilled *repository.File
+}
+
+func (z *s3Zip) Size() int64 {
+	return z.object.Size
+}
+
+func (z *s3Zip) ModTime() time.Time {
+	return z.object.LastModified
+}
+
+func (z *s3Zip) Read(p []byte) (int, error) {
+	if z.offset >= z.object.Size {
+		return 0, io.EOF
+	}
+
+	if z.body == nil {
+		body, err := z.client.Get(z.key, z.offset)
+		if err != nil {
+			return 0, err
+		}
+		z.body = body
+	}
+
+	n, err := z.body.Read(p)
+	z.offset += int64(n)
+	return n, err
+}
+
+func (z *s3Zip) Seek(offset int64, whence int) (int64, error) {
+	var position int64
+	switch whence {
+	case io.SeekStart:
+		position = offset
+	case io.SeekCurrent:
+		position = z.offset + offset
+	case io.SeekEnd:
+		position = z.object.Size + offset
+	default:
+		return 0, errors.Errorf("invalid whence %d", whence)
+	}
+
+	if position < 0 {
+		return 0, errors.Errorf("negative position %d", position)
+	}
+
+	// the open stream is only useful if reading continues from where it is
+	if position != z.offset {
+		z.closeBody()
+		z.offset = position
+	}
+
+	return position, nil
+}
+
+func (z *s3Zip) ReadAt(p []byte, off int64) (int, error) {
+	spilled, err := z.spill()
+	if err != nil {
+		return 0, err
+	}
+	return spilled.ReadAt(p, off)
+}
+
+func (z *s3Zip) spill() (*repository.File, error) {
+	z.lock.Lock()
+	defer z.lock.Unlock()
+
+	if z.spilled != nil {
+		return z.spilled, nil
+	}
+
+	body, err := z.client.Get(z.key, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer body.Close()
+
+	spilled, err := repository.Spill(z.tmpDir, body)
+	if err != nil {
+		return nil, err
+	}
+
+	z.spilled = spilled
+	return spilled, nil
+}
+
+func (z *s3Zip) closeBody() {
+	if z.body != nil {
+		_ = z.body.Close()
+		z.body = nil
+	}
+}
+
+func (z *s3Zip) Close() error {
+	z.closeBody()
+
+	z.lock.Lock()
+	defer z.lock.Unlock()
+
+	if z.spilled != nil {
+		return z.spilled.Close()
+	}
+	return nil
+}
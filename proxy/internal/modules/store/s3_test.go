@@ -0,0 +1,80 @@
+package store
+
+import (
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/clients/s3"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+func Test_s3Store(t *testing.T) {
+	ts := httptest.NewServer(s3.NewFake("modules", "AKIAEXAMPLE", "secret"))
+	defer ts.Close()
+
+	client, err := s3.New(s3.Options{
+		Endpoint:        ts.URL,
+		Bucket:          "modules",
+		AccessKeyID:     "AKIAEXAMPLE",
+		SecretAccessKey: "secret",
+	})
+	require.NoError(t, err)
+
+	zips := NewS3Store(client, "/proxy/", "", stats.Discard())
+	mod := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.8.1"}
+
+	_, err = zips.OpenZip(mod)
+	require.Error(t, err)
+
+	err = zips.DelZip(mod)
+	require.Error(t, err)
+
+	err = zips.PutZip(mod, repository.Blob("zip content"))
+	require.NoError(t, err)
+
+	// zips are never overwritten
+	err = zips.PutZip(mod, repository.Blob("other content"))
+	require.Error(t, err)
+
+	// stored underneath the prefix
+	_, err = client.Stat("proxy/github.com/pkg/errors/v0.8.1.zip")
+	require.NoError(t, err)
+
+	zip, err := zips.OpenZip(mod)
+	require.NoError(t, err)
+	require.Equal(t, int64(11), zip.Size())
+	require.False(t, zip.ModTime().IsZero())
+
+	// streamed from the start
+	content, err := ioutil.ReadAll(zip)
+	require.NoError(t, err)
+	require.Equal(t, "zip content", string(content))
+
+	// streamed from an offset
+	_, err = zip.Seek(4, io.SeekStart)
+	require.NoError(t, err)
+	content, err = ioutil.ReadAll(zip)
+	require.NoError(t, err)
+	require.Equal(t, "content", string(content))
+
+	// read at random
+	p := make([]byte, 3)
+	_, err = zip.ReadAt(p, 1)
+	require.NoError(t, err)
+	require.Equal(t, "ip ", string(p))
+
+	err = zip.Close()
+	require.NoError(t, err)
+
+	err = zips.DelZip(mod)
+	require.NoError(t, err)
+
+	_, err = zips.OpenZip(mod)
+	require.Error(t, err)
+}
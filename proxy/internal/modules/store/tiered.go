@@ -0,0 +1,227 @@
+package store
+
+import (
+	"container/list"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// tieredStore is a ZipStore which keeps a bounded cache of zips on local disk
+// in front of a remote ZipStore, e.g. the mysqlStore or the s3Store.
+//
+// Zips are read through the cache: a zip which is not cached is read from the
+// remote store and cached on the way. Zips are written through the cache: the
+// remote store is always written first, and is the source of truth. Once the
+// cached zips take up more than the limit, the least recently used are removed
+// from the cache (but not from the remote store).
+type tieredStore struct {
+	local   ZipStore
+	remote  ZipStore
+	limit   int64
+	emitter stats.Sender
+	log     loggy.Logger
+
+	lock   sync.Mutex
+	size   int64                                // bytes of all cached zips
+	used   *list.List                           // of *cached, most recently used first
+	cached map[coordinates.Module]*list.Element // of *cached
+}
+
+type cached struct {
+	mod  coordinates.Module
+	size int64
+}
+
+// NewTieredStore creates a ZipStore which caches up to limit bytes of zips
+// from remote in the local directory of options. Zips already in the local
+// directory (e.g. from before a restart) are part of the cache.
+func NewTieredStore(options Options, remote ZipStore, limit int64, emitter stats.Sender) (ZipStore, error) {
+	s := &tieredStore{
+		local:   NewStore(options, emitter),
+		remote:  remote,
+		limit:   limit,
+		emitter: emitter,
+		log:     loggy.New("tiered-store"),
+		used:    list.New(),
+		cached:  make(map[coordinates.Module]*list.Element),
+	}
+
+	if err := s.load(options.Directory); err != nil {
+		return nil, err
+	}
+
+	s.log.Infof("loaded %d cached zips, %d bytes of %d", len(s.cached), s.size, s.limit)
+	s.trim()
+	return s, nil
+}
+
+// load adds the zips already in dir to the cache, the most recently
+// modified being the most recently used.
+func (s *tieredStore) load(dir string) error {
+	type found struct {
+		cached
+		modified int64
+	}
+	var zips []found
+
+	if err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || !strings.HasSuffix(info.Name(), ".zip") {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		zips = append(zips, found{
+			cached: cached{
+				mod: coordinates.Module{
+					Source:  filepath.ToSlash(rel),
+					Version: strings.TrimSuffix(info.Name(), ".zip"),
+				},
+				size: info.Size(),
+			},
+			modified: info.ModTime().UnixNano(),
+		})
+		return nil
+	}); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	// oldest first, each pushed in front of the last
+	sort.Slice(zips, func(x, y int) bool {
+		return zips[x].modified < zips[y].modified
+	})
+
+	for _, zip := range zips {
+		s.add(zip.mod, zip.size)
+	}
+	return nil
+}
+
+func (s *tieredStore) OpenZip(mod coordinates.Module) (Zip, error) {
+	if zip, err := s.local.OpenZip(mod); err == nil {
+		s.emitter.Count("tiered-cache-hit", 1)
+		s.touch(mod, zip.Size())
+		return zip, nil
+	}
+
+	s.emitter.Count("tiered-cache-miss", 1)
+
+	zip, err := s.remote.OpenZip(mod)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.cache(mod, zip); err != nil {
+		// still available from the remote store, just not cached
+		s.log.Warnf("failed to cache zip of %s, %v", mod, err)
+		s.emitter.Count("tiered-cache-failure", 1)
+		return zip, nil
+	}
+
+	// the remote copy is no longer needed, serve the cached copy instead
+	ignore.Close(zip)
+	return s.local.OpenZip(mod)
+}
+
+func (s *tieredStore) cache(mod coordinates.Module, archive repository.Archive) error {
+	if err := s.local.PutZip(mod, archive); err != nil {
+		return err
+	}
+	s.touch(mod, archive.Size())
+	return nil
+}
+
+func (s *tieredStore) PutZip(mod coordinates.Module, archive repository.Archive) error {
+	if err := s.remote.PutZip(mod, archive); err != nil {
+		return err
+	}
+
+	// a newly stored module is likely to be requested soon
+	if err := s.cache(mod, archive); err != nil {
+		s.log.Warnf("failed to cache zip of %s, %v", mod, err)
+		s.emitter.Count("tiered-cache-failure", 1)
+	}
+	return nil
+}
+
+func (s *tieredStore) DelZip(mod coordinates.Module) error {
+	if err := s.remote.DelZip(mod); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if _, exists := s.cached[mod]; exists {
+		s.evict(mod)
+	}
+	return nil
+}
+
+// touch marks mod as the most recently used, adding it to the cache
+// if necessary, and removes whatever no longer fits in the cache.
+func (s *tieredStore) touch(mod coordinates.Module, size int64) {
+	s.lock.Lock()
+	if element, exists := s.cached[mod]; exists {
+		s.used.MoveToFront(element)
+	} else {
+		s.add(mod, size)
+	}
+	s.lock.Unlock()
+
+	s.trim()
+}
+
+// add must be called while holding the lock, unless loading
+func (s *tieredStore) add(mod coordinates.Module, size int64) {
+	s.cached[mod] = s.used.PushFront(&cached{mod: mod, size: size})
+	s.size += size
+}
+
+func (s *tieredStore) trim() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	// always keep the most recently used zip, even if alone it is too big
+	for s.size > s.limit && s.used.Len() > 1 {
+		oldest := s.used.Back().Value.(*cached)
+		s.evict(oldest.mod)
+	}
+
+	s.emitter.Gauge("tiered-cache-bytes", int(s.size))
+}
+
+// evict must be called while holding the lock
+func (s *tieredStore) evict(mod coordinates.Module) {
+	element := s.cached[mod]
+	entry := element.Value.(*cached)
+
+	s.used.Remove(element)
+	delete(s.cached, mod)
+	s.size -= entry.size
+
+	if err := s.local.DelZip(mod); err != nil {
+		s.log.Warnf("failed to remove cached zip of %s, %v", mod, err)
+		return
+	}
+
+	s.log.Tracef("removed cached zip of %s, %d bytes", mod, entry.size)
+	s.emitter.Count("tiered-cache-evict", 1)
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+func cachedIn(t *testing.T, dir string, mod coordinates.Module) bool {
+	_, err := os.Stat(filepath.Join(dir, pathOf(mod), zipName(mod)))
+	return err == nil
+}
+
+func Test_tieredStore(t *testing.T) {
+	remoteDir, err := ioutil.TempDir("", "remote-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(remoteDir) }()
+
+	localDir, err := ioutil.TempDir("", "local-")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(localDir) }()
+
+	remote := NewStore(Options{Directory: remoteDir}, stats.Discard())
+
+	modA := newMod("example.com/a", "v1.0.0")
+	modB := newMod("example.com/b", "v1.0.0")
+	modC := newMod("example.com/c", "v1.0.0")
+	blob := repository.Blob("0123456789") // 10 bytes each
+
+	// modA is only in the remote store
+	err = remote.PutZip(modA, blob)
+	require.NoError(t, err)
+
+	// room for two zips
+	zips, err := NewTieredStore(Options{Directory: localDir}, remote, 20, stats.Discard())
+	require.NoError(t, err)
+
+	read := func(mod coordinates.Module) {
+		zip, err := zips.OpenZip(mod)
+		require.NoError(t, err)
+		defer zip.Close()
+		content, err := ioutil.ReadAll(zip)
+		require.NoError(t, err)
+		require.Equal(t, []byte(blob), content)
+	}
+
+	// read through
+	require.False(t, cachedIn(t, localDir, modA))
+	read(modA)
+	require.True(t, cachedIn(t, localDir, modA))
+
+	// write through
+	err = zips.PutZip(modB, blob)
+	require.NoError(t, err)
+	require.True(t, cachedIn(t, remoteDir, modB))
+	require.True(t, cachedIn(t, localDir, modB))
+
+	// modA is used more recently than modB
+	read(modA)
+
+	// so modB is removed from the cache to make room
+	err = zips.PutZip(modC, blob)
+	require.NoError(t, err)
+	require.True(t, cachedIn(t, localDir, modA))
+	require.False(t, cachedIn(t, localDir, modB))
+	require.True(t, cachedIn(t, localDir, modC))
+
+	// but is still in the remote store, and cached again in place of modA
+	read(modB)
+	require.False(t, cachedIn(t, localDir, modA))
+	require.True(t, cachedIn(t, localDir, modB))
+
+	// removed from both
+	err = zips.DelZip(modC)
+	require.NoError(t, err)
+	require.False(t, cachedIn(t, remoteDir, modC))
+	require.False(t, cachedIn(t, localDir, modC))
+
+	_, err = zips.OpenZip(modC)
+	require.Error(t, err)
+
+	// after a restart, the cached zips are still cached
+	restarted, err := NewTieredStore(Options{Directory: localDir}, remote, 20, stats.Discard())
+	require.NoError(t, err)
+	require.Len(t, restarted.(*tieredStore).cached, 1)
+	require.Contains(t, restarted.(*tieredStore).cached, modB)
+}
@@ -22,14 +22,14 @@ type ZipStoreMock struct {
 	beforeDelZipCounter uint64
 	DelZipMock          mZipStoreMockDelZip
 
-	funcGetZip          func(m1 coordinates.Module) (b1 repository.Blob, err error)
-	inspectFuncGetZip   func(m1 coordinates.Module)
-	afterGetZipCounter  uint64
-	beforeGetZipCounter uint64
-	GetZipMock          mZipStoreMockGetZip
-
-	funcPutZip          func(m1 coordinates.Module, b1 repository.Blob) (err error)
-	inspectFuncPutZip   func(m1 coordinates.Module, b1 repository.Blob)
+	funcOpenZip          func(m1 coordinates.Module) (z1 Zip, err error)
+	inspectFuncOpenZip   func(m1 coordinates.Module)
+	afterOpenZipCounter  uint64
+	beforeOpenZipCounter uint64
+	OpenZipMock          mZipStoreMockOpenZip
+
+	funcPutZip          func(m1 coordinates.Module, a1 repository.Archive) (err error)
+	inspectFuncPutZip   func(m1 coordinates.Module, a1 repository.Archive)
 	afterPutZipCounter  uint64
 	beforePutZipCounter uint64
 	PutZipMock          mZipStoreMockPutZip
@@ -45,8 +45,8 @@ func NewZipStoreMock(t minimock.Tester) *ZipStoreMock {
 	m.DelZipMock = mZipStoreMockDelZip{mock: m}
 	m.DelZipMock.callArgs = []*ZipStoreMockDelZipParams{}
 
-	m.GetZipMock = mZipStoreMockGetZip{mock: m}
-	m.GetZipMock.callArgs = []*ZipStoreMockGetZipParams{}
+	m.OpenZipMock = mZipStoreMockOpenZip{mock: m}
+	m.OpenZipMock.callArgs = []*ZipStoreMockOpenZipParams{}
 
 	m.PutZipMock = mZipStoreMockPutZip{mock: m}
 	m.PutZipMock.callArgs = []*ZipStoreMockPutZipParams{}
@@ -269,219 +269,219 @@ func (m *ZipStoreMock) MinimockDelZipInspect() {
 	}
 }
 
-type mZipStoreMockGetZip struct {
+type mZipStoreMockOpenZip struct {
 	mock               *ZipStoreMock
-	defaultExpectation *ZipStoreMockGetZipExpectation
-	expectations       []*ZipStoreMockGetZipExpectation
+	defaultExpectation *ZipStoreMockOpenZipExpectation
+	expectations       []*ZipStoreMockOpenZipExpectation
 
-	callArgs []*ZipStoreMockGetZipParams
+	callArgs []*ZipStoreMockOpenZipParams
 	mutex    sync.RWMutex
 }
 
-// ZipStoreMockGetZipExpectation specifies expectation struct of the ZipStore.GetZip
-type ZipStoreMockGetZipExpectation struct {
+// ZipStoreMockOpenZipExpectation specifies expectation struct of the ZipStore.OpenZip
+type ZipStoreMockOpenZipExpectation struct {
 	mock    *ZipStoreMock
-	params  *ZipStoreMockGetZipParams
-	results *ZipStoreMockGetZipResults
+	params  *ZipStoreMockOpenZipParams
+	results *ZipStoreMockOpenZipResults
 	Counter uint64
 }
 
-// ZipStoreMockGetZipParams contains parameters of the ZipStore.GetZip
-type ZipStoreMockGetZipParams struct {
+// ZipStoreMockOpenZipParams contains parameters of the ZipStore.OpenZip
+type ZipStoreMockOpenZipParams struct {
 	m1 coordinates.Module
 }
 
-// ZipStoreMockGetZipResults contains results of the ZipStore.GetZip
-type ZipStoreMockGetZipResults struct {
-	b1  repository.Blob
+// ZipStoreMockOpenZipResults contains results of the ZipStore.OpenZip
+type ZipStoreMockOpenZipResults struct {
+	z1  Zip
 	err error
 }
 
-// Expect sets up expected params for ZipStore.GetZip
-func (mmGetZip *mZipStoreMockGetZip) Expect(m1 coordinates.Module) *mZipStoreMockGetZip {
-	if mmGetZip.mock.funcGetZip != nil {
-		mmGetZip.mock.t.Fatalf("ZipStoreMock.GetZip mock is already set by Set")
+// Expect sets up expected params for ZipStore.OpenZip
+func (mmOpenZip *mZipStoreMockOpenZip) Expect(m1 coordinates.Module) *mZipStoreMockOpenZip {
+	if mmOpenZip.mock.funcOpenZip != nil {
+		mmOpenZip.mock.t.Fatalf("ZipStoreMock.OpenZip mock is already set by Set")
 	}
 
-	if mmGetZip.defaultExpectation == nil {
-		mmGetZip.defaultExpectation = &ZipStoreMockGetZipExpectation{}
+	if mmOpenZip.defaultExpectation == nil {
+		mmOpenZip.defaultExpectation = &ZipStoreMockOpenZipExpectation{}
 	}
 
-	mmGetZip.defaultExpectation.params = &ZipStoreMockGetZipParams{m1}
-	for _, e := range mmGetZip.expectations {
-		if minimock.Equal(e.params, mmGetZip.defaultExpectation.params) {
-			mmGetZip.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmGetZip.defaultExpectation.params)
+	mmOpenZip.defaultExpectation.params = &ZipStoreMockOpenZipParams{m1}
+	for _, e := range mmOpenZip.expectations {
+		if minimock.Equal(e.params, mmOpenZip.defaultExpectation.params) {
+			mmOpenZip.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmOpenZip.defaultExpectation.params)
 		}
 	}
 
-	return mmGetZip
+	return mmOpenZip
 }
 
-// Inspect accepts an inspector function that has same arguments as the ZipStore.GetZip
-func (mmGetZip *mZipStoreMockGetZip) Inspect(f func(m1 coordinates.Module)) *mZipStoreMockGetZip {
-	if mmGetZip.mock.inspectFuncGetZip != nil {
-		mmGetZip.mock.t.Fatalf("Inspect function is already set for ZipStoreMock.GetZip")
+// Inspect accepts an inspector function that has same arguments as the ZipStore.OpenZip
+func (mmOpenZip *mZipStoreMockOpenZip) Inspect(f func(m1 coordinates.Module)) *mZipStoreMockOpenZip {
+	if mmOpenZip.mock.inspectFuncOpenZip != nil {
+		mmOpenZip.mock.t.Fatalf("Inspect function is already set for ZipStoreMock.OpenZip")
 	}
 
-	mmGetZip.mock.inspectFuncGetZip = f
+	mmOpenZip.mock.inspectFuncOpenZip = f
 
-	return mmGetZip
+	return mmOpenZip
 }
 
-// Return sets up results that will be returned by ZipStore.GetZip
-func (mmGetZip *mZipStoreMockGetZip) Return(b1 repository.Blob, err error) *ZipStoreMock {
-	if mmGetZip.mock.funcGetZip != nil {
-		mmGetZip.mock.t.Fatalf("ZipStoreMock.GetZip mock is already set by Set")
+// Return sets up results that will be returned by ZipStore.OpenZip
+func (mmOpenZip *mZipStoreMockOpenZip) Return(z1 Zip, err error) *ZipStoreMock {
+	if mmOpenZip.mock.funcOpenZip != nil {
+		mmOpenZip.mock.t.Fatalf("ZipStoreMock.OpenZip mock is already set by Set")
 	}
 
-	if mmGetZip.defaultExpectation == nil {
-		mmGetZip.defaultExpectation = &ZipStoreMockGetZipExpectation{mock: mmGetZip.mock}
+	if mmOpenZip.defaultExpectation == nil {
+		mmOpenZip.defaultExpectation = &ZipStoreMockOpenZipExpectation{mock: mmOpenZip.mock}
 	}
-	mmGetZip.defaultExpectation.results = &ZipStoreMockGetZipResults{b1, err}
-	return mmGetZip.mock
+	mmOpenZip.defaultExpectation.results = &ZipStoreMockOpenZipResults{z1, err}
+	return mmOpenZip.mock
 }
 
-//Set uses given function f to mock the ZipStore.GetZip method
-func (mmGetZip *mZipStoreMockGetZip) Set(f func(m1 coordinates.Module) (b1 repository.Blob, err error)) *ZipStoreMock {
-	if mmGetZip.defaultExpectation != nil {
-		mmGetZip.mock.t.Fatalf("Default expectation is already set for the ZipStore.GetZip method")
+//Set uses given function f to mock the ZipStore.OpenZip method
+func (mmOpenZip *mZipStoreMockOpenZip) Set(f func(m1 coordinates.Module) (z1 Zip, err error)) *ZipStoreMock {
+	if mmOpenZip.defaultExpectation != nil {
+		mmOpenZip.mock.t.Fatalf("Default expectation is already set for the ZipStore.OpenZip method")
 	}
 
-	if len(mmGetZip.expectations) > 0 {
-		mmGetZip.mock.t.Fatalf("Some expectations are already set for the ZipStore.GetZip method")
+	if len(mmOpenZip.expectations) > 0 {
+		mmOpenZip.mock.t.Fatalf("Some expectations are already set for the ZipStore.OpenZip method")
 	}
 
-	mmGetZip.mock.funcGetZip = f
-	return mmGetZip.mock
+	mmOpenZip.mock.funcOpenZip = f
+	return mmOpenZip.mock
 }
 
-// When sets expectation for the ZipStore.GetZip which will trigger the result defined by the following
+// When sets expectation for the ZipStore.OpenZip which will trigger the result defined by the following
 // Then helper
-func (mmGetZip *mZipStoreMockGetZip) When(m1 coordinates.Module) *ZipStoreMockGetZipExpectation {
-	if mmGetZip.mock.funcGetZip != nil {
-		mmGetZip.mock.t.Fatalf("ZipStoreMock.GetZip mock is already set by Set")
+func (mmOpenZip *mZipStoreMockOpenZip) When(m1 coordinates.Module) *ZipStoreMockOpenZipExpectation {
+	if mmOpenZip.mock.funcOpenZip != nil {
+		mmOpenZip.mock.t.Fatalf("ZipStoreMock.OpenZip mock is already set by Set")
 	}
 
-	expectation := &ZipStoreMockGetZipExpectation{
-		mock:   mmGetZip.mock,
-		params: &ZipStoreMockGetZipParams{m1},
+	expectation := &ZipStoreMockOpenZipExpectation{
+		mock:   mmOpenZip.mock,
+		params: &ZipStoreMockOpenZipParams{m1},
 	}
-	mmGetZip.expectations = append(mmGetZip.expectations, expectation)
+	mmOpenZip.expectations = append(mmOpenZip.expectations, expectation)
 	return expectation
 }
 
-// Then sets up ZipStore.GetZip return parameters for the expectation previously defined by the When method
-func (e *ZipStoreMockGetZipExpectation) Then(b1 repository.Blob, err error) *ZipStoreMock {
-	e.results = &ZipStoreMockGetZipResults{b1, err}
+// Then sets up ZipStore.OpenZip return parameters for the expectation previously defined by the When method
+func (e *ZipStoreMockOpenZipExpectation) Then(z1 Zip, err error) *ZipStoreMock {
+	e.results = &ZipStoreMockOpenZipResults{z1, err}
 	return e.mock
 }
 
-// GetZip implements ZipStore
-func (mmGetZip *ZipStoreMock) GetZip(m1 coordinates.Module) (b1 repository.Blob, err error) {
-	mm_atomic.AddUint64(&mmGetZip.beforeGetZipCounter, 1)
-	defer mm_atomic.AddUint64(&mmGetZip.afterGetZipCounter, 1)
+// OpenZip implements ZipStore
+func (mmOpenZip *ZipStoreMock) OpenZip(m1 coordinates.Module) (z1 Zip, err error) {
+	mm_atomic.AddUint64(&mmOpenZip.beforeOpenZipCounter, 1)
+	defer mm_atomic.AddUint64(&mmOpenZip.afterOpenZipCounter, 1)
 
-	if mmGetZip.inspectFuncGetZip != nil {
-		mmGetZip.inspectFuncGetZip(m1)
+	if mmOpenZip.inspectFuncOpenZip != nil {
+		mmOpenZip.inspectFuncOpenZip(m1)
 	}
 
-	mm_params := &ZipStoreMockGetZipParams{m1}
+	mm_params := &ZipStoreMockOpenZipParams{m1}
 
 	// Record call args
-	mmGetZip.GetZipMock.mutex.Lock()
-	mmGetZip.GetZipMock.callArgs = append(mmGetZip.GetZipMock.callArgs, mm_params)
-	mmGetZip.GetZipMock.mutex.Unlock()
+	mmOpenZip.OpenZipMock.mutex.Lock()
+	mmOpenZip.OpenZipMock.callArgs = append(mmOpenZip.OpenZipMock.callArgs, mm_params)
+	mmOpenZip.OpenZipMock.mutex.Unlock()
 
-	for _, e := range mmGetZip.GetZipMock.expectations {
+	for _, e := range mmOpenZip.OpenZipMock.expectations {
 		if minimock.Equal(e.params, mm_params) {
 			mm_atomic.AddUint64(&e.Counter, 1)
-			return e.results.b1, e.results.err
+			return e.results.z1, e.results.err
 		}
 	}
 
-	if mmGetZip.GetZipMock.defaultExpectation != nil {
-		mm_atomic.AddUint64(&mmGetZip.GetZipMock.defaultExpectation.Counter, 1)
-		mm_want := mmGetZip.GetZipMock.defaultExpectation.params
-		mm_got := ZipStoreMockGetZipParams{m1}
+	if mmOpenZip.OpenZipMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmOpenZip.OpenZipMock.defaultExpectation.Counter, 1)
+		mm_want := mmOpenZip.OpenZipMock.defaultExpectation.params
+		mm_got := ZipStoreMockOpenZipParams{m1}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
-			mmGetZip.t.Errorf("ZipStoreMock.GetZip got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+			mmOpenZip.t.Errorf("ZipStoreMock.OpenZip got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
 
-		mm_results := mmGetZip.GetZipMock.defaultExpectation.results
+		mm_results := mmOpenZip.OpenZipMock.defaultExpectation.results
 		if mm_results == nil {
-			mmGetZip.t.Fatal("No results are set for the ZipStoreMock.GetZip")
+			mmOpenZip.t.Fatal("No results are set for the ZipStoreMock.OpenZip")
 		}
-		return (*mm_results).b1, (*mm_results).err
+		return (*mm_results).z1, (*mm_results).err
 	}
-	if mmGetZip.funcGetZip != nil {
-		return mmGetZip.funcGetZip(m1)
+	if mmOpenZip.funcOpenZip != nil {
+		return mmOpenZip.funcOpenZip(m1)
 	}
-	mmGetZip.t.Fatalf("Unexpected call to ZipStoreMock.GetZip. %v", m1)
+	mmOpenZip.t.Fatalf("Unexpected call to ZipStoreMock.OpenZip. %v", m1)
 	return
 }
 
-// GetZipAfterCounter returns a count of finished ZipStoreMock.GetZip invocations
-func (mmGetZip *ZipStoreMock) GetZipAfterCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmGetZip.afterGetZipCounter)
+// OpenZipAfterCounter returns a count of finished ZipStoreMock.OpenZip invocations
+func (mmOpenZip *ZipStoreMock) OpenZipAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmOpenZip.afterOpenZipCounter)
 }
 
-// GetZipBeforeCounter returns a count of ZipStoreMock.GetZip invocations
-func (mmGetZip *ZipStoreMock) GetZipBeforeCounter() uint64 {
-	return mm_atomic.LoadUint64(&mmGetZip.beforeGetZipCounter)
+// OpenZipBeforeCounter returns a count of ZipStoreMock.OpenZip invocations
+func (mmOpenZip *ZipStoreMock) OpenZipBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmOpenZip.beforeOpenZipCounter)
 }
 
-// Calls returns a list of arguments used in each call to ZipStoreMock.GetZip.
+// Calls returns a list of arguments used in each call to ZipStoreMock.OpenZip.
 // The list is in the same order as the calls were made (i.e. recent calls have a higher index)
-func (mmGetZip *mZipStoreMockGetZip) Calls() []*ZipStoreMockGetZipParams {
-	mmGetZip.mutex.RLock()
+func (mmOpenZip *mZipStoreMockOpenZip) Calls() []*ZipStoreMockOpenZipParams {
+	mmOpenZip.mutex.RLock()
 
-	argCopy := make([]*ZipStoreMockGetZipParams, len(mmGetZip.callArgs))
-	copy(argCopy, mmGetZip.callArgs)
+	argCopy := make([]*ZipStoreMockOpenZipParams, len(mmOpenZip.callArgs))
+	copy(argCopy, mmOpenZip.callArgs)
 
-	mmGetZip.mutex.RUnlock()
+	mmOpenZip.mutex.RUnlock()
 
 	return argCopy
 }
 
-// MinimockGetZipDone returns true if the count of the GetZip invocations corresponds
+// MinimockOpenZipDone returns true if the count of the OpenZip invocations corresponds
 // the number of defined expectations
-func (m *ZipStoreMock) MinimockGetZipDone() bool {
-	for _, e := range m.GetZipMock.expectations {
+func (m *ZipStoreMock) MinimockOpenZipDone() bool {
+	for _, e := range m.OpenZipMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
 			return false
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.GetZipMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetZipCounter) < 1 {
+	if m.OpenZipMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterOpenZipCounter) < 1 {
 		return false
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcGetZip != nil && mm_atomic.LoadUint64(&m.afterGetZipCounter) < 1 {
+	if m.funcOpenZip != nil && mm_atomic.LoadUint64(&m.afterOpenZipCounter) < 1 {
 		return false
 	}
 	return true
 }
 
-// MinimockGetZipInspect logs each unmet expectation
-func (m *ZipStoreMock) MinimockGetZipInspect() {
-	for _, e := range m.GetZipMock.expectations {
+// MinimockOpenZipInspect logs each unmet expectation
+func (m *ZipStoreMock) MinimockOpenZipInspect() {
+	for _, e := range m.OpenZipMock.expectations {
 		if mm_atomic.LoadUint64(&e.Counter) < 1 {
-			m.t.Errorf("Expected call to ZipStoreMock.GetZip with params: %#v", *e.params)
+			m.t.Errorf("Expected call to ZipStoreMock.OpenZip with params: %#v", *e.params)
 		}
 	}
 
 	// if default expectation was set then invocations count should be greater than zero
-	if m.GetZipMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterGetZipCounter) < 1 {
-		if m.GetZipMock.defaultExpectation.params == nil {
-			m.t.Error("Expected call to ZipStoreMock.GetZip")
+	if m.OpenZipMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterOpenZipCounter) < 1 {
+		if m.OpenZipMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to ZipStoreMock.OpenZip")
 		} else {
-			m.t.Errorf("Expected call to ZipStoreMock.GetZip with params: %#v", *m.GetZipMock.defaultExpectation.params)
+			m.t.Errorf("Expected call to ZipStoreMock.OpenZip with params: %#v", *m.OpenZipMock.defaultExpectation.params)
 		}
 	}
 	// if func was set then invocations count should be greater than zero
-	if m.funcGetZip != nil && mm_atomic.LoadUint64(&m.afterGetZipCounter) < 1 {
-		m.t.Error("Expected call to ZipStoreMock.GetZip")
+	if m.funcOpenZip != nil && mm_atomic.LoadUint64(&m.afterOpenZipCounter) < 1 {
+		m.t.Error("Expected call to ZipStoreMock.OpenZip")
 	}
 }
 
@@ -505,7 +505,7 @@ type ZipStoreMockPutZipExpectation struct {
 // ZipStoreMockPutZipParams contains parameters of the ZipStore.PutZip
 type ZipStoreMockPutZipParams struct {
 	m1 coordinates.Module
-	b1 repository.Blob
+	a1 repository.Archive
 }
 
 // ZipStoreMockPutZipResults contains results of the ZipStore.PutZip
@@ -514,7 +514,7 @@ type ZipStoreMockPutZipResults struct {
 }
 
 // Expect sets up expected params for ZipStore.PutZip
-func (mmPutZip *mZipStoreMockPutZip) Expect(m1 coordinates.Module, b1 repository.Blob) *mZipStoreMockPutZip {
+func (mmPutZip *mZipStoreMockPutZip) Expect(m1 coordinates.Module, a1 repository.Archive) *mZipStoreMockPutZip {
 	if mmPutZip.mock.funcPutZip != nil {
 		mmPutZip.mock.t.Fatalf("ZipStoreMock.PutZip mock is already set by Set")
 	}
@@ -523,7 +523,7 @@ func (mmPutZip *mZipStoreMockPutZip) Expect(m1 coordinates.Module, b1 repository
 		mmPutZip.defaultExpectation = &ZipStoreMockPutZipExpectation{}
 	}
 
-	mmPutZip.defaultExpectation.params = &ZipStoreMockPutZipParams{m1, b1}
+	mmPutZip.defaultExpectation.params = &ZipStoreMockPutZipParams{m1, a1}
 	for _, e := range mmPutZip.expectations {
 		if minimock.Equal(e.params, mmPutZip.defaultExpectation.params) {
 			mmPutZip.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmPutZip.defaultExpectation.params)
@@ -534,7 +534,7 @@ func (mmPutZip *mZipStoreMockPutZip) Expect(m1 coordinates.Module, b1 repository
 }
 
 // Inspect accepts an inspector function that has same arguments as the ZipStore.PutZip
-func (mmPutZip *mZipStoreMockPutZip) Inspect(f func(m1 coordinates.Module, b1 repository.Blob)) *mZipStoreMockPutZip {
+func (mmPutZip *mZipStoreMockPutZip) Inspect(f func(m1 coordinates.Module, a1 repository.Archive)) *mZipStoreMockPutZip {
 	if mmPutZip.mock.inspectFuncPutZip != nil {
 		mmPutZip.mock.t.Fatalf("Inspect function is already set for ZipStoreMock.PutZip")
 	}
@@ -558,7 +558,7 @@ func (mmPutZip *mZipStoreMockPutZip) Return(err error) *ZipStoreMock {
 }
 
 //Set uses given function f to mock the ZipStore.PutZip method
-func (mmPutZip *mZipStoreMockPutZip) Set(f func(m1 coordinates.Module, b1 repository.Blob) (err error)) *ZipStoreMock {
+func (mmPutZip *mZipStoreMockPutZip) Set(f func(m1 coordinates.Module, a1 repository.Archive) (err error)) *ZipStoreMock {
 	if mmPutZip.defaultExpectation != nil {
 		mmPutZip.mock.t.Fatalf("Default expectation is already set for the ZipStore.PutZip method")
 	}
@@ -573,14 +573,14 @@ func (mmPutZip *mZipStoreMockPutZip) Set(f func(m1 coordinates.Module, b1 reposi
 
 // When sets expectation for the ZipStore.PutZip which will trigger the result defined by the following
 // Then helper
-func (mmPutZip *mZipStoreMockPutZip) When(m1 coordinates.Module, b1 repository.Blob) *ZipStoreMockPutZipExpectation {
+func (mmPutZip *mZipStoreMockPutZip) When(m1 coordinates.Module, a1 repository.Archive) *ZipStoreMockPutZipExpectation {
 	if mmPutZip.mock.funcPutZip != nil {
 		mmPutZip.mock.t.Fatalf("ZipStoreMock.PutZip mock is already set by Set")
 	}
 
 	expectation := &ZipStoreMockPutZipExpectation{
 		mock:   mmPutZip.mock,
-		params: &ZipStoreMockPutZipParams{m1, b1},
+		params: &ZipStoreMockPutZipParams{m1, a1},
 	}
 	mmPutZip.expectations = append(mmPutZip.expectations, expectation)
 	return expectation
@@ -593,15 +593,15 @@ func (e *ZipStoreMockPutZipExpectation) Then(err error) *ZipStoreMock {
 }
 
 // PutZip implements ZipStore
-func (mmPutZip *ZipStoreMock) PutZip(m1 coordinates.Module, b1 repository.Blob) (err error) {
+func (mmPutZip *ZipStoreMock) PutZip(m1 coordinates.Module, a1 repository.Archive) (err error) {
 	mm_atomic.AddUint64(&mmPutZip.beforePutZipCounter, 1)
 	defer mm_atomic.AddUint64(&mmPutZip.afterPutZipCounter, 1)
 
 	if mmPutZip.inspectFuncPutZip != nil {
-		mmPutZip.inspectFuncPutZip(m1, b1)
+		mmPutZip.inspectFuncPutZip(m1, a1)
 	}
 
-	mm_params := &ZipStoreMockPutZipParams{m1, b1}
+	mm_params := &ZipStoreMockPutZipParams{m1, a1}
 
 	// Record call args
 	mmPutZip.PutZipMock.mutex.Lock()
@@ -618,7 +618,7 @@ func (mmPutZip *ZipStoreMock) PutZip(m1 coordinates.Module, b1 repository.Blob)
 	if mmPutZip.PutZipMock.defaultExpectation != nil {
 		mm_atomic.AddUint64(&mmPutZip.PutZipMock.defaultExpectation.Counter, 1)
 		mm_want := mmPutZip.PutZipMock.defaultExpectation.params
-		mm_got := ZipStoreMockPutZipParams{m1, b1}
+		mm_got := ZipStoreMockPutZipParams{m1, a1}
 		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
 			mmPutZip.t.Errorf("ZipStoreMock.PutZip got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
 		}
@@ -630,9 +630,9 @@ func (mmPutZip *ZipStoreMock) PutZip(m1 coordinates.Module, b1 repository.Blob)
 		return (*mm_results).err
 	}
 	if mmPutZip.funcPutZip != nil {
-		return mmPutZip.funcPutZip(m1, b1)
+		return mmPutZip.funcPutZip(m1, a1)
 	}
-	mmPutZip.t.Fatalf("Unexpected call to ZipStoreMock.PutZip. %v %v", m1, b1)
+	mmPutZip.t.Fatalf("Unexpected call to ZipStoreMock.PutZip. %v %v", m1, a1)
 	return
 }
 
@@ -706,7 +706,7 @@ func (m *ZipStoreMock) MinimockFinish() {
 	if !m.minimockDone() {
 		m.MinimockDelZipInspect()
 
-		m.MinimockGetZipInspect()
+		m.MinimockOpenZipInspect()
 
 		m.MinimockPutZipInspect()
 		m.t.FailNow()
@@ -733,6 +733,6 @@ func (m *ZipStoreMock) minimockDone() bool {
 	done := true
 	return done &&
 		m.MinimockDelZipDone() &&
-		m.MinimockGetZipDone() &&
+		m.MinimockOpenZipDone() &&
 		m.MinimockPutZipDone()
 }
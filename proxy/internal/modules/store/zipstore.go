@@ -1,7 +1,11 @@
 package store
 
 import (
+	"bytes"
 	"database/sql"
+	"io"
+	"os"
+	"time"
 
 	"gophers.dev/pkgs/loggy"
 
@@ -15,11 +19,52 @@ import (
 //go:generate go run github.com/gojuno/minimock/v3/cmd/minimock -g -i ZipStore -s _mock.go
 
 type ZipStore interface {
-	PutZip(coordinates.Module, repository.Blob) error
-	GetZip(coordinates.Module) (repository.Blob, error)
+	PutZip(coordinates.Module, repository.Archive) error
+	OpenZip(coordinates.Module) (Zip, error)
 	DelZip(coordinates.Module) error
 }
 
+// A Zip is an open zip archive of a module in a ZipStore. A Zip is read as
+// a stream, from any offset, so that it can be served (or just a range of it)
+// without holding the whole archive in memory. A Zip must be closed once it
+// is no longer needed.
+type Zip interface {
+	io.ReadSeeker
+	io.ReaderAt
+	io.Closer
+	Size() int64
+	ModTime() time.Time
+}
+
+// fileZip is a Zip of a file on disk.
+type fileZip struct {
+	*os.File
+	info os.FileInfo
+}
+
+func (z *fileZip) Size() int64 {
+	return z.info.Size()
+}
+
+func (z *fileZip) ModTime() time.Time {
+	return z.info.ModTime()
+}
+
+// memZip is a Zip which has been read into memory already, when the
+// underlying storage cannot be streamed from.
+type memZip struct {
+	*bytes.Reader
+	modTime time.Time
+}
+
+func (z *memZip) ModTime() time.Time {
+	return z.modTime
+}
+
+func (z *memZip) Close() error {
+	return nil
+}
+
 func Connect(dsn setup.DSN, emitter stats.Sender) (*mysqlStore, error) {
 	db, err := database.Connect("mysql", dsn)
 	if err != nil {
@@ -0,0 +1,49 @@
+package problems
+
+import (
+	"math"
+	"time"
+)
+
+// Backoff determines how long to wait before trying again after a module
+// has failed to download. The delay doubles after each consecutive failure,
+// starting from Initial, but never grows beyond Max (if Max is set).
+type Backoff struct {
+	Initial time.Duration
+	Max     time.Duration
+}
+
+// Delay returns how long to wait after the given number of consecutive
+// failed attempts.
+func (b Backoff) Delay(attempts int) time.Duration {
+	if attempts <= 0 || b.Initial <= 0 {
+		return 0
+	}
+
+	delay := b.Initial
+	for i := 1; i < attempts && delay < math.MaxInt64/2; i++ {
+		delay *= 2
+		if b.Max > 0 && delay >= b.Max {
+			break
+		}
+	}
+
+	if b.Max > 0 && delay > b.Max {
+		return b.Max
+	}
+	return delay
+}
+
+// Again creates the Problem for another failed attempt at downloading a module
+// at now, counting the attempts already recorded in previous.
+func (b Backoff) Again(previous Problem, err error, now time.Time) Problem {
+	problem := Create(previous.Module, err)
+	problem.FirstSeen = now
+	problem.Time = now
+	if !previous.FirstSeen.IsZero() {
+		problem.FirstSeen = previous.FirstSeen
+	}
+	problem.Attempts = previous.Attempts + 1
+	problem.NextAttempt = problem.Time.Add(b.Delay(problem.Attempts))
+	return problem
+}
@@ -0,0 +1,65 @@
+package problems
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+)
+
+func Test_Backoff_Delay(t *testing.T) {
+	try := func(b Backoff, attempts int, exp time.Duration) {
+		result := b.Delay(attempts)
+		require.Equal(t, exp, result, "attempts: %d", attempts)
+	}
+
+	b := Backoff{
+		Initial: 1 * time.Minute,
+		Max:     1 * time.Hour,
+	}
+
+	try(b, 0, 0)
+	try(b, 1, 1*time.Minute)
+	try(b, 2, 2*time.Minute)
+	try(b, 3, 4*time.Minute)
+	try(b, 6, 32*time.Minute)
+	try(b, 7, 1*time.Hour)
+	try(b, 1000, 1*time.Hour)
+
+	// no backoff configured
+	try(Backoff{}, 3, 0)
+
+	// no maximum configured
+	try(Backoff{Initial: 1 * time.Second}, 11, 1024*time.Second)
+	require.True(t, Backoff{Initial: 1 * time.Second}.Delay(1000) > 0)
+}
+
+func Test_Backoff_Again(t *testing.T) {
+	b := Backoff{
+		Initial: 1 * time.Minute,
+		Max:     1 * time.Hour,
+	}
+
+	mod := coordinates.Module{
+		Source:  "github.com/foo/bar",
+		Version: "v1.2.3",
+	}
+
+	now := time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+	first := b.Again(Problem{Module: mod}, errors.New("broken"), now)
+	require.Equal(t, mod, first.Module)
+	require.Equal(t, 1, first.Attempts)
+	require.Equal(t, "broken", first.Message)
+	require.Equal(t, now, first.Time)
+	require.Equal(t, now, first.FirstSeen)
+	require.Equal(t, now.Add(1*time.Minute), first.NextAttempt)
+
+	second := b.Again(first, errors.New("still broken"), now.Add(time.Minute))
+	require.Equal(t, 2, second.Attempts)
+	require.Equal(t, "still broken", second.Message)
+	require.Equal(t, second.Time.Add(2*time.Minute), second.NextAttempt)
+	require.Equal(t, first.FirstSeen, second.FirstSeen)
+}
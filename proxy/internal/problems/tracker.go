@@ -14,37 +14,126 @@ import (
 
 type Tracker interface {
 	Set(Problem)
+	Resolve(module coordinates.Module, resolution string) bool
 	Problem(module coordinates.Module) (Problem, bool)
 	Problems() []Problem
+	Resolved() []Problem
 }
 
+// Resolutions describing why a Problem was resolved.
+const (
+	Downloaded   = "downloaded"
+	Acknowledged = "acknowledged"
+	Verified     = "verified"
+)
+
 type Problem struct {
-	Module  coordinates.Module `json:"module"`
-	Time    time.Time          `json:"time"`
-	Message string             `json:"message"`
+	Module coordinates.Module `json:"module"`
+
+	// FirstSeen is when the problem first occurred, and Time is
+	// when the problem most recently occurred.
+	FirstSeen time.Time `json:"first_seen"`
+	Time      time.Time `json:"time"`
+
+	// Message is the error of the most recent occurrence.
+	Message string `json:"message"`
+
+	// Attempts is the number of times the problem has occurred.
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+
+	// Resolved is when the problem was resolved, and Resolution describes
+	// how it was resolved. Both are unset for problems not yet resolved.
+	Resolved   *time.Time `json:"resolved,omitempty"`
+	Resolution string     `json:"resolution,omitempty"`
+}
+
+// ResolvedAt returns when the problem was resolved, or the zero time if it
+// has not been resolved.
+func (p Problem) ResolvedAt() time.Time {
+	if p.Resolved == nil {
+		return time.Time{}
+	}
+	return *p.Resolved
 }
 
 func Create(mod coordinates.Module, err error) Problem {
+	now := time.Now()
 	return Problem{
-		Module:  mod,
-		Time:    time.Now(),
-		Message: err.Error(),
+		Module:    mod,
+		FirstSeen: now,
+		Time:      now,
+		Message:   err.Error(),
 	}
 }
 
+// A Store is used to persist problems, so that they are not lost when
+// the proxy is restarted.
+type Store interface {
+	PutProblem(Problem) error
+	DeleteProblem(coordinates.Module) error
+	ListProblems() ([]Problem, error)
+
+	PutResolved(Problem) error
+	DeleteResolved(before time.Time) error
+	ListResolved() ([]Problem, error)
+}
+
 type tracker struct {
-	log      loggy.Logger
-	lock     sync.RWMutex
-	problems map[coordinates.Module]Problem
+	log       loggy.Logger
+	lock      sync.RWMutex
+	problems  map[coordinates.Module]Problem
+	resolved  []Problem // oldest first
+	retention time.Duration
+	store     Store
+	now       func() time.Time
 }
 
-func New(name string) Tracker {
+// New creates a Tracker which keeps problems only in memory. Resolved
+// problems are remembered for the retention period.
+func New(name string, retention time.Duration) Tracker {
 	return &tracker{
-		log:      loggy.New("problems-" + name),
-		problems: make(map[coordinates.Module]Problem),
+		log:       loggy.New("problems-" + name),
+		problems:  make(map[coordinates.Module]Problem),
+		retention: retention,
+		now:       time.Now,
 	}
 }
 
+// NewPersistent creates a Tracker which also persists problems to store,
+// starting with the problems that have already been persisted. Resolved
+// problems are remembered for the retention period.
+func NewPersistent(name string, retention time.Duration, store Store) (Tracker, error) {
+	existing, err := store.ListProblems()
+	if err != nil {
+		return nil, err
+	}
+
+	resolved, err := store.ListResolved()
+	if err != nil {
+		return nil, err
+	}
+	sort.Sort(byResolved(resolved))
+
+	t := &tracker{
+		log:       loggy.New("problems-" + name),
+		problems:  make(map[coordinates.Module]Problem, len(existing)),
+		resolved:  resolved,
+		retention: retention,
+		store:     store,
+		now:       time.Now,
+	}
+
+	for _, problem := range existing {
+		// problems persisted by older versions have a zero resolved time
+		problem.Resolved = nil
+		t.problems[problem.Module] = problem
+	}
+	t.log.Infof("loaded %d existing and %d resolved problems", len(existing), len(resolved))
+
+	return t, nil
+}
+
 func (t *tracker) Set(problem Problem) {
 	t.log.Tracef("setting problem for module %s", problem.Module)
 
@@ -52,6 +141,81 @@ func (t *tracker) Set(problem Problem) {
 	defer t.lock.Unlock()
 
 	t.problems[problem.Module] = problem
+
+	if t.store != nil {
+		if err := t.store.PutProblem(problem); err != nil {
+			t.log.Errorf("failed to persist problem for module %s: %v", problem.Module, err)
+		}
+	}
+}
+
+// Resolve removes the problem of mod, if there is one, and adds it to
+// the history of resolved problems. Returns whether there was a problem.
+func (t *tracker) Resolve(mod coordinates.Module, resolution string) bool {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	problem, exists := t.problems[mod]
+	if !exists {
+		return false
+	}
+
+	t.log.Infof("resolving problem for module %s, %s", mod, resolution)
+	delete(t.problems, mod)
+
+	resolved := t.now()
+	problem.Resolved = &resolved
+	problem.Resolution = resolution
+	t.resolved = append(t.resolved, problem)
+	t.expire()
+
+	if t.store != nil {
+		if err := t.store.DeleteProblem(mod); err != nil {
+			t.log.Errorf("failed to delete persisted problem for module %s: %v", mod, err)
+		}
+		if err := t.store.PutResolved(problem); err != nil {
+			t.log.Errorf("failed to persist resolved problem for module %s: %v", mod, err)
+		}
+	}
+
+	return true
+}
+
+// expire forgets resolved problems older than the retention period,
+// must be called while holding the lock
+func (t *tracker) expire() {
+	cutoff := t.now().Add(-t.retention)
+
+	i := 0
+	for i < len(t.resolved) && t.resolved[i].ResolvedAt().Before(cutoff) {
+		i++
+	}
+
+	if i == 0 {
+		return
+	}
+
+	t.resolved = t.resolved[i:]
+	if t.store != nil {
+		if err := t.store.DeleteResolved(cutoff); err != nil {
+			t.log.Errorf("failed to delete expired resolved problems: %v", err)
+		}
+	}
+}
+
+// Resolved returns the problems resolved within the retention period,
+// most recently resolved first.
+func (t *tracker) Resolved() []Problem {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.expire()
+
+	resolved := make([]Problem, 0, len(t.resolved))
+	for i := len(t.resolved) - 1; i >= 0; i-- {
+		resolved = append(resolved, t.resolved[i])
+	}
+	return resolved
 }
 
 func (t *tracker) Problem(mod coordinates.Module) (Problem, bool) {
@@ -95,3 +259,9 @@ func (p byName) Less(x, y int) bool {
 	}
 	return false
 }
+
+type byResolved []Problem
+
+func (p byResolved) Len() int           { return len(p) }
+func (p byResolved) Swap(x, y int)      { p[x], p[y] = p[y], p[x] }
+func (p byResolved) Less(x, y int) bool { return p[x].ResolvedAt().Before(p[y].ResolvedAt()) }
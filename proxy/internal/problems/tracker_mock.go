@@ -27,6 +27,18 @@ type TrackerMock struct {
 	beforeProblemsCounter uint64
 	ProblemsMock          mTrackerMockProblems
 
+	funcResolve          func(module coordinates.Module, resolution string) (b1 bool)
+	inspectFuncResolve   func(module coordinates.Module, resolution string)
+	afterResolveCounter  uint64
+	beforeResolveCounter uint64
+	ResolveMock          mTrackerMockResolve
+
+	funcResolved          func() (pa1 []Problem)
+	inspectFuncResolved   func()
+	afterResolvedCounter  uint64
+	beforeResolvedCounter uint64
+	ResolvedMock          mTrackerMockResolved
+
 	funcSet          func(p1 Problem)
 	inspectFuncSet   func(p1 Problem)
 	afterSetCounter  uint64
@@ -46,6 +58,11 @@ func NewTrackerMock(t minimock.Tester) *TrackerMock {
 
 	m.ProblemsMock = mTrackerMockProblems{mock: m}
 
+	m.ResolveMock = mTrackerMockResolve{mock: m}
+	m.ResolveMock.callArgs = []*TrackerMockResolveParams{}
+
+	m.ResolvedMock = mTrackerMockResolved{mock: m}
+
 	m.SetMock = mTrackerMockSet{mock: m}
 	m.SetMock.callArgs = []*TrackerMockSetParams{}
 
@@ -411,6 +428,365 @@ func (m *TrackerMock) MinimockProblemsInspect() {
 	}
 }
 
+type mTrackerMockResolve struct {
+	mock               *TrackerMock
+	defaultExpectation *TrackerMockResolveExpectation
+	expectations       []*TrackerMockResolveExpectation
+
+	callArgs []*TrackerMockResolveParams
+	mutex    sync.RWMutex
+}
+
+// TrackerMockResolveExpectation specifies expectation struct of the Tracker.Resolve
+type TrackerMockResolveExpectation struct {
+	mock    *TrackerMock
+	params  *TrackerMockResolveParams
+	results *TrackerMockResolveResults
+	Counter uint64
+}
+
+// TrackerMockResolveParams contains parameters of the Tracker.Resolve
+type TrackerMockResolveParams struct {
+	module     coordinates.Module
+	resolution string
+}
+
+// TrackerMockResolveResults contains results of the Tracker.Resolve
+type TrackerMockResolveResults struct {
+	b1 bool
+}
+
+// Expect sets up expected params for Tracker.Resolve
+func (mmResolve *mTrackerMockResolve) Expect(module coordinates.Module, resolution string) *mTrackerMockResolve {
+	if mmResolve.mock.funcResolve != nil {
+		mmResolve.mock.t.Fatalf("TrackerMock.Resolve mock is already set by Set")
+	}
+
+	if mmResolve.defaultExpectation == nil {
+		mmResolve.defaultExpectation = &TrackerMockResolveExpectation{}
+	}
+
+	mmResolve.defaultExpectation.params = &TrackerMockResolveParams{module, resolution}
+	for _, e := range mmResolve.expectations {
+		if minimock.Equal(e.params, mmResolve.defaultExpectation.params) {
+			mmResolve.mock.t.Fatalf("Expectation set by When has same params: %#v", *mmResolve.defaultExpectation.params)
+		}
+	}
+
+	return mmResolve
+}
+
+// Inspect accepts an inspector function that has same arguments as the Tracker.Resolve
+func (mmResolve *mTrackerMockResolve) Inspect(f func(module coordinates.Module, resolution string)) *mTrackerMockResolve {
+	if mmResolve.mock.inspectFuncResolve != nil {
+		mmResolve.mock.t.Fatalf("Inspect function is already set for TrackerMock.Resolve")
+	}
+
+	mmResolve.mock.inspectFuncResolve = f
+
+	return mmResolve
+}
+
+// Return sets up results that will be returned by Tracker.Resolve
+func (mmResolve *mTrackerMockResolve) Return(b1 bool) *TrackerMock {
+	if mmResolve.mock.funcResolve != nil {
+		mmResolve.mock.t.Fatalf("TrackerMock.Resolve mock is already set by Set")
+	}
+
+	if mmResolve.defaultExpectation == nil {
+		mmResolve.defaultExpectation = &TrackerMockResolveExpectation{mock: mmResolve.mock}
+	}
+	mmResolve.defaultExpectation.results = &TrackerMockResolveResults{b1}
+	return mmResolve.mock
+}
+
+//Set uses given function f to mock the Tracker.Resolve method
+func (mmResolve *mTrackerMockResolve) Set(f func(module coordinates.Module, resolution string) (b1 bool)) *TrackerMock {
+	if mmResolve.defaultExpectation != nil {
+		mmResolve.mock.t.Fatalf("Default expectation is already set for the Tracker.Resolve method")
+	}
+
+	if len(mmResolve.expectations) > 0 {
+		mmResolve.mock.t.Fatalf("Some expectations are already set for the Tracker.Resolve method")
+	}
+
+	mmResolve.mock.funcResolve = f
+	return mmResolve.mock
+}
+
+// When sets expectation for the Tracker.Resolve which will trigger the result defined by the following
+// Then helper
+func (mmResolve *mTrackerMockResolve) When(module coordinates.Module, resolution string) *TrackerMockResolveExpectation {
+	if mmResolve.mock.funcResolve != nil {
+		mmResolve.mock.t.Fatalf("TrackerMock.Resolve mock is already set by Set")
+	}
+
+	expectation := &TrackerMockResolveExpectation{
+		mock:   mmResolve.mock,
+		params: &TrackerMockResolveParams{module, resolution},
+	}
+	mmResolve.expectations = append(mmResolve.expectations, expectation)
+	return expectation
+}
+
+// Then sets up Tracker.Resolve return parameters for the expectation previously defined by the When method
+func (e *TrackerMockResolveExpectation) Then(b1 bool) *TrackerMock {
+	e.results = &TrackerMockResolveResults{b1}
+	return e.mock
+}
+
+// Resolve implements Tracker
+func (mmResolve *TrackerMock) Resolve(module coordinates.Module, resolution string) (b1 bool) {
+	mm_atomic.AddUint64(&mmResolve.beforeResolveCounter, 1)
+	defer mm_atomic.AddUint64(&mmResolve.afterResolveCounter, 1)
+
+	if mmResolve.inspectFuncResolve != nil {
+		mmResolve.inspectFuncResolve(module, resolution)
+	}
+
+	mm_params := &TrackerMockResolveParams{module, resolution}
+
+	// Record call args
+	mmResolve.ResolveMock.mutex.Lock()
+	mmResolve.ResolveMock.callArgs = append(mmResolve.ResolveMock.callArgs, mm_params)
+	mmResolve.ResolveMock.mutex.Unlock()
+
+	for _, e := range mmResolve.ResolveMock.expectations {
+		if minimock.Equal(e.params, mm_params) {
+			mm_atomic.AddUint64(&e.Counter, 1)
+			return e.results.b1
+		}
+	}
+
+	if mmResolve.ResolveMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmResolve.ResolveMock.defaultExpectation.Counter, 1)
+		mm_want := mmResolve.ResolveMock.defaultExpectation.params
+		mm_got := TrackerMockResolveParams{module, resolution}
+		if mm_want != nil && !minimock.Equal(*mm_want, mm_got) {
+			mmResolve.t.Errorf("TrackerMock.Resolve got unexpected parameters, want: %#v, got: %#v%s\n", *mm_want, mm_got, minimock.Diff(*mm_want, mm_got))
+		}
+
+		mm_results := mmResolve.ResolveMock.defaultExpectation.results
+		if mm_results == nil {
+			mmResolve.t.Fatal("No results are set for the TrackerMock.Resolve")
+		}
+		return (*mm_results).b1
+	}
+	if mmResolve.funcResolve != nil {
+		return mmResolve.funcResolve(module, resolution)
+	}
+	mmResolve.t.Fatalf("Unexpected call to TrackerMock.Resolve. %v %v", module, resolution)
+	return
+}
+
+// ResolveAfterCounter returns a count of finished TrackerMock.Resolve invocations
+func (mmResolve *TrackerMock) ResolveAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolve.afterResolveCounter)
+}
+
+// ResolveBeforeCounter returns a count of TrackerMock.Resolve invocations
+func (mmResolve *TrackerMock) ResolveBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolve.beforeResolveCounter)
+}
+
+// Calls returns a list of arguments used in each call to TrackerMock.Resolve.
+// The list is in the same order as the calls were made (i.e. recent calls have a higher index)
+func (mmResolve *mTrackerMockResolve) Calls() []*TrackerMockResolveParams {
+	mmResolve.mutex.RLock()
+
+	argCopy := make([]*TrackerMockResolveParams, len(mmResolve.callArgs))
+	copy(argCopy, mmResolve.callArgs)
+
+	mmResolve.mutex.RUnlock()
+
+	return argCopy
+}
+
+// MinimockResolveDone returns true if the count of the Resolve invocations corresponds
+// the number of defined expectations
+func (m *TrackerMock) MinimockResolveDone() bool {
+	for _, e := range m.ResolveMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolveMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolve != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockResolveInspect logs each unmet expectation
+func (m *TrackerMock) MinimockResolveInspect() {
+	for _, e := range m.ResolveMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Errorf("Expected call to TrackerMock.Resolve with params: %#v", *e.params)
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolveMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		if m.ResolveMock.defaultExpectation.params == nil {
+			m.t.Error("Expected call to TrackerMock.Resolve")
+		} else {
+			m.t.Errorf("Expected call to TrackerMock.Resolve with params: %#v", *m.ResolveMock.defaultExpectation.params)
+		}
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolve != nil && mm_atomic.LoadUint64(&m.afterResolveCounter) < 1 {
+		m.t.Error("Expected call to TrackerMock.Resolve")
+	}
+}
+
+type mTrackerMockResolved struct {
+	mock               *TrackerMock
+	defaultExpectation *TrackerMockResolvedExpectation
+	expectations       []*TrackerMockResolvedExpectation
+}
+
+// TrackerMockResolvedExpectation specifies expectation struct of the Tracker.Resolved
+type TrackerMockResolvedExpectation struct {
+	mock *TrackerMock
+
+	results *TrackerMockResolvedResults
+	Counter uint64
+}
+
+// TrackerMockResolvedResults contains results of the Tracker.Resolved
+type TrackerMockResolvedResults struct {
+	pa1 []Problem
+}
+
+// Expect sets up expected params for Tracker.Resolved
+func (mmResolved *mTrackerMockResolved) Expect() *mTrackerMockResolved {
+	if mmResolved.mock.funcResolved != nil {
+		mmResolved.mock.t.Fatalf("TrackerMock.Resolved mock is already set by Set")
+	}
+
+	if mmResolved.defaultExpectation == nil {
+		mmResolved.defaultExpectation = &TrackerMockResolvedExpectation{}
+	}
+
+	return mmResolved
+}
+
+// Inspect accepts an inspector function that has same arguments as the Tracker.Resolved
+func (mmResolved *mTrackerMockResolved) Inspect(f func()) *mTrackerMockResolved {
+	if mmResolved.mock.inspectFuncResolved != nil {
+		mmResolved.mock.t.Fatalf("Inspect function is already set for TrackerMock.Resolved")
+	}
+
+	mmResolved.mock.inspectFuncResolved = f
+
+	return mmResolved
+}
+
+// Return sets up results that will be returned by Tracker.Resolved
+func (mmResolved *mTrackerMockResolved) Return(pa1 []Problem) *TrackerMock {
+	if mmResolved.mock.funcResolved != nil {
+		mmResolved.mock.t.Fatalf("TrackerMock.Resolved mock is already set by Set")
+	}
+
+	if mmResolved.defaultExpectation == nil {
+		mmResolved.defaultExpectation = &TrackerMockResolvedExpectation{mock: mmResolved.mock}
+	}
+	mmResolved.defaultExpectation.results = &TrackerMockResolvedResults{pa1}
+	return mmResolved.mock
+}
+
+//Set uses given function f to mock the Tracker.Resolved method
+func (mmResolved *mTrackerMockResolved) Set(f func() (pa1 []Problem)) *TrackerMock {
+	if mmResolved.defaultExpectation != nil {
+		mmResolved.mock.t.Fatalf("Default expectation is already set for the Tracker.Resolved method")
+	}
+
+	if len(mmResolved.expectations) > 0 {
+		mmResolved.mock.t.Fatalf("Some expectations are already set for the Tracker.Resolved method")
+	}
+
+	mmResolved.mock.funcResolved = f
+	return mmResolved.mock
+}
+
+// Resolved implements Tracker
+func (mmResolved *TrackerMock) Resolved() (pa1 []Problem) {
+	mm_atomic.AddUint64(&mmResolved.beforeResolvedCounter, 1)
+	defer mm_atomic.AddUint64(&mmResolved.afterResolvedCounter, 1)
+
+	if mmResolved.inspectFuncResolved != nil {
+		mmResolved.inspectFuncResolved()
+	}
+
+	if mmResolved.ResolvedMock.defaultExpectation != nil {
+		mm_atomic.AddUint64(&mmResolved.ResolvedMock.defaultExpectation.Counter, 1)
+
+		mm_results := mmResolved.ResolvedMock.defaultExpectation.results
+		if mm_results == nil {
+			mmResolved.t.Fatal("No results are set for the TrackerMock.Resolved")
+		}
+		return (*mm_results).pa1
+	}
+	if mmResolved.funcResolved != nil {
+		return mmResolved.funcResolved()
+	}
+	mmResolved.t.Fatalf("Unexpected call to TrackerMock.Resolved.")
+	return
+}
+
+// ResolvedAfterCounter returns a count of finished TrackerMock.Resolved invocations
+func (mmResolved *TrackerMock) ResolvedAfterCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolved.afterResolvedCounter)
+}
+
+// ResolvedBeforeCounter returns a count of TrackerMock.Resolved invocations
+func (mmResolved *TrackerMock) ResolvedBeforeCounter() uint64 {
+	return mm_atomic.LoadUint64(&mmResolved.beforeResolvedCounter)
+}
+
+// MinimockResolvedDone returns true if the count of the Resolved invocations corresponds
+// the number of defined expectations
+func (m *TrackerMock) MinimockResolvedDone() bool {
+	for _, e := range m.ResolvedMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			return false
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolvedMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolvedCounter) < 1 {
+		return false
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolved != nil && mm_atomic.LoadUint64(&m.afterResolvedCounter) < 1 {
+		return false
+	}
+	return true
+}
+
+// MinimockResolvedInspect logs each unmet expectation
+func (m *TrackerMock) MinimockResolvedInspect() {
+	for _, e := range m.ResolvedMock.expectations {
+		if mm_atomic.LoadUint64(&e.Counter) < 1 {
+			m.t.Error("Expected call to TrackerMock.Resolved")
+		}
+	}
+
+	// if default expectation was set then invocations count should be greater than zero
+	if m.ResolvedMock.defaultExpectation != nil && mm_atomic.LoadUint64(&m.afterResolvedCounter) < 1 {
+		m.t.Error("Expected call to TrackerMock.Resolved")
+	}
+	// if func was set then invocations count should be greater than zero
+	if m.funcResolved != nil && mm_atomic.LoadUint64(&m.afterResolvedCounter) < 1 {
+		m.t.Error("Expected call to TrackerMock.Resolved")
+	}
+}
+
 type mTrackerMockSet struct {
 	mock               *TrackerMock
 	defaultExpectation *TrackerMockSetExpectation
@@ -605,6 +981,10 @@ func (m *TrackerMock) MinimockFinish() {
 
 		m.MinimockProblemsInspect()
 
+		m.MinimockResolveInspect()
+
+		m.MinimockResolvedInspect()
+
 		m.MinimockSetInspect()
 		m.t.FailNow()
 	}
@@ -631,5 +1011,7 @@ func (m *TrackerMock) minimockDone() bool {
 	return done &&
 		m.MinimockProblemDone() &&
 		m.MinimockProblemsDone() &&
+		m.MinimockResolveDone() &&
+		m.MinimockResolvedDone() &&
 		m.MinimockSetDone()
 }
@@ -1,6 +1,7 @@
 package problems
 
 import (
+	"encoding/json"
 	"errors"
 	"sort"
 	"testing"
@@ -12,7 +13,7 @@ import (
 )
 
 func Test_Tracker_empty_default(t *testing.T) {
-	pt := New("foo")
+	pt := New("foo", 1*time.Hour)
 
 	problems := pt.Problems()
 	require.Equal(t, 0, len(problems))
@@ -25,7 +26,7 @@ func Test_Tracker_empty_default(t *testing.T) {
 }
 
 func Test_Tracker_Set_one(t *testing.T) {
-	pt := New("foo")
+	pt := New("foo", 1*time.Hour)
 
 	pt.Set(Problem{
 		Module: coordinates.Module{
@@ -47,6 +48,154 @@ func Test_Tracker_Set_one(t *testing.T) {
 	require.Equal(t, "foobar is broken", problem.Message)
 }
 
+func Test_Tracker_Resolve(t *testing.T) {
+	pt := New("foo", 1*time.Hour)
+
+	mod := coordinates.Module{
+		Source:  "github.com/foo/bar",
+		Version: "1.2.3",
+	}
+
+	pt.Set(Create(mod, errors.New("foobar is broken")))
+	require.Equal(t, 1, len(pt.Problems()))
+
+	resolved := pt.Resolve(mod, Downloaded)
+	require.True(t, resolved)
+	require.Equal(t, 0, len(pt.Problems()))
+
+	_, exists := pt.Problem(mod)
+	require.False(t, exists)
+
+	history := pt.Resolved()
+	require.Equal(t, 1, len(history))
+	require.Equal(t, mod, history[0].Module)
+	require.Equal(t, Downloaded, history[0].Resolution)
+	require.False(t, history[0].ResolvedAt().IsZero())
+
+	// resolving a module without a problem is a no-op
+	resolved = pt.Resolve(mod, Acknowledged)
+	require.False(t, resolved)
+	require.Equal(t, 1, len(pt.Resolved()))
+}
+
+func Test_Tracker_Resolved_retention(t *testing.T) {
+	pt := New("foo", 1*time.Hour).(*tracker)
+
+	start := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	mod1 := coordinates.Module{
+		Source:  "github.com/foo/bar",
+		Version: "1.2.3",
+	}
+
+	mod2 := coordinates.Module{
+		Source:  "github.com/foo/baz",
+		Version: "1.2.3",
+	}
+
+	pt.Set(Create(mod1, errors.New("m1")))
+	pt.Set(Create(mod2, errors.New("m2")))
+
+	pt.now = func() time.Time { return start }
+	pt.Resolve(mod1, Downloaded)
+
+	pt.now = func() time.Time { return start.Add(30 * time.Minute) }
+	pt.Resolve(mod2, Acknowledged)
+
+	// most recently resolved first
+	history := pt.Resolved()
+	require.Equal(t, 2, len(history))
+	require.Equal(t, mod2, history[0].Module)
+	require.Equal(t, mod1, history[1].Module)
+
+	// mod1 falls out of the retention window
+	pt.now = func() time.Time { return start.Add(61 * time.Minute) }
+	history = pt.Resolved()
+	require.Equal(t, 1, len(history))
+	require.Equal(t, mod2, history[0].Module)
+}
+
+type memStore struct {
+	problems map[coordinates.Module]Problem
+	resolved []Problem
+}
+
+func (s *memStore) PutProblem(problem Problem) error {
+	s.problems[problem.Module] = problem
+	return nil
+}
+
+func (s *memStore) DeleteProblem(mod coordinates.Module) error {
+	delete(s.problems, mod)
+	return nil
+}
+
+func (s *memStore) ListProblems() ([]Problem, error) {
+	problems := make([]Problem, 0, len(s.problems))
+	for _, problem := range s.problems {
+		problems = append(problems, problem)
+	}
+	return problems, nil
+}
+
+func (s *memStore) PutResolved(problem Problem) error {
+	s.resolved = append(s.resolved, problem)
+	return nil
+}
+
+func (s *memStore) DeleteResolved(before time.Time) error {
+	var keep []Problem
+	for _, problem := range s.resolved {
+		if !problem.ResolvedAt().Before(before) {
+			keep = append(keep, problem)
+		}
+	}
+	s.resolved = keep
+	return nil
+}
+
+func (s *memStore) ListResolved() ([]Problem, error) {
+	return s.resolved, nil
+}
+
+func Test_Tracker_persistent(t *testing.T) {
+	store := &memStore{problems: make(map[coordinates.Module]Problem)}
+
+	mod1 := coordinates.Module{
+		Source:  "github.com/foo/bar",
+		Version: "1.2.3",
+	}
+
+	mod2 := coordinates.Module{
+		Source:  "github.com/foo/baz",
+		Version: "1.2.3",
+	}
+
+	pt, err := NewPersistent("foo", 1*time.Hour, store)
+	require.NoError(t, err)
+
+	pt.Set(Create(mod1, errors.New("m1")))
+	pt.Set(Create(mod2, errors.New("m2")))
+	pt.Resolve(mod2, Downloaded)
+	require.Equal(t, 1, len(store.problems))
+	require.Equal(t, 1, len(store.resolved))
+
+	// a new tracker picks up where the old one left off
+	pt2, err := NewPersistent("foo", 1*time.Hour, store)
+	require.NoError(t, err)
+
+	problem, exists := pt2.Problem(mod1)
+	require.True(t, exists)
+	require.Equal(t, "m1", problem.Message)
+
+	_, exists = pt2.Problem(mod2)
+	require.False(t, exists)
+
+	history := pt2.Resolved()
+	require.Equal(t, 1, len(history))
+	require.Equal(t, mod2, history[0].Module)
+}
+
 func Test_byName(t *testing.T) {
 	mod1 := coordinates.Module{
 		Source:  "github.com/zzz/bar",
@@ -95,3 +244,25 @@ func Test_byName(t *testing.T) {
 	// mod1 is zzz
 	require.Equal(t, mod1, problems[5].Module)
 }
+
+func Test_Problem_json(t *testing.T) {
+	mod := coordinates.Module{Source: "github.com/foo/bar", Version: "v1.2.3"}
+	problem := Create(mod, errors.New("broken"))
+
+	// an open problem has no resolved time at all
+	bs, err := json.Marshal(problem)
+	require.NoError(t, err)
+	require.NotContains(t, string(bs), `"resolved"`)
+	require.True(t, problem.ResolvedAt().IsZero())
+
+	resolved := time.Date(2019, 6, 1, 0, 0, 0, 0, time.UTC)
+	problem.Resolved = &resolved
+	bs, err = json.Marshal(problem)
+	require.NoError(t, err)
+	require.Contains(t, string(bs), `"resolved":"2019-06-01T00:00:00Z"`)
+
+	var decoded Problem
+	err = json.Unmarshal(bs, &decoded)
+	require.NoError(t, err)
+	require.Equal(t, resolved, decoded.ResolvedAt())
+}
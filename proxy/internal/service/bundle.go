@@ -0,0 +1,88 @@
+package service
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/ignore"
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/proxy/config"
+	"oss.indeed.com/go/modprox/proxy/internal/bundle"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
+)
+
+// Export writes the modules stored by a proxy with the given Configuration
+// into a bundle file. If registered is set, only the modules currently listed
+// by the registry are exported, otherwise every module in the index is.
+func Export(configuration config.Configuration, filename string, registered bool) error {
+	p, err := storageOf(configuration)
+	if err != nil {
+		return errors.Wrap(err, "failed to open storage")
+	}
+
+	mods, err := p.index.List()
+	if err != nil {
+		return errors.Wrap(err, "failed to list modules to export")
+	}
+
+	if registered {
+		if err := initRegistryClient(p); err != nil {
+			return err
+		}
+		registryAPI := get.NewRegistryAPI(p.registryClient, p.index)
+		if mods, err = registryAPI.ModulesRegistered(); err != nil {
+			return errors.Wrap(err, "failed to list modules registered with registry")
+		}
+	}
+
+	// written next to the bundle and renamed once complete, so that
+	// a partially written bundle is never mistaken for a whole one
+	tmp, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename)+".")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	manifest, err := bundle.NewExporter(p.index, p.store).Export(mods, tmp)
+	if err != nil {
+		ignore.Close(tmp)
+		return err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(tmp.Name(), filename); err != nil {
+		return err
+	}
+
+	loggy.New("export").Infof("exported %d modules into %s", len(manifest.Modules), filename)
+	return nil
+}
+
+// Import adds the modules of a bundle file to the storage of a proxy with
+// the given Configuration. Modules the proxy already has are skipped, so a
+// bundle may be imported again, e.g. after an import failed part way.
+func Import(configuration config.Configuration, filename string) error {
+	p, err := storageOf(configuration)
+	if err != nil {
+		return errors.Wrap(err, "failed to open storage")
+	}
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return err
+	}
+	defer ignore.Close(f)
+
+	importer := bundle.NewImporter(p.index, p.store, configuration.Downloads.TmpPath)
+	imported, err := importer.Import(f)
+	loggy.New("import").Infof("imported %d modules from %s, %d were already stored",
+		imported.Added, filename, imported.Skipped)
+	return err
+}
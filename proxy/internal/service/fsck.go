@@ -0,0 +1,68 @@
+package service
+
+import (
+	"github.com/pkg/errors"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/proxy/config"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+// Fsck checks the consistency of the boltdb index and the zips of a proxy with
+// the given Configuration, repairing what can be repaired if repair is set. An
+// error is returned if any problems remain.
+func Fsck(configuration config.Configuration, repair bool) error {
+	p, err := storageOf(configuration)
+	if err != nil {
+		return errors.Wrap(err, "failed to open storage")
+	}
+
+	if err := initRegistryClient(p); err != nil {
+		return err
+	}
+
+	return fsck(p, repair)
+}
+
+func initFsck(p *Proxy) error {
+	cfg := p.config.Fsck
+	if !cfg.OnStartup {
+		return nil
+	}
+
+	return fsck(p, cfg.Repair)
+}
+
+func fsck(p *Proxy, repair bool) error {
+	if p.config.ModuleStorage == nil || p.config.ZipS3Storage != nil {
+		return errors.New("fsck requires zips and index stored in module_storage")
+	}
+
+	checker, err := store.NewChecker(p.index, p.config.ModuleStorage.DataPath, p.emitter)
+	if err != nil {
+		return err
+	}
+
+	// the registry knows the serial IDs of modules missing from the index,
+	// without them the index entries of such modules cannot be rebuilt
+	registered, err := get.NewRegistryAPI(p.registryClient, p.index).ModulesRegistered()
+	if err != nil {
+		p.log.Warnf("failed to list modules registered with registry, %v", err)
+		registered = nil // so zips missing from the index are not taken for orphans
+	} else if registered == nil {
+		registered = []coordinates.SerialModule{} // nothing is registered
+	}
+
+	p.log.Infof("checking consistency of index and zips in %s", p.config.ModuleStorage.DataPath)
+	report, err := checker.Check(repair, registered)
+	if err != nil {
+		return errors.Wrap(err, "failed to check consistency of index and zips")
+	}
+
+	p.log.Infof("fsck %s", report)
+	if n := report.Problems(); n > 0 {
+		return errors.Errorf("fsck found %d problems which remain", n)
+	}
+	return nil
+}
@@ -3,12 +3,17 @@ package service
 import (
 	"net/http"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/pkg/errors"
 
+	"gophers.dev/pkgs/repeat/x"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
 	"oss.indeed.com/go/modprox/pkg/clients/payloads"
 	"oss.indeed.com/go/modprox/pkg/clients/registry"
+	"oss.indeed.com/go/modprox/pkg/clients/s3"
 	"oss.indeed.com/go/modprox/pkg/clients/zips"
 	"oss.indeed.com/go/modprox/pkg/history"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
@@ -17,11 +22,13 @@ import (
 	"oss.indeed.com/go/modprox/pkg/upstream"
 	"oss.indeed.com/go/modprox/pkg/webutil"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/bg"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 	"oss.indeed.com/go/modprox/proxy/internal/problems"
 	"oss.indeed.com/go/modprox/proxy/internal/status/heartbeat"
 	"oss.indeed.com/go/modprox/proxy/internal/status/startup"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
 	"oss.indeed.com/go/modprox/proxy/internal/web"
 )
 
@@ -44,8 +51,27 @@ func initSender(p *Proxy) error {
 }
 
 func initTrackers(p *Proxy) error {
-	dlTracker := problems.New("downloads")
+	// both the boltdb and mysql indexes are capable of storing problems
+	problemStore, ok := p.index.(problems.Store)
+	if !ok {
+		return errors.New("module index is not capable of storing problems")
+	}
+
+	retentionS := p.config.Problems.ResolvedRetentionS
+	if retentionS <= 0 {
+		retentionS = 24 * 60 * 60
+	}
+	retention := time.Duration(retentionS) * time.Second
+
+	dlTracker, err := problems.NewPersistent("downloads", retention, problemStore)
+	if err != nil {
+		return errors.Wrap(err, "unable to load download problems")
+	}
 	p.dlTracker = dlTracker
+
+	// integrity problems are found again on the next check after a
+	// restart, so there is no need to persist them
+	p.integrity = problems.New("integrity", retention)
 	return nil
 }
 
@@ -86,6 +112,10 @@ func initStore(p *Proxy) error {
 		return errors.New("configs must be specified for either file or db module index (but not both)")
 	}
 
+	if p.config.ZipS3Storage != nil {
+		return initS3Store(p)
+	}
+
 	if p.config.ModuleStorage != nil {
 		storePath := p.config.ModuleStorage.DataPath
 		if storePath == "" {
@@ -93,10 +123,17 @@ func initStore(p *Proxy) error {
 		}
 
 		tmpPath := p.config.ModuleStorage.TmpPath
-		p.store = store.NewStore(store.Options{
+		options := store.Options{
 			Directory:    storePath,
 			TmpDirectory: tmpPath,
-		}, p.emitter)
+		}
+
+		if p.config.ModuleStorage.ContentAddressed {
+			p.log.Infof("storing module zips by content, identical zips are stored once")
+			p.store = store.NewContentStore(options, p.index, p.emitter)
+		} else {
+			p.store = store.NewStore(options, p.emitter)
+		}
 	} else if p.config.ModuleDBStorage != nil {
 		_, dsn, err := dbStorageDSN(p, p.config.ModuleDBStorage)
 		if err != nil {
@@ -111,6 +148,120 @@ func initStore(p *Proxy) error {
 	return nil
 }
 
+func initZipCache(p *Proxy) error {
+	cache := p.config.ZipCache
+	if cache == nil {
+		return nil
+	}
+
+	if cache.DataPath == "" {
+		return errors.New("zip_cache.data_path is required")
+	}
+
+	if cache.SizeMB <= 0 {
+		return errors.Errorf("zip_cache.size_mb must be > 0, got %d", cache.SizeMB)
+	}
+
+	p.log.Infof("caching up to %d MB of module zips in %s", cache.SizeMB, cache.DataPath)
+	tiered, err := store.NewTieredStore(store.Options{
+		Directory:    cache.DataPath,
+		TmpDirectory: cache.TmpPath,
+	}, p.store, cache.SizeMB*1024*1024, p.emitter)
+	if err != nil {
+		return errors.Wrap(err, "unable to load zip_cache")
+	}
+
+	p.store = tiered
+	return nil
+}
+
+func initAccessLog(p *Proxy) error {
+	eviction := p.config.Eviction
+	if !eviction.Enabled {
+		// nothing is evicted, so there is no point in recording accesses
+		p.accesses = store.DiscardAccesses()
+		p.counter = store.DiscardCounts()
+		return nil
+	}
+
+	if eviction.QuotaMB <= 0 {
+		return errors.Errorf("eviction.quota_mb must be > 0 when eviction is enabled, got %d", eviction.QuotaMB)
+	}
+
+	switch bg.Policy(eviction.Policy) {
+	case "", bg.LRU, bg.LFU:
+	default:
+		return errors.Errorf("eviction.policy must be %q or %q, got %q", bg.LRU, bg.LFU, eviction.Policy)
+	}
+
+	if eviction.CacheOnly && !p.config.PullThrough.Enabled {
+		return errors.New("eviction.cache_only requires pull_through to be enabled")
+	}
+
+	// both the boltdb and mysql indexes are capable of recording accesses
+	accesses, ok := p.index.(store.AccessLog)
+	if !ok {
+		return errors.New("module index is not capable of recording accesses")
+	}
+	p.accesses = accesses
+
+	// serving a zip only counts the access in memory, the counts are
+	// written to the index in batches by the evictor
+	p.counter = store.NewAccessCounter(accesses)
+	return nil
+}
+
+func initIngester(p *Proxy) error {
+	// both the boltdb and mysql indexes are capable of keeping a journal
+	journal, ok := p.index.(store.Journal)
+	if !ok {
+		return errors.New("module index is not capable of keeping a journal")
+	}
+
+	ingester, err := store.NewIngester(p.index, p.store, journal, p.emitter)
+	if err != nil {
+		return errors.Wrap(err, "unable to create ingester")
+	}
+
+	p.ingester = ingester
+	return nil
+}
+
+func initS3Store(p *Proxy) error {
+	cfg := p.config.ZipS3Storage
+
+	accessKeyID := cfg.AccessKeyID
+	if accessKeyID == "" {
+		accessKeyID = os.Getenv("AWS_ACCESS_KEY_ID")
+	}
+
+	secretAccessKey := cfg.SecretAccessKey
+	if secretAccessKey == "" {
+		secretAccessKey = os.Getenv("AWS_SECRET_ACCESS_KEY")
+	}
+
+	timeoutS := cfg.RequestTimeoutS
+	if timeoutS <= 0 {
+		timeoutS = 5 * 60 // zips can be large
+	}
+
+	client, err := s3.New(s3.Options{
+		Endpoint:        cfg.Endpoint,
+		Region:          cfg.Region,
+		Bucket:          cfg.Bucket,
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secretAccessKey,
+		Timeout:         time.Duration(timeoutS) * time.Second,
+	})
+	if err != nil {
+		return errors.Wrap(err, "unable to create s3 client for zip_s3_storage")
+	}
+
+	p.log.Infof("storing module zips in s3 bucket %q at %s", cfg.Bucket, cfg.Endpoint)
+	p.store = store.NewS3Store(client, cfg.Prefix, p.config.Downloads.TmpPath, p.emitter)
+	return nil
+}
+
 func dbStorageDSN(p *Proxy, conf *setup.PersistentStore) (string, setup.DSN, error) {
 	kind, dsn, err := conf.DSN()
 	if err != nil {
@@ -164,43 +315,300 @@ func initZipClients(p *Proxy) error {
 			Timeout: 1 * time.Minute,
 		},
 	)
-	p.upstreamClient = zips.NewUpstreamClient(httpClient)
+
+	// create upstream clients for domains with a vcs transport, e.g. git,
+	// or those of go-import meta tags with the hg, svn or fossil vcs
+	gitClient, hgClient, fossilClient := initMirrorClients(p)
+	p.revisions = gitClient // for resolving branches into versions
+	svnClient := zips.NewSVNClient(
+		zips.SVNOptions{
+			Timeout: 10 * time.Minute,
+			TmpDir:  p.config.Downloads.TmpPath,
+		},
+	)
+	vcsClients := []zips.UpstreamClient{gitClient, hgClient, svnClient, fossilClient}
+	p.upstreamClient = zips.NewUpstreamClient(append(vcsClients, httpClient)...)
+
+	// and for every other domain too, if mirroring is enabled
+	if p.config.Mirrors.Enabled {
+		p.log.Infof("mirrors enabled, upstream repositories will be cloned into mirrors")
+		retryAfter := zips.DefaultCloneRetry
+		if retryS := p.config.Mirrors.CloneRetryS; retryS != 0 {
+			retryAfter = time.Duration(retryS) * time.Second
+		}
+		p.upstreamClient = zips.NewUpstreamClient(
+			append(vcsClients, zips.NewMirroringClient(gitClient, httpClient, retryAfter))...,
+		)
+	}
+
+	// create a client for looking up commits of upstream versions
+	apis := zips.DefaultAPIs()
+	for _, api := range p.config.Transforms.DomainAPIs {
+		apis[api.Domain] = zips.API{
+			Kind:    zips.APIKind(api.Kind),
+			BaseURL: api.BaseURL,
+		}
+	}
+	p.commitClient = zips.NewCommitClient(
+		zips.CommitClientOptions{
+			APIs:    apis,
+			Timeout: 1 * time.Minute,
+		},
+	)
 
 	return nil
 }
 
-func initBGWorker(p *Proxy) error {
-	reloadFreqS := time.Duration(p.config.Registry.PollFrequencyS) * time.Second
-	registryRequester := get.NewRegistryAPI(
-		p.registryClient,
-		p.index,
-	)
+func initMirrorClients(p *Proxy) (git zips.GitClient, hg, fossil zips.MirrorClient) {
+	cfg := p.config.Mirrors
+
+	mirrorsPath := cfg.Path
+	if mirrorsPath == "" {
+		tmpPath := p.config.Downloads.TmpPath
+		if tmpPath == "" {
+			tmpPath = os.TempDir()
+		}
+		mirrorsPath = filepath.Join(tmpPath, "git-mirrors")
+	}
+
+	queryTTL := zips.DefaultQueryTTL
+	if cacheS := cfg.QueryCacheS; cacheS != 0 {
+		queryTTL = time.Duration(cacheS) * time.Second
+	}
+
+	options := zips.MirrorOptions{
+		Directory: mirrorsPath,
+		TmpDir:    p.config.Downloads.TmpPath,
+		Timeout:   10 * time.Minute,
+		MaxSize:   cfg.MaxSizeMB * 1024 * 1024,
+		MaxUnused: time.Duration(cfg.MaxUnusedS) * time.Second,
+		QueryTTL:  queryTTL,
+	}
+	git = zips.NewGitClient(options)
+	hg = zips.NewHgClient(options)
+	fossil = zips.NewFossilClient(options)
+
+	if cfg.MaxSizeMB <= 0 && cfg.MaxUnusedS <= 0 {
+		return git, hg, fossil
+	}
+
+	intervalS := cfg.IntervalS
+	if intervalS <= 0 {
+		intervalS = 60 * 60
+	}
+
+	// on startup, and then every interval, with max_size_mb
+	// applying to the mirrors of every VCS together
+	go func() {
+		_ = x.Interval(time.Duration(intervalS)*time.Second, func() error {
+			usage, err := zips.CollectAll(git, hg, fossil)
+			if err != nil {
+				p.log.Errorf("failed to collect mirrors, %v", err)
+				return nil // try again next time
+			}
+			p.emitter.Gauge("mirrors-count", usage.Mirrors)
+			p.emitter.Gauge("mirrors-bytes", int(usage.Bytes))
+			p.emitter.Count("mirrors-removed", usage.Removed)
+			return nil
+		})
+	}()
 
-	resolver := upstream.NewResolver(
+	return git, hg, fossil
+}
+
+func initSumDB(p *Proxy) error {
+	cfg := p.config.SumDBServer
+	if !cfg.Enabled {
+		return nil
+	}
+
+	// every instance signing with the key must serve the same tree, which
+	// only a database shared by the instances makes sure of
+	if p.config.ModuleDBStorage == nil && !cfg.SingleInstance {
+		return errors.New("sumdb_server requires module_db_storage shared by every instance of the proxy, " +
+			"or single_instance if no other instance serves the checksum database")
+	}
+
+	// both the boltdb and mysql indexes are capable of storing the log
+	recordStore, ok := p.index.(sumdb.Store)
+	if !ok {
+		return errors.New("module index is not capable of storing a checksum database")
+	}
+
+	sumLog, err := sumdb.New(cfg.PrivateKey, recordStore, p.emitter)
+	if err != nil {
+		return errors.Wrap(err, "unable to create checksum database")
+	}
+
+	p.log.Infof("serving checksum database %s", sumLog.Name())
+	p.sumLog = sumLog
+
+	// modules stored before the log was enabled, or imported or migrated
+	// since, have no record yet; this can take a while for a large index,
+	// but is done before anything is downloaded, so that they are appended
+	// in the order of the index rather than in between downloads
+	if _, err := sumLog.Backfill(p.index); err != nil {
+		return errors.Wrap(err, "unable to backfill checksum database")
+	}
+	return nil
+}
+
+func initVerifier(p *Proxy) error {
+	var verifiers []checksum.Verifier
+
+	cfg := p.config.ChecksumDB
+	if cfg.Enabled {
+		verifier, err := checksum.New(checksum.Options{
+			URL:       cfg.URL,
+			Key:       cfg.PublicKey,
+			Private:   cfg.Private,
+			CachePath: cfg.CachePath,
+			Timeout:   1 * time.Minute,
+		})
+		if err != nil {
+			return errors.Wrap(err, "unable to create checksum database verifier")
+		}
+		p.log.Infof("downloaded modules will be verified against checksum database %s", cfg.URL)
+		verifiers = append(verifiers, verifier)
+	} else {
+		p.log.Warnf("checksum database is disabled, downloaded modules will not be verified")
+	}
+
+	// modules must pass verification before they are recorded in our own log
+	if p.sumLog != nil {
+		verifiers = append(verifiers, p.sumLog)
+	}
+
+	if len(verifiers) == 0 {
+		p.verifier = checksum.Disabled()
+		return nil
+	}
+
+	p.verifier = checksum.Chain(verifiers...)
+	return nil
+}
+
+func initDownloader(p *Proxy) error {
+	resolver := upstream.NewRevisionResolver(
+		p.revisions,
 		initTransforms(p)...,
 	)
 
-	downloader := get.New(
+	p.downloader = get.New(
 		p.proxyClient,
 		p.upstreamClient,
+		p.commitClient,
+		p.revisions,
 		resolver,
-		p.store,
+		p.ingester,
+		p.verifier,
+		p.dlTracker,
+		p.config.Downloads.TmpPath,
+		p.emitter,
+	)
+
+	return nil
+}
+
+func initFetcher(p *Proxy) error {
+	if !p.config.PullThrough.Enabled {
+		p.fetcher = fetch.Disabled()
+		return nil
+	}
+
+	p.log.Infof("pull-through enabled, missing modules will be fetched on-demand")
+	p.fetcher = fetch.New(
+		p.downloader,
+		get.NewRegistryAPI(p.registryClient, p.index),
 		p.index,
+		p.dlTracker,
 		p.emitter,
 	)
 
+	return nil
+}
+
+func initBGWorker(p *Proxy) error {
+	reloadFreqS := time.Duration(p.config.Registry.PollFrequencyS) * time.Second
+	registryRequester := get.NewRegistryAPI(
+		p.registryClient,
+		p.index,
+	)
+
 	p.bgWorker = bg.New(
 		p.emitter,
 		p.dlTracker,
+		p.integrity,
 		p.index,
 		p.store,
+		p.accesses,
+		p.counter,
+		p.ingester,
+		p.verifier,
 		registryRequester,
-		downloader,
+		p.downloader,
 	)
 
+	retryInitialS := p.config.Downloads.RetryInitialS
+	if retryInitialS <= 0 {
+		retryInitialS = 60
+	}
+
+	retryMaxS := p.config.Downloads.RetryMaxS
+	if retryMaxS <= 0 {
+		retryMaxS = 6 * 60 * 60
+	}
+
+	prune := p.config.Prune
+	if prune.Enabled && prune.GracePeriodS <= 0 {
+		return errors.Errorf(
+			"prune.grace_period_s must be > 0 when pruning is enabled, got %d",
+			prune.GracePeriodS,
+		)
+	}
+
+	integrity := p.config.Integrity
+	integrityIntervalS := integrity.IntervalS
+	if integrityIntervalS <= 0 {
+		integrityIntervalS = 24 * 60 * 60
+	}
+
+	eviction := p.config.Eviction
+	evictionIntervalS := eviction.IntervalS
+	if evictionIntervalS <= 0 {
+		evictionIntervalS = 10 * 60
+	}
+
+	policy := bg.Policy(eviction.Policy)
+	if policy == "" {
+		policy = bg.LRU
+	}
+
 	// start the background worker polling the registry
 	p.bgWorker.Start(bg.Options{
-		Frequency: reloadFreqS,
+		Frequency:         reloadFreqS,
+		Parallelism:       p.config.Downloads.Parallelism,
+		DomainParallelism: p.config.Downloads.DomainParallelism,
+		Backoff: problems.Backoff{
+			Initial: time.Duration(retryInitialS) * time.Second,
+			Max:     time.Duration(retryMaxS) * time.Second,
+		},
+		Prune: bg.PruneOptions{
+			Enabled:     prune.Enabled,
+			DryRun:      prune.DryRun,
+			GracePeriod: time.Duration(prune.GracePeriodS) * time.Second,
+		},
+		Integrity: bg.IntegrityOptions{
+			Enabled:   integrity.Enabled,
+			Frequency: time.Duration(integrityIntervalS) * time.Second,
+		},
+		Evict: bg.EvictOptions{
+			Enabled:   eviction.Enabled,
+			Quota:     eviction.QuotaMB * 1024 * 1024,
+			Policy:    policy,
+			CacheOnly: eviction.CacheOnly,
+			Frequency: time.Duration(evictionIntervalS) * time.Second,
+		},
 	})
 
 	return nil
@@ -210,9 +618,9 @@ func initTransforms(p *Proxy) []upstream.Transform {
 	transforms := make([]upstream.Transform, 0, 1)
 	transforms = append(transforms, initGoGetTransform(p))
 	transforms = append(transforms, initStaticRedirectTransforms(p)...)
+	transforms = append(transforms, initTransportTransforms(p)...)
 	transforms = append(transforms, initSetPathTransform(p))
 	transforms = append(transforms, initHeaderTransforms(p)...)
-	transforms = append(transforms, initTransportTransforms(p)...)
 	return transforms
 }
 
@@ -220,7 +628,15 @@ func initGoGetTransform(p *Proxy) upstream.Transform {
 	// Previously hidden behind p.config.Transforms.AutomaticRedirect, however
 	// automatically following the go-get=1 redirect is the only correct implementation,
 	// so that value is now ignored and the redirect is always followed.
-	return upstream.NewAutomaticGoGetTransform()
+	ttl := upstream.DefaultGoGetTTL
+	if cacheS := p.config.Transforms.GoGetCacheS; cacheS != 0 {
+		ttl = time.Duration(cacheS) * time.Second
+	}
+	failureTTL := upstream.DefaultGoGetFailureTTL
+	if cacheS := p.config.Transforms.GoGetFailureCacheS; cacheS != 0 {
+		failureTTL = time.Duration(cacheS) * time.Second
+	}
+	return upstream.NewGoGetTransform(ttl, failureTTL)
 }
 
 func initStaticRedirectTransforms(p *Proxy) []upstream.Transform {
@@ -317,8 +733,15 @@ func initWebServer(p *Proxy) error {
 		middles,
 		p.index,
 		p.store,
+		p.counter,
+		p.ingester,
+		p.downloader,
+		p.fetcher,
 		p.emitter,
 		p.dlTracker,
+		p.integrity,
+		p.bgWorker,
+		p.sumLog,
 		p.history,
 	)
 
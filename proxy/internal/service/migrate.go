@@ -0,0 +1,95 @@
+package service
+
+import (
+	"path/filepath"
+
+	"github.com/pkg/errors"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/proxy/config"
+	"oss.indeed.com/go/modprox/proxy/internal/migrate"
+)
+
+// Migrate copies every module stored by a proxy with the from Configuration
+// into the storage of the to Configuration. Only the index and zip store of
+// each are set up, the zip cache is bypassed so that only the storage which
+// is the source of truth is read and written. The destination index must
+// not be the same as the source index, otherwise every module would already
+// be in the destination, and none of the zips would be copied.
+func Migrate(from, to config.Configuration) error {
+	if sharedIndex(from, to) {
+		return errors.New("source and destination share the same index, the destination index must be a different one")
+	}
+
+	source, err := storageOf(from)
+	if err != nil {
+		return errors.Wrap(err, "failed to open source storage")
+	}
+
+	destination, err := storageOf(to)
+	if err != nil {
+		return errors.Wrap(err, "failed to open destination storage")
+	}
+
+	log := loggy.New("migrate")
+	emitter := destination.emitter
+
+	progress, err := migrate.New(
+		migrate.Storage{Index: source.index, Store: source.store},
+		migrate.Storage{Index: destination.index, Store: destination.store},
+		func(progress migrate.Progress) {
+			done := progress.Copied + progress.Skipped + progress.Failed
+			log.Infof("[%d/%d] copied %d, skipped %d, failed %d",
+				done, progress.Total, progress.Copied, progress.Skipped, progress.Failed)
+			emitter.Gauge("migrate-mods-done", done)
+			emitter.Gauge("migrate-mods-failed", progress.Failed)
+		},
+	).Run()
+
+	log.Infof("migrated %d modules, %d were already migrated, %d failed",
+		progress.Copied, progress.Skipped, progress.Failed)
+	return err
+}
+
+func storageOf(configuration config.Configuration) (*Proxy, error) {
+	p := &Proxy{
+		config: configuration,
+		log:    loggy.New("proxy-service"),
+	}
+
+	for _, f := range []initer{
+		initSender,
+		initIndex,
+		initStore,
+	} {
+		if err := f(p); err != nil {
+			return nil, err
+		}
+	}
+
+	return p, nil
+}
+
+// sharedIndex returns whether both configurations use the same index, in
+// which case the index would be opened twice (which a boltdb index does not
+// allow), and would already contain every module to be migrated.
+func sharedIndex(from, to config.Configuration) bool {
+	switch {
+	case from.ModuleStorage != nil && to.ModuleStorage != nil:
+		return samePath(from.ModuleStorage.IndexPath, to.ModuleStorage.IndexPath)
+	case from.ModuleDBStorage != nil && to.ModuleDBStorage != nil:
+		a, b := from.ModuleDBStorage.MySQL, to.ModuleDBStorage.MySQL
+		return a.Address == b.Address && a.Database == b.Database
+	}
+	return false
+}
+
+func samePath(a, b string) bool {
+	absA, errA := filepath.Abs(a)
+	absB, errB := filepath.Abs(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return absA == absB
+}
@@ -5,15 +5,19 @@ import (
 
 	"gophers.dev/pkgs/loggy"
 
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
 	"oss.indeed.com/go/modprox/pkg/clients/registry"
 	"oss.indeed.com/go/modprox/pkg/clients/zips"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/upstream"
 	"oss.indeed.com/go/modprox/pkg/webutil"
 	"oss.indeed.com/go/modprox/proxy/config"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/bg"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/get"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 	"oss.indeed.com/go/modprox/proxy/internal/problems"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
 )
 
 type Proxy struct {
@@ -22,12 +26,21 @@ type Proxy struct {
 	emitter        stats.Sender
 	index          store.Index
 	store          store.ZipStore
+	accesses       store.AccessLog
+	counter        store.AccessCounter
+	ingester       store.Ingester
 	registryClient registry.Client
 	proxyClient    zips.ProxyClient
 	upstreamClient zips.UpstreamClient
+	commitClient   zips.CommitClient
+	revisions      upstream.Revisioner
+	verifier       checksum.Verifier
+	sumLog         *sumdb.Log
 	downloader     get.Downloader
+	fetcher        fetch.Fetcher
 	bgWorker       bg.Worker
 	dlTracker      problems.Tracker
+	integrity      problems.Tracker
 	log            loggy.Logger
 	history        string
 }
@@ -40,11 +53,19 @@ func NewProxy(configuration config.Configuration) *Proxy {
 
 	for _, f := range []initer{
 		initSender,
-		initTrackers,
 		initIndex,
+		initTrackers,
 		initStore,
+		initZipCache,
+		initAccessLog,
+		initIngester,
 		initRegistryClient,
+		initFsck,
 		initZipClients,
+		initSumDB,
+		initVerifier,
+		initDownloader,
+		initFetcher,
 		initBGWorker,
 		initHeartbeatSender,
 		initStartupConfigSender,
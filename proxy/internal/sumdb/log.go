@@ -0,0 +1,309 @@
+package sumdb
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/module"
+	xsumdb "golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// A Store is used to persist the records and hashes of the transparency log.
+//
+// Records are numbered from 0, and each record is the go.sum lines of one
+// module. The hashes are the stored hashes of the tree, as described by
+// the golang.org/x/mod/sumdb/tlog package.
+type Store interface {
+	tlog.HashReader
+
+	// AppendRecord stores the record with the given id (which must be the
+	// number of records already stored), along with the hashes which must be
+	// stored for the record, starting at tlog.StoredHashIndex(0, id). The
+	// record and hashes must be stored atomically. If id is no longer the
+	// number of records, e.g. because another instance of the proxy sharing
+	// the Store appended a record first, a Conflict is returned.
+	AppendRecord(id int64, mod coordinates.Module, data []byte, hashes []tlog.Hash) error
+
+	// LookupRecord returns the id of the record of mod, if there is one.
+	LookupRecord(mod coordinates.Module) (int64, bool, error)
+
+	// ReadRecords returns the data of the n records starting at id.
+	ReadRecords(id, n int64) ([][]byte, error)
+
+	// CountRecords returns the number of records stored.
+	CountRecords() (int64, error)
+}
+
+// A Conflict is the error returned by a Store when a record is appended with
+// an id other than the number of records already stored.
+type Conflict struct {
+	ID      int64 // of the record being appended
+	Records int64 // already stored, or -1 if not known
+}
+
+func (c *Conflict) Error() string {
+	if c.Records < 0 {
+		return fmt.Sprintf("cannot append record %d to log, another record was appended first", c.ID)
+	}
+	return fmt.Sprintf("cannot append record %d to log of %d records", c.ID, c.Records)
+}
+
+// IsConflict returns whether the cause of err is a Conflict.
+func IsConflict(err error) bool {
+	_, is := errors.Cause(err).(*Conflict)
+	return is
+}
+
+// appendAttempts is how many times a record is appended before giving up,
+// when other instances of the proxy sharing the Store keep appending first.
+const appendAttempts = 5
+
+// A Log is a signed, append-only transparency log of the hashes of every
+// module stored by the proxy, which is served using the same protocol as
+// the Go checksum database, so the go command can use the proxy as its GOSUMDB.
+//
+// The first hashes seen for a module are recorded, and from then on the Log
+// refuses any content for that module which does not match those hashes.
+//
+// Every instance of the proxy serving the Log under the same name must share
+// one Store, otherwise each would sign a different tree, which the go command
+// reports as a security error.
+type Log struct {
+	signer  note.Signer
+	store   Store
+	emitter stats.Sender
+	log     loggy.Logger
+
+	// appending to the log must be done one record at a time, since each
+	// record depends on the hashes of every record appended before it
+	lock sync.Mutex
+}
+
+var (
+	_ checksum.Verifier = (*Log)(nil)
+	_ xsumdb.ServerOps  = (*Log)(nil)
+)
+
+// New creates a Log signed using the given private key, which is of the
+// form generated by note.GenerateKey, e.g. PRIVATE+KEY+sumdb.example.com+...
+func New(privateKey string, store Store, emitter stats.Sender) (*Log, error) {
+	signer, err := note.NewSigner(privateKey)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid checksum database private key")
+	}
+
+	return &Log{
+		signer:  signer,
+		store:   store,
+		emitter: emitter,
+		log:     loggy.New("sumdb-log"),
+	}, nil
+}
+
+// Name is the name of the checksum database, as it appears in its keys.
+func (l *Log) Name() string {
+	return l.signer.Name()
+}
+
+// Handler returns the http.Handler serving the lookup, latest, and tile
+// endpoints of the checksum database protocol.
+func (l *Log) Handler() http.Handler {
+	return xsumdb.NewServer(l)
+}
+
+// Verify implements checksum.Verifier by recording the hashes of modules
+// not yet in the log, and comparing the hashes of modules that are.
+func (l *Log) Verify(mod coordinates.Module, zipHash, modHash string) error {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		id, exists, err := l.store.LookupRecord(mod)
+		if err != nil {
+			return errors.Wrapf(err, "failed to lookup %s in checksum database", mod)
+		}
+
+		if exists {
+			return l.compare(mod, id, zipHash, modHash)
+		}
+
+		// another instance sharing the store appended a record first, which
+		// may even be the record of mod, so look it up again
+		err = l.append(mod, zipHash, modHash)
+		if !IsConflict(err) || attempt == appendAttempts {
+			return err
+		}
+		l.log.Warnf("appending %s to checksum database conflicted, will try again: %v", mod, err)
+		l.emitter.Count("sumdb-append-conflict", 1)
+	}
+}
+
+// An Index is the modules stored by the proxy, along with their hashes.
+type Index interface {
+	List() ([]coordinates.SerialModule, error)
+	Hashes(coordinates.Module) (repository.Hashes, error)
+}
+
+// Backfill appends a record for each module of index which is not yet in the
+// log, e.g. modules stored before the log was enabled, or brought in by an
+// import or a migration, which were never passed through Verify. Modules
+// without recorded hashes are left for the integrity checker, which records
+// their hashes through Verify. Returns the number of records appended.
+func (l *Log) Backfill(index Index) (int, error) {
+	mods, err := index.List()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list modules to backfill checksum database")
+	}
+
+	appended := 0
+	for _, mod := range mods {
+		_, exists, err := l.store.LookupRecord(mod.Module)
+		if err != nil {
+			return appended, errors.Wrapf(err, "failed to lookup %s in checksum database", mod.Module)
+		}
+		if exists {
+			continue
+		}
+
+		hashes, err := index.Hashes(mod.Module)
+		if err != nil {
+			l.log.Warnf("failed to get hashes of %s to backfill checksum database: %v", mod.Module, err)
+			continue
+		}
+		if hashes.Zip == "" || hashes.Mod == "" {
+			continue
+		}
+
+		// appended through Verify, in case the module was recorded meanwhile
+		if err := l.Verify(mod.Module, hashes.Zip, hashes.Mod); err != nil {
+			l.log.Errorf("failed to backfill %s into checksum database: %v", mod.Module, err)
+			l.emitter.Count("sumdb-backfill-failure", 1)
+			continue
+		}
+		appended++
+	}
+
+	l.log.Infof("backfilled %d of %d modules into checksum database", appended, len(mods))
+	return appended, nil
+}
+
+func (l *Log) compare(mod coordinates.Module, id int64, zipHash, modHash string) error {
+	records, err := l.store.ReadRecords(id, 1)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read record of %s from checksum database", mod)
+	}
+
+	expected := map[string]string{}
+	for _, line := range strings.Split(string(records[0]), "\n") {
+		// each line is of the form "<source> <version>[/go.mod] <hash>"
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		if strings.HasSuffix(fields[1], "/go.mod") {
+			expected["go.mod"] = fields[2]
+		} else {
+			expected["zip"] = fields[2]
+		}
+	}
+
+	for file, actual := range map[string]string{"zip": zipHash, "go.mod": modHash} {
+		if expected[file] != actual {
+			return &checksum.Mismatch{
+				Module:   mod,
+				File:     file,
+				Expected: expected[file],
+				Actual:   actual,
+			}
+		}
+	}
+
+	return nil
+}
+
+func (l *Log) append(mod coordinates.Module, zipHash, modHash string) error {
+	id, err := l.store.CountRecords()
+	if err != nil {
+		return errors.Wrap(err, "failed to count records of checksum database")
+	}
+
+	data := record(mod, zipHash, modHash)
+	hashes, err := tlog.StoredHashes(id, data, l.store)
+	if err != nil {
+		return errors.Wrapf(err, "failed to compute hashes for record of %s", mod)
+	}
+
+	if err := l.store.AppendRecord(id, mod, data, hashes); err != nil {
+		l.emitter.Count("sumdb-append-failure", 1)
+		return errors.Wrapf(err, "failed to append record of %s to checksum database", mod)
+	}
+
+	l.log.Infof("appended %s to checksum database as record %d", mod, id)
+	l.emitter.Count("sumdb-append-ok", 1)
+	return nil
+}
+
+// record creates the content of the record of a module, which is the
+// same as the lines of a go.sum file for that module.
+func record(mod coordinates.Module, zipHash, modHash string) []byte {
+	hashes := repository.Hashes{Zip: zipHash, Mod: modHash}
+	return []byte(hashes.GoSum(mod))
+}
+
+// Signed implements sumdb.ServerOps.Signed
+func (l *Log) Signed(context.Context) ([]byte, error) {
+	n, err := l.store.CountRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	hash, err := tlog.TreeHash(n, l.store)
+	if err != nil {
+		return nil, err
+	}
+
+	text := tlog.FormatTree(tlog.Tree{N: n, Hash: hash})
+	return note.Sign(&note.Note{Text: string(text)}, l.signer)
+}
+
+// ReadRecords implements sumdb.ServerOps.ReadRecords
+func (l *Log) ReadRecords(_ context.Context, id, n int64) ([][]byte, error) {
+	return l.store.ReadRecords(id, n)
+}
+
+// Lookup implements sumdb.ServerOps.Lookup
+func (l *Log) Lookup(_ context.Context, m module.Version) (int64, error) {
+	id, exists, err := l.store.LookupRecord(coordinates.Module{
+		Source:  m.Path,
+		Version: m.Version,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if !exists {
+		// the sumdb.Server reports a not-exist error as 404
+		return 0, &os.PathError{Op: "lookup", Path: m.String(), Err: os.ErrNotExist}
+	}
+
+	return id, nil
+}
+
+// ReadTileData implements sumdb.ServerOps.ReadTileData
+func (l *Log) ReadTileData(_ context.Context, t tlog.Tile) ([]byte, error) {
+	return tlog.ReadTileData(t, l.store)
+}
@@ -0,0 +1,273 @@
+package sumdb
+
+import (
+	"context"
+	"crypto/rand"
+	"net/http/httptest"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/mod/sumdb/note"
+	"golang.org/x/mod/sumdb/tlog"
+
+	"oss.indeed.com/go/modprox/pkg/clients/checksum"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// memStore is an in-memory Store
+type memStore struct {
+	lock    sync.Mutex
+	records [][]byte
+	lookup  map[coordinates.Module]int64
+	hashes  []tlog.Hash
+}
+
+func newMemStore() *memStore {
+	return &memStore{lookup: make(map[coordinates.Module]int64)}
+}
+
+func (s *memStore) AppendRecord(id int64, mod coordinates.Module, data []byte, hashes []tlog.Hash) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if id != int64(len(s.records)) {
+		return &Conflict{ID: id, Records: int64(len(s.records))}
+	}
+	s.records = append(s.records, data)
+	s.lookup[mod] = id
+	s.hashes = append(s.hashes, hashes...)
+	return nil
+}
+
+func (s *memStore) LookupRecord(mod coordinates.Module) (int64, bool, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	id, exists := s.lookup[mod]
+	return id, exists, nil
+}
+
+func (s *memStore) ReadRecords(id, n int64) ([][]byte, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if id+n > int64(len(s.records)) {
+		return nil, os.ErrNotExist
+	}
+	return s.records[id : id+n], nil
+}
+
+func (s *memStore) CountRecords() (int64, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return int64(len(s.records)), nil
+}
+
+func (s *memStore) ReadHashes(indexes []int64) ([]tlog.Hash, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	hashes := make([]tlog.Hash, 0, len(indexes))
+	for _, index := range indexes {
+		if index >= int64(len(s.hashes)) {
+			return nil, os.ErrNotExist
+		}
+		hashes = append(hashes, s.hashes[index])
+	}
+	return hashes, nil
+}
+
+const (
+	zipHash = "h1:DaLgb81tB9bWlUSbzgQDlxb08dI2NyLKbjnOeG7aC8M="
+	modHash = "h1:bwawxfHBFNV+L2hUp1rHADufV3IMtnDRdf1r5NINEl0="
+)
+
+func newLog(t *testing.T) (*Log, string) {
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sumdb.example.com")
+	require.NoError(t, err)
+
+	emitter := stats.NewSenderMock(t)
+	emitter.CountMock.Return()
+
+	log, err := New(skey, newMemStore(), emitter)
+	require.NoError(t, err)
+	return log, vkey
+}
+
+func Test_New_bad_key(t *testing.T) {
+	_, err := New("not a key", newMemStore(), stats.Discard())
+	require.Error(t, err)
+}
+
+func Test_Log_Verify(t *testing.T) {
+	log, _ := newLog(t)
+	require.Equal(t, "sumdb.example.com", log.Name())
+
+	mod := coordinates.Module{Source: "example.com/a", Version: "v1.0.0"}
+
+	// the first time a module is seen, its hashes are recorded
+	err := log.Verify(mod, zipHash, modHash)
+	require.NoError(t, err)
+
+	// and from then on, the same hashes are accepted
+	err = log.Verify(mod, zipHash, modHash)
+	require.NoError(t, err)
+
+	// but anything else is not
+	err = log.Verify(mod, "h1:tampered=", modHash)
+	require.True(t, checksum.IsMismatch(err))
+
+	err = log.Verify(mod, zipHash, "h1:tampered=")
+	require.True(t, checksum.IsMismatch(err))
+}
+
+// racingStore is a memStore shared with another instance of the proxy, which
+// appends a record of its own right after the first count of the records
+type racingStore struct {
+	*memStore
+	race func()
+}
+
+func (s *racingStore) CountRecords() (int64, error) {
+	n, err := s.memStore.CountRecords()
+	if race := s.race; race != nil {
+		s.race = nil
+		race()
+	}
+	return n, err
+}
+
+func Test_Log_Verify_conflict(t *testing.T) {
+	skey, _, err := note.GenerateKey(rand.Reader, "sumdb.example.com")
+	require.NoError(t, err)
+
+	emitter := stats.NewSenderMock(t)
+	emitter.CountMock.Return()
+
+	shared := newMemStore()
+	other, err := New(skey, shared, emitter)
+	require.NoError(t, err)
+
+	modA := coordinates.Module{Source: "example.com/a", Version: "v1.0.0"}
+	modB := coordinates.Module{Source: "example.com/b", Version: "v1.0.0"}
+
+	store := &racingStore{memStore: shared, race: func() {
+		require.NoError(t, other.Verify(modB, zipHash, modHash))
+	}}
+	log, err := New(skey, store, emitter)
+	require.NoError(t, err)
+
+	// the record of the other instance is appended first, and ours after it
+	err = log.Verify(modA, zipHash, modHash)
+	require.NoError(t, err)
+
+	idB, _, err := shared.LookupRecord(modB)
+	require.NoError(t, err)
+	require.Equal(t, int64(0), idB)
+
+	idA, _, err := shared.LookupRecord(modA)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), idA)
+
+	// and both instances sign the same tree
+	mine, err := log.Signed(context.Background())
+	require.NoError(t, err)
+	theirs, err := other.Signed(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, mine, theirs)
+}
+
+// memIndex is an in-memory Index
+type memIndex map[coordinates.Module]repository.Hashes
+
+func (i memIndex) List() ([]coordinates.SerialModule, error) {
+	mods := make([]coordinates.SerialModule, 0, len(i))
+	for mod := range i {
+		mods = append(mods, coordinates.SerialModule{Module: mod})
+	}
+	return mods, nil
+}
+
+func (i memIndex) Hashes(mod coordinates.Module) (repository.Hashes, error) {
+	return i[mod], nil
+}
+
+func Test_Log_Backfill(t *testing.T) {
+	log, _ := newLog(t)
+
+	recorded := coordinates.Module{Source: "example.com/a", Version: "v1.0.0"}
+	missing := coordinates.Module{Source: "example.com/a", Version: "v1.1.0"}
+	unhashed := coordinates.Module{Source: "example.com/b", Version: "v0.1.0"}
+
+	err := log.Verify(recorded, zipHash, modHash)
+	require.NoError(t, err)
+
+	index := memIndex{
+		recorded: {Zip: zipHash, Mod: modHash},
+		missing:  {Zip: zipHash, Mod: modHash},
+		unhashed: {},
+	}
+
+	// only the module with hashes but without a record is appended
+	appended, err := log.Backfill(index)
+	require.NoError(t, err)
+	require.Equal(t, 1, appended)
+
+	_, exists, err := log.store.LookupRecord(missing)
+	require.NoError(t, err)
+	require.True(t, exists)
+
+	_, exists, err = log.store.LookupRecord(unhashed)
+	require.NoError(t, err)
+	require.False(t, exists)
+
+	// and backfilling again appends nothing
+	appended, err = log.Backfill(index)
+	require.NoError(t, err)
+	require.Equal(t, 0, appended)
+}
+
+// the log must be usable as a GOSUMDB by the same client the go command uses
+func Test_Log_served(t *testing.T) {
+	log, vkey := newLog(t)
+
+	mods := []coordinates.Module{
+		{Source: "example.com/a", Version: "v1.0.0"},
+		{Source: "example.com/a", Version: "v1.1.0"},
+		{Source: "example.com/b", Version: "v0.1.0"},
+	}
+	for _, mod := range mods {
+		err := log.Verify(mod, zipHash, modHash)
+		require.NoError(t, err)
+	}
+
+	server := httptest.NewServer(log.Handler())
+	defer server.Close()
+
+	client, err := checksum.New(checksum.Options{
+		URL: server.URL,
+		Key: vkey,
+	})
+	require.NoError(t, err)
+
+	for _, mod := range mods {
+		err := client.Verify(mod, zipHash, modHash)
+		require.NoError(t, err)
+	}
+
+	err = client.Verify(mods[0], "h1:tampered=", modHash)
+	require.True(t, checksum.IsMismatch(err))
+
+	// a module that is not in the log cannot be verified
+	err = client.Verify(coordinates.Module{
+		Source:  "example.com/c",
+		Version: "v1.0.0",
+	}, zipHash, modHash)
+	require.Error(t, err)
+	require.False(t, checksum.IsMismatch(err))
+}
@@ -0,0 +1,58 @@
+package web
+
+import (
+	"net/http"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/web/output"
+)
+
+const goSumPath = "/v1/gosum/"
+
+type goSum struct {
+	index   store.Index
+	emitter stats.Sender
+	log     loggy.Logger
+}
+
+func newGoSum(index store.Index, emitter stats.Sender) http.Handler {
+	return &goSum{
+		index:   index,
+		emitter: emitter,
+		log:     loggy.New("go-sum"),
+	}
+}
+
+// e.g. GET http://localhost:9000/v1/gosum/github.com/example/toolkit@v1.0.0
+
+func (h *goSum) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mod, err := modFromGoSumPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.emitter.Count("api-gosum-bad-request", 1)
+		return
+	}
+
+	h.log.Infof("serving request for go.sum lines of: %s", mod)
+
+	hashes, err := h.index.Hashes(mod)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		h.emitter.Count("api-gosum-not-found", 1)
+		return
+	}
+
+	// modules stored before hashes were recorded will not have any until
+	// the integrity checker has gotten around to filling them in
+	if hashes.Zip == "" || hashes.Mod == "" {
+		http.Error(w, "no hashes recorded for module", http.StatusNotFound)
+		h.emitter.Count("api-gosum-not-found", 1)
+		return
+	}
+
+	output.Write(w, output.Text, hashes.GoSum(mod))
+	h.emitter.Count("api-gosum-ok", 1)
+}
@@ -1,11 +1,17 @@
 package web
 
 import (
+	"encoding/json"
+	"fmt"
 	"net/http"
 
+	"github.com/pkg/errors"
+
 	"gophers.dev/pkgs/loggy"
 
+	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/bg"
 	"oss.indeed.com/go/modprox/proxy/internal/problems"
 	"oss.indeed.com/go/modprox/proxy/internal/web/output"
 )
@@ -32,3 +38,144 @@ func (h *downloadProblems) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	output.WriteJSON(w, dlProblems)
 }
+
+type retryDownload struct {
+	bgWorker bg.Worker
+	emitter  stats.Sender
+	log      loggy.Logger
+}
+
+func newRetryDownload(bgWorker bg.Worker, emitter stats.Sender) http.Handler {
+	return &retryDownload{
+		bgWorker: bgWorker,
+		emitter:  emitter,
+		log:      loggy.New("retry-download"),
+	}
+}
+
+// e.g. POST http://localhost:9000/v1/problems/downloads/retry
+// with body {"source": "github.com/example/toolkit", "version": "v1.0.0"}
+
+func (h *retryDownload) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var mod coordinates.Module
+	if err := json.NewDecoder(r.Body).Decode(&mod); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.emitter.Count("api-retry-download-bad-request", 1)
+		return
+	}
+
+	h.log.Infof("forcing retry of download of %s", mod)
+
+	if err := h.bgWorker.Retry(mod); err != nil {
+		code, metric := retryFailure(err)
+		h.log.Warnf("failed to retry download of %s, %v", mod, err)
+		http.Error(w, err.Error(), code)
+		h.emitter.Count(metric, 1)
+		return
+	}
+
+	// the download itself happens in the background, its outcome
+	// shows up in the download problems like any other attempt
+	msg := fmt.Sprintf("retrying download of module %s", mod)
+	output.WriteStatus(w, http.StatusAccepted, output.Text, msg)
+	h.emitter.Count("api-retry-download-ok", 1)
+}
+
+// retryFailure returns the status code and metric of a failed retry.
+func retryFailure(err error) (int, string) {
+	switch errors.Cause(err) {
+	case bg.ErrNoProblem:
+		return http.StatusNotFound, "api-retry-download-not-found"
+	case bg.ErrAlreadyIndexed:
+		return http.StatusConflict, "api-retry-download-conflict"
+	default:
+		return http.StatusInternalServerError, "api-retry-download-failure"
+	}
+}
+
+type resolvedProblems struct {
+	dlTracker problems.Tracker
+	emitter   stats.Sender
+	log       loggy.Logger
+}
+
+func newResolvedProblems(dlTracker problems.Tracker, emitter stats.Sender) http.Handler {
+	return &resolvedProblems{
+		dlTracker: dlTracker,
+		emitter:   emitter,
+		log:       loggy.New("resolved-problems"),
+	}
+}
+
+// e.g. GET http://localhost:9000/v1/problems/downloads/resolved
+
+func (h *resolvedProblems) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.emitter.Count("api-resolved-problems", 1)
+
+	resolved := h.dlTracker.Resolved()
+	h.log.Tracef("reporting %d resolved problems", len(resolved))
+
+	output.WriteJSON(w, resolved)
+}
+
+type ackProblem struct {
+	dlTracker problems.Tracker
+	emitter   stats.Sender
+	log       loggy.Logger
+}
+
+func newAckProblem(dlTracker problems.Tracker, emitter stats.Sender) http.Handler {
+	return &ackProblem{
+		dlTracker: dlTracker,
+		emitter:   emitter,
+		log:       loggy.New("ack-problem"),
+	}
+}
+
+// e.g. POST http://localhost:9000/v1/problems/downloads/ack
+// with body {"source": "github.com/example/toolkit", "version": "v1.0.0"}
+
+func (h *ackProblem) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var mod coordinates.Module
+	if err := json.NewDecoder(r.Body).Decode(&mod); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.emitter.Count("api-ack-problem-bad-request", 1)
+		return
+	}
+
+	if !h.dlTracker.Resolve(mod, problems.Acknowledged) {
+		msg := fmt.Sprintf("no download problem for %s", mod)
+		http.Error(w, msg, http.StatusNotFound)
+		h.emitter.Count("api-ack-problem-not-found", 1)
+		return
+	}
+
+	msg := fmt.Sprintf("problem for module %s acknowledged", mod)
+	output.Write(w, output.Text, msg)
+	h.emitter.Count("api-ack-problem-ok", 1)
+}
+
+type integrityProblems struct {
+	integrity problems.Tracker
+	emitter   stats.Sender
+	log       loggy.Logger
+}
+
+func newIntegrityProblems(integrity problems.Tracker, emitter stats.Sender) http.Handler {
+	return &integrityProblems{
+		integrity: integrity,
+		emitter:   emitter,
+		log:       loggy.New("integrity-problems"),
+	}
+}
+
+// e.g. GET http://localhost:9000/v1/problems/integrity
+
+func (h *integrityProblems) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.emitter.Count("api-integrity-problems", 1)
+
+	mismatched := h.integrity.Problems()
+	h.log.Tracef("reporting %d integrity problems", len(mismatched))
+
+	output.WriteJSON(w, mismatched)
+}
@@ -0,0 +1,23 @@
+package web
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/proxy/internal/modules/bg"
+)
+
+func Test_retryFailure(t *testing.T) {
+	try := func(err error, expCode int, expMetric string) {
+		code, metric := retryFailure(err)
+		require.Equal(t, expCode, code)
+		require.Equal(t, expMetric, metric)
+	}
+
+	try(errors.Wrap(bg.ErrNoProblem, "github.com/foo/bar@v1.0.0"), http.StatusNotFound, "api-retry-download-not-found")
+	try(errors.Wrap(bg.ErrAlreadyIndexed, "github.com/foo/bar@v1.0.0"), http.StatusConflict, "api-retry-download-conflict")
+	try(errors.New("boom"), http.StatusInternalServerError, "api-retry-download-failure")
+}
@@ -1,10 +1,15 @@
 package web
 
 import (
+	"net/http"
 	"strings"
 
+	"github.com/pkg/errors"
+
 	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 )
 
 // GET baseURL/module/@v/list fetches a list of all known versions, one per line.
@@ -20,6 +25,27 @@ func modInfoFromPath(p string) (coordinates.Module, error) {
 	return repository.Parse(p)
 }
 
+// GET baseURL/module/@latest fetches the .info of the latest known version.
+
+func moduleFromLatestPath(p string) (string, error) {
+	p = demangle(p)
+	source := strings.Trim(strings.TrimSuffix(p, "/@latest"), "/")
+	if source == "" || strings.Contains(source, "@") {
+		return "", errors.Errorf("malformed latest request: %q", p)
+	}
+	return source, nil
+}
+
+// GET baseURL/v1/gosum/module@version fetches the go.sum lines of a module.
+
+func modFromGoSumPath(p string) (coordinates.Module, error) {
+	p = demangle(strings.TrimPrefix(p, goSumPath))
+	if !strings.Contains(p, "@") {
+		return coordinates.Module{}, errors.Errorf("malformed go.sum request: %q", p)
+	}
+	return repository.Parse(p)
+}
+
 // from the Go documentation: https://tip.golang.org/cmd/go/#hdr-Module_proxy_protocol
 //
 // To avoid problems when serving from case-sensitive file systems, the <module> and <version>
@@ -53,3 +79,22 @@ func demangle(s string) string {
 
 	return correct.String()
 }
+
+// fetchMissing fetches mod on-demand if it is not in index, returning whether
+// it was fetched. Only a module which is not in the index is fetched; failing
+// to read the index is not a reason to download the module all over again.
+// The returned status is that to respond with when err is not nil.
+func fetchMissing(index store.Index, fetcher fetch.Fetcher, mod coordinates.Module) (bool, int, error) {
+	exists, _, err := index.Contains(mod)
+	if err != nil {
+		return false, http.StatusInternalServerError, err
+	}
+	if exists {
+		return false, http.StatusOK, nil
+	}
+
+	if err := fetcher.Fetch(mod); err != nil {
+		return false, http.StatusNotFound, err
+	}
+	return true, http.StatusOK, nil
+}
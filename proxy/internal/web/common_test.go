@@ -1,9 +1,15 @@
 package web
 
 import (
+	"net/http"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 )
 
 func Test_demangle(t *testing.T) {
@@ -18,3 +24,69 @@ func Test_demangle(t *testing.T) {
 	try("foo/bar!", "foo/bar!")
 	try("foo!A/bar!B", "foo!A/bar!B")
 }
+
+func Test_moduleFromLatestPath(t *testing.T) {
+	try := func(input, exp string, expErr bool) {
+		output, err := moduleFromLatestPath(input)
+		require.True(t, expErr == (err != nil), "err was: %v", err)
+		require.Equal(t, exp, output)
+	}
+
+	try("/github.com/foo/bar/@latest", "github.com/foo/bar", false)
+	try("/github.com/!burnt!sushi/toml/@latest", "github.com/BurntSushi/toml", false)
+	try("/@latest", "", true)
+	try("/github.com/foo/bar/@v/@latest", "", true)
+}
+
+func Test_modFromGoSumPath(t *testing.T) {
+	try := func(input string, exp coordinates.Module, expErr bool) {
+		output, err := modFromGoSumPath(input)
+		require.True(t, expErr == (err != nil), "err was: %v", err)
+		require.Equal(t, exp, output)
+	}
+
+	try("/v1/gosum/github.com/pkg/errors@v0.8.1", coordinates.Module{
+		Source:  "github.com/pkg/errors",
+		Version: "v0.8.1",
+	}, false)
+	try("/v1/gosum/github.com/!burnt!sushi/toml@v0.3.1", coordinates.Module{
+		Source:  "github.com/BurntSushi/toml",
+		Version: "v0.3.1",
+	}, false)
+	try("/v1/gosum/github.com/pkg/errors", coordinates.Module{}, true)
+	try("/v1/gosum/", coordinates.Module{}, true)
+}
+
+func Test_fetchMissing(t *testing.T) {
+	index := store.NewIndexMock(t)
+	defer index.MinimockFinish()
+
+	fetcher := fetch.NewFetcherMock(t)
+	defer fetcher.MinimockFinish()
+
+	stored := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.8.1"}
+	missing := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.9.0"}
+	unknown := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.9.1"}
+	broken := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.9.2"}
+
+	index.ContainsMock.When(stored).Then(true, 1, nil)
+	index.ContainsMock.When(missing).Then(false, 0, nil)
+	index.ContainsMock.When(unknown).Then(false, 0, nil)
+	index.ContainsMock.When(broken).Then(false, 0, errors.New("index is broken"))
+
+	// only modules which are not in the index are fetched
+	fetcher.FetchMock.When(missing).Then(nil)
+	fetcher.FetchMock.When(unknown).Then(errors.New("no such version"))
+
+	try := func(mod coordinates.Module, expFetched bool, expStatus int, expErr bool) {
+		fetched, status, err := fetchMissing(index, fetcher, mod)
+		require.Equal(t, expFetched, fetched)
+		require.Equal(t, expStatus, status)
+		require.Equal(t, expErr, err != nil)
+	}
+
+	try(stored, false, http.StatusOK, false)
+	try(missing, true, http.StatusOK, false)
+	try(unknown, false, http.StatusNotFound, true)
+	try(broken, false, http.StatusInternalServerError, true)
+}
@@ -6,19 +6,23 @@ import (
 	"gophers.dev/pkgs/loggy"
 
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 	"oss.indeed.com/go/modprox/proxy/internal/web/output"
 )
 
 type moduleFile struct {
 	index   store.Index
+	fetcher fetch.Fetcher
 	emitter stats.Sender
 	log     loggy.Logger
 }
 
-func modFile(index store.Index, emitter stats.Sender) http.Handler {
+func modFile(index store.Index, fetcher fetch.Fetcher, emitter stats.Sender) http.Handler {
 	return &moduleFile{
 		index:   index,
+		fetcher: fetcher,
 		emitter: emitter,
 		log:     loggy.New("mod-file"),
 	}
@@ -33,12 +37,29 @@ func (h *moduleFile) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	}
 	h.log.Infof("serving request for go.mod file of %s", mod)
 
-	modFile, err := h.index.Mod(mod)
+	// only the .info of a branch, tag or commit hash is ever served
+	if repository.IsQuery(mod.Version) {
+		http.Error(w, "not a canonical version: "+mod.Version, http.StatusNotFound)
+		h.emitter.Count("mod-file-not-found", 1)
+		return
+	}
+
+	fetched, status, err := fetchMissing(h.index, h.fetcher, mod)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), status)
 		h.emitter.Count("mod-file-not-found", 1)
 		return
 	}
+	if fetched {
+		h.emitter.Count("mod-file-fetched", 1)
+	}
+
+	modFile, err := h.index.Mod(mod)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.emitter.Count("mod-file-failure", 1)
+		return
+	}
 
 	output.Write(w, output.Text, modFile)
 	h.emitter.Count("mod-file-ok", 1)
@@ -5,22 +5,35 @@ import (
 
 	"gophers.dev/pkgs/loggy"
 
+	"oss.indeed.com/go/modprox/pkg/coordinates"
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 	"oss.indeed.com/go/modprox/proxy/internal/web/output"
 )
 
+// A Resolver resolves a branch, tag or abbreviated commit hash into the
+// canonical version it refers to, e.g. the get.Downloader.
+type Resolver interface {
+	Resolve(mod coordinates.Module) (coordinates.Module, error)
+}
+
 type moduleInfo struct {
-	log     loggy.Logger
-	emitter stats.Sender
-	index   store.Index
+	log      loggy.Logger
+	emitter  stats.Sender
+	index    store.Index
+	resolver Resolver
+	fetcher  fetch.Fetcher
 }
 
-func modInfo(index store.Index, emitter stats.Sender) http.Handler {
+func modInfo(index store.Index, resolver Resolver, fetcher fetch.Fetcher, emitter stats.Sender) http.Handler {
 	return &moduleInfo{
-		index:   index,
-		emitter: emitter,
-		log:     loggy.New("mod-info"),
+		index:    index,
+		resolver: resolver,
+		fetcher:  fetcher,
+		emitter:  emitter,
+		log:      loggy.New("mod-info"),
 	}
 }
 
@@ -37,12 +50,44 @@ func (h *moduleInfo) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Infof("serving request for .info of: %s", mod)
 
-	revInfo, err := h.index.Info(mod)
+	// e.g. go get github.com/example/toolkit@master asks for master.info,
+	// which is the .info of the version master is resolved into, whether or
+	// not the proxy fetches modules on-demand
+	query := repository.IsQuery(mod.Version)
+	if query {
+		resolved, err := h.resolver.Resolve(mod)
+		if err != nil {
+			h.log.Warnf("failed to resolve version of %s, %v", mod, err)
+			http.Error(w, err.Error(), http.StatusNotFound)
+			h.emitter.Count("mod-info-not-resolved", 1)
+			return
+		}
+		mod = resolved
+	}
+
+	fetched, status, err := fetchMissing(h.index, h.fetcher, mod)
+	if err != nil && query && status == http.StatusNotFound {
+		// the version is known all the same, even if it is not stored
+		h.log.Infof("serving resolved version of %s without storing it, %v", mod, err)
+		output.Write(w, output.JSON, repository.RevInfoOf(mod.Version).String())
+		h.emitter.Count("mod-info-resolved", 1)
+		return
+	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), status)
 		h.emitter.Count("mod-info-not-found", 1)
 		return
 	}
+	if fetched {
+		h.emitter.Count("mod-info-fetched", 1)
+	}
+
+	revInfo, err := h.index.Info(mod)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.emitter.Count("mod-info-failure", 1)
+		return
+	}
 
 	content := revInfo.String()
 	output.Write(w, output.JSON, content)
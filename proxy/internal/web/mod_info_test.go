@@ -0,0 +1,67 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+)
+
+// resolverFunc is a Resolver which calls itself
+type resolverFunc func(coordinates.Module) (coordinates.Module, error)
+
+func (f resolverFunc) Resolve(mod coordinates.Module) (coordinates.Module, error) {
+	return f(mod)
+}
+
+func Test_modInfo_query(t *testing.T) {
+	index := store.NewIndexMock(t)
+	defer index.MinimockFinish()
+
+	stored := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.9.1"}
+	pseudo := coordinates.Module{Source: "github.com/pkg/errors", Version: "v0.0.0-20180111040409-fbec762f837d"}
+
+	resolver := resolverFunc(func(mod coordinates.Module) (coordinates.Module, error) {
+		switch mod.Version {
+		case "v0.9":
+			return stored, nil
+		case "master":
+			return pseudo, nil
+		}
+		return mod, errors.New("no such branch")
+	})
+
+	index.ContainsMock.When(stored).Then(true, 1, nil)
+	index.ContainsMock.When(pseudo).Then(false, 0, nil)
+	info := repository.RevInfoOf(stored.Version).WithCommit("fbec762f837dfbec762f837d", time.Unix(1500000000, 0))
+	index.InfoMock.When(stored).Then(info, nil)
+
+	// resolving does not depend on fetching modules on-demand
+	handler := modInfo(index, resolver, fetch.Disabled(), stats.Discard())
+
+	try := func(path string, expStatus int, expBody string) {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		require.Equal(t, expStatus, w.Code)
+		if expBody != "" {
+			require.JSONEq(t, expBody, w.Body.String())
+		}
+	}
+
+	// a stored version is served from the index
+	try("/github.com/pkg/errors/@v/v0.9.info", http.StatusOK, info.String())
+
+	// and one which is not stored is known all the same
+	try("/github.com/pkg/errors/@v/master.info", http.StatusOK, repository.RevInfoOf(pseudo.Version).String())
+
+	try("/github.com/pkg/errors/@v/nope.info", http.StatusNotFound, "")
+}
@@ -0,0 +1,141 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"gophers.dev/pkgs/loggy"
+	"gophers.dev/pkgs/semantic"
+
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
+	"oss.indeed.com/go/modprox/proxy/internal/web/output"
+)
+
+type moduleLatest struct {
+	index   store.Index
+	emitter stats.Sender
+	log     loggy.Logger
+}
+
+func modLatest(index store.Index, emitter stats.Sender) http.Handler {
+	return &moduleLatest{
+		index:   index,
+		emitter: emitter,
+		log:     loggy.New("mod-latest"),
+	}
+}
+
+// e.g. GET http://localhost:9000/github.com/example/toolkit/@latest
+
+func (h *moduleLatest) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	source, err := moduleFromLatestPath(r.URL.Path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		h.emitter.Count("mod-latest-bad-request", 1)
+		return
+	}
+
+	h.log.Infof("serving request for @latest of: %s", source)
+
+	versions, err := h.index.Versions(source)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		h.emitter.Count("mod-latest-not-found", 1)
+		return
+	}
+
+	version, exists := latestVersion(versions)
+	if !exists {
+		http.Error(w, "no versions of module in index", http.StatusNotFound)
+		h.emitter.Count("mod-latest-not-found", 1)
+		return
+	}
+
+	revInfo, err := h.index.Info(coordinates.Module{
+		Source:  source,
+		Version: version,
+	})
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		h.emitter.Count("mod-latest-not-found", 1)
+		return
+	}
+
+	output.Write(w, output.JSON, revInfo.String())
+	h.emitter.Count("mod-latest-ok", 1)
+}
+
+type candidate struct {
+	version string
+	tag     semantic.Tag
+}
+
+// latestVersion picks the version the go command expects to be returned
+// from the @latest endpoint, which is the highest release version. If there
+// are no release versions, the highest pre-release version is used, and if
+// there are no pre-release versions then the most recent pseudo-version.
+func latestVersion(versions []string) (string, bool) {
+	var releases, prereleases, pseudos []candidate
+	for _, version := range versions {
+		tag, ok := semantic.Parse(strings.TrimSuffix(version, "+incompatible"))
+		if !ok {
+			continue
+		}
+
+		c := candidate{version: version, tag: tag}
+		switch {
+		case repository.IsPseudoVersion(version):
+			pseudos = append(pseudos, c)
+		case tag.Extension == "":
+			releases = append(releases, c)
+		default:
+			prereleases = append(prereleases, c)
+		}
+	}
+
+	if len(releases) > 0 {
+		return highest(releases), true
+	}
+
+	if len(prereleases) > 0 {
+		return highest(prereleases), true
+	}
+
+	if len(pseudos) > 0 {
+		return newest(pseudos), true
+	}
+
+	return "", false
+}
+
+func highest(candidates []candidate) string {
+	sort.SliceStable(candidates, func(x, y int) bool {
+		return candidates[x].tag.Less(candidates[y].tag)
+	})
+	return candidates[len(candidates)-1].version
+}
+
+// pseudo-versions are ordered by their commit timestamp, which is the
+// second to last dash-separated element of the version string
+func newest(candidates []candidate) string {
+	sort.SliceStable(candidates, func(x, y int) bool {
+		return timestampOf(candidates[x].version) < timestampOf(candidates[y].version)
+	})
+	return candidates[len(candidates)-1].version
+}
+
+func timestampOf(pseudo string) string {
+	split := strings.Split(pseudo, "-")
+	if len(split) < 3 {
+		return ""
+	}
+	stamp := split[len(split)-2]
+	if i := strings.LastIndex(stamp, "."); i >= 0 {
+		stamp = stamp[i+1:]
+	}
+	return stamp
+}
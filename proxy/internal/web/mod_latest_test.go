@@ -0,0 +1,48 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_latestVersion(t *testing.T) {
+	try := func(versions []string, exp string, expExists bool) {
+		result, exists := latestVersion(versions)
+		require.Equal(t, expExists, exists)
+		require.Equal(t, exp, result)
+	}
+
+	// no versions
+	try(nil, "", false)
+	try([]string{"not-a-version"}, "", false)
+
+	// highest release wins over everything else
+	try([]string{
+		"v1.0.0",
+		"v1.3.0",
+		"v1.2.0",
+		"v1.4.0-alpha1",
+		"v1.3.1-0.20180111040409-fbec762f837d",
+	}, "v1.3.0", true)
+
+	// incompatible releases are still releases
+	try([]string{
+		"v1.0.0",
+		"v2.3.3+incompatible",
+	}, "v2.3.3+incompatible", true)
+
+	// highest pre-release if there are no releases
+	try([]string{
+		"v1.1.0-alpha1",
+		"v1.2.0-alpha1",
+		"v0.0.0-20190111040409-fbec762f837d",
+	}, "v1.2.0-alpha1", true)
+
+	// most recent pseudo-version if there is nothing else
+	try([]string{
+		"v0.0.0-20190111040409-aaaaaaaaaaaa",
+		"v0.0.0-20190311040409-bbbbbbbbbbbb",
+		"v0.0.0-20180111040409-cccccccccccc",
+	}, "v0.0.0-20190311040409-bbbbbbbbbbbb", true)
+}
@@ -12,18 +12,16 @@ import (
 )
 
 type removeModule struct {
-	index   store.Index
-	store   store.ZipStore
-	emitter stats.Sender
-	log     loggy.Logger
+	ingester store.Ingester
+	emitter  stats.Sender
+	log      loggy.Logger
 }
 
-func modRM(index store.Index, store store.ZipStore, emitter stats.Sender) http.Handler {
+func modRM(ingester store.Ingester, emitter stats.Sender) http.Handler {
 	return &removeModule{
-		store:   store,
-		emitter: emitter,
-		index:   index,
-		log:     loggy.New("mod-rm"),
+		ingester: ingester,
+		emitter:  emitter,
+		log:      loggy.New("mod-rm"),
 	}
 }
 
@@ -39,15 +37,9 @@ func (h *removeModule) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Infof("serving request for removal of %s", mod)
 
-	if err := h.index.Remove(mod); err != nil {
-		h.log.Errorf("failed to remove module from index %s: %v", mod, err)
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
-	}
-
-	// and remove from the store itself
-	if err := h.store.DelZip(mod); err != nil {
-		h.log.Errorf("failed to remove module from store %s: %v", mod, err)
+	// from both the index and the store itself
+	if err := h.ingester.Remove(mod); err != nil {
+		h.log.Errorf("failed to remove module %s: %v", mod, err)
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
@@ -2,25 +2,41 @@ package web
 
 import (
 	"net/http"
+	"time"
 
+	"gophers.dev/pkgs/ignore"
 	"gophers.dev/pkgs/loggy"
 
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/pkg/repository"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 	"oss.indeed.com/go/modprox/proxy/internal/web/output"
 )
 
 type moduleZip struct {
-	store   store.ZipStore
-	emitter stats.Sender
-	log     loggy.Logger
+	index    store.Index
+	store    store.ZipStore
+	accesses store.AccessCounter
+	fetcher  fetch.Fetcher
+	emitter  stats.Sender
+	log      loggy.Logger
 }
 
-func modZip(store store.ZipStore, emitter stats.Sender) http.Handler {
+func modZip(
+	index store.Index,
+	store store.ZipStore,
+	accesses store.AccessCounter,
+	fetcher fetch.Fetcher,
+	emitter stats.Sender,
+) http.Handler {
 	return &moduleZip{
-		store:   store,
-		emitter: emitter,
-		log:     loggy.New("mod-zip"),
+		index:    index,
+		store:    store,
+		accesses: accesses,
+		fetcher:  fetcher,
+		emitter:  emitter,
+		log:      loggy.New("mod-zip"),
 	}
 }
 
@@ -36,15 +52,44 @@ func (h *moduleZip) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	h.log.Infof("serving request for .zip file of %s", mod)
 
-	zipBlob, err := h.store.GetZip(mod)
+	// only the .info of a branch, tag or commit hash is ever served
+	if repository.IsQuery(mod.Version) {
+		http.Error(w, "not a canonical version: "+mod.Version, http.StatusNotFound)
+		h.emitter.Count("mod-zip-not-found", 1)
+		return
+	}
+
+	fetched, status, err := fetchMissing(h.index, h.fetcher, mod)
 	if err != nil {
 		h.log.Warnf("failed to get zip file of %s, %v", mod, err)
-		http.Error(w, err.Error(), http.StatusNotFound)
+		http.Error(w, err.Error(), status)
 		h.emitter.Count("mod-zip-not-found", 1)
 		return
 	}
+	if fetched {
+		h.emitter.Count("mod-zip-fetched", 1)
+	}
+
+	zip, err := h.store.OpenZip(mod)
+	if err != nil {
+		h.log.Errorf("failed to open zip file of %s, %v", mod, err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		h.emitter.Count("mod-zip-failure", 1)
+		return
+	}
+	defer ignore.Close(zip)
+
+	// only counted in memory, the evictor writes the counts to the index
+	h.accesses.Count(mod, time.Now())
+
+	// the zip hash never changes for a version, which makes it a good etag;
+	// modules stored before hashes were recorded just go without one
+	var etag string
+	if hashes, err := h.index.Hashes(mod); err == nil {
+		etag = hashes.Zip
+	}
 
-	h.log.Infof("sending zip which is %d bytes", len(zipBlob))
-	output.WriteZip(w, zipBlob)
+	h.log.Infof("sending zip which is %d bytes", zip.Size())
+	output.ServeZip(w, r, etag, zip.ModTime(), zip)
 	h.emitter.Count("mod-zip-ok", 1)
 }
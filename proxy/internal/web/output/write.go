@@ -2,17 +2,17 @@ package output
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
 	"strconv"
-
-	"oss.indeed.com/go/modprox/pkg/repository"
+	"time"
 )
 
 const (
 	headerContentType             = "Content-Type"
 	headerContentDescription      = "Content-Description"
 	headerContentTransferEncoding = "Content-Transfer-Encoding"
-	headerContentLength           = "Content-Length"
+	headerETag                    = "ETag"
 )
 
 const (
@@ -25,8 +25,13 @@ const (
 )
 
 func Write(w http.ResponseWriter, mime, content string) {
+	WriteStatus(w, http.StatusOK, mime, content)
+}
+
+// WriteStatus is Write with a status other than 200 OK, e.g. 202 Accepted.
+func WriteStatus(w http.ResponseWriter, status int, mime, content string) {
 	w.Header().Set(headerContentType, mime)
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(status)
 	_, _ = w.Write([]byte(content))
 }
 
@@ -37,13 +42,17 @@ func WriteJSON(w http.ResponseWriter, i interface{}) {
 	}
 }
 
-func WriteZip(w http.ResponseWriter, blob repository.Blob) {
+// ServeZip sends content as a zip file. Range and conditional requests are
+// handled using modTime and etag, either of which may be left empty.
+func ServeZip(w http.ResponseWriter, r *http.Request, etag string, modTime time.Time, content io.ReadSeeker) {
 	w.Header().Set(headerContentType, Zip)
 	w.Header().Add(headerContentType, OctetStream)
 	w.Header().Set(headerContentDescription, FileTransfer)
 	w.Header().Set(headerContentTransferEncoding, Binary)
-	w.Header().Set(headerContentLength, strconv.Itoa(len(blob)))
+	if etag != "" {
+		w.Header().Set(headerETag, strconv.Quote(etag))
+	}
 
-	w.WriteHeader(http.StatusOK)
-	_, _ = w.Write(blob)
+	// the name is only used to guess the content type, which is already set
+	http.ServeContent(w, r, "", modTime, content)
 }
@@ -10,8 +10,11 @@ import (
 
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
 	"oss.indeed.com/go/modprox/pkg/webutil"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/bg"
+	"oss.indeed.com/go/modprox/proxy/internal/modules/fetch"
 	"oss.indeed.com/go/modprox/proxy/internal/modules/store"
 	"oss.indeed.com/go/modprox/proxy/internal/problems"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
 )
 
 const (
@@ -23,30 +26,58 @@ func NewRouter(
 	middles []webutil.Middleware,
 	index store.Index,
 	store store.ZipStore,
+	accesses store.AccessCounter,
+	ingester store.Ingester,
+	resolver Resolver,
+	fetcher fetch.Fetcher,
 	emitter stats.Sender,
 	dlProblems problems.Tracker,
+	integrityProblems problems.Tracker,
+	bgWorker bg.Worker,
+	sumLog *sumdb.Log,
 	history string,
 ) http.Handler {
 
 	router := mux.NewRouter()
 
+	// checksum database operations, only if this proxy serves one
+	//
+	// e.g. GET http://localhost:9000/sumdb/sumdb.example.com/supported
+	// e.g. GET http://localhost:9000/sumdb/sumdb.example.com/lookup/github.com/example/toolkit@v1.0.0
+	if sumLog != nil {
+		router.PathPrefix("/sumdb/" + sumLog.Name() + "/").Handler(newSumDB(sumLog, emitter)).Methods(get)
+	}
+
 	// mod operations
 	//
 	// e.g. GET  http://localhost:9000/github.com/example/toolkit/@v/v1.0.0.info
 	// e.g. GET  http://localhost:9000/github.com/example/toolkit/@v.list
+	// e.g. GET  http://localhost:9000/github.com/example/toolkit/@latest
 	// e.g. POST http://localhost:9000/github.com/example/toolkit/@v/v1.0.0.rm
 	router.PathPrefix("/").Handler(modList(index, emitter)).MatcherFunc(suffix("list")).Methods(get)
-	router.PathPrefix("/").Handler(modInfo(index, emitter)).MatcherFunc(suffix(".info")).Methods(get)
-	router.PathPrefix("/").Handler(modFile(index, emitter)).MatcherFunc(suffix(".mod")).Methods(get)
-	router.PathPrefix("/").Handler(modZip(store, emitter)).MatcherFunc(suffix(".zip")).Methods(get)
-	router.PathPrefix("/").Handler(modRM(index, store, emitter)).MatcherFunc(suffix(".rm")).Methods(post)
+	router.PathPrefix("/").Handler(modLatest(index, emitter)).MatcherFunc(suffix("/@latest")).Methods(get)
+	router.PathPrefix("/").Handler(modInfo(index, resolver, fetcher, emitter)).MatcherFunc(suffix(".info")).Methods(get)
+	router.PathPrefix("/").Handler(modFile(index, fetcher, emitter)).MatcherFunc(suffix(".mod")).Methods(get)
+	router.PathPrefix("/").Handler(modZip(index, store, accesses, fetcher, emitter)).MatcherFunc(suffix(".zip")).Methods(get)
+	router.PathPrefix("/").Handler(modRM(ingester, emitter)).MatcherFunc(suffix(".rm")).Methods(post)
 
 	// metadata about this app
 	router.PathPrefix("/history").Handler(appHistory(emitter, history)).Methods(get)
 
 	// api operations
 	//
+	// e.g. GET  http://localhost:9000/v1/problems/downloads
+	// e.g. GET  http://localhost:9000/v1/problems/downloads/resolved
+	// e.g. POST http://localhost:9000/v1/problems/downloads/retry
+	// e.g. POST http://localhost:9000/v1/problems/downloads/ack
+	// e.g. GET  http://localhost:9000/v1/problems/integrity
+	// e.g. GET  http://localhost:9000/v1/gosum/github.com/example/toolkit@v1.0.0
+	router.PathPrefix("/v1/problems/downloads/resolved").Handler(newResolvedProblems(dlProblems, emitter)).Methods(get)
+	router.PathPrefix("/v1/problems/downloads/retry").Handler(newRetryDownload(bgWorker, emitter)).Methods(post)
+	router.PathPrefix("/v1/problems/downloads/ack").Handler(newAckProblem(dlProblems, emitter)).Methods(post)
 	router.PathPrefix("/v1/problems/downloads").Handler(newDownloadProblems(dlProblems, emitter)).Methods(get)
+	router.PathPrefix("/v1/problems/integrity").Handler(newIntegrityProblems(integrityProblems, emitter)).Methods(get)
+	router.PathPrefix(goSumPath).Handler(newGoSum(index, emitter)).Methods(get)
 
 	// default behavior (404)
 	router.PathPrefix("/").HandlerFunc(notFound(emitter))
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http"
+
+	"gophers.dev/pkgs/loggy"
+
+	"oss.indeed.com/go/modprox/pkg/metrics/stats"
+	"oss.indeed.com/go/modprox/proxy/internal/sumdb"
+)
+
+type sumDB struct {
+	prefix  string
+	server  http.Handler
+	emitter stats.Sender
+	log     loggy.Logger
+}
+
+// The go command asks a proxy whether it can proxy a checksum database
+// (by the name in GOSUMDB) before using it to access that database.
+// Since this proxy only serves its own checksum database, that is the
+// only name for which it answers.
+func newSumDB(sumLog *sumdb.Log, emitter stats.Sender) http.Handler {
+	prefix := "/sumdb/" + sumLog.Name()
+	return &sumDB{
+		prefix: prefix,
+		// the checksum database server expects paths relative to its root
+		server:  http.StripPrefix(prefix, sumLog.Handler()),
+		emitter: emitter,
+		log:     loggy.New("sumdb"),
+	}
+}
+
+// e.g. GET http://localhost:9000/sumdb/sumdb.example.com/supported
+// e.g. GET http://localhost:9000/sumdb/sumdb.example.com/latest
+// e.g. GET http://localhost:9000/sumdb/sumdb.example.com/lookup/github.com/example/toolkit@v1.0.0
+// e.g. GET http://localhost:9000/sumdb/sumdb.example.com/tile/8/0/000
+
+func (h *sumDB) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.log.Tracef("serving request for checksum database %s", r.URL.Path)
+
+	if r.URL.Path == h.prefix+"/supported" {
+		w.WriteHeader(http.StatusOK)
+		h.emitter.Count("sumdb-supported", 1)
+		return
+	}
+
+	h.server.ServeHTTP(w, r)
+	h.emitter.Count("sumdb-request", 1)
+}
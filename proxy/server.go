@@ -9,3 +9,27 @@ import (
 func Start(configuration config.Configuration) {
 	service.NewProxy(configuration).Run()
 }
+
+// Migrate copies every module from the storage of one Configuration into
+// the storage of another. A Migrate which fails part way may be run again,
+// and continues with whatever was not copied yet.
+func Migrate(from, to config.Configuration) error {
+	return service.Migrate(from, to)
+}
+
+// Export writes the modules stored by a proxy into a bundle file, either
+// every module or only those currently listed by the registry.
+func Export(configuration config.Configuration, filename string, registered bool) error {
+	return service.Export(configuration, filename, registered)
+}
+
+// Import adds the modules of a bundle file to the storage of a proxy.
+func Import(configuration config.Configuration, filename string) error {
+	return service.Import(configuration, filename)
+}
+
+// Fsck checks the consistency of the index and zips of a proxy, repairing
+// what can be repaired if repair is set.
+func Fsck(configuration config.Configuration, repair bool) error {
+	return service.Fsck(configuration, repair)
+}
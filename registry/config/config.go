@@ -19,6 +19,7 @@ type Configuration struct {
 	Statsd      stats.Statsd          `json:"statsd"`
 	Proxies     Proxies               `json:"proxies"`
 	ProxyClient ProxyClient           `json:"proxy_client"`
+	Resolver    Resolver              `json:"resolver"`
 }
 
 type ProxyClient struct {
@@ -26,6 +27,17 @@ type ProxyClient struct {
 	BaseURL  string `json:"base_url"` // e.g. "proxy.golang.org"
 }
 
+// Resolver configures how the branches, tags and commit hashes of registered
+// modules are resolved into versions. With Proxy set to a modprox proxy, they
+// are resolved by that proxy from the upstream source of each module. Modules
+// matching one of the Private patterns (in the same format as GOPRIVATE) are
+// never resolved by the proxy_client, which is otherwise used if there is no
+// Proxy or it failed to resolve the version.
+type Resolver struct {
+	Proxy   *ProxyClient `json:"proxy,omitempty"`
+	Private []string     `json:"private,omitempty"`
+}
+
 func (c Configuration) String() string {
 	return configutil.Format(c)
 }
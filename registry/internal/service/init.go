@@ -50,6 +50,25 @@ func initProxyClient(r *Registry) error {
 	return nil
 }
 
+func initResolver(r *Registry) error {
+	cfg := r.config.Resolver
+
+	var proxyClient zips.ProxyClient
+	if cfg.Proxy != nil {
+		r.log.Infof("resolving versions through proxy %s://%s", cfg.Proxy.Protocol, cfg.Proxy.BaseURL)
+		proxyClient = zips.NewProxyClient(
+			zips.ProxyClientOptions{
+				Protocol: cfg.Proxy.Protocol,
+				BaseURL:  cfg.Proxy.BaseURL,
+				Timeout:  1 * time.Minute,
+			},
+		)
+	}
+
+	r.resolver = web.NewResolver(proxyClient, r.proxyClient, cfg.Private)
+	return nil
+}
+
 func initStore(r *Registry) error {
 	kind, dsn, err := r.config.Database.DSN()
 	if err != nil {
@@ -102,6 +121,7 @@ func initWebServer(r *Registry) error {
 		r.emitter,
 		r.history,
 		r.proxyClient,
+		r.resolver,
 	)
 
 	server, err := r.config.WebServer.Server(mux)
@@ -9,6 +9,7 @@ import (
 	"oss.indeed.com/go/modprox/pkg/metrics/stats"
 	"oss.indeed.com/go/modprox/registry/config"
 	"oss.indeed.com/go/modprox/registry/internal/data"
+	"oss.indeed.com/go/modprox/registry/internal/web"
 )
 
 type Registry struct {
@@ -18,6 +19,7 @@ type Registry struct {
 	log         loggy.Logger
 	history     string
 	proxyClient zips.ProxyClient
+	resolver    web.Resolver
 }
 
 func NewRegistry(config config.Configuration) *Registry {
@@ -32,6 +34,7 @@ func NewRegistry(config config.Configuration) *Registry {
 		initProxyPrune,
 		initHistory,
 		initProxyClient,
+		initResolver,
 		initWebServer,
 	} {
 		if err := f(r); err != nil {
@@ -24,13 +24,14 @@ type newPage struct {
 }
 
 type newHandler struct {
-	html    *template.Template
-	store   data.Store
-	emitter stats.Sender
-	log     loggy.Logger
+	html     *template.Template
+	store    data.Store
+	resolver Resolver
+	emitter  stats.Sender
+	log      loggy.Logger
 }
 
-func newAddHandler(store data.Store, emitter stats.Sender) http.Handler {
+func newAddHandler(store data.Store, resolver Resolver, emitter stats.Sender) http.Handler {
 	html := static.MustParseTemplates(
 		"static/html/layout.html",
 		"static/html/navbar.html",
@@ -38,10 +39,11 @@ func newAddHandler(store data.Store, emitter stats.Sender) http.Handler {
 	)
 
 	return &newHandler{
-		html:    html,
-		store:   store,
-		emitter: emitter,
-		log:     loggy.New("add-modules-handler"),
+		html:     html,
+		store:    store,
+		resolver: resolver,
+		emitter:  emitter,
+		log:      loggy.New("add-modules-handler"),
 	}
 }
 
@@ -91,6 +93,7 @@ func (h *newHandler) post(r *http.Request) (int, *newPage, error) {
 	if err != nil {
 		return http.StatusBadRequest, nil, err
 	}
+	h.resolveVersions(mods)
 
 	modulesAdded, err := h.storeNewMods(mods)
 	if err != nil {
@@ -176,3 +179,17 @@ func (h *newHandler) parseLine(line string) Parsed {
 		Err:    err,
 	}
 }
+
+// resolveVersions resolves the versions of parsed modules which are a branch,
+// tag or abbreviated commit hash, as only semantic versions are registered.
+func (h *newHandler) resolveVersions(mods []Parsed) {
+	for i, parsed := range mods {
+		if parsed.Err != nil {
+			continue
+		}
+		mods[i].Module, mods[i].Err = h.resolver.Resolve(parsed.Module)
+		if mods[i].Err == nil && mods[i].Module != parsed.Module {
+			h.log.Infof("resolved %s to version %s", parsed.Module, mods[i].Module.Version)
+		}
+	}
+}
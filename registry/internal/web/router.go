@@ -26,6 +26,7 @@ func NewRouter(
 	emitter stats.Sender,
 	history string,
 	proxyClient zips.ProxyClient,
+	resolver Resolver,
 ) http.Handler {
 
 	// 1) a router onto which sub-routers will be mounted
@@ -40,10 +41,10 @@ func NewRouter(
 	})))
 
 	// 3) an API handler, not CSRF protected
-	router.Handle("/v1/", routeAPI(middleAPI, store, emitter))
+	router.Handle("/v1/", routeAPI(middleAPI, store, emitter, resolver))
 
 	// 4) a webUI handler, is CSRF protected
-	router.Handle("/", routeWebUI(middleUI, store, emitter, history, proxyClient))
+	router.Handle("/", routeWebUI(middleUI, store, emitter, history, proxyClient, resolver))
 
 	return router
 }
@@ -55,18 +56,18 @@ func routeStatics(files http.Handler) http.Handler {
 	return sub
 }
 
-func routeAPI(middles []webutil.Middleware, store data.Store, emitter stats.Sender) http.Handler {
+func routeAPI(middles []webutil.Middleware, store data.Store, emitter stats.Sender, resolver Resolver) http.Handler {
 	sub := mux.NewRouter()
 	sub.Handle("/v1/registry/sources/list", newRegistryList(store, emitter)).Methods(get, post)
-	sub.Handle("/v1/registry/sources/new", registryAdd(store, emitter)).Methods(post)
+	sub.Handle("/v1/registry/sources/new", registryAdd(store, resolver, emitter)).Methods(post)
 	sub.Handle("/v1/proxy/heartbeat", newHeartbeatHandler(store, emitter)).Methods(post)
 	sub.Handle("/v1/proxy/configuration", newStartupHandler(store, emitter)).Methods(post)
 	return webutil.Chain(sub, middles...)
 }
 
-func routeWebUI(middles []webutil.Middleware, store data.Store, emitter stats.Sender, history string, proxyClient zips.ProxyClient) http.Handler {
+func routeWebUI(middles []webutil.Middleware, store data.Store, emitter stats.Sender, history string, proxyClient zips.ProxyClient, resolver Resolver) http.Handler {
 	sub := mux.NewRouter()
-	sub.Handle("/mods/new", newAddHandler(store, emitter)).Methods(get, post)
+	sub.Handle("/mods/new", newAddHandler(store, resolver, emitter)).Methods(get, post)
 	sub.Handle("/mods/list", newModsListHandler(store, emitter)).Methods(get)
 	sub.Handle("/mods/show", newShowHandler(store, emitter)).Methods(get, post)
 	sub.Handle("/mods/find", newFindHandler(emitter, proxyClient)).Methods(get, post)
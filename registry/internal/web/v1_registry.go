@@ -13,7 +13,7 @@ import (
 	"oss.indeed.com/go/modprox/registry/internal/data"
 )
 
-func registryAdd(store data.Store, emitter stats.Sender) http.HandlerFunc {
+func registryAdd(store data.Store, resolver Resolver, emitter stats.Sender) http.HandlerFunc {
 	log := loggy.New("registry-add-api")
 
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -27,6 +27,18 @@ func registryAdd(store data.Store, emitter stats.Sender) http.HandlerFunc {
 			return
 		}
 
+		// branches, tags and commit hashes are registered as the
+		// version they are resolved into, or not at all
+		for i, mod := range wantToAdd {
+			resolved, err := resolver.Resolve(mod)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("%s: %v", mod, err), http.StatusBadRequest)
+				emitter.Count("api-addmod-unresolved", 1)
+				return
+			}
+			wantToAdd[i] = resolved
+		}
+
 		modulesAdded, err := store.InsertModules(wantToAdd)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
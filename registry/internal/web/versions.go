@@ -0,0 +1,115 @@
+package web
+
+import (
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"oss.indeed.com/go/modprox/pkg/clients/zips"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+// A Resolver resolves the version of a module being registered into a
+// canonical version, if it is a branch, tag or abbreviated commit hash.
+type Resolver interface {
+	Resolve(mod coordinates.Module) (coordinates.Module, error)
+}
+
+// NewResolver creates a Resolver which resolves versions through proxy, a
+// modprox proxy which resolves them from upstream sources, if it is set. Only
+// modules with a path not matching one of the private patterns (in the same
+// format as GOPRIVATE) are resolved through the public proxy instead, when
+// there is no proxy or it failed to resolve them, so that private modules are
+// never revealed to the public proxy.
+func NewResolver(proxy, public zips.ProxyClient, private []string) Resolver {
+	return &resolver{
+		proxy:   proxy,
+		public:  public,
+		private: private,
+	}
+}
+
+type resolver struct {
+	proxy   zips.ProxyClient // may be nil
+	public  zips.ProxyClient
+	private []string
+}
+
+func (r *resolver) Resolve(mod coordinates.Module) (coordinates.Module, error) {
+	if !repository.IsQuery(mod.Version) {
+		return mod, nil
+	}
+
+	isPrivate := matchesPrivate(r.private, mod.Source)
+
+	if r.proxy != nil {
+		resolved, err := resolveVersion(r.proxy, mod)
+		if err == nil || isPrivate {
+			return resolved, err
+		}
+	} else if isPrivate {
+		return mod, errors.Errorf("unable to resolve version %q of private module without a proxy", mod.Version)
+	}
+
+	return resolveVersion(r.public, mod)
+}
+
+// matchesPrivate returns whether the module path, or any of its prefixes,
+// matches one of the glob patterns, the same as the go command does for the
+// patterns of GOPRIVATE.
+func matchesPrivate(patterns []string, modPath string) bool {
+	for _, pattern := range patterns {
+		pattern = strings.TrimSuffix(strings.TrimSpace(pattern), "/")
+		if pattern == "" {
+			continue
+		}
+
+		// compare the pattern with as many leading elements of the path
+		n := strings.Count(pattern, "/")
+		prefix := modPath
+		for i := 0; i < len(modPath); i++ {
+			if modPath[i] == '/' {
+				if n == 0 {
+					prefix = modPath[:i]
+					break
+				}
+				n--
+			}
+		}
+		if n > 0 {
+			continue
+		}
+
+		if matched, _ := path.Match(pattern, prefix); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveVersion returns mod with its version resolved into a canonical
+// version, if it is a branch, tag or abbreviated commit hash rather than a
+// semantic version. The version is resolved by the proxy of proxyClient, the
+// same as it is for go get, e.g. master may be resolved into
+// v0.0.0-20200102030405-abcdefabcdef.
+func resolveVersion(proxyClient zips.ProxyClient, mod coordinates.Module) (coordinates.Module, error) {
+	if !repository.IsQuery(mod.Version) {
+		return mod, nil
+	}
+
+	info, err := proxyClient.Info(mod)
+	if err != nil {
+		return mod, errors.Wrapf(err, "unable to resolve version %q", mod.Version)
+	}
+
+	if repository.IsQuery(info.Version) {
+		return mod, errors.Errorf("version %q resolved into invalid version %q", mod.Version, info.Version)
+	}
+
+	return coordinates.Module{
+		Source:  mod.Source,
+		Version: info.Version,
+	}, nil
+}
@@ -0,0 +1,150 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+
+	"oss.indeed.com/go/modprox/pkg/clients/zips"
+	"oss.indeed.com/go/modprox/pkg/coordinates"
+	"oss.indeed.com/go/modprox/pkg/repository"
+)
+
+func Test_resolveVersion(t *testing.T) {
+	proxyClient := zips.NewProxyClientMock(t)
+	defer proxyClient.MinimockFinish()
+
+	branch := coordinates.Module{Source: "github.com/foo/bar", Version: "master"}
+	proxyClient.InfoMock.When(branch).Then(repository.RevInfo{
+		Version: "v1.2.4-0.20180111040409-fbec762f837d",
+	}, nil)
+
+	mod, err := resolveVersion(proxyClient, branch)
+	require.NoError(t, err)
+	require.Equal(t, coordinates.Module{
+		Source:  "github.com/foo/bar",
+		Version: "v1.2.4-0.20180111040409-fbec762f837d",
+	}, mod)
+}
+
+func Test_resolveVersion_semver(t *testing.T) {
+	proxyClient := zips.NewProxyClientMock(t)
+	defer proxyClient.MinimockFinish()
+
+	// not asked, as there is nothing to resolve
+	for _, version := range []string{
+		"v1.2.3",
+		"v2.0.0+incompatible",
+		"v0.0.0-20180111040409-fbec762f837d",
+	} {
+		mod := coordinates.Module{Source: "github.com/foo/bar", Version: version}
+		resolved, err := resolveVersion(proxyClient, mod)
+		require.NoError(t, err)
+		require.Equal(t, mod, resolved)
+	}
+}
+
+func Test_resolveVersion_failure(t *testing.T) {
+	proxyClient := zips.NewProxyClientMock(t)
+	defer proxyClient.MinimockFinish()
+
+	nope := coordinates.Module{Source: "github.com/foo/bar", Version: "nope"}
+	proxyClient.InfoMock.When(nope).Then(repository.RevInfo{}, errors.New("not found"))
+
+	_, err := resolveVersion(proxyClient, nope)
+	require.EqualError(t, err, `unable to resolve version "nope": not found`)
+}
+
+func Test_resolveVersion_invalid(t *testing.T) {
+	proxyClient := zips.NewProxyClientMock(t)
+	defer proxyClient.MinimockFinish()
+
+	// a proxy must never resolve into something which is not a version
+	nope := coordinates.Module{Source: "github.com/foo/bar", Version: "nope"}
+	proxyClient.InfoMock.When(nope).Then(repository.RevInfo{Version: "nope"}, nil)
+
+	_, err := resolveVersion(proxyClient, nope)
+	require.EqualError(t, err, `version "nope" resolved into invalid version "nope"`)
+}
+
+func Test_Resolver_proxy(t *testing.T) {
+	proxy := zips.NewProxyClientMock(t)
+	defer proxy.MinimockFinish()
+
+	public := zips.NewProxyClientMock(t)
+	defer public.MinimockFinish()
+
+	branch := coordinates.Module{Source: "example.com/private/lib", Version: "master"}
+	proxy.InfoMock.When(branch).Then(repository.RevInfo{
+		Version: "v0.0.0-20180111040409-fbec762f837d",
+	}, nil)
+
+	resolver := NewResolver(proxy, public, []string{"example.com/private"})
+	mod, err := resolver.Resolve(branch)
+	require.NoError(t, err)
+	require.Equal(t, "v0.0.0-20180111040409-fbec762f837d", mod.Version)
+}
+
+func Test_Resolver_private(t *testing.T) {
+	proxy := zips.NewProxyClientMock(t)
+	defer proxy.MinimockFinish()
+
+	public := zips.NewProxyClientMock(t)
+	defer public.MinimockFinish()
+
+	// private modules are never sent to the public proxy
+	branch := coordinates.Module{Source: "example.com/private/lib", Version: "master"}
+	proxy.InfoMock.When(branch).Then(repository.RevInfo{}, errors.New("not found"))
+
+	resolver := NewResolver(proxy, public, []string{"*.corp.example.com", "example.com/private"})
+	_, err := resolver.Resolve(branch)
+	require.EqualError(t, err, `unable to resolve version "master": not found`)
+
+	resolver = NewResolver(nil, public, []string{"example.com/private"})
+	_, err = resolver.Resolve(branch)
+	require.EqualError(t, err, `unable to resolve version "master" of private module without a proxy`)
+}
+
+func Test_Resolver_public(t *testing.T) {
+	proxy := zips.NewProxyClientMock(t)
+	defer proxy.MinimockFinish()
+
+	public := zips.NewProxyClientMock(t)
+	defer public.MinimockFinish()
+
+	// public modules fall back to the public proxy
+	branch := coordinates.Module{Source: "github.com/foo/bar", Version: "master"}
+	proxy.InfoMock.When(branch).Then(repository.RevInfo{}, errors.New("not found"))
+	public.InfoMock.When(branch).Then(repository.RevInfo{
+		Version: "v1.2.4-0.20180111040409-fbec762f837d",
+	}, nil)
+
+	resolver := NewResolver(proxy, public, []string{"example.com/private"})
+	mod, err := resolver.Resolve(branch)
+	require.NoError(t, err)
+	require.Equal(t, "v1.2.4-0.20180111040409-fbec762f837d", mod.Version)
+}
+
+func Test_matchesPrivate(t *testing.T) {
+	patterns := []string{"*.corp.example.com", "example.com/private", "github.com/org/*-internal"}
+
+	for _, modPath := range []string{
+		"git.corp.example.com/lib",
+		"example.com/private",
+		"example.com/private/lib",
+		"github.com/org/tools-internal",
+		"github.com/org/tools-internal/v2",
+	} {
+		require.True(t, matchesPrivate(patterns, modPath), modPath)
+	}
+
+	for _, modPath := range []string{
+		"corp.example.com/lib",
+		"example.com",
+		"example.com/privately",
+		"github.com/org/tools",
+	} {
+		require.False(t, matchesPrivate(patterns, modPath), modPath)
+	}
+}